@@ -0,0 +1,60 @@
+// Command logdecode converts the binary logs written by the CBOR and MSGPACK
+// formatters into JSON lines, or logfmt lines with -text, for humans.
+//
+// Usage:
+//
+//	logdecode [-format CBOR|MSGPACK] [-text] [file ...]
+//
+// The files are read in turn, or the standard input when there is none. The
+// format of each entry is detected unless -format is given.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/americanas-go/log/decode"
+)
+
+func main() {
+	format := flag.String("format", "", "format of the logs, CBOR or MSGPACK, detected when empty")
+	text := flag.Bool("text", false, "write logfmt lines instead of JSON lines")
+	flag.Parse()
+
+	convert := decode.JSON
+	if *text {
+		convert = decode.Text
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	err := run(w, flag.Args(), strings.ToUpper(*format), convert)
+	if ferr := w.Flush(); err == nil {
+		err = ferr
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "logdecode:", err)
+		os.Exit(1)
+	}
+}
+
+func run(w io.Writer, paths []string, format string, convert func(io.Writer, io.Reader, string) error) error {
+	if len(paths) == 0 {
+		return convert(w, os.Stdin, format)
+	}
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		err = convert(w, f, format)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
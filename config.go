@@ -0,0 +1,335 @@
+package log
+
+import (
+	"os"
+	"time"
+)
+
+// Config is a backend independent logger configuration.
+//
+// The backend is selected by name among the registered ones, so switching the
+// logging library is a matter of changing Backend. Start from DefaultConfig
+// when decoding partial configurations, since the zero value of Config
+// disables every output. Empty strings and zero numbers keep the default of
+// the backend.
+type Config struct {
+	Backend        string              `json:"backend" yaml:"backend" mapstructure:"backend"`                      // registered backend name, e.g. zap, zerolog or logrus
+	ErrorFieldName string              `json:"errorFieldName" yaml:"errorFieldName" mapstructure:"errorFieldName"` // field name for error logging
+	FieldNames     FieldNamesConfig    `json:"fieldNames" yaml:"fieldNames" mapstructure:"fieldNames"`
+	PriorityFields []string            `json:"priorityFields" yaml:"priorityFields" mapstructure:"priorityFields"` // fields written first, in this order, the others being sorted by key
+	RedactedFields []string            `json:"redactedFields" yaml:"redactedFields" mapstructure:"redactedFields"` // fields whose values are written as [REDACTED]
+	Time           TimeConfig          `json:"time" yaml:"time" mapstructure:"time"`
+	Console        ConsoleConfig       `json:"console" yaml:"console" mapstructure:"console"`
+	File           FileConfig          `json:"file" yaml:"file" mapstructure:"file"`
+	Syslog         SyslogConfig        `json:"syslog" yaml:"syslog" mapstructure:"syslog"`
+	Network        NetworkConfig       `json:"network" yaml:"network" mapstructure:"network"`
+	GELF           GELFConfig          `json:"gelf" yaml:"gelf" mapstructure:"gelf"`
+	Forward        ForwardConfig       `json:"forward" yaml:"forward" mapstructure:"forward"`
+	Loki           LokiConfig          `json:"loki" yaml:"loki" mapstructure:"loki"`
+	Elasticsearch  ElasticsearchConfig `json:"elasticsearch" yaml:"elasticsearch" mapstructure:"elasticsearch"`
+	OTLP           OTLPConfig          `json:"otlp" yaml:"otlp" mapstructure:"otlp"`
+	Journald       JournaldConfig      `json:"journald" yaml:"journald" mapstructure:"journald"`
+	GCP            GCPConfig           `json:"gcp" yaml:"gcp" mapstructure:"gcp"`
+	CloudWatch     CloudWatchConfig    `json:"cloudWatch" yaml:"cloudWatch" mapstructure:"cloudWatch"`
+}
+
+// FieldNamesConfig configures the names of the fields written on every entry.
+// Empty names keep the backend default.
+type FieldNamesConfig struct {
+	Time       string `json:"time" yaml:"time" mapstructure:"time"`                   // time field name
+	Level      string `json:"level" yaml:"level" mapstructure:"level"`                // level field name
+	Message    string `json:"message" yaml:"message" mapstructure:"message"`          // message field name
+	Caller     string `json:"caller" yaml:"caller" mapstructure:"caller"`             // caller field name
+	Stacktrace string `json:"stacktrace" yaml:"stacktrace" mapstructure:"stacktrace"` // stacktrace field name
+}
+
+// TimeConfig configures the time field written on every entry.
+type TimeConfig struct {
+	Format string `json:"format" yaml:"format" mapstructure:"format"` // ISO8601/RFC3339/RFC3339NANO/EPOCH/EPOCH_MILLIS/EPOCH_NANOS or a time.Format layout
+	UTC    bool   `json:"utc" yaml:"utc" mapstructure:"utc"`          // write the time in UTC instead of the local time zone
+}
+
+// ConsoleConfig configures the console output.
+type ConsoleConfig struct {
+	Enabled    bool   `json:"enabled" yaml:"enabled" mapstructure:"enabled"`          // enable/disable console logging
+	Level      string `json:"level" yaml:"level" mapstructure:"level"`                // console log level
+	Formatter  string `json:"formatter" yaml:"formatter" mapstructure:"formatter"`    // console formatter TEXT/JSON/LOGFMT/CLOUDWATCH/ECS/GCP/CBOR/MSGPACK/AUTO/PRETTY
+	Writer     string `json:"writer" yaml:"writer" mapstructure:"writer"`             // console writer STDOUT/STDERR/SPLIT
+	SplitLevel string `json:"splitLevel" yaml:"splitLevel" mapstructure:"splitLevel"` // level from which entries go to stderr when the writer is SPLIT
+}
+
+// FileConfig configures the file output.
+type FileConfig struct {
+	Enabled         bool        `json:"enabled" yaml:"enabled" mapstructure:"enabled"`                         // enable/disable file logging
+	Level           string      `json:"level" yaml:"level" mapstructure:"level"`                               // file log level
+	Formatter       string      `json:"formatter" yaml:"formatter" mapstructure:"formatter"`                   // file formatter TEXT/JSON/LOGFMT/CLOUDWATCH/ECS/GCP/CBOR/MSGPACK/AUTO/PRETTY
+	Path            string      `json:"path" yaml:"path" mapstructure:"path"`                                  // file log path
+	Name            string      `json:"name" yaml:"name" mapstructure:"name"`                                  // file log filename
+	MaxSize         int         `json:"maxSize" yaml:"maxSize" mapstructure:"maxSize"`                         // log file max size (MB)
+	Compress        bool        `json:"compress" yaml:"compress" mapstructure:"compress"`                      // enabled/disable file compress
+	MaxAge          int         `json:"maxAge" yaml:"maxAge" mapstructure:"maxAge"`                            // file max age
+	MaxBackups      int         `json:"maxBackups" yaml:"maxBackups" mapstructure:"maxBackups"`                // file max backups, unlimited when zero
+	MaxTotalSize    int         `json:"maxTotalSize" yaml:"maxTotalSize" mapstructure:"maxTotalSize"`          // file and backups max total size (MB), unlimited when zero
+	Rotation        string      `json:"rotation" yaml:"rotation" mapstructure:"rotation"`                      // file rotation SIZE/DAILY/HOURLY, NONE to reopen the file after an external rotation
+	Pattern         string      `json:"pattern" yaml:"pattern" mapstructure:"pattern"`                         // file backup name, with time layouts between braces, after the file name and the rotation when empty
+	LocalTime       bool        `json:"localTime" yaml:"localTime" mapstructure:"localTime"`                   // name the backups and start the periods in local time instead of UTC
+	RotateOnStartup bool        `json:"rotateOnStartup" yaml:"rotateOnStartup" mapstructure:"rotateOnStartup"` // rotate the existing file when the logger is created
+	Mode            os.FileMode `json:"mode" yaml:"mode" mapstructure:"mode"`                                  // file permissions when it is created, before the umask
+	DirMode         os.FileMode `json:"dirMode" yaml:"dirMode" mapstructure:"dirMode"`                         // file missing parent directories permissions when they are created, before the umask
+	ReopenOnSIGHUP  bool        `json:"reopenOnSighup" yaml:"reopenOnSighup" mapstructure:"reopenOnSighup"`    // reopen the file on SIGHUP when its rotation is NONE
+}
+
+// SyslogConfig configures the syslog output.
+type SyslogConfig struct {
+	Enabled  bool   `json:"enabled" yaml:"enabled" mapstructure:"enabled"`    // enable/disable syslog logging
+	Level    string `json:"level" yaml:"level" mapstructure:"level"`          // syslog log level
+	Network  string `json:"network" yaml:"network" mapstructure:"network"`    // syslog network udp/tcp/unixgram/unix
+	Address  string `json:"address" yaml:"address" mapstructure:"address"`    // syslog address, host:port or socket path
+	Format   string `json:"format" yaml:"format" mapstructure:"format"`       // syslog message format RFC5424/RFC3164
+	Facility string `json:"facility" yaml:"facility" mapstructure:"facility"` // syslog facility, e.g. USER or LOCAL0
+	AppName  string `json:"appName" yaml:"appName" mapstructure:"appName"`    // application name, the executable name when empty
+	Hostname string `json:"hostname" yaml:"hostname" mapstructure:"hostname"` // host name, the system host name when empty
+	ProcID   string `json:"procId" yaml:"procId" mapstructure:"procId"`       // process id, the current process id when empty
+}
+
+// NetworkConfig configures the network output, which streams the entries as
+// JSON lines. The backoffs are read from durations such as "100ms" in YAML and
+// from nanoseconds in JSON.
+type NetworkConfig struct {
+	Enabled      bool          `json:"enabled" yaml:"enabled" mapstructure:"enabled"`                // enable/disable network logging
+	Level        string        `json:"level" yaml:"level" mapstructure:"level"`                      // network log level
+	Protocol     string        `json:"protocol" yaml:"protocol" mapstructure:"protocol"`             // network protocol tcp/tls/udp
+	Address      string        `json:"address" yaml:"address" mapstructure:"address"`                // network address, host:port
+	BufferSize   int           `json:"bufferSize" yaml:"bufferSize" mapstructure:"bufferSize"`       // entries kept in memory while disconnected
+	SpillPath    string        `json:"spillPath" yaml:"spillPath" mapstructure:"spillPath"`          // file receiving the entries overflowing the buffer
+	SpillMaxSize int           `json:"spillMaxSize" yaml:"spillMaxSize" mapstructure:"spillMaxSize"` // largest size of the spill file (MB)
+	MinBackoff   time.Duration `json:"minBackoff" yaml:"minBackoff" mapstructure:"minBackoff"`       // first delay between reconnections
+	MaxBackoff   time.Duration `json:"maxBackoff" yaml:"maxBackoff" mapstructure:"maxBackoff"`       // longest delay between reconnections
+}
+
+// GELFConfig configures the GELF output, which sends the entries to Graylog.
+type GELFConfig struct {
+	Enabled     bool   `json:"enabled" yaml:"enabled" mapstructure:"enabled"`             // enable/disable gelf logging
+	Level       string `json:"level" yaml:"level" mapstructure:"level"`                   // gelf log level
+	Protocol    string `json:"protocol" yaml:"protocol" mapstructure:"protocol"`          // gelf protocol udp/tcp
+	Address     string `json:"address" yaml:"address" mapstructure:"address"`             // gelf input address, host:port
+	Compression string `json:"compression" yaml:"compression" mapstructure:"compression"` // compression of udp messages GZIP/ZLIB/NONE
+	Host        string `json:"host" yaml:"host" mapstructure:"host"`                      // host field, the system host name when empty
+	ChunkSize   int    `json:"chunkSize" yaml:"chunkSize" mapstructure:"chunkSize"`       // largest udp datagram, in bytes
+}
+
+// ForwardConfig configures the forward output, which sends the entries to
+// Fluentd or Fluent Bit with the Forward protocol.
+type ForwardConfig struct {
+	Enabled       bool          `json:"enabled" yaml:"enabled" mapstructure:"enabled"`                   // enable/disable forward logging
+	Level         string        `json:"level" yaml:"level" mapstructure:"level"`                         // forward log level
+	Address       string        `json:"address" yaml:"address" mapstructure:"address"`                   // forward input address, host:port
+	Tag           string        `json:"tag" yaml:"tag" mapstructure:"tag"`                               // tag of the entries
+	TagField      string        `json:"tagField" yaml:"tagField" mapstructure:"tagField"`                // field holding the tag of an entry
+	Mode          string        `json:"mode" yaml:"mode" mapstructure:"mode"`                            // forward mode MESSAGE/FORWARD/PACKED_FORWARD
+	RequireAck    bool          `json:"requireAck" yaml:"requireAck" mapstructure:"requireAck"`          // wait for the acknowledgement of each message
+	AckTimeout    time.Duration `json:"ackTimeout" yaml:"ackTimeout" mapstructure:"ackTimeout"`          // longest wait for an acknowledgement
+	BatchSize     int           `json:"batchSize" yaml:"batchSize" mapstructure:"batchSize"`             // entries of a batch
+	FlushInterval time.Duration `json:"flushInterval" yaml:"flushInterval" mapstructure:"flushInterval"` // longest wait before a batch is sent
+	BufferSize    int           `json:"bufferSize" yaml:"bufferSize" mapstructure:"bufferSize"`          // entries waiting to be sent
+	MaxRetries    int           `json:"maxRetries" yaml:"maxRetries" mapstructure:"maxRetries"`          // retries of a message not sent or not acknowledged
+}
+
+// LokiConfig configures the loki output, which pushes the entries to Grafana
+// Loki.
+type LokiConfig struct {
+	Enabled     bool              `json:"enabled" yaml:"enabled" mapstructure:"enabled"`             // enable/disable loki logging
+	Level       string            `json:"level" yaml:"level" mapstructure:"level"`                   // loki log level
+	URL         string            `json:"url" yaml:"url" mapstructure:"url"`                         // push endpoint
+	TenantID    string            `json:"tenantID" yaml:"tenantID" mapstructure:"tenantID"`          // tenant of the entries, sent in the X-Scope-OrgID header
+	Labels      map[string]string `json:"labels" yaml:"labels" mapstructure:"labels"`                // static labels of every stream
+	LabelFields []string          `json:"labelFields" yaml:"labelFields" mapstructure:"labelFields"` // fields promoted to stream labels
+	MaxStreams  int               `json:"maxStreams" yaml:"maxStreams" mapstructure:"maxStreams"`    // most streams opened by the promoted fields
+	Encoding    string            `json:"encoding" yaml:"encoding" mapstructure:"encoding"`          // push encoding JSON/PROTOBUF
+	Gzip        bool              `json:"gzip" yaml:"gzip" mapstructure:"gzip"`                      // gzip the JSON pushes
+	BatchSize   int               `json:"batchSize" yaml:"batchSize" mapstructure:"batchSize"`       // entries of a batch
+	BatchWait   time.Duration     `json:"batchWait" yaml:"batchWait" mapstructure:"batchWait"`       // longest wait before a batch is sent
+	BufferSize  int               `json:"bufferSize" yaml:"bufferSize" mapstructure:"bufferSize"`    // entries waiting to be sent
+	Timeout     time.Duration     `json:"timeout" yaml:"timeout" mapstructure:"timeout"`             // timeout of a push
+	MaxRetries  int               `json:"maxRetries" yaml:"maxRetries" mapstructure:"maxRetries"`    // retries of a push rejected with 429 or 5xx
+}
+
+// ElasticsearchConfig configures the elasticsearch output, which indexes the
+// entries into Elasticsearch or OpenSearch with the _bulk API.
+type ElasticsearchConfig struct {
+	Enabled    bool          `json:"enabled" yaml:"enabled" mapstructure:"enabled"`          // enable/disable elasticsearch logging
+	Level      string        `json:"level" yaml:"level" mapstructure:"level"`                // elasticsearch log level
+	URL        string        `json:"url" yaml:"url" mapstructure:"url"`                      // base URL of the cluster
+	Index      string        `json:"index" yaml:"index" mapstructure:"index"`                // index name template, such as app-logs-{2006.01.02}
+	Username   string        `json:"username" yaml:"username" mapstructure:"username"`       // user of the basic authentication
+	Password   string        `json:"password" yaml:"password" mapstructure:"password"`       // password of the basic authentication
+	APIKey     string        `json:"apiKey" yaml:"apiKey" mapstructure:"apiKey"`             // encoded API key, instead of the basic authentication
+	ECS        bool          `json:"ecs" yaml:"ecs" mapstructure:"ecs"`                      // name the time, message, level and caller after the Elastic Common Schema
+	BatchSize  int           `json:"batchSize" yaml:"batchSize" mapstructure:"batchSize"`    // entries of a batch
+	BatchAge   time.Duration `json:"batchAge" yaml:"batchAge" mapstructure:"batchAge"`       // longest wait before a batch is sent
+	BufferSize int           `json:"bufferSize" yaml:"bufferSize" mapstructure:"bufferSize"` // entries waiting to be sent
+	Timeout    time.Duration `json:"timeout" yaml:"timeout" mapstructure:"timeout"`          // timeout of a bulk request
+	MaxRetries int           `json:"maxRetries" yaml:"maxRetries" mapstructure:"maxRetries"` // retries of a bulk request or item rejected with 429 or 5xx
+}
+
+// OTLPConfig configures the otlp output, which exports the entries as
+// OpenTelemetry log records over OTLP/HTTP or OTLP/gRPC.
+type OTLPConfig struct {
+	Enabled            bool              `json:"enabled" yaml:"enabled" mapstructure:"enabled"`                                  // enable/disable otlp logging
+	Level              string            `json:"level" yaml:"level" mapstructure:"level"`                                        // otlp log level
+	Protocol           string            `json:"protocol" yaml:"protocol" mapstructure:"protocol"`                               // export protocol HTTP/GRPC
+	Endpoint           string            `json:"endpoint" yaml:"endpoint" mapstructure:"endpoint"`                               // URL of the collector, the default of the protocol when empty
+	Headers            map[string]string `json:"headers" yaml:"headers" mapstructure:"headers"`                                  // headers of the exports, such as an authorization
+	ServiceName        string            `json:"serviceName" yaml:"serviceName" mapstructure:"serviceName"`                      // service.name resource attribute
+	ServiceVersion     string            `json:"serviceVersion" yaml:"serviceVersion" mapstructure:"serviceVersion"`             // service.version resource attribute
+	ResourceAttributes map[string]string `json:"resourceAttributes" yaml:"resourceAttributes" mapstructure:"resourceAttributes"` // other resource attributes
+	TraceIDField       string            `json:"traceIDField" yaml:"traceIDField" mapstructure:"traceIDField"`                   // field holding the hex trace id
+	SpanIDField        string            `json:"spanIDField" yaml:"spanIDField" mapstructure:"spanIDField"`                      // field holding the hex span id
+	BatchSize          int               `json:"batchSize" yaml:"batchSize" mapstructure:"batchSize"`                            // records of a batch
+	BatchTimeout       time.Duration     `json:"batchTimeout" yaml:"batchTimeout" mapstructure:"batchTimeout"`                   // longest wait before a batch is exported
+	BufferSize         int               `json:"bufferSize" yaml:"bufferSize" mapstructure:"bufferSize"`                         // records waiting to be exported
+	Timeout            time.Duration     `json:"timeout" yaml:"timeout" mapstructure:"timeout"`                                  // timeout of an export
+	MaxRetries         int               `json:"maxRetries" yaml:"maxRetries" mapstructure:"maxRetries"`                         // retries of an export failed with a retryable status
+}
+
+// JournaldConfig configures the journald output, which writes the entries to
+// the systemd journal with its native protocol.
+type JournaldConfig struct {
+	Enabled    bool   `json:"enabled" yaml:"enabled" mapstructure:"enabled"`          // enable/disable journald logging
+	Level      string `json:"level" yaml:"level" mapstructure:"level"`                // journald log level
+	Socket     string `json:"socket" yaml:"socket" mapstructure:"socket"`             // path of the journald socket
+	Identifier string `json:"identifier" yaml:"identifier" mapstructure:"identifier"` // SYSLOG_IDENTIFIER of the entries
+}
+
+// GCPConfig configures the GCP formatter, which writes the structured JSON of
+// Google Cloud Logging.
+type GCPConfig struct {
+	ProjectID        string `json:"projectID" yaml:"projectID" mapstructure:"projectID"`                      // project of the traces, the trace id is written as is when empty
+	TraceIDField     string `json:"traceIDField" yaml:"traceIDField" mapstructure:"traceIDField"`             // field holding the trace id
+	SpanIDField      string `json:"spanIDField" yaml:"spanIDField" mapstructure:"spanIDField"`                // field holding the span id
+	HTTPRequestField string `json:"httpRequestField" yaml:"httpRequestField" mapstructure:"httpRequestField"` // field holding the HTTP request
+}
+
+// CloudWatchConfig configures the CLOUDWATCH formatter, which writes the
+// "Key: value" lines of the CloudWatch Logs formatter of logrus.
+type CloudWatchConfig struct {
+	PrefixFields     []string `json:"prefixFields" yaml:"prefixFields" mapstructure:"prefixFields"`             // fields written before the message, in this order
+	DisableSorting   bool     `json:"disableSorting" yaml:"disableSorting" mapstructure:"disableSorting"`       // write the other fields unsorted
+	QuoteEmptyFields bool     `json:"quoteEmptyFields" yaml:"quoteEmptyFields" mapstructure:"quoteEmptyFields"` // quote empty values
+}
+
+// DefaultConfig returns a Config with the console output enabled at INFO level
+// on stdout and every other output disabled, matching the defaults of every
+// backend.
+func DefaultConfig() *Config {
+	return &Config{
+		ErrorFieldName: "err",
+		Console: ConsoleConfig{
+			Enabled:    true,
+			Level:      "INFO",
+			Formatter:  "TEXT",
+			Writer:     "STDOUT",
+			SplitLevel: "WARN",
+		},
+		File: FileConfig{
+			Enabled:   false,
+			Level:     "INFO",
+			Formatter: "TEXT",
+			Path:      "/tmp",
+			Name:      "application.log",
+			MaxSize:   100,
+			Compress:  true,
+			MaxAge:    28,
+			Rotation:  "SIZE",
+			Mode:      0o600,
+			DirMode:   0o755,
+		},
+		Syslog: SyslogConfig{
+			Enabled:  false,
+			Level:    "INFO",
+			Network:  "udp",
+			Address:  "localhost:514",
+			Format:   "RFC5424",
+			Facility: "USER",
+		},
+		Network: NetworkConfig{
+			Enabled:    false,
+			Level:      "INFO",
+			Protocol:   "tcp",
+			Address:    "localhost:5170",
+			BufferSize: 1024,
+			MinBackoff: 100 * time.Millisecond,
+			MaxBackoff: 30 * time.Second,
+		},
+		GELF: GELFConfig{
+			Enabled:     false,
+			Level:       "INFO",
+			Protocol:    "udp",
+			Address:     "localhost:12201",
+			Compression: "GZIP",
+			ChunkSize:   1420,
+		},
+		Forward: ForwardConfig{
+			Enabled:       false,
+			Level:         "INFO",
+			Address:       "localhost:24224",
+			Tag:           "app",
+			Mode:          "FORWARD",
+			AckTimeout:    5 * time.Second,
+			BatchSize:     100,
+			FlushInterval: time.Second,
+			BufferSize:    1024,
+			MaxRetries:    10,
+		},
+		Loki: LokiConfig{
+			Enabled:    false,
+			Level:      "INFO",
+			URL:        "http://localhost:3100/loki/api/v1/push",
+			MaxStreams: 100,
+			Encoding:   "JSON",
+			BatchSize:  100,
+			BatchWait:  time.Second,
+			BufferSize: 1024,
+			Timeout:    10 * time.Second,
+			MaxRetries: 10,
+		},
+		Elasticsearch: ElasticsearchConfig{
+			Enabled:    false,
+			Level:      "INFO",
+			URL:        "http://localhost:9200",
+			Index:      "logs-{2006.01.02}",
+			ECS:        true,
+			BatchSize:  100,
+			BatchAge:   time.Second,
+			BufferSize: 1024,
+			Timeout:    10 * time.Second,
+			MaxRetries: 5,
+		},
+		OTLP: OTLPConfig{
+			Enabled:      false,
+			Level:        "INFO",
+			Protocol:     "HTTP",
+			TraceIDField: "trace_id",
+			SpanIDField:  "span_id",
+			BatchSize:    512,
+			BatchTimeout: time.Second,
+			BufferSize:   2048,
+			Timeout:      10 * time.Second,
+			MaxRetries:   5,
+		},
+		Journald: JournaldConfig{
+			Enabled: false,
+			Level:   "INFO",
+			Socket:  "/run/systemd/journal/socket",
+		},
+		GCP: GCPConfig{
+			TraceIDField:     "trace_id",
+			SpanIDField:      "span_id",
+			HTTPRequestField: "httpRequest",
+		},
+		CloudWatch: CloudWatchConfig{
+			PrefixFields:     []string{"RequestId"},
+			QuoteEmptyFields: true,
+		},
+	}
+}
@@ -3,10 +3,10 @@ package log
 import "context"
 
 func ToContext(ctx context.Context) context.Context {
-	return l.ToContext(ctx)
+	return l().ToContext(ctx)
 }
 
 // FromContext calls concrete Logger.FromContext().
 func FromContext(ctx context.Context) Logger {
-	return l.FromContext(ctx)
+	return l().FromContext(ctx)
 }
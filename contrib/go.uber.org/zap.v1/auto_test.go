@@ -0,0 +1,77 @@
+package zap
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type AutoSuite struct {
+	suite.Suite
+}
+
+func TestAutoSuite(t *testing.T) {
+	suite.Run(t, new(AutoSuite))
+}
+
+func (s *AutoSuite) SetupTest() {
+	for _, key := range []string{"NO_COLOR", "FORCE_COLOR", "CLICOLOR", "CLICOLOR_FORCE"} {
+		s.T().Setenv(key, "")
+	}
+}
+
+// logFile logs a warning with an AUTO file output, returning the line.
+func (s *AutoSuite) logFile() string {
+	dir := s.T().TempDir()
+	NewLogger(
+		WithConsoleEnabled(false),
+		WithFileEnabled(true),
+		WithFilePath(dir),
+		WithFileName("app.log"),
+		WithFileFormatter("AUTO"),
+	).WithField("order", 1).Warn("order created")
+
+	data, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	s.Require().NoError(err)
+	return strings.TrimSuffix(string(data), "\n")
+}
+
+func (s *AutoSuite) TestLoggerNotTerminal() {
+	line := s.logFile()
+	s.Assert().Regexp(`^\{"level":"warn",.*"msg":"order created","order":1\}$`, line)
+}
+
+func (s *AutoSuite) TestLoggerForceColor() {
+	s.T().Setenv("FORCE_COLOR", "1")
+
+	line := s.logFile()
+	s.Assert().Regexp("^\\S+\t\x1b\\[33mwarn\x1b\\[0m\t.*order created\t\\{\"order\": 1\\}$", line)
+}
+
+func (s *AutoSuite) TestLoggerNoColor() {
+	s.T().Setenv("CLICOLOR_FORCE", "1")
+	s.T().Setenv("NO_COLOR", "1")
+
+	line := s.logFile()
+	s.Assert().Regexp("^\\S+\twarn\t.*order created\t\\{\"order\": 1\\}$", line)
+}
+
+func (s *AutoSuite) Test_getOutputEncoder() {
+	options := defaultOptions()
+	names := getFieldNames(options)
+
+	got := reflect.TypeOf(getOutputEncoder("AUTO", &bytes.Buffer{}, names, options)).String()
+	s.Assert().Equal("*zapcore.jsonEncoder", got)
+
+	got = reflect.TypeOf(getOutputEncoder("LOGFMT", &bytes.Buffer{}, names, options)).String()
+	s.Assert().Equal("*zap.mapEncoder", got)
+
+	s.T().Setenv("FORCE_COLOR", "1")
+	got = reflect.TypeOf(getOutputEncoder("AUTO", &bytes.Buffer{}, names, options)).String()
+	s.Assert().Equal("zapcore.consoleEncoder", got)
+}
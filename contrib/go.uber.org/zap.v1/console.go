@@ -0,0 +1,112 @@
+package zap
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/americanas-go/log/internal/color"
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/americanas-go/log/internal/pretty"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Console writers.
+const (
+	ConsoleWriterStdout = "STDOUT" // every entry goes to stdout
+	ConsoleWriterStderr = "STDERR" // every entry goes to stderr
+	ConsoleWriterSplit  = "SPLIT"  // entries from the split level go to stderr, the others to stdout
+)
+
+// consoleSink is a console destination with the levels it accepts. out is the
+// file behind writer, telling whether the console is a terminal.
+type consoleSink struct {
+	writer  zapcore.WriteSyncer
+	out     *os.File
+	enabler zapcore.LevelEnabler
+}
+
+// getConsoleSinks returns the destinations of the console output, resolving
+// os.Stdout and os.Stderr when called so that they can be redirected.
+func getConsoleSinks(options *Options) []consoleSink {
+	level := logLevel(options.Console.Level)
+
+	switch options.Console.Writer {
+	case ConsoleWriterStderr:
+		return []consoleSink{{writer: zapcore.Lock(os.Stderr), out: os.Stderr, enabler: level}}
+	case ConsoleWriterSplit:
+		split := logLevel(options.Console.SplitLevel)
+		return []consoleSink{
+			{
+				writer: zapcore.Lock(os.Stdout),
+				out:    os.Stdout,
+				enabler: zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+					return level.Enabled(l) && l < split
+				}),
+			},
+			{
+				writer: zapcore.Lock(os.Stderr),
+				out:    os.Stderr,
+				enabler: zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+					return level.Enabled(l) && l >= split
+				}),
+			},
+		}
+	default:
+		return []consoleSink{{writer: zapcore.Lock(os.Stdout), out: os.Stdout, enabler: level}}
+	}
+}
+
+// getOutputEncoder returns the encoder of format for an output writing to w,
+// resolving AUTO to text, colored by level, when w is a terminal and to JSON
+// otherwise, as decided by color.Auto. PRETTY is colored when w is a terminal.
+func getOutputEncoder(format string, w io.Writer, names fieldNames, options *Options) zapcore.Encoder {
+	if format == "PRETTY" {
+		return newPrettyEncoder(color.Enabled(w), names, options)
+	}
+	if format != "AUTO" {
+		return getEncoder(format, names, options)
+	}
+
+	text, colored := color.Auto(w)
+	if !text {
+		return getEncoder("JSON", names, options)
+	}
+	encoderConfig := getEncoderConfig(names, options)
+	if colored {
+		encoderConfig.EncodeLevel = colorLevelEncoder
+	}
+	return zapcore.NewConsoleEncoder(encoderConfig)
+}
+
+// colorLevelEncoder writes the level in lower case, in the color of the level
+// shared by the backends.
+func colorLevelEncoder(level zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendString(color.Level(entryLevel(level), level.String()))
+}
+
+// newPrettyEncoder returns an encoder writing the entries for the humans
+// reading a console, their times relative to the creation of the encoder.
+func newPrettyEncoder(colored bool, names fieldNames, options *Options) zapcore.Encoder {
+	start := time.Now()
+	if options.Time.Clock != nil {
+		start = options.Time.Clock()
+	}
+	prettyOptions := pretty.Options{
+		Start:    start,
+		Color:    colored,
+		ErrorKey: options.ErrorFieldName,
+		StackKey: names.Stacktrace,
+
+		PriorityKeys: options.PriorityFields,
+	}
+	return &mapEncoder{
+		MapObjectEncoder: zapcore.NewMapObjectEncoder(),
+		names:            names,
+		appendEntry: func(dst []byte, e entry.Entry) []byte {
+			return pretty.Append(dst, e, prettyOptions)
+		},
+	}
+}
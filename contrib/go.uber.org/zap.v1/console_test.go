@@ -0,0 +1,19 @@
+package zap
+
+import (
+	"testing"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/logtest"
+)
+
+func TestConsole(t *testing.T) {
+	logtest.Console(t, func(writer, splitLevel string) log.Logger {
+		return NewLogger(
+			WithConsoleLevel("DEBUG"),
+			WithConsoleFormatter("JSON"),
+			WithConsoleWriter(writer),
+			WithConsoleSplitLevel(splitLevel),
+		)
+	}, "msg", nil)
+}
@@ -0,0 +1,116 @@
+package zap
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/americanas-go/log"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap/zapcore"
+)
+
+type EcsSuite struct {
+	suite.Suite
+}
+
+func TestEcsSuite(t *testing.T) {
+	suite.Run(t, new(EcsSuite))
+}
+
+// logFile logs with a file output using formatter, returning the decoded
+// lines.
+func (s *EcsSuite) logFile(formatter string, logWith func(logger log.Logger)) []map[string]interface{} {
+	dir := s.T().TempDir()
+	logWith(NewLogger(
+		WithConsoleEnabled(false),
+		WithFileEnabled(true),
+		WithFilePath(dir),
+		WithFileName("app.log"),
+		WithFileFormatter(formatter),
+	))
+
+	data, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	s.Require().NoError(err)
+
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		var m map[string]interface{}
+		s.Require().NoError(json.Unmarshal([]byte(line), &m), line)
+		lines = append(lines, m)
+	}
+	return lines
+}
+
+func (s *EcsSuite) TestLogger() {
+	lines := s.logFile("ECS", func(logger log.Logger) {
+		logger.
+			WithFields(map[string]interface{}{"http.request.method": "GET", "trace_id": "abc"}).
+			WithError(errors.New("something bad")).
+			Error("failed")
+	})
+
+	s.Require().Len(lines, 1)
+	line := lines[0]
+	s.Assert().NotEmpty(line["@timestamp"])
+	s.Assert().Equal("error", line["log.level"])
+	s.Assert().Equal("failed", line["message"])
+	s.Assert().Equal("8.11.0", line["ecs.version"])
+	s.Assert().Equal(map[string]interface{}{"request": map[string]interface{}{"method": "GET"}}, line["http"])
+	s.Assert().Equal(map[string]interface{}{"id": "abc"}, line["trace"])
+
+	ecsError := line["error"].(map[string]interface{})
+	s.Assert().Equal("something bad", ecsError["message"])
+	s.Assert().Equal("*errors.errorString", ecsError["type"])
+
+	origin := line["log"].(map[string]interface{})["origin"].(map[string]interface{})
+	s.Assert().Equal("zap.v1/ecs_test.go", origin["file"].(map[string]interface{})["name"])
+	s.Assert().NotZero(origin["file"].(map[string]interface{})["line"])
+	s.Assert().NotEmpty(origin["function"])
+}
+
+func (s *EcsSuite) TestLoggerFields() {
+	lines := s.logFile("ECS", func(logger log.Logger) {
+		failed := logger.WithError(errors.New("something bad"))
+		s.Assert().Equal(log.Fields{"err": "something bad"}, failed.Fields())
+
+		failed.WithField("user.id", 1).Info("hello")
+		failed.WithField("err", "replaced").Info("hello")
+	})
+
+	s.Require().Len(lines, 2)
+	s.Assert().Equal(map[string]interface{}{"id": float64(1)}, lines[0]["user"])
+	s.Assert().Equal(map[string]interface{}{"message": "something bad", "type": "*errors.errorString"}, lines[0]["error"])
+	s.Assert().Equal(map[string]interface{}{"message": "replaced"}, lines[1]["error"])
+}
+
+func (s *EcsSuite) TestLoggerJSONError() {
+	lines := s.logFile("JSON", func(logger log.Logger) {
+		logger.WithError(errors.New("something bad")).Info("failed")
+	})
+
+	s.Require().Len(lines, 1)
+	s.Assert().Equal("something bad", lines[0]["err"])
+}
+
+func (s *EcsSuite) TestEncodeEntry() {
+	options := defaultOptions()
+	options.ErrorFieldName = "error"
+
+	enc := getEncoder("ECS", getFieldNames(options), options)
+	enc.AddString("a.b", "c")
+
+	buf, err := enc.Clone().EncodeEntry(zapcore.Entry{
+		Level:   zapcore.WarnLevel,
+		Time:    time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+		Message: "Blah",
+	}, []zapcore.Field{{Key: "error", Type: zapcore.ErrorType, Interface: errors.New("bad")}})
+	s.Require().NoError(err)
+
+	s.Assert().JSONEq(`{"@timestamp":"2021-01-02T03:04:05.000Z","log.level":"warn","message":"Blah","ecs.version":"8.11.0",
+		"a":{"b":"c"},"error":{"message":"bad","type":"*errors.errorString"}}`, buf.String())
+}
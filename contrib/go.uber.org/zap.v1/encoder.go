@@ -0,0 +1,197 @@
+package zap
+
+import (
+	"github.com/americanas-go/log/internal/binlog"
+	"github.com/americanas-go/log/internal/cloudwatch"
+	"github.com/americanas-go/log/internal/ecs"
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/americanas-go/log/internal/gcp"
+	"github.com/americanas-go/log/internal/logfmt"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+var mapPool = buffer.NewPool()
+
+// mapEncoder is a zapcore.Encoder gathering the fields as a map, handing them
+// with the entry to the encoding of a format, such as LOGFMT, CLOUDWATCH, ECS, GCP, CBOR or MSGPACK.
+type mapEncoder struct {
+	*zapcore.MapObjectEncoder
+	names       fieldNames
+	appendEntry func(dst []byte, e entry.Entry) []byte
+}
+
+// newLogfmtEncoder returns an encoder writing logfmt lines, whose core keys
+// are the field names, the default ones being those of logfmt. The time is
+// written as RFC3339, as by the other backends, unless another format than the
+// default one is set.
+func newLogfmtEncoder(names fieldNames, options *Options) zapcore.Encoder {
+	keys := logfmtKeys(names)
+	logfmtOptions := logfmt.Options{
+		TimeKey:    keys.Time,
+		LevelKey:   keys.Level,
+		MessageKey: keys.Message,
+		CallerKey:  keys.Caller,
+
+		PriorityKeys: options.PriorityFields,
+	}
+	if options.Time.Format != defaultTimeFormat {
+		logfmtOptions.TimeFormat = options.Time.Format
+	}
+	return &mapEncoder{
+		MapObjectEncoder: zapcore.NewMapObjectEncoder(),
+		names:            names,
+		appendEntry: func(dst []byte, e entry.Entry) []byte {
+			return logfmt.Append(dst, e, logfmtOptions)
+		},
+	}
+}
+
+// logfmtKeys returns names with the default time, level and message field
+// names of zap replaced by the default keys of logfmt, so that the LOGFMT lines
+// are the same whichever backend writes them.
+func logfmtKeys(names fieldNames) fieldNames {
+	if names.Time == defaultTimeFieldName {
+		names.Time = logfmt.DefaultTimeKey
+	}
+	if names.Level == defaultLevelFieldName {
+		names.Level = logfmt.DefaultLevelKey
+	}
+	if names.Message == defaultMessageFieldName {
+		names.Message = logfmt.DefaultMessageKey
+	}
+	return names
+}
+
+// newBinaryEncoder returns an encoder writing the entries as CBOR or
+// MessagePack maps, for format, whose core keys are those of names.
+func newBinaryEncoder(format string, names fieldNames, options *Options) zapcore.Encoder {
+	binlogOptions := binlog.Options{
+		Format:     format,
+		TimeKey:    names.Time,
+		LevelKey:   names.Level,
+		MessageKey: names.Message,
+		CallerKey:  names.Caller,
+
+		PriorityKeys: options.PriorityFields,
+	}
+	return &mapEncoder{
+		MapObjectEncoder: zapcore.NewMapObjectEncoder(),
+		names:            names,
+		appendEntry: func(dst []byte, e entry.Entry) []byte {
+			return binlog.Append(dst, e, binlogOptions)
+		},
+	}
+}
+
+// newCloudWatchEncoder returns an encoder writing the "Key: value" lines of the
+// CloudWatch Logs formatter of logrus.
+func newCloudWatchEncoder(names fieldNames, options *Options) zapcore.Encoder {
+	cwOptions := cloudwatch.Options{
+		PrefixFields:     options.CloudWatch.PrefixFields,
+		DisableSorting:   options.CloudWatch.DisableSorting,
+		QuoteEmptyFields: options.CloudWatch.QuoteEmptyFields,
+		CallerKey:        names.Caller,
+
+		PriorityKeys: options.PriorityFields,
+	}
+	return &mapEncoder{
+		MapObjectEncoder: zapcore.NewMapObjectEncoder(),
+		names:            names,
+		appendEntry: func(dst []byte, e entry.Entry) []byte {
+			return cloudwatch.Append(dst, e, cwOptions)
+		},
+	}
+}
+
+// newECSEncoder returns an encoder writing Elastic Common Schema JSON lines.
+func newECSEncoder(names fieldNames, options *Options) zapcore.Encoder {
+	ecsOptions := ecs.Options{
+		ErrorKey:      options.ErrorFieldName,
+		StacktraceKey: names.Stacktrace,
+		PriorityKeys:  options.PriorityFields,
+	}
+	return &mapEncoder{
+		MapObjectEncoder: zapcore.NewMapObjectEncoder(),
+		names:            names,
+		appendEntry: func(dst []byte, e entry.Entry) []byte {
+			return ecs.Append(dst, e, ecsOptions)
+		},
+	}
+}
+
+// newGCPEncoder returns an encoder writing the structured JSON lines of Google
+// Cloud Logging.
+func newGCPEncoder(names fieldNames, options *Options) zapcore.Encoder {
+	gcpOptions := gcp.Options{
+		ProjectID:      options.GCP.ProjectID,
+		TraceIDKey:     options.GCP.TraceIDField,
+		SpanIDKey:      options.GCP.SpanIDField,
+		HTTPRequestKey: options.GCP.HTTPRequestField,
+
+		PriorityKeys: options.PriorityFields,
+	}
+	return &mapEncoder{
+		MapObjectEncoder: zapcore.NewMapObjectEncoder(),
+		names:            names,
+		appendEntry: func(dst []byte, e entry.Entry) []byte {
+			return gcp.Append(dst, e, gcpOptions)
+		},
+	}
+}
+
+func (e *mapEncoder) Clone() zapcore.Encoder {
+	clone := &mapEncoder{MapObjectEncoder: zapcore.NewMapObjectEncoder(), names: e.names, appendEntry: e.appendEntry}
+	for k, v := range e.Fields {
+		clone.Fields[k] = copyValue(v)
+	}
+	return clone
+}
+
+func (e *mapEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	enc := e.Clone().(*mapEncoder)
+	for _, f := range fields {
+		// the error is kept as is for its type and stack trace
+		if f.Type == zapcore.ErrorType {
+			enc.Fields[f.Key] = f.Interface
+			continue
+		}
+		f.AddTo(enc)
+	}
+	unwrapErrors(enc.Fields)
+
+	if e.names.Stacktrace != "" && ent.Stack != "" {
+		enc.Fields[e.names.Stacktrace] = ent.Stack
+	}
+
+	var caller, function string
+	if e.names.Caller != "" && ent.Caller.Defined {
+		caller = ent.Caller.TrimmedPath()
+		function = ent.Caller.Function
+	}
+
+	buf := mapPool.Get()
+	_, _ = buf.Write(e.appendEntry(nil, entry.Entry{
+		Time:     ent.Time,
+		Level:    entryLevel(ent.Level),
+		Message:  ent.Message,
+		Caller:   caller,
+		Function: function,
+		Fields:   enc.Fields,
+	}))
+	return buf, nil
+}
+
+// copyValue copies the maps built by zapcore.MapObjectEncoder, which are
+// written to by the namespaces.
+func copyValue(v interface{}) interface{} {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	}
+	c := make(map[string]interface{}, len(m))
+	for k, e := range m {
+		c[k] = copyValue(e)
+	}
+	return c
+}
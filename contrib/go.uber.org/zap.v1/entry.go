@@ -0,0 +1,95 @@
+package zap
+
+import (
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/closer"
+	"github.com/americanas-go/log/internal/entry"
+	"go.uber.org/zap/zapcore"
+)
+
+// entryCore is a zapcore.Core handing the entries to an entry.Writer, for the
+// outputs which encode the entries themselves.
+type entryCore struct {
+	zapcore.LevelEnabler
+	writer entry.Writer
+	names  fieldNames
+	fields []zapcore.Field
+}
+
+func newEntryCore(writer entry.Writer, enabler zapcore.LevelEnabler, names fieldNames) zapcore.Core {
+	return &entryCore{LevelEnabler: enabler, writer: writer, names: names}
+}
+
+func (c *entryCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.fields = append(clone.fields[:len(clone.fields):len(clone.fields)], fields...)
+	return &clone
+}
+
+func (c *entryCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *entryCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range c.fields {
+		f.AddTo(enc)
+	}
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	unwrapErrors(enc.Fields)
+
+	if c.names.Stacktrace != "" && ent.Stack != "" {
+		enc.Fields[c.names.Stacktrace] = ent.Stack
+	}
+
+	var caller, function string
+	if c.names.Caller != "" && ent.Caller.Defined {
+		caller = ent.Caller.TrimmedPath()
+		function = ent.Caller.Function
+	}
+
+	err := c.writer.WriteEntry(entry.Entry{
+		Time:     ent.Time,
+		Level:    entryLevel(ent.Level),
+		Message:  ent.Message,
+		Caller:   caller,
+		Function: function,
+		Fields:   enc.Fields,
+	})
+	if ent.Level > zapcore.ErrorLevel {
+		// the program may be crashing, the buffered entries are sent first, as
+		// zap syncs its own cores
+		_ = c.Sync()
+	}
+	return err
+}
+
+// Sync sends the entries buffered by the writer, when it buffers any.
+func (c *entryCore) Sync() error {
+	if f, ok := c.writer.(closer.Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+func entryLevel(level zapcore.Level) log.Level {
+	switch level {
+	case zapcore.DebugLevel:
+		return log.DebugLevel
+	case zapcore.InfoLevel:
+		return log.InfoLevel
+	case zapcore.WarnLevel:
+		return log.WarnLevel
+	case zapcore.ErrorLevel:
+		return log.ErrorLevel
+	case zapcore.DPanicLevel, zapcore.PanicLevel:
+		return log.PanicLevel
+	default:
+		return log.FatalLevel
+	}
+}
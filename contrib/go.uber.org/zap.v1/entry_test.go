@@ -0,0 +1,345 @@
+package zap
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/americanas-go/log/internal/msgpack"
+	"github.com/americanas-go/log/internal/protobuf"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap/zapcore"
+)
+
+type EntrySuite struct {
+	suite.Suite
+}
+
+func TestEntrySuite(t *testing.T) {
+	suite.Run(t, new(EntrySuite))
+}
+
+type entryRecorder struct {
+	entries []entry.Entry
+}
+
+func (r *entryRecorder) WriteEntry(e entry.Entry) error {
+	r.entries = append(r.entries, e)
+	return nil
+}
+
+func (s *EntrySuite) TestEntryCore() {
+	at := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	recorder := &entryRecorder{}
+
+	core := newEntryCore(recorder, zapcore.InfoLevel, fieldNames{Message: "msg"})
+	logger := newSugaredLogger(withTime(core, options([]Option{WithTimeClock(func() time.Time { return at })})))
+
+	logger.With("ID", "1").Debug("debug")
+	logger.With("ID", "1").Warnw("warn", "count", 2)
+
+	s.Assert().Equal([]entry.Entry{
+		{Time: at, Level: log.WarnLevel, Message: "warn", Fields: log.Fields{"ID": "1", "count": int64(2)}},
+	}, recorder.entries)
+}
+
+func (s *EntrySuite) TestSyslog() {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	logger := NewLogger(
+		WithConsoleEnabled(false),
+		WithFieldNames("ts", "level", "msg", "", ""),
+		WithTimeClock(func() time.Time { return time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC) }),
+		WithSyslogEnabled(true),
+		WithSyslogAddress(conn.LocalAddr().String()),
+		WithSyslogFacility("LOCAL0"),
+		WithSyslogAppName("app"),
+		WithSyslogHostname("host"),
+		WithSyslogProcID("42"),
+	)
+	logger.WithField("ID", "1").Warn("blah")
+
+	buf := make([]byte, 1024)
+	s.Require().NoError(conn.SetReadDeadline(time.Now().Add(5 * time.Second)))
+	n, _, err := conn.ReadFrom(buf)
+	s.Require().NoError(err)
+	s.Assert().Equal(`<132>1 2021-01-02T03:04:05.000000Z host app 42 - [fields@32473 ID="1"] blah`, string(buf[:n]))
+}
+
+func (s *EntrySuite) TestGELF() {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	logger := NewLogger(
+		WithConsoleEnabled(false),
+		WithFieldNames("ts", "level", "msg", "", ""),
+		WithTimeClock(func() time.Time { return time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC) }),
+		WithGELFEnabled(true),
+		WithGELFLevel("WARN"),
+		WithGELFAddress(conn.LocalAddr().String()),
+		WithGELFCompression("NONE"),
+		WithGELFHost("host"),
+	)
+	logger.Info("skipped")
+	logger.WithField("ID", "1").Warn("blah")
+
+	buf := make([]byte, 1024)
+	s.Require().NoError(conn.SetReadDeadline(time.Now().Add(5 * time.Second)))
+	n, _, err := conn.ReadFrom(buf)
+	s.Require().NoError(err)
+	s.Assert().JSONEq(`{"version":"1.1","host":"host","short_message":"blah","timestamp":1609556645.000,"level":4,"_ID":"1"}`, string(buf[:n]))
+}
+
+func (s *EntrySuite) TestForward() {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+	defer ln.Close()
+
+	logger := NewLogger(
+		WithConsoleEnabled(false),
+		WithFieldNames("ts", "level", "msg", "", ""),
+		WithForwardEnabled(true),
+		WithForwardLevel("WARN"),
+		WithForwardAddress(ln.Addr().String()),
+		WithForwardTag("orders"),
+		WithForwardMode("MESSAGE"),
+	)
+	logger.Info("skipped")
+	logger.WithField("ID", "1").Warn("blah")
+
+	conn, err := ln.Accept()
+	s.Require().NoError(err)
+	defer conn.Close()
+	s.Require().NoError(conn.SetReadDeadline(time.Now().Add(5 * time.Second)))
+
+	v, err := msgpack.NewDecoder(conn).Decode()
+	s.Require().NoError(err)
+	msg := v.([]interface{})
+	s.Assert().Equal("orders", msg[0])
+	s.Assert().Equal(map[string]interface{}{"ID": "1", "level": "WARN", "msg": "blah"}, msg[2])
+}
+
+func (s *EntrySuite) TestLoki() {
+	bodies := make(chan []byte, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- body
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	logger := NewLogger(
+		WithConsoleEnabled(false),
+		WithFieldNames("ts", "level", "msg", "", ""),
+		WithLokiEnabled(true),
+		WithLokiLevel("WARN"),
+		WithLokiURL(srv.URL+"/loki/api/v1/push"),
+		WithLokiLabels(map[string]string{"app": "orders"}),
+		WithLokiLabelFields("service"),
+		WithLokiBatch(1, time.Second),
+	)
+	logger.Info("skipped")
+	logger.WithFields(log.Fields{"ID": "1", "service": "api"}).Warn("blah")
+
+	var request struct {
+		Streams []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		} `json:"streams"`
+	}
+	select {
+	case body := <-bodies:
+		s.Require().NoError(json.Unmarshal(body, &request))
+	case <-time.After(5 * time.Second):
+		s.FailNow("no push received")
+	}
+	s.Require().Len(request.Streams, 1)
+	s.Assert().Equal(map[string]string{"app": "orders", "service": "api"}, request.Streams[0].Stream)
+	s.Require().Len(request.Streams[0].Values, 1)
+	s.Assert().JSONEq(`{"ID":"1","level":"WARN","msg":"blah"}`, request.Streams[0].Values[0][1])
+}
+
+func (s *EntrySuite) TestElasticsearch() {
+	bodies := make(chan []byte, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- body
+		_, _ = rw.Write([]byte(`{"errors":false,"items":[{"create":{"status":201}}]}`))
+	}))
+	defer srv.Close()
+
+	logger := NewLogger(
+		WithConsoleEnabled(false),
+		WithTimeClock(func() time.Time { return time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC) }),
+		WithElasticsearchEnabled(true),
+		WithElasticsearchLevel("WARN"),
+		WithElasticsearchURL(srv.URL),
+		WithElasticsearchIndex("app-logs-{2006.01.02}"),
+		WithElasticsearchBatch(1, time.Second),
+	)
+	logger.Info("skipped")
+	logger.WithField("ID", "1").Warn("blah")
+
+	var body []byte
+	select {
+	case body = <-bodies:
+	case <-time.After(5 * time.Second):
+		s.FailNow("no bulk request received")
+	}
+	lines := strings.Split(strings.TrimSuffix(string(body), "\n"), "\n")
+	s.Require().Len(lines, 2)
+	s.Assert().JSONEq(`{"create":{"_index":"app-logs-2021.01.02"}}`, lines[0])
+
+	var doc map[string]interface{}
+	s.Require().NoError(json.Unmarshal([]byte(lines[1]), &doc))
+	s.Assert().Equal("blah", doc["message"])
+	s.Assert().Equal("warn", doc["log.level"])
+	s.Assert().Equal("1", doc["ID"])
+	s.Assert().Equal("2021-01-02T03:04:05Z", doc["@timestamp"])
+}
+
+func (s *EntrySuite) TestOTLP() {
+	bodies := make(chan []byte, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- body
+	}))
+	defer srv.Close()
+
+	logger := NewLogger(
+		WithConsoleEnabled(false),
+		WithOTLPEnabled(true),
+		WithOTLPLevel("WARN"),
+		WithOTLPEndpoint(srv.URL+"/v1/logs"),
+		WithOTLPService("orders", "1.2.3"),
+		WithOTLPBatch(1, time.Second),
+	)
+	logger.Info("skipped")
+	logger.WithFields(log.Fields{"trace_id": "4bf92f3577b34da6a3ce929d0e0e4736", "ID": "1"}).Warn("blah")
+
+	var body []byte
+	select {
+	case body = <-bodies:
+	case <-time.After(5 * time.Second):
+		s.FailNow("no export received")
+	}
+
+	// resource_logs > scope_logs > log_records
+	fields := s.parseProtobuf(body)
+	fields = s.parseProtobuf(fields[0].Bytes)
+	s.Assert().Contains(string(fields[0].Bytes), "orders")
+	fields = s.parseProtobuf(fields[1].Bytes)
+	s.Require().Len(fields, 2)
+
+	record := map[int]protobuf.Field{}
+	var attributes []byte
+	for _, f := range s.parseProtobuf(fields[1].Bytes) {
+		record[f.Number] = f
+		if f.Number == 6 {
+			attributes = append(attributes, f.Bytes...)
+		}
+	}
+	s.Assert().Equal(uint64(13), record[2].Varint)
+	s.Assert().Equal("WARN", string(record[3].Bytes))
+	s.Assert().Contains(string(record[5].Bytes), "blah")
+	s.Assert().Contains(string(attributes), "ID")
+	s.Assert().Len(record[9].Bytes, 16)
+}
+
+func (s *EntrySuite) TestOTLPSpanContext() {
+	bodies := make(chan []byte, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- body
+	}))
+	defer srv.Close()
+
+	logger := NewLogger(
+		WithConsoleEnabled(false),
+		WithOTLPEnabled(true),
+		WithOTLPEndpoint(srv.URL+"/v1/logs"),
+		WithOTLPBatch(1, time.Second),
+		WithOTLPSpanContext(func(ctx context.Context) (string, string) {
+			ids, _ := ctx.Value(spanKey{}).([2]string)
+			return ids[0], ids[1]
+		}),
+	)
+	ctx := context.WithValue(context.Background(), spanKey{}, [2]string{"4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"})
+	logger.FromContext(ctx).Info("blah")
+
+	var body []byte
+	select {
+	case body = <-bodies:
+	case <-time.After(5 * time.Second):
+		s.FailNow("no export received")
+	}
+
+	// resource_logs > scope_logs > log_records
+	fields := s.parseProtobuf(body)
+	fields = s.parseProtobuf(fields[0].Bytes)
+	fields = s.parseProtobuf(fields[1].Bytes)
+	record := map[int]protobuf.Field{}
+	for _, f := range s.parseProtobuf(fields[1].Bytes) {
+		record[f.Number] = f
+	}
+	s.Assert().Equal("4bf92f3577b34da6a3ce929d0e0e4736", hex.EncodeToString(record[9].Bytes))
+	s.Assert().Equal("00f067aa0ba902b7", hex.EncodeToString(record[10].Bytes))
+}
+
+// spanKey is the context key of the span ids of TestOTLPSpanContext.
+type spanKey struct{}
+
+func (s *EntrySuite) TestJournald() {
+	dir, err := os.MkdirTemp("", "journald")
+	s.Require().NoError(err)
+	defer os.RemoveAll(dir)
+
+	socket := filepath.Join(dir, "socket")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	logger := NewLogger(
+		WithConsoleEnabled(false),
+		WithFieldNames("ts", "level", "msg", "caller", ""),
+		WithJournaldEnabled(true),
+		WithJournaldLevel("WARN"),
+		WithJournaldSocket(socket),
+		WithJournaldIdentifier("orders"),
+	)
+	logger.Info("skipped")
+	logger.WithField("request.id", "1").Warn("blah")
+
+	buf := make([]byte, 4096)
+	s.Require().NoError(conn.SetReadDeadline(time.Now().Add(5 * time.Second)))
+	n, err := conn.Read(buf)
+	s.Require().NoError(err)
+
+	msg := string(buf[:n])
+	s.Assert().True(strings.HasPrefix(msg, "MESSAGE=blah\nPRIORITY=4\nSYSLOG_IDENTIFIER=orders\n"), msg)
+	s.Assert().Contains(msg, "\nREQUEST_ID=1\n")
+	s.Assert().Contains(msg, "\nCODE_FILE=")
+	s.Assert().Contains(msg, "\nCODE_LINE=")
+	s.Assert().Contains(msg, "\nCODE_FUNC=")
+	s.Assert().NotContains(msg, "\nCALLER=")
+}
+
+func (s *EntrySuite) parseProtobuf(b []byte) []protobuf.Field {
+	fields, err := protobuf.Parse(b)
+	s.Require().NoError(err)
+	return fields
+}
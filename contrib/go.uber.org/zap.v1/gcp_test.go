@@ -0,0 +1,70 @@
+package zap
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/americanas-go/log"
+	"github.com/stretchr/testify/suite"
+)
+
+type GcpSuite struct {
+	suite.Suite
+}
+
+func TestGcpSuite(t *testing.T) {
+	suite.Run(t, new(GcpSuite))
+}
+
+func (s *GcpSuite) TestLogger() {
+	dir := s.T().TempDir()
+	logger := NewLogger(
+		WithConsoleEnabled(false),
+		WithFileEnabled(true),
+		WithFilePath(dir),
+		WithFileName("app.log"),
+		WithFileFormatter("GCP"),
+		WithFileLevel("DEBUG"),
+		WithGCPProjectID("orders-prod"),
+		WithGCPTraceIDField("traceID"),
+	)
+
+	request := httptest.NewRequest("POST", "/orders", nil)
+	logger.Debug("started")
+	logger.
+		WithFields(map[string]interface{}{"traceID": "abc", "span_id": "def", "httpRequest": request}).
+		WithError(errors.New("something bad")).
+		Error("failed")
+
+	data, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	s.Require().NoError(err)
+
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		var m log.Fields
+		s.Require().NoError(json.Unmarshal([]byte(line), &m), line)
+		lines = append(lines, m)
+	}
+
+	s.Require().Len(lines, 2)
+	s.Assert().Equal("DEBUG", lines[0]["severity"])
+	s.Assert().Equal("started", lines[0]["message"])
+	s.Assert().NotEmpty(lines[0]["timestamp"])
+
+	line := lines[1]
+	s.Assert().Equal("ERROR", line["severity"])
+	s.Assert().Equal("projects/orders-prod/traces/abc", line["logging.googleapis.com/trace"])
+	s.Assert().Equal("def", line["logging.googleapis.com/spanId"])
+	s.Assert().Equal("something bad", line["err"])
+	s.Assert().Equal("POST", line["httpRequest"].(map[string]interface{})["requestMethod"])
+
+	location := line["logging.googleapis.com/sourceLocation"].(map[string]interface{})
+	s.Assert().NotEmpty(location["file"])
+	s.Assert().NotEmpty(location["line"])
+	s.Assert().NotEmpty(location["function"])
+}
@@ -0,0 +1,30 @@
+package zap
+
+import (
+	"testing"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/logtest"
+)
+
+func TestLogfmt(t *testing.T) {
+	logtest.Logfmt(t, newFileLogger)
+}
+
+// newFileLogger returns the logger of the tests of the file formatters.
+func newFileLogger(file logtest.File) log.Logger {
+	options := []Option{
+		WithConsoleEnabled(false),
+		WithFileEnabled(true),
+		WithFilePath(file.Dir),
+		WithFileName("app.log"),
+		WithFileFormatter(file.Formatter),
+		WithFileLevel("DEBUG"),
+		WithFieldNames(file.Names.Time, file.Names.Level, file.Names.Message, file.Names.Caller, ""),
+		WithTimeClock(file.Clock),
+	}
+	if file.TimeFormat != "" {
+		options = append(options, WithTimeFormat(file.TimeFormat))
+	}
+	return NewLogger(options...)
+}
@@ -2,13 +2,27 @@ package zap
 
 import (
 	"context"
+	"encoding/json"
 	"io"
-	"os"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/americanas-go/log"
-	"gopkg.in/natefinch/lumberjack.v2"
+	"github.com/americanas-go/log/internal/closer"
+	"github.com/americanas-go/log/internal/cloudwatch"
+	"github.com/americanas-go/log/internal/elasticsearch"
+	"github.com/americanas-go/log/internal/forward"
+	"github.com/americanas-go/log/internal/gcp"
+	"github.com/americanas-go/log/internal/gelf"
+	"github.com/americanas-go/log/internal/journald"
+	"github.com/americanas-go/log/internal/loki"
+	"github.com/americanas-go/log/internal/network"
+	"github.com/americanas-go/log/internal/order"
+	"github.com/americanas-go/log/internal/otlp"
+	"github.com/americanas-go/log/internal/reopen"
+	"github.com/americanas-go/log/internal/rotate"
+	"github.com/americanas-go/log/internal/syslog"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -17,19 +31,103 @@ import (
 type ctxKey string
 
 const (
-	key                     ctxKey = "ctxfields"
-	defaultConsoleFormatter        = "TEXT"
-	defaultConsoleEnabled          = true
-	defaultConsoleLevel            = "INFO"
-	defaultFileEnabled             = false
-	defaultFileLevel               = "INFO"
-	defaultFilePath                = "/tmp"
-	defaultFileName                = "application.log"
-	defaultFileMaxSize             = 100
-	defaultFileCompress            = true
-	defaultFileMaxAge              = 28
-	defaultFileFormatter           = "TEXT"
-	defaultErrorFieldName          = "err"
+	key                               ctxKey = "ctxfields"
+	defaultConsoleFormatter                  = "TEXT"
+	defaultConsoleEnabled                    = true
+	defaultConsoleLevel                      = "INFO"
+	defaultConsoleWriter                     = ConsoleWriterStdout
+	defaultConsoleSplitLevel                 = "WARN"
+	defaultFileEnabled                       = false
+	defaultFileLevel                         = "INFO"
+	defaultFilePath                          = "/tmp"
+	defaultFileName                          = "application.log"
+	defaultFileMaxSize                       = 100
+	defaultFileCompress                      = true
+	defaultFileMaxAge                        = 28
+	defaultFileMaxBackups                    = 0
+	defaultFileMaxTotalSize                  = 0
+	defaultFileRotation                      = rotate.RotationSize
+	defaultFileLocalTime                     = false
+	defaultFileRotateOnStartup               = false
+	defaultFileMode                          = 0o600
+	defaultFileDirMode                       = 0o755
+	defaultFileFormatter                     = "TEXT"
+	defaultSyslogEnabled                     = false
+	defaultSyslogLevel                       = "INFO"
+	defaultSyslogNetwork                     = "udp"
+	defaultSyslogAddress                     = "localhost:514"
+	defaultSyslogFormat                      = syslog.FormatRFC5424
+	defaultSyslogFacility                    = "USER"
+	defaultNetworkEnabled                    = false
+	defaultNetworkLevel                      = "INFO"
+	defaultNetworkProtocol                   = network.ProtocolTCP
+	defaultNetworkAddress                    = "localhost:5170"
+	defaultNetworkBufferSize                 = 1024
+	defaultNetworkSpillMaxSize               = 100
+	defaultNetworkMinBackoff                 = 100 * time.Millisecond
+	defaultNetworkMaxBackoff                 = 30 * time.Second
+	defaultGELFEnabled                       = false
+	defaultGELFLevel                         = "INFO"
+	defaultGELFProtocol                      = gelf.ProtocolUDP
+	defaultGELFAddress                       = "localhost:12201"
+	defaultGELFCompression                   = gelf.CompressionGzip
+	defaultGELFChunkSize                     = 1420
+	defaultForwardEnabled                    = false
+	defaultForwardLevel                      = "INFO"
+	defaultForwardAddress                    = "localhost:24224"
+	defaultForwardTag                        = "app"
+	defaultForwardMode                       = forward.ModeForward
+	defaultForwardAckTimeout                 = 5 * time.Second
+	defaultForwardBatchSize                  = 100
+	defaultForwardFlushInterval              = time.Second
+	defaultForwardBufferSize                 = 1024
+	defaultForwardMaxRetries                 = 10
+	defaultLokiEnabled                       = false
+	defaultLokiLevel                         = "INFO"
+	defaultLokiURL                           = "http://localhost:3100/loki/api/v1/push"
+	defaultLokiMaxStreams                    = 100
+	defaultLokiEncoding                      = loki.EncodingJSON
+	defaultLokiBatchSize                     = 100
+	defaultLokiBatchWait                     = time.Second
+	defaultLokiBufferSize                    = 1024
+	defaultLokiTimeout                       = 10 * time.Second
+	defaultLokiMaxRetries                    = 10
+	defaultElasticsearchEnabled              = false
+	defaultElasticsearchLevel                = "INFO"
+	defaultElasticsearchURL                  = "http://localhost:9200"
+	defaultElasticsearchIndex                = "logs-{2006.01.02}"
+	defaultElasticsearchECS                  = true
+	defaultElasticsearchBatchSize            = 100
+	defaultElasticsearchBatchAge             = time.Second
+	defaultElasticsearchBufferSize           = 1024
+	defaultElasticsearchTimeout              = 10 * time.Second
+	defaultElasticsearchMaxRetries           = 5
+	defaultOTLPEnabled                       = false
+	defaultOTLPLevel                         = "INFO"
+	defaultOTLPProtocol                      = otlp.ProtocolHTTP
+	defaultOTLPTraceIDField                  = "trace_id"
+	defaultOTLPSpanIDField                   = "span_id"
+	defaultOTLPBatchSize                     = 512
+	defaultOTLPBatchTimeout                  = time.Second
+	defaultOTLPBufferSize                    = 2048
+	defaultOTLPTimeout                       = 10 * time.Second
+	defaultOTLPMaxRetries                    = 5
+	defaultJournaldEnabled                   = false
+	defaultJournaldLevel                     = "INFO"
+	defaultJournaldSocket                    = journald.DefaultSocket
+	defaultGCPTraceIDField                   = gcp.DefaultTraceIDKey
+	defaultGCPSpanIDField                    = gcp.DefaultSpanIDKey
+	defaultGCPHTTPRequestField               = gcp.DefaultHTTPRequestKey
+	defaultCloudWatchDisableSorting          = false
+	defaultCloudWatchQuoteEmptyFields        = true
+	defaultErrorFieldName                    = "err"
+	defaultTimeFormat                        = TimeFormatISO8601
+
+	defaultTimeFieldName       = "ts"
+	defaultLevelFieldName      = "level"
+	defaultMessageFieldName    = "msg"
+	defaultCallerFieldName     = "caller"
+	defaultStacktraceFieldName = "stacktrace"
 )
 
 // NewLogger constructs a new Logger from provided variadic Option.
@@ -43,34 +141,174 @@ func NewLoggerWithOptions(options *Options) log.Logger {
 
 	cores := []zapcore.Core{}
 	var writers []io.Writer
+	outputs := &closer.Group{}
+	names := getFieldNames(options)
 
 	if options.Console.Enabled {
-		level := logLevel(options.Console.Level)
-		writer := zapcore.Lock(os.Stdout)
-		coreconsole := zapcore.NewCore(getEncoder(options.Console.Formatter), writer, level)
-		cores = append(cores, coreconsole)
-		writers = append(writers, writer)
+		for _, sink := range getConsoleSinks(options) {
+			coreconsole := zapcore.NewCore(getOutputEncoder(options.Console.Formatter, sink.out, names, options), sink.writer, sink.enabler)
+			cores = append(cores, coreconsole)
+			writers = append(writers, sink.writer)
+		}
 	}
 
 	if options.File.Enabled {
-		s := []string{options.File.Path, "/", options.File.Name}
-		fileLocation := strings.Join(s, "")
-
-		lumber := &lumberjack.Logger{
-			Filename: fileLocation,
-			MaxSize:  options.File.MaxSize,
-			Compress: options.File.Compress,
-			MaxAge:   options.File.MaxAge,
-		}
+		file := getFileWriter(options)
 
 		level := logLevel(options.File.Level)
-		writer := zapcore.AddSync(lumber)
-		corefile := zapcore.NewCore(getEncoder(options.File.Formatter), writer, level)
+		writer := zapcore.AddSync(file)
+		corefile := zapcore.NewCore(getOutputEncoder(options.File.Formatter, file, names, options), writer, level)
 		cores = append(cores, corefile)
-		writers = append(writers, lumber)
+		writers = append(writers, file)
+		outputs.Add(file)
+	}
+
+	if options.Syslog.Enabled {
+		writer := syslog.New(syslog.Options{
+			Network:  options.Syslog.Network,
+			Address:  options.Syslog.Address,
+			Format:   options.Syslog.Format,
+			Facility: options.Syslog.Facility,
+			AppName:  options.Syslog.AppName,
+			Hostname: options.Syslog.Hostname,
+			ProcID:   options.Syslog.ProcID,
+
+			PriorityKeys: options.PriorityFields,
+		})
+		cores = append(cores, newEntryCore(writer, logLevel(options.Syslog.Level), names))
+		outputs.Add(writer)
+	}
+
+	if options.GELF.Enabled {
+		writer := gelf.New(gelf.Options{
+			Protocol:    options.GELF.Protocol,
+			Address:     options.GELF.Address,
+			Compression: options.GELF.Compression,
+			Host:        options.GELF.Host,
+			ChunkSize:   options.GELF.ChunkSize,
+		})
+		cores = append(cores, newEntryCore(writer, logLevel(options.GELF.Level), names))
+		outputs.Add(writer)
+	}
+
+	if options.Forward.Enabled {
+		writer := forward.New(forward.Options{
+			Address:       options.Forward.Address,
+			Tag:           options.Forward.Tag,
+			TagField:      options.Forward.TagField,
+			Mode:          options.Forward.Mode,
+			RequireAck:    options.Forward.RequireAck,
+			AckTimeout:    options.Forward.AckTimeout,
+			BatchSize:     options.Forward.BatchSize,
+			FlushInterval: options.Forward.FlushInterval,
+			BufferSize:    options.Forward.BufferSize,
+			MaxRetries:    options.Forward.MaxRetries,
+			MessageKey:    names.Message,
+			LevelKey:      names.Level,
+			CallerKey:     names.Caller,
+
+			PriorityKeys: options.PriorityFields,
+		})
+		cores = append(cores, newEntryCore(writer, logLevel(options.Forward.Level), names))
+		outputs.Add(writer)
 	}
 
-	combinedCore := zapcore.NewTee(cores...)
+	if options.Loki.Enabled {
+		writer := loki.New(loki.Options{
+			URL:         options.Loki.URL,
+			TenantID:    options.Loki.TenantID,
+			Labels:      options.Loki.Labels,
+			LabelFields: options.Loki.LabelFields,
+			MaxStreams:  options.Loki.MaxStreams,
+			Encoding:    options.Loki.Encoding,
+			Gzip:        options.Loki.Gzip,
+			BatchSize:   options.Loki.BatchSize,
+			BatchWait:   options.Loki.BatchWait,
+			BufferSize:  options.Loki.BufferSize,
+			Timeout:     options.Loki.Timeout,
+			MaxRetries:  options.Loki.MaxRetries,
+			MessageKey:  names.Message,
+			LevelKey:    names.Level,
+			CallerKey:   names.Caller,
+		})
+		cores = append(cores, newEntryCore(writer, logLevel(options.Loki.Level), names))
+		outputs.Add(writer)
+	}
+
+	if options.Elasticsearch.Enabled {
+		writer := elasticsearch.New(elasticsearch.Options{
+			URL:        options.Elasticsearch.URL,
+			Index:      options.Elasticsearch.Index,
+			Username:   options.Elasticsearch.Username,
+			Password:   options.Elasticsearch.Password,
+			APIKey:     options.Elasticsearch.APIKey,
+			ECS:        options.Elasticsearch.ECS,
+			BatchSize:  options.Elasticsearch.BatchSize,
+			BatchAge:   options.Elasticsearch.BatchAge,
+			BufferSize: options.Elasticsearch.BufferSize,
+			Timeout:    options.Elasticsearch.Timeout,
+			MaxRetries: options.Elasticsearch.MaxRetries,
+			TimeKey:    names.Time,
+			MessageKey: names.Message,
+			LevelKey:   names.Level,
+			CallerKey:  names.Caller,
+		})
+		cores = append(cores, newEntryCore(writer, logLevel(options.Elasticsearch.Level), names))
+		outputs.Add(writer)
+	}
+
+	if options.OTLP.Enabled {
+		writer := otlp.New(otlp.Options{
+			Protocol:           options.OTLP.Protocol,
+			Endpoint:           options.OTLP.Endpoint,
+			Headers:            options.OTLP.Headers,
+			ServiceName:        options.OTLP.ServiceName,
+			ServiceVersion:     options.OTLP.ServiceVersion,
+			ResourceAttributes: options.OTLP.ResourceAttributes,
+			TraceIDField:       options.OTLP.TraceIDField,
+			SpanIDField:        options.OTLP.SpanIDField,
+			BatchSize:          options.OTLP.BatchSize,
+			BatchTimeout:       options.OTLP.BatchTimeout,
+			BufferSize:         options.OTLP.BufferSize,
+			Timeout:            options.OTLP.Timeout,
+			MaxRetries:         options.OTLP.MaxRetries,
+
+			PriorityKeys: options.PriorityFields,
+		})
+		cores = append(cores, newEntryCore(writer, logLevel(options.OTLP.Level), names))
+		outputs.Add(writer)
+	}
+
+	if options.Journald.Enabled {
+		writer := journald.New(journald.Options{
+			Socket:     options.Journald.Socket,
+			Identifier: options.Journald.Identifier,
+
+			PriorityKeys: options.PriorityFields,
+		})
+		cores = append(cores, newEntryCore(writer, logLevel(options.Journald.Level), names))
+		outputs.Add(writer)
+	}
+
+	// the network output is always JSON lines
+	if options.Network.Enabled {
+		writer := network.New(network.Options{
+			Protocol:     options.Network.Protocol,
+			Address:      options.Network.Address,
+			TLSConfig:    options.Network.TLSConfig,
+			BufferSize:   options.Network.BufferSize,
+			SpillPath:    options.Network.SpillPath,
+			SpillMaxSize: options.Network.SpillMaxSize,
+			MinBackoff:   options.Network.MinBackoff,
+			MaxBackoff:   options.Network.MaxBackoff,
+			Metrics:      options.Network.Metrics,
+		})
+		corenetwork := zapcore.NewCore(getEncoder("JSON", names, options), writer, logLevel(options.Network.Level))
+		cores = append(cores, corenetwork)
+		outputs.Add(writer)
+	}
+
+	combinedCore := withTime(zapcore.NewTee(cores...), options)
 
 	// AddCallerSkip skips 2 number of callers, this is important else the file that gets
 	// logged will always be the wrapped file. In our case zap.go
@@ -90,6 +328,13 @@ func NewLoggerWithOptions(options *Options) log.Logger {
 		writers:        writers,
 		core:           combinedCore,
 		errorFieldName: errorField,
+		priorityFields: options.PriorityFields,
+		outputs:        outputs,
+		spans: &otlp.SpanContext{
+			Func:         options.OTLP.SpanContext,
+			TraceIDField: options.OTLP.TraceIDField,
+			SpanIDField:  options.OTLP.SpanIDField,
+		},
 	}
 
 	log.SetGlobalLogger(newlogger)
@@ -97,39 +342,173 @@ func NewLoggerWithOptions(options *Options) log.Logger {
 	return newlogger
 }
 
+// failedWriter is the writer of a file output which could not be created,
+// failing every write with the error, reported as the other write errors.
+type failedWriter struct {
+	err error
+}
+
+func (w failedWriter) Write([]byte) (int, error) {
+	return 0, w.err
+}
+
+// getFileWriter returns the writer of the file output: the file is rotated
+// unless its rotation is NONE, it is reopened after an external rotation then.
+func getFileWriter(options *Options) io.Writer {
+	s := []string{options.File.Path, "/", options.File.Name}
+	fileLocation := strings.Join(s, "")
+
+	if options.File.Rotation == rotate.RotationNone {
+		if options.File.ReopenOnSIGHUP {
+			reopen.NotifySIGHUP()
+		}
+		writer, err := reopen.New(reopen.Options{
+			Filename: fileLocation,
+			FileMode: options.File.Mode,
+			DirMode:  options.File.DirMode,
+		})
+		if err != nil {
+			return failedWriter{err: err}
+		}
+		return writer
+	}
+	return rotate.New(rotate.Options{
+		Filename:        fileLocation,
+		Rotation:        options.File.Rotation,
+		Pattern:         options.File.Pattern,
+		MaxSize:         options.File.MaxSize,
+		MaxAge:          options.File.MaxAge,
+		MaxBackups:      options.File.MaxBackups,
+		MaxTotalSize:    options.File.MaxTotalSize,
+		Compress:        options.File.Compress,
+		LocalTime:       options.File.LocalTime,
+		RotateOnStartup: options.File.RotateOnStartup,
+		FileMode:        options.File.Mode,
+		DirMode:         options.File.DirMode,
+	})
+}
+
+// Reopen reopens the files of the file outputs whose rotation is NONE, as on
+// SIGHUP, after they were rotated by an external tool such as logrotate.
+func Reopen() error {
+	return reopen.Reopen()
+}
+
 func defaultOptions() *Options {
-	return &Options{
+	options := &Options{
 		ErrorFieldName: defaultErrorFieldName,
-
-		Console: struct {
-			Enabled   bool
-			Level     string
-			Formatter string
-		}{
-			Enabled:   defaultConsoleEnabled,
-			Level:     defaultConsoleLevel,
-			Formatter: defaultConsoleFormatter,
-		},
-		File: struct {
-			Enabled   bool
-			Level     string
-			Path      string
-			Name      string
-			MaxSize   int
-			Compress  bool
-			MaxAge    int
-			Formatter string
-		}{
-			Enabled:   defaultFileEnabled,
-			Level:     defaultFileLevel,
-			Path:      defaultFilePath,
-			Name:      defaultFileName,
-			MaxSize:   defaultFileMaxSize,
-			Compress:  defaultFileCompress,
-			MaxAge:    defaultFileMaxAge,
-			Formatter: defaultFileFormatter,
-		},
 	}
+
+	options.Time.Format = defaultTimeFormat
+
+	options.Console.Enabled = defaultConsoleEnabled
+	options.Console.Level = defaultConsoleLevel
+	options.Console.Formatter = defaultConsoleFormatter
+	options.Console.Writer = defaultConsoleWriter
+	options.Console.SplitLevel = defaultConsoleSplitLevel
+
+	options.File.Enabled = defaultFileEnabled
+	options.File.Level = defaultFileLevel
+	options.File.Path = defaultFilePath
+	options.File.Name = defaultFileName
+	options.File.MaxSize = defaultFileMaxSize
+	options.File.Compress = defaultFileCompress
+	options.File.MaxAge = defaultFileMaxAge
+	options.File.MaxBackups = defaultFileMaxBackups
+	options.File.MaxTotalSize = defaultFileMaxTotalSize
+	options.File.Rotation = defaultFileRotation
+	options.File.LocalTime = defaultFileLocalTime
+	options.File.RotateOnStartup = defaultFileRotateOnStartup
+	options.File.Mode = defaultFileMode
+	options.File.DirMode = defaultFileDirMode
+	options.File.Formatter = defaultFileFormatter
+
+	options.Syslog.Enabled = defaultSyslogEnabled
+	options.Syslog.Level = defaultSyslogLevel
+	options.Syslog.Network = defaultSyslogNetwork
+	options.Syslog.Address = defaultSyslogAddress
+	options.Syslog.Format = defaultSyslogFormat
+	options.Syslog.Facility = defaultSyslogFacility
+
+	options.Network.Enabled = defaultNetworkEnabled
+	options.Network.Level = defaultNetworkLevel
+	options.Network.Protocol = defaultNetworkProtocol
+	options.Network.Address = defaultNetworkAddress
+	options.Network.BufferSize = defaultNetworkBufferSize
+	options.Network.SpillMaxSize = defaultNetworkSpillMaxSize
+	options.Network.MinBackoff = defaultNetworkMinBackoff
+	options.Network.MaxBackoff = defaultNetworkMaxBackoff
+
+	options.GELF.Enabled = defaultGELFEnabled
+	options.GELF.Level = defaultGELFLevel
+	options.GELF.Protocol = defaultGELFProtocol
+	options.GELF.Address = defaultGELFAddress
+	options.GELF.Compression = defaultGELFCompression
+	options.GELF.ChunkSize = defaultGELFChunkSize
+
+	options.Forward.Enabled = defaultForwardEnabled
+	options.Forward.Level = defaultForwardLevel
+	options.Forward.Address = defaultForwardAddress
+	options.Forward.Tag = defaultForwardTag
+	options.Forward.Mode = defaultForwardMode
+	options.Forward.AckTimeout = defaultForwardAckTimeout
+	options.Forward.BatchSize = defaultForwardBatchSize
+	options.Forward.FlushInterval = defaultForwardFlushInterval
+	options.Forward.BufferSize = defaultForwardBufferSize
+	options.Forward.MaxRetries = defaultForwardMaxRetries
+
+	options.Loki.Enabled = defaultLokiEnabled
+	options.Loki.Level = defaultLokiLevel
+	options.Loki.URL = defaultLokiURL
+	options.Loki.MaxStreams = defaultLokiMaxStreams
+	options.Loki.Encoding = defaultLokiEncoding
+	options.Loki.BatchSize = defaultLokiBatchSize
+	options.Loki.BatchWait = defaultLokiBatchWait
+	options.Loki.BufferSize = defaultLokiBufferSize
+	options.Loki.Timeout = defaultLokiTimeout
+	options.Loki.MaxRetries = defaultLokiMaxRetries
+
+	options.Elasticsearch.Enabled = defaultElasticsearchEnabled
+	options.Elasticsearch.Level = defaultElasticsearchLevel
+	options.Elasticsearch.URL = defaultElasticsearchURL
+	options.Elasticsearch.Index = defaultElasticsearchIndex
+	options.Elasticsearch.ECS = defaultElasticsearchECS
+	options.Elasticsearch.BatchSize = defaultElasticsearchBatchSize
+	options.Elasticsearch.BatchAge = defaultElasticsearchBatchAge
+	options.Elasticsearch.BufferSize = defaultElasticsearchBufferSize
+	options.Elasticsearch.Timeout = defaultElasticsearchTimeout
+	options.Elasticsearch.MaxRetries = defaultElasticsearchMaxRetries
+
+	options.OTLP.Enabled = defaultOTLPEnabled
+	options.OTLP.Level = defaultOTLPLevel
+	options.OTLP.Protocol = defaultOTLPProtocol
+	options.OTLP.TraceIDField = defaultOTLPTraceIDField
+	options.OTLP.SpanIDField = defaultOTLPSpanIDField
+	options.OTLP.BatchSize = defaultOTLPBatchSize
+	options.OTLP.BatchTimeout = defaultOTLPBatchTimeout
+	options.OTLP.BufferSize = defaultOTLPBufferSize
+	options.OTLP.Timeout = defaultOTLPTimeout
+	options.OTLP.MaxRetries = defaultOTLPMaxRetries
+
+	options.Journald.Enabled = defaultJournaldEnabled
+	options.Journald.Level = defaultJournaldLevel
+	options.Journald.Socket = defaultJournaldSocket
+
+	options.GCP.TraceIDField = defaultGCPTraceIDField
+	options.GCP.SpanIDField = defaultGCPSpanIDField
+	options.GCP.HTTPRequestField = defaultGCPHTTPRequestField
+
+	options.CloudWatch.PrefixFields = []string{cloudwatch.DefaultPrefixField}
+	options.CloudWatch.DisableSorting = defaultCloudWatchDisableSorting
+	options.CloudWatch.QuoteEmptyFields = defaultCloudWatchQuoteEmptyFields
+
+	options.FieldNames.Time = defaultTimeFieldName
+	options.FieldNames.Level = defaultLevelFieldName
+	options.FieldNames.Message = defaultMessageFieldName
+	options.FieldNames.Caller = defaultCallerFieldName
+	options.FieldNames.Stacktrace = defaultStacktraceFieldName
+
+	return options
 }
 
 func options(option []Option) *Options {
@@ -148,20 +527,61 @@ func newSugaredLogger(core zapcore.Core) *zap.SugaredLogger {
 	).Sugar()
 }
 
-func getEncoder(format string) zapcore.Encoder {
-	encoderConfig := zap.NewProductionEncoderConfig()
-	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+// fieldNames holds the keys of the fields written on every entry. An empty
+// key omits the field.
+type fieldNames struct {
+	Time       string
+	Level      string
+	Message    string
+	Caller     string
+	Stacktrace string
+}
+
+func getFieldNames(options *Options) fieldNames {
+	names := fieldNames(options.FieldNames)
+	if names == (fieldNames{}) {
+		return fieldNames(defaultOptions().FieldNames)
+	}
+	if names.Message == "" {
+		names.Message = defaultMessageFieldName
+	}
+	return names
+}
+
+func getEncoder(format string, names fieldNames, options *Options) zapcore.Encoder {
+	encoderConfig := getEncoderConfig(names, options)
 
 	switch format {
 	case "JSON":
 		return zapcore.NewJSONEncoder(encoderConfig)
+	case "LOGFMT":
+		return newLogfmtEncoder(names, options)
+	case "CLOUDWATCH":
+		return newCloudWatchEncoder(names, options)
+	case "ECS":
+		return newECSEncoder(names, options)
+	case "GCP":
+		return newGCPEncoder(names, options)
+	case "CBOR", "MSGPACK":
+		return newBinaryEncoder(format, names, options)
 	default:
 		return zapcore.NewConsoleEncoder(encoderConfig)
 	}
 }
 
+func getEncoderConfig(names fieldNames, options *Options) zapcore.EncoderConfig {
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.EncodeTime = timeEncoder(options.Time.Format)
+	encoderConfig.TimeKey = names.Time
+	encoderConfig.LevelKey = names.Level
+	encoderConfig.MessageKey = names.Message
+	encoderConfig.CallerKey = names.Caller
+	encoderConfig.StacktraceKey = names.Stacktrace
+	return encoderConfig
+}
+
 func logLevel(level string) zapcore.Level {
-	switch level {
+	switch strings.ToUpper(level) {
 	case "TRACE":
 		return zapcore.DebugLevel
 	case "WARN":
@@ -185,6 +605,10 @@ type zapLogger struct {
 	writers        []io.Writer
 	core           zapcore.Core
 	errorFieldName string
+	priorityFields []string
+	outputs        *closer.Group
+	spans          *otlp.SpanContext // ids of the span of the contexts of FromContext
+	err            error             // error of WithError, see with
 }
 
 // Printf uses (*zap.SugaredLogger).Infof to log a templated message.
@@ -223,6 +647,7 @@ func (l *zapLogger) Error(args ...interface{}) {
 }
 
 // Fatal uses (*zap.SugaredLogger).Fatal to log a message and call os.Exit(1).
+// The cores sync their outputs before, as zap syncs them on fatal entries.
 func (l *zapLogger) Fatal(args ...interface{}) {
 	l.sugaredLogger.Fatal(args...)
 }
@@ -234,16 +659,24 @@ func (l *zapLogger) Panic(args ...interface{}) {
 
 // WithField constructs a new Logger with l.fields and provided key and value field.
 func (l *zapLogger) WithField(key string, value interface{}) log.Logger {
-	newFields := log.Fields{}
-	for k, v := range l.fields {
-		newFields[k] = v
+	err := l.err
+	if key == l.errorFieldName {
+		err = nil
 	}
 
-	newFields[key] = value
+	return l.with(log.Fields{key: value}, err)
+}
 
-	f := mapToSlice(newFields)
-	newLogger := newSugaredLogger(l.core).With(f...)
-	return &zapLogger{newLogger, newFields, l.writers, l.core, l.errorFieldName}
+// Sync sends the entries buffered by the outputs, such as the batches of the
+// loki output, and waits for them to be sent.
+func (l *zapLogger) Sync() error {
+	return l.outputs.Flush()
+}
+
+// Close syncs the logger then closes its outputs, which l shares with the
+// loggers it was derived from and those derived from it.
+func (l *zapLogger) Close() error {
+	return l.outputs.Close()
 }
 
 // Output returns a Writer that represents the zap writers.
@@ -283,6 +716,18 @@ func (l *zapLogger) Panicf(format string, args ...interface{}) {
 
 // WithFields constructs a new Logger with l.fields and the provided fields.
 func (l *zapLogger) WithFields(fields map[string]interface{}) log.Logger {
+	err := l.err
+	if _, ok := fields[l.errorFieldName]; ok {
+		err = nil
+	}
+
+	return l.with(fields, err)
+}
+
+// with constructs a new Logger with l.fields and fields. err is the error of
+// the error field, whose message is in the fields: it is given as is to the
+// encoders using its type, such as ECS.
+func (l *zapLogger) with(fields map[string]interface{}, err error) *zapLogger {
 	newFields := log.Fields{}
 
 	for k, v := range l.fields {
@@ -293,9 +738,18 @@ func (l *zapLogger) WithFields(fields map[string]interface{}) log.Logger {
 		newFields[k] = v
 	}
 
-	f := mapToSlice(newFields)
+	values := newFields
+	if err != nil {
+		values = log.Fields{}
+		for k, v := range newFields {
+			values[k] = v
+		}
+		values[l.errorFieldName] = err
+	}
+
+	f := mapToSlice(values, l.priorityFields)
 	newLogger := newSugaredLogger(l.core).With(f...)
-	return &zapLogger{newLogger, newFields, l.writers, l.core, l.errorFieldName}
+	return &zapLogger{newLogger, newFields, l.writers, l.core, l.errorFieldName, l.priorityFields, l.outputs, l.spans, err}
 }
 
 // WithTypeOf adds type and package information fields.
@@ -310,7 +764,7 @@ func (l *zapLogger) WithTypeOf(obj interface{}) log.Logger {
 }
 
 func (l *zapLogger) WithError(err error) log.Logger {
-	return l.WithField(l.errorFieldName, err.Error())
+	return l.with(log.Fields{l.errorFieldName: err.Error()}, err)
 }
 
 func (l *zapLogger) Fields() log.Fields {
@@ -330,9 +784,13 @@ func (l *zapLogger) ToContext(ctx context.Context) context.Context {
 	return context.WithValue(ctx, key, ctxFields)
 }
 
-// FromContext returns a Logger from ctx.
+// FromContext returns a Logger from ctx, with the ids of its span when a span
+// context function is set.
 func (l *zapLogger) FromContext(ctx context.Context) log.Logger {
 	fields := fieldsFromContext(ctx)
+	for k, v := range l.spans.Fields(ctx) {
+		fields[k] = v
+	}
 	return l.WithFields(fields)
 }
 
@@ -352,14 +810,42 @@ func fieldsFromContext(ctx context.Context) log.Fields {
 	return fields
 }
 
-func mapToSlice(m log.Fields) []interface{} {
+// mapToSlice returns the key value pairs of m for zap.SugaredLogger.With, the
+// priority fields first and the others sorted by key, so that the fields are
+// written in the same order on every entry.
+func mapToSlice(m log.Fields, priority []string) []interface{} {
 	f := make([]interface{}, 2*len(m))
 	i := 0
-	for k, v := range m {
+	for _, k := range order.Keys(m, priority) {
+		v := m[k]
+		if err, ok := v.(error); ok {
+			f[i] = zap.Reflect(k, errorValue{err})
+			i = i + 1
+			continue
+		}
 		f[i] = k
 		f[i+1] = v
 		i = i + 2
 	}
 
-	return f
+	return f[:i]
+}
+
+// errorValue is an error field, written as its message by the JSON and
+// console encoders and kept as is by the encoders using its type.
+type errorValue struct {
+	error
+}
+
+func (e errorValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.Error())
+}
+
+// unwrapErrors replaces the errorValue of fields by their error.
+func unwrapErrors(fields map[string]interface{}) {
+	for k, v := range fields {
+		if e, ok := v.(errorValue); ok {
+			fields[k] = e.error
+		}
+	}
 }
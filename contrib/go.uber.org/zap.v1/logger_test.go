@@ -9,9 +9,11 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/americanas-go/log"
 	"github.com/stretchr/testify/suite"
@@ -303,7 +305,7 @@ func TestLoggerMethod(t *testing.T) {
 func buildLogger() *zapLogger {
 	level := logLevel("TRACE")
 	writer := zapcore.Lock(os.Stdout)
-	coreconsole := zapcore.NewCore(getEncoder("TEXT"), writer, level)
+	coreconsole := zapcore.NewCore(getEncoder("TEXT", getFieldNames(defaultOptions()), defaultOptions()), writer, level)
 
 	core := zapcore.NewTee(coreconsole)
 	zaplogger := newSugaredLogger(core)
@@ -329,7 +331,7 @@ func (s *LoggerSuite) TestLoggerWithMethods() {
 				return l.WithField("ID", "1")
 			},
 			want: func() log.Logger {
-				return &zapLogger{l.sugaredLogger.With("ID", "1"), log.Fields{"ID": "1"}, l.writers, l.core, l.errorFieldName}
+				return &zapLogger{l.sugaredLogger.With("ID", "1"), log.Fields{"ID": "1"}, l.writers, l.core, l.errorFieldName, l.priorityFields, l.outputs, l.spans, nil}
 			},
 		},
 		{
@@ -344,7 +346,7 @@ func (s *LoggerSuite) TestLoggerWithMethods() {
 				return &zapLogger{l.sugaredLogger.With("ID", "12", "Name", "Stockton"), log.Fields{
 					"ID":   "12",
 					"Name": "Stockton",
-				}, l.writers, l.core, l.errorFieldName}
+				}, l.writers, l.core, l.errorFieldName, l.priorityFields, l.outputs, l.spans, nil}
 			},
 		},
 		{
@@ -366,6 +368,10 @@ func (s *LoggerSuite) TestLoggerWithMethods() {
 					l.writers,
 					l.core,
 					l.errorFieldName,
+					l.priorityFields,
+					l.outputs,
+					l.spans,
+					nil,
 				}
 				return l2
 			},
@@ -378,7 +384,7 @@ func (s *LoggerSuite) TestLoggerWithMethods() {
 			want: func() log.Logger {
 				return &zapLogger{l.sugaredLogger.With("err", "something bad"), log.Fields{
 					"err": "something bad",
-				}, l.writers, l.core, l.errorFieldName}
+				}, l.writers, l.core, l.errorFieldName, l.priorityFields, l.outputs, l.spans, nil}
 			},
 		},
 	}
@@ -515,6 +521,36 @@ func (s *LoggerSuite) Test_getEncoder() {
 			in:   "JSON",
 			want: "*zapcore.jsonEncoder",
 		},
+		{
+			name: "when LOGFMT",
+			in:   "LOGFMT",
+			want: "*zap.mapEncoder",
+		},
+		{
+			name: "when CLOUDWATCH",
+			in:   "CLOUDWATCH",
+			want: "*zap.mapEncoder",
+		},
+		{
+			name: "when ECS",
+			in:   "ECS",
+			want: "*zap.mapEncoder",
+		},
+		{
+			name: "when GCP",
+			in:   "GCP",
+			want: "*zap.mapEncoder",
+		},
+		{
+			name: "when CBOR",
+			in:   "CBOR",
+			want: "*zap.mapEncoder",
+		},
+		{
+			name: "when MSGPACK",
+			in:   "MSGPACK",
+			want: "*zap.mapEncoder",
+		},
 		{
 			name: "when default",
 			in:   "TEXT",
@@ -523,8 +559,79 @@ func (s *LoggerSuite) Test_getEncoder() {
 	}
 	for _, t := range tt {
 		s.Run(t.name, func() {
-			got := reflect.TypeOf(getEncoder(t.in)).String()
+			got := reflect.TypeOf(getEncoder(t.in, getFieldNames(defaultOptions()), defaultOptions())).String()
 			s.Assert().True(got == t.want, "got  %v\nwant %v", got, t.want)
 		})
 	}
 }
+
+func (s *LoggerSuite) Test_getEncoderFieldNames() {
+	entry := zapcore.Entry{
+		Level:   zapcore.InfoLevel,
+		Time:    time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+		Message: "Blah",
+		Caller:  zapcore.NewEntryCaller(0, "/src/main.go", 10, true),
+		Stack:   "main.main()",
+	}
+
+	tt := []struct {
+		name  string
+		names fieldNames
+		want  string
+	}{
+		{
+			name:  "when default",
+			names: getFieldNames(defaultOptions()),
+			want:  `{"level":"info","ts":"2021-01-02T03:04:05.000Z","caller":"src/main.go:10","msg":"Blah","stacktrace":"main.main()"}` + "\n",
+		},
+		{
+			name:  "when custom",
+			names: fieldNames{Time: "@timestamp", Level: "severity", Message: "message", Caller: "source", Stacktrace: "stack"},
+			want:  `{"severity":"info","@timestamp":"2021-01-02T03:04:05.000Z","source":"src/main.go:10","message":"Blah","stack":"main.main()"}` + "\n",
+		},
+		{
+			name:  "when omitted",
+			names: fieldNames{Message: "message"},
+			want:  `{"message":"Blah"}` + "\n",
+		},
+		{
+			name:  "when all empty",
+			names: getFieldNames(&Options{}),
+			want:  `{"level":"info","ts":"2021-01-02T03:04:05.000Z","caller":"src/main.go:10","msg":"Blah","stacktrace":"main.main()"}` + "\n",
+		},
+	}
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			buf, err := getEncoder("JSON", t.names, defaultOptions()).EncodeEntry(entry, nil)
+			s.Require().NoError(err)
+			s.Assert().Equal(t.want, buf.String())
+		})
+	}
+}
+
+func (s *LoggerSuite) TestLoggerFileReopen() {
+	dir := filepath.Join(s.T().TempDir(), "logs")
+	logger := NewLogger(
+		WithConsoleEnabled(false),
+		WithFileEnabled(true),
+		WithFilePath(dir),
+		WithFileName("app.log"),
+		WithFileRotation("NONE"),
+	)
+
+	logger.Info("before")
+	// logrotate renames the file, then sends SIGHUP
+	s.Require().NoError(os.Rename(filepath.Join(dir, "app.log"), filepath.Join(dir, "app.log.1")))
+	s.Require().NoError(Reopen())
+	logger.Info("after")
+
+	rotated, err := os.ReadFile(filepath.Join(dir, "app.log.1"))
+	s.Require().NoError(err)
+	s.Assert().Contains(string(rotated), "before")
+	s.Assert().NotContains(string(rotated), "after")
+
+	reopened, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	s.Require().NoError(err)
+	s.Assert().Contains(string(reopened), "after")
+	s.Assert().NotContains(string(reopened), "before")
+}
@@ -1,33 +1,327 @@
 package zap
 
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/forward"
+	"github.com/americanas-go/log/internal/gelf"
+	"github.com/americanas-go/log/internal/loki"
+	"github.com/americanas-go/log/internal/network"
+	"github.com/americanas-go/log/internal/otlp"
+	"github.com/americanas-go/log/internal/rotate"
+	"github.com/americanas-go/log/internal/syslog"
+)
+
 type Options struct {
+	Time struct {
+		Format string           // time format ISO8601/RFC3339/RFC3339NANO/EPOCH/EPOCH_MILLIS/EPOCH_NANOS or a time.Format layout
+		UTC    bool             // write the time in UTC instead of the local time zone
+		Clock  func() time.Time // clock used to timestamp entries, time.Now when nil
+	}
 	Console struct {
-		Enabled   bool   // enable/disable console logging
-		Level     string // console log level
-		Formatter string // console formatter TEXT/JSON
+		Enabled    bool   // enable/disable console logging
+		Level      string // console log level
+		Formatter  string // console formatter TEXT/JSON/LOGFMT/CLOUDWATCH/ECS/GCP/CBOR/MSGPACK/AUTO/PRETTY
+		Writer     string // console writer STDOUT/STDERR/SPLIT
+		SplitLevel string // level from which entries go to stderr when the writer is SPLIT
 	}
 	File struct {
-		Enabled   bool   // enable/disable file logging
-		Level     string // file log level
-		Path      string // file log path
-		Name      string // file log filename
-		MaxSize   int    // log file max size (MB)
-		Compress  bool   // enabled/disable file compress
-		MaxAge    int    // file max age
-		Formatter string // file formatter TEXT/JSON
+		Enabled         bool        // enable/disable file logging
+		Level           string      // file log level
+		Path            string      // file log path
+		Name            string      // file log filename
+		MaxSize         int         // log file max size (MB)
+		Compress        bool        // enabled/disable file compress
+		MaxAge          int         // file max age
+		MaxBackups      int         // file max backups, unlimited when zero
+		MaxTotalSize    int         // file and backups max total size (MB), unlimited when zero
+		Rotation        string      // file rotation SIZE/DAILY/HOURLY, NONE to reopen the file after an external rotation
+		Pattern         string      // file backup name, with time layouts between braces, after the file name and the rotation when empty
+		LocalTime       bool        // name the backups and start the periods in local time instead of UTC
+		RotateOnStartup bool        // rotate the existing file when the logger is created
+		Mode            os.FileMode // file permissions when it is created, before the umask
+		DirMode         os.FileMode // file missing parent directories permissions when they are created, before the umask
+		ReopenOnSIGHUP  bool        // reopen the file on SIGHUP when its rotation is NONE
+		Formatter       string      // file formatter TEXT/JSON/LOGFMT/CLOUDWATCH/ECS/GCP/CBOR/MSGPACK/AUTO/PRETTY
+	}
+	Syslog struct {
+		Enabled  bool   // enable/disable syslog logging
+		Level    string // syslog log level
+		Network  string // syslog network udp/tcp/unixgram/unix
+		Address  string // syslog address, host:port or socket path
+		Format   string // syslog message format RFC5424/RFC3164
+		Facility string // syslog facility, e.g. USER or LOCAL0
+		AppName  string // application name, the executable name when empty
+		Hostname string // host name, the system host name when empty
+		ProcID   string // process id, the current process id when empty
+	}
+	Network struct {
+		Enabled      bool            // enable/disable network logging
+		Level        string          // network log level
+		Protocol     string          // network protocol tcp/tls/udp
+		Address      string          // network address, host:port
+		BufferSize   int             // entries kept in memory while disconnected
+		SpillPath    string          // file receiving the entries overflowing the buffer, dropped when empty
+		SpillMaxSize int             // largest size of the spill file (MB)
+		MinBackoff   time.Duration   // first delay between reconnections
+		MaxBackoff   time.Duration   // longest delay between reconnections
+		TLSConfig    *tls.Config     // configuration of tls connections, the system roots when nil
+		Metrics      *NetworkMetrics // counters of the output, updated when not nil
+	}
+	GELF struct {
+		Enabled     bool   // enable/disable gelf logging
+		Level       string // gelf log level
+		Protocol    string // gelf protocol udp/tcp
+		Address     string // gelf input address, host:port
+		Compression string // compression of udp messages GZIP/ZLIB/NONE
+		Host        string // host field, the system host name when empty
+		ChunkSize   int    // largest udp datagram, in bytes
+	}
+	Forward struct {
+		Enabled       bool          // enable/disable forward logging
+		Level         string        // forward log level
+		Address       string        // forward input address, host:port
+		Tag           string        // tag of the entries
+		TagField      string        // field holding the tag of an entry, removed from its record
+		Mode          string        // forward mode MESSAGE/FORWARD/PACKED_FORWARD
+		RequireAck    bool          // wait for the acknowledgement of each message
+		AckTimeout    time.Duration // longest wait for an acknowledgement
+		BatchSize     int           // entries of a batch
+		FlushInterval time.Duration // longest wait before a batch is sent
+		BufferSize    int           // entries waiting to be sent
+		MaxRetries    int           // retries of a message not sent or not acknowledged
+	}
+	Loki struct {
+		Enabled     bool              // enable/disable loki logging
+		Level       string            // loki log level
+		URL         string            // push endpoint
+		TenantID    string            // tenant of the entries, sent in the X-Scope-OrgID header
+		Labels      map[string]string // static labels of every stream
+		LabelFields []string          // fields promoted to stream labels, removed from the line
+		MaxStreams  int               // most streams opened by the promoted fields
+		Encoding    string            // push encoding JSON/PROTOBUF
+		Gzip        bool              // gzip the JSON pushes
+		BatchSize   int               // entries of a batch
+		BatchWait   time.Duration     // longest wait before a batch is sent
+		BufferSize  int               // entries waiting to be sent
+		Timeout     time.Duration     // timeout of a push
+		MaxRetries  int               // retries of a push rejected with 429 or 5xx
+	}
+	Elasticsearch struct {
+		Enabled    bool          // enable/disable elasticsearch logging
+		Level      string        // elasticsearch log level
+		URL        string        // base URL of the cluster
+		Index      string        // index name template, such as app-logs-{2006.01.02}
+		Username   string        // user of the basic authentication
+		Password   string        // password of the basic authentication
+		APIKey     string        // encoded API key, instead of the basic authentication
+		ECS        bool          // name the time, message, level and caller after the Elastic Common Schema
+		BatchSize  int           // entries of a batch
+		BatchAge   time.Duration // longest wait before a batch is sent
+		BufferSize int           // entries waiting to be sent
+		Timeout    time.Duration // timeout of a bulk request
+		MaxRetries int           // retries of a bulk request or item rejected with 429 or 5xx
+	}
+	OTLP struct {
+		Enabled            bool              // enable/disable otlp logging
+		Level              string            // otlp log level
+		Protocol           string            // export protocol HTTP/GRPC
+		Endpoint           string            // URL of the collector, the default of the protocol when empty
+		Headers            map[string]string // headers of the exports, such as an authorization
+		ServiceName        string            // service.name resource attribute, unknown_service:<executable> when empty
+		ServiceVersion     string            // service.version resource attribute
+		ResourceAttributes map[string]string // other resource attributes
+		TraceIDField       string            // field holding the hex trace id
+		SpanIDField        string            // field holding the hex span id
+		BatchSize          int               // records of a batch
+		BatchTimeout       time.Duration     // longest wait before a batch is exported
+		BufferSize         int               // records waiting to be exported
+		Timeout            time.Duration     // timeout of an export
+		MaxRetries         int               // retries of an export failed with a retryable status
+
+		SpanContext func(ctx context.Context) (traceID, spanID string) // hex ids of the span of a context, added to the fields by FromContext
+	}
+	Journald struct {
+		Enabled    bool   // enable/disable journald logging
+		Level      string // journald log level
+		Socket     string // path of the journald socket
+		Identifier string // SYSLOG_IDENTIFIER of the entries, the executable name when empty
+	}
+	GCP struct {
+		ProjectID        string // project of the traces, the trace id is written as is when empty
+		TraceIDField     string // field holding the trace id, written as logging.googleapis.com/trace
+		SpanIDField      string // field holding the span id, written as logging.googleapis.com/spanId
+		HTTPRequestField string // field holding the HTTP request, written as httpRequest
+	}
+	CloudWatch struct {
+		PrefixFields     []string // fields written before the message, in this order
+		DisableSorting   bool     // write the other fields unsorted
+		QuoteEmptyFields bool     // quote empty values
+	}
+	FieldNames struct {
+		Time       string // time field name, empty to omit the field
+		Level      string // level field name, empty to omit the field
+		Message    string // message field name
+		Caller     string // caller field name, empty to omit the field
+		Stacktrace string // stacktrace field name, empty to omit the field
 	}
 
-	ErrorFieldName string // define field name for error logging
+	ErrorFieldName string   // define field name for error logging
+	PriorityFields []string // fields written first, in this order, the others being sorted by key
 }
 
+// NetworkMetrics counts the activity of the network output of a logger. It is
+// updated by the output and can be read at any time.
+type NetworkMetrics = network.Metrics
+
 type Option func(options *Options)
 
+var (
+	formatters     = []string{"TEXT", "JSON", "LOGFMT", "CLOUDWATCH", "ECS", "GCP", "CBOR", "MSGPACK", "AUTO", "PRETTY"}
+	consoleWriters = []string{ConsoleWriterStdout, ConsoleWriterStderr, ConsoleWriterSplit}
+)
+
+// validate reports every option holding a value the logger can not honour,
+// named by name from its path in Options, such as Console.Level.
+func (o *Options) validate(name func(path string) string) error {
+	return errors.Join(
+		checkLevel(name("Console.Level"), o.Console.Level),
+		checkOneOf(name("Console.Formatter"), o.Console.Formatter, formatters),
+		checkOneOf(name("Console.Writer"), o.Console.Writer, consoleWriters),
+		checkLevel(name("Console.SplitLevel"), o.Console.SplitLevel),
+		checkLevel(name("File.Level"), o.File.Level),
+		checkOneOf(name("File.Formatter"), o.File.Formatter, formatters),
+		checkNotNegative(name("File.MaxSize"), o.File.MaxSize),
+		checkNotNegative(name("File.MaxAge"), o.File.MaxAge),
+		checkNotNegative(name("File.MaxBackups"), o.File.MaxBackups),
+		checkNotNegative(name("File.MaxTotalSize"), o.File.MaxTotalSize),
+		checkOneOf(name("File.Rotation"), o.File.Rotation, rotate.Rotations),
+		checkLevel(name("Syslog.Level"), o.Syslog.Level),
+		checkOneOf(name("Syslog.Network"), o.Syslog.Network, syslog.Networks),
+		checkOneOf(name("Syslog.Format"), o.Syslog.Format, syslog.Formats),
+		checkOneOf(name("Syslog.Facility"), o.Syslog.Facility, syslog.Facilities),
+		checkLevel(name("Network.Level"), o.Network.Level),
+		checkOneOf(name("Network.Protocol"), o.Network.Protocol, network.Protocols),
+		checkNotNegative(name("Network.BufferSize"), o.Network.BufferSize),
+		checkNotNegative(name("Network.SpillMaxSize"), o.Network.SpillMaxSize),
+		checkLevel(name("GELF.Level"), o.GELF.Level),
+		checkOneOf(name("GELF.Protocol"), o.GELF.Protocol, gelf.Protocols),
+		checkOneOf(name("GELF.Compression"), o.GELF.Compression, gelf.Compressions),
+		checkNotNegative(name("GELF.ChunkSize"), o.GELF.ChunkSize),
+		checkLevel(name("Forward.Level"), o.Forward.Level),
+		checkOneOf(name("Forward.Mode"), o.Forward.Mode, forward.Modes),
+		checkNotNegative(name("Forward.BatchSize"), o.Forward.BatchSize),
+		checkNotNegative(name("Forward.BufferSize"), o.Forward.BufferSize),
+		checkNotNegative(name("Forward.MaxRetries"), o.Forward.MaxRetries),
+		checkLevel(name("Loki.Level"), o.Loki.Level),
+		checkOneOf(name("Loki.Encoding"), o.Loki.Encoding, loki.Encodings),
+		checkNotNegative(name("Loki.MaxStreams"), o.Loki.MaxStreams),
+		checkNotNegative(name("Loki.BatchSize"), o.Loki.BatchSize),
+		checkNotNegative(name("Loki.BufferSize"), o.Loki.BufferSize),
+		checkNotNegative(name("Loki.MaxRetries"), o.Loki.MaxRetries),
+		checkLevel(name("Elasticsearch.Level"), o.Elasticsearch.Level),
+		checkNotNegative(name("Elasticsearch.BatchSize"), o.Elasticsearch.BatchSize),
+		checkNotNegative(name("Elasticsearch.BufferSize"), o.Elasticsearch.BufferSize),
+		checkNotNegative(name("Elasticsearch.MaxRetries"), o.Elasticsearch.MaxRetries),
+		checkLevel(name("OTLP.Level"), o.OTLP.Level),
+		checkOneOf(name("OTLP.Protocol"), o.OTLP.Protocol, otlp.Protocols),
+		checkOTLPEndpoint(name("OTLP.Endpoint"), o.OTLP.Protocol, o.OTLP.Endpoint),
+		checkNotNegative(name("OTLP.BatchSize"), o.OTLP.BatchSize),
+		checkNotNegative(name("OTLP.BufferSize"), o.OTLP.BufferSize),
+		checkNotNegative(name("OTLP.MaxRetries"), o.OTLP.MaxRetries),
+		checkLevel(name("Journald.Level"), o.Journald.Level),
+	)
+}
+
+// optionPath names an option after its path in Options.
+func optionPath(path string) string {
+	return path
+}
+
+func checkLevel(name string, value string) error {
+	if _, err := log.ParseLevel(value); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+func checkOneOf(name string, value string, values []string) error {
+	for _, v := range values {
+		if v == value {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: unknown value %q, expected one of %v", name, value, values)
+}
+
+func checkOTLPEndpoint(name string, protocol string, endpoint string) error {
+	if err := otlp.CheckEndpoint(protocol, endpoint); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+func checkNotNegative(name string, value int) error {
+	if value < 0 {
+		return fmt.Errorf("%s: must not be negative, got %d", name, value)
+	}
+	return nil
+}
+
 func WithErrorFieldName(value string) Option {
 	return func(options *Options) {
 		options.ErrorFieldName = value
 	}
 }
 
+// WithPriorityFields sets the fields written first, in this order, such as
+// request_id or trace_id. The other fields are sorted by key.
+func WithPriorityFields(value ...string) Option {
+	return func(options *Options) {
+		options.PriorityFields = value
+	}
+}
+
+// WithFieldNames sets the names of the fields written on every entry.
+// An empty name omits the field, except for the message.
+func WithFieldNames(time, level, message, caller, stacktrace string) Option {
+	return func(options *Options) {
+		options.FieldNames.Time = time
+		options.FieldNames.Level = level
+		options.FieldNames.Message = message
+		options.FieldNames.Caller = caller
+		options.FieldNames.Stacktrace = stacktrace
+	}
+}
+
+// WithTimeFormat sets the format of the time field, one of ISO8601, RFC3339,
+// RFC3339NANO, EPOCH, EPOCH_MILLIS and EPOCH_NANOS or a time.Format layout.
+func WithTimeFormat(value string) Option {
+	return func(options *Options) {
+		options.Time.Format = value
+	}
+}
+
+// WithTimeUTC sets whether the time is written in UTC instead of the local time zone.
+func WithTimeUTC(value bool) Option {
+	return func(options *Options) {
+		options.Time.UTC = value
+	}
+}
+
+// WithTimeClock sets the clock used to timestamp the entries, mostly useful in tests.
+func WithTimeClock(value func() time.Time) Option {
+	return func(options *Options) {
+		options.Time.Clock = value
+	}
+}
+
 func WithConsoleEnabled(value bool) Option {
 	return func(options *Options) {
 		options.Console.Enabled = value
@@ -46,6 +340,23 @@ func WithConsoleFormatter(value string) Option {
 	}
 }
 
+// WithConsoleWriter sets where the console entries are written: STDOUT,
+// STDERR or SPLIT, which sends the entries from the split level to stderr and
+// the others to stdout.
+func WithConsoleWriter(value string) Option {
+	return func(options *Options) {
+		options.Console.Writer = value
+	}
+}
+
+// WithConsoleSplitLevel sets the level from which entries go to stderr when
+// the console writer is SPLIT.
+func WithConsoleSplitLevel(value string) Option {
+	return func(options *Options) {
+		options.Console.SplitLevel = value
+	}
+}
+
 func WithFileEnabled(value bool) Option {
 	return func(options *Options) {
 		options.File.Enabled = value
@@ -88,8 +399,730 @@ func WithFileMaxAge(value int) Option {
 	}
 }
 
+// WithFileMaxBackups sets the number of backups kept, all of them when zero.
+func WithFileMaxBackups(value int) Option {
+	return func(options *Options) {
+		options.File.MaxBackups = value
+	}
+}
+
+// WithFileMaxTotalSize sets the megabytes used by the file and its backups,
+// the oldest backups being removed beyond. Unlimited when zero.
+func WithFileMaxTotalSize(value int) Option {
+	return func(options *Options) {
+		options.File.MaxTotalSize = value
+	}
+}
+
+// WithFileRotation sets when the file is rotated, SIZE, DAILY or HOURLY. The
+// file is also rotated when it reaches MaxSize with DAILY and HOURLY. With NONE
+// it is not rotated but reopened by Reopen, on SIGHUP with ReopenOnSIGHUP, and
+// when its path leads to another file, after being rotated by an external tool.
+func WithFileRotation(value string) Option {
+	return func(options *Options) {
+		options.File.Rotation = value
+	}
+}
+
+// WithFilePattern sets the name of the backups, with time layouts between
+// braces such as application-{2006-01-02}.log.
+func WithFilePattern(value string) Option {
+	return func(options *Options) {
+		options.File.Pattern = value
+	}
+}
+
+// WithFileLocalTime sets whether the backups are named and the periods start
+// in local time instead of UTC.
+func WithFileLocalTime(value bool) Option {
+	return func(options *Options) {
+		options.File.LocalTime = value
+	}
+}
+
+// WithFileRotateOnStartup sets whether the existing file is rotated when the
+// logger is created.
+func WithFileRotateOnStartup(value bool) Option {
+	return func(options *Options) {
+		options.File.RotateOnStartup = value
+	}
+}
+
+// WithFileMode sets the permissions of the file when it is created, before
+// the umask.
+func WithFileMode(value os.FileMode) Option {
+	return func(options *Options) {
+		options.File.Mode = value
+	}
+}
+
+// WithFileDirMode sets the permissions of the missing parent directories of
+// the file when they are created, before the umask.
+func WithFileDirMode(value os.FileMode) Option {
+	return func(options *Options) {
+		options.File.DirMode = value
+	}
+}
+
+// WithFileReopenOnSIGHUP sets whether the file is reopened when the process
+// receives SIGHUP, which then no longer terminates it, when its rotation is
+// NONE.
+func WithFileReopenOnSIGHUP(value bool) Option {
+	return func(options *Options) {
+		options.File.ReopenOnSIGHUP = value
+	}
+}
+
 func WithFileFormatter(value string) Option {
 	return func(options *Options) {
 		options.File.Formatter = value
 	}
 }
+
+func WithSyslogEnabled(value bool) Option {
+	return func(options *Options) {
+		options.Syslog.Enabled = value
+	}
+}
+
+func WithSyslogLevel(value string) Option {
+	return func(options *Options) {
+		options.Syslog.Level = value
+	}
+}
+
+// WithSyslogNetwork sets the network of the syslog daemon: udp, tcp, unixgram
+// or unix.
+func WithSyslogNetwork(value string) Option {
+	return func(options *Options) {
+		options.Syslog.Network = value
+	}
+}
+
+// WithSyslogAddress sets the address of the syslog daemon, host:port or the
+// path of a socket.
+func WithSyslogAddress(value string) Option {
+	return func(options *Options) {
+		options.Syslog.Address = value
+	}
+}
+
+// WithSyslogFormat sets the format of the syslog messages, RFC5424 or RFC3164.
+func WithSyslogFormat(value string) Option {
+	return func(options *Options) {
+		options.Syslog.Format = value
+	}
+}
+
+// WithSyslogFacility sets the syslog facility, e.g. USER or LOCAL0.
+func WithSyslogFacility(value string) Option {
+	return func(options *Options) {
+		options.Syslog.Facility = value
+	}
+}
+
+// WithSyslogAppName sets the application name of the syslog messages.
+func WithSyslogAppName(value string) Option {
+	return func(options *Options) {
+		options.Syslog.AppName = value
+	}
+}
+
+// WithSyslogHostname sets the host name of the syslog messages.
+func WithSyslogHostname(value string) Option {
+	return func(options *Options) {
+		options.Syslog.Hostname = value
+	}
+}
+
+// WithSyslogProcID sets the process id of the syslog messages.
+func WithSyslogProcID(value string) Option {
+	return func(options *Options) {
+		options.Syslog.ProcID = value
+	}
+}
+
+// WithNetworkEnabled sets whether the entries are also streamed as JSON lines
+// to a network endpoint, such as a local Vector or Fluent Bit agent.
+func WithNetworkEnabled(value bool) Option {
+	return func(options *Options) {
+		options.Network.Enabled = value
+	}
+}
+
+// WithNetworkLevel sets the level of the network output.
+func WithNetworkLevel(value string) Option {
+	return func(options *Options) {
+		options.Network.Level = value
+	}
+}
+
+// WithNetworkProtocol sets the protocol of the network output: tcp, tls or udp.
+func WithNetworkProtocol(value string) Option {
+	return func(options *Options) {
+		options.Network.Protocol = value
+	}
+}
+
+// WithNetworkAddress sets the host:port the network output connects to.
+func WithNetworkAddress(value string) Option {
+	return func(options *Options) {
+		options.Network.Address = value
+	}
+}
+
+// WithNetworkBufferSize sets how many entries are kept in memory while the
+// network output is disconnected.
+func WithNetworkBufferSize(value int) Option {
+	return func(options *Options) {
+		options.Network.BufferSize = value
+	}
+}
+
+// WithNetworkSpillPath sets the file receiving the entries which overflow the
+// buffer of the network output, sent once it is drained.
+func WithNetworkSpillPath(value string) Option {
+	return func(options *Options) {
+		options.Network.SpillPath = value
+	}
+}
+
+// WithNetworkSpillMaxSize sets the largest size of the spill file in
+// megabytes, the entries which would exceed it are dropped.
+func WithNetworkSpillMaxSize(value int) Option {
+	return func(options *Options) {
+		options.Network.SpillMaxSize = value
+	}
+}
+
+// WithNetworkBackoff sets the first and the longest delays between the
+// reconnections of the network output, which double at each failure.
+func WithNetworkBackoff(min time.Duration, max time.Duration) Option {
+	return func(options *Options) {
+		options.Network.MinBackoff = min
+		options.Network.MaxBackoff = max
+	}
+}
+
+// WithNetworkTLSConfig sets the configuration of the tls connections.
+func WithNetworkTLSConfig(value *tls.Config) Option {
+	return func(options *Options) {
+		options.Network.TLSConfig = value
+	}
+}
+
+// WithNetworkMetrics sets the counters updated by the network output.
+func WithNetworkMetrics(value *NetworkMetrics) Option {
+	return func(options *Options) {
+		options.Network.Metrics = value
+	}
+}
+
+// WithGELFEnabled sets whether the entries are also sent to a Graylog GELF input.
+func WithGELFEnabled(value bool) Option {
+	return func(options *Options) {
+		options.GELF.Enabled = value
+	}
+}
+
+// WithGELFLevel sets the level of the gelf output.
+func WithGELFLevel(value string) Option {
+	return func(options *Options) {
+		options.GELF.Level = value
+	}
+}
+
+// WithGELFProtocol sets the protocol of the gelf output: udp or tcp.
+func WithGELFProtocol(value string) Option {
+	return func(options *Options) {
+		options.GELF.Protocol = value
+	}
+}
+
+// WithGELFAddress sets the host:port of the GELF input.
+func WithGELFAddress(value string) Option {
+	return func(options *Options) {
+		options.GELF.Address = value
+	}
+}
+
+// WithGELFCompression sets the compression of the udp messages: GZIP, ZLIB or
+// NONE. Messages sent over tcp are never compressed.
+func WithGELFCompression(value string) Option {
+	return func(options *Options) {
+		options.GELF.Compression = value
+	}
+}
+
+// WithGELFHost sets the host field of the messages.
+func WithGELFHost(value string) Option {
+	return func(options *Options) {
+		options.GELF.Host = value
+	}
+}
+
+// WithGELFChunkSize sets the largest udp datagram, larger messages are split
+// in chunks.
+func WithGELFChunkSize(value int) Option {
+	return func(options *Options) {
+		options.GELF.ChunkSize = value
+	}
+}
+
+// WithForwardEnabled sets whether the entries are also sent to Fluentd or
+// Fluent Bit with the Forward protocol.
+func WithForwardEnabled(value bool) Option {
+	return func(options *Options) {
+		options.Forward.Enabled = value
+	}
+}
+
+// WithForwardLevel sets the level of the forward output.
+func WithForwardLevel(value string) Option {
+	return func(options *Options) {
+		options.Forward.Level = value
+	}
+}
+
+// WithForwardAddress sets the host:port of the forward input.
+func WithForwardAddress(value string) Option {
+	return func(options *Options) {
+		options.Forward.Address = value
+	}
+}
+
+// WithForwardTag sets the tag of the entries.
+func WithForwardTag(value string) Option {
+	return func(options *Options) {
+		options.Forward.Tag = value
+	}
+}
+
+// WithForwardTagField sets the field holding the tag of an entry, instead of
+// the one set by WithForwardTag. The field is removed from the record.
+func WithForwardTagField(value string) Option {
+	return func(options *Options) {
+		options.Forward.TagField = value
+	}
+}
+
+// WithForwardMode sets the mode of the forward messages: MESSAGE, one entry
+// per message, or FORWARD and PACKED_FORWARD, one batch per message.
+func WithForwardMode(value string) Option {
+	return func(options *Options) {
+		options.Forward.Mode = value
+	}
+}
+
+// WithForwardRequireAck sets whether each message is sent again until the
+// aggregator acknowledges it, waiting at most timeout.
+func WithForwardRequireAck(value bool, timeout time.Duration) Option {
+	return func(options *Options) {
+		options.Forward.RequireAck = value
+		options.Forward.AckTimeout = timeout
+	}
+}
+
+// WithForwardBatch sets the entries of a batch and the longest wait before a
+// batch is sent.
+func WithForwardBatch(size int, flushInterval time.Duration) Option {
+	return func(options *Options) {
+		options.Forward.BatchSize = size
+		options.Forward.FlushInterval = flushInterval
+	}
+}
+
+// WithForwardBufferSize sets how many entries wait to be sent, the next ones
+// are dropped.
+func WithForwardBufferSize(value int) Option {
+	return func(options *Options) {
+		options.Forward.BufferSize = value
+	}
+}
+
+// WithForwardMaxRetries sets how many times a message not sent or not
+// acknowledged is sent again before being dropped.
+func WithForwardMaxRetries(value int) Option {
+	return func(options *Options) {
+		options.Forward.MaxRetries = value
+	}
+}
+
+// WithLokiEnabled sets whether the entries are also pushed to Grafana Loki.
+func WithLokiEnabled(value bool) Option {
+	return func(options *Options) {
+		options.Loki.Enabled = value
+	}
+}
+
+// WithLokiLevel sets the level of the loki output.
+func WithLokiLevel(value string) Option {
+	return func(options *Options) {
+		options.Loki.Level = value
+	}
+}
+
+// WithLokiURL sets the push endpoint, such as
+// http://localhost:3100/loki/api/v1/push.
+func WithLokiURL(value string) Option {
+	return func(options *Options) {
+		options.Loki.URL = value
+	}
+}
+
+// WithLokiTenantID sets the tenant of the entries, sent in the X-Scope-OrgID
+// header.
+func WithLokiTenantID(value string) Option {
+	return func(options *Options) {
+		options.Loki.TenantID = value
+	}
+}
+
+// WithLokiLabels sets the static labels of every stream.
+func WithLokiLabels(value map[string]string) Option {
+	return func(options *Options) {
+		options.Loki.Labels = value
+	}
+}
+
+// WithLokiLabelFields sets the fields promoted to stream labels. They are
+// removed from the line.
+func WithLokiLabelFields(value ...string) Option {
+	return func(options *Options) {
+		options.Loki.LabelFields = value
+	}
+}
+
+// WithLokiMaxStreams sets how many streams the promoted fields open. Beyond
+// it, the fields of the entries stay in the line.
+func WithLokiMaxStreams(value int) Option {
+	return func(options *Options) {
+		options.Loki.MaxStreams = value
+	}
+}
+
+// WithLokiEncoding sets the encoding of the pushes: JSON or PROTOBUF, snappy
+// compressed.
+func WithLokiEncoding(value string) Option {
+	return func(options *Options) {
+		options.Loki.Encoding = value
+	}
+}
+
+// WithLokiGzip sets whether the JSON pushes are gzipped.
+func WithLokiGzip(value bool) Option {
+	return func(options *Options) {
+		options.Loki.Gzip = value
+	}
+}
+
+// WithLokiBatch sets the entries of a batch and the longest wait before a
+// batch is sent.
+func WithLokiBatch(size int, wait time.Duration) Option {
+	return func(options *Options) {
+		options.Loki.BatchSize = size
+		options.Loki.BatchWait = wait
+	}
+}
+
+// WithLokiBufferSize sets how many entries wait to be sent, the next ones are
+// dropped.
+func WithLokiBufferSize(value int) Option {
+	return func(options *Options) {
+		options.Loki.BufferSize = value
+	}
+}
+
+// WithLokiTimeout sets the timeout of a push.
+func WithLokiTimeout(value time.Duration) Option {
+	return func(options *Options) {
+		options.Loki.Timeout = value
+	}
+}
+
+// WithLokiMaxRetries sets how many times a push rejected with 429 or 5xx is
+// retried.
+func WithLokiMaxRetries(value int) Option {
+	return func(options *Options) {
+		options.Loki.MaxRetries = value
+	}
+}
+
+// WithElasticsearchEnabled sets whether the entries are also indexed into
+// Elasticsearch or OpenSearch.
+func WithElasticsearchEnabled(value bool) Option {
+	return func(options *Options) {
+		options.Elasticsearch.Enabled = value
+	}
+}
+
+// WithElasticsearchLevel sets the level of the elasticsearch output.
+func WithElasticsearchLevel(value string) Option {
+	return func(options *Options) {
+		options.Elasticsearch.Level = value
+	}
+}
+
+// WithElasticsearchURL sets the base URL of the cluster, such as
+// http://localhost:9200.
+func WithElasticsearchURL(value string) Option {
+	return func(options *Options) {
+		options.Elasticsearch.URL = value
+	}
+}
+
+// WithElasticsearchIndex sets the index name template. The parts between
+// braces are time layouts, such as app-logs-{2006.01.02}, and a template
+// without braces is a layout as a whole.
+func WithElasticsearchIndex(value string) Option {
+	return func(options *Options) {
+		options.Elasticsearch.Index = value
+	}
+}
+
+// WithElasticsearchBasicAuth sets the user and password of the basic
+// authentication.
+func WithElasticsearchBasicAuth(username, password string) Option {
+	return func(options *Options) {
+		options.Elasticsearch.Username = username
+		options.Elasticsearch.Password = password
+	}
+}
+
+// WithElasticsearchAPIKey sets the encoded API key of the requests, used
+// instead of the basic authentication.
+func WithElasticsearchAPIKey(value string) Option {
+	return func(options *Options) {
+		options.Elasticsearch.APIKey = value
+	}
+}
+
+// WithElasticsearchECS sets whether the time, message, level and caller are
+// named after the Elastic Common Schema, instead of the field names.
+func WithElasticsearchECS(value bool) Option {
+	return func(options *Options) {
+		options.Elasticsearch.ECS = value
+	}
+}
+
+// WithElasticsearchBatch sets the entries of a batch and the longest wait
+// before a batch is sent.
+func WithElasticsearchBatch(size int, age time.Duration) Option {
+	return func(options *Options) {
+		options.Elasticsearch.BatchSize = size
+		options.Elasticsearch.BatchAge = age
+	}
+}
+
+// WithElasticsearchBufferSize sets how many entries wait to be sent, the next
+// ones are dropped.
+func WithElasticsearchBufferSize(value int) Option {
+	return func(options *Options) {
+		options.Elasticsearch.BufferSize = value
+	}
+}
+
+// WithElasticsearchTimeout sets the timeout of a bulk request.
+func WithElasticsearchTimeout(value time.Duration) Option {
+	return func(options *Options) {
+		options.Elasticsearch.Timeout = value
+	}
+}
+
+// WithElasticsearchMaxRetries sets how many times a bulk request or item
+// rejected with 429 or 5xx is retried.
+func WithElasticsearchMaxRetries(value int) Option {
+	return func(options *Options) {
+		options.Elasticsearch.MaxRetries = value
+	}
+}
+
+// WithOTLPEnabled sets whether the entries are also exported as OpenTelemetry
+// log records.
+func WithOTLPEnabled(value bool) Option {
+	return func(options *Options) {
+		options.OTLP.Enabled = value
+	}
+}
+
+// WithOTLPLevel sets the level of the otlp output.
+func WithOTLPLevel(value string) Option {
+	return func(options *Options) {
+		options.OTLP.Level = value
+	}
+}
+
+// WithOTLPProtocol sets the export protocol, HTTP for OTLP/HTTP or GRPC for
+// OTLP/gRPC.
+func WithOTLPProtocol(value string) Option {
+	return func(options *Options) {
+		options.OTLP.Protocol = value
+	}
+}
+
+// WithOTLPEndpoint sets the URL of the collector, such as
+// http://localhost:4318/v1/logs for HTTP or http://localhost:4317 for GRPC.
+func WithOTLPEndpoint(value string) Option {
+	return func(options *Options) {
+		options.OTLP.Endpoint = value
+	}
+}
+
+// WithOTLPHeaders sets the headers of the exports, such as an authorization.
+func WithOTLPHeaders(value map[string]string) Option {
+	return func(options *Options) {
+		options.OTLP.Headers = value
+	}
+}
+
+// WithOTLPService sets the service.name and service.version resource
+// attributes.
+func WithOTLPService(name, version string) Option {
+	return func(options *Options) {
+		options.OTLP.ServiceName = name
+		options.OTLP.ServiceVersion = version
+	}
+}
+
+// WithOTLPResourceAttributes sets the other resource attributes, such as
+// deployment.environment.
+func WithOTLPResourceAttributes(value map[string]string) Option {
+	return func(options *Options) {
+		options.OTLP.ResourceAttributes = value
+	}
+}
+
+// WithOTLPTraceFields sets the fields holding the hex trace and span ids,
+// which correlate the records with their span.
+func WithOTLPTraceFields(traceID, spanID string) Option {
+	return func(options *Options) {
+		options.OTLP.TraceIDField = traceID
+		options.OTLP.SpanIDField = spanID
+	}
+}
+
+// WithOTLPBatch sets the records of a batch and the longest wait before a
+// batch is exported.
+func WithOTLPBatch(size int, timeout time.Duration) Option {
+	return func(options *Options) {
+		options.OTLP.BatchSize = size
+		options.OTLP.BatchTimeout = timeout
+	}
+}
+
+// WithOTLPBufferSize sets how many records wait to be exported, the next ones
+// are dropped.
+func WithOTLPBufferSize(value int) Option {
+	return func(options *Options) {
+		options.OTLP.BufferSize = value
+	}
+}
+
+// WithOTLPTimeout sets the timeout of an export.
+func WithOTLPTimeout(value time.Duration) Option {
+	return func(options *Options) {
+		options.OTLP.Timeout = value
+	}
+}
+
+// WithOTLPMaxRetries sets how many times an export failed with a retryable
+// status is retried.
+func WithOTLPMaxRetries(value int) Option {
+	return func(options *Options) {
+		options.OTLP.MaxRetries = value
+	}
+}
+
+// WithOTLPSpanContext sets the function returning the hex trace and span ids of
+// the span of a context, such as the span context of OpenTelemetry. FromContext
+// adds them to the fields named by WithOTLPTraceFields.
+func WithOTLPSpanContext(value func(ctx context.Context) (traceID, spanID string)) Option {
+	return func(options *Options) {
+		options.OTLP.SpanContext = value
+	}
+}
+
+// WithJournaldEnabled sets whether the entries are also written to the systemd
+// journal, with their fields as journal fields.
+func WithJournaldEnabled(value bool) Option {
+	return func(options *Options) {
+		options.Journald.Enabled = value
+	}
+}
+
+// WithJournaldLevel sets the level of the journald output.
+func WithJournaldLevel(value string) Option {
+	return func(options *Options) {
+		options.Journald.Level = value
+	}
+}
+
+// WithJournaldSocket sets the path of the journald socket.
+func WithJournaldSocket(value string) Option {
+	return func(options *Options) {
+		options.Journald.Socket = value
+	}
+}
+
+// WithJournaldIdentifier sets the SYSLOG_IDENTIFIER of the entries.
+func WithJournaldIdentifier(value string) Option {
+	return func(options *Options) {
+		options.Journald.Identifier = value
+	}
+}
+
+// WithGCPProjectID sets the project of the traces written by the GCP formatter,
+// which links the entries to Cloud Trace.
+func WithGCPProjectID(value string) Option {
+	return func(options *Options) {
+		options.GCP.ProjectID = value
+	}
+}
+
+// WithGCPTraceIDField sets the field holding the trace id written by the GCP
+// formatter.
+func WithGCPTraceIDField(value string) Option {
+	return func(options *Options) {
+		options.GCP.TraceIDField = value
+	}
+}
+
+// WithGCPSpanIDField sets the field holding the span id written by the GCP
+// formatter.
+func WithGCPSpanIDField(value string) Option {
+	return func(options *Options) {
+		options.GCP.SpanIDField = value
+	}
+}
+
+// WithGCPHTTPRequestField sets the field holding the HTTP request written by
+// the GCP formatter.
+func WithGCPHTTPRequestField(value string) Option {
+	return func(options *Options) {
+		options.GCP.HTTPRequestField = value
+	}
+}
+
+// WithCloudWatchPrefixFields sets the fields written before the message by the
+// CLOUDWATCH formatter, such as the RequestId of a Lambda invocation.
+func WithCloudWatchPrefixFields(value ...string) Option {
+	return func(options *Options) {
+		options.CloudWatch.PrefixFields = value
+	}
+}
+
+// WithCloudWatchDisableSorting sets whether the CLOUDWATCH formatter writes
+// the fields following the message unsorted.
+func WithCloudWatchDisableSorting(value bool) Option {
+	return func(options *Options) {
+		options.CloudWatch.DisableSorting = value
+	}
+}
+
+// WithCloudWatchQuoteEmptyFields sets whether the CLOUDWATCH formatter quotes
+// empty values.
+func WithCloudWatchQuoteEmptyFields(value bool) Option {
+	return func(options *Options) {
+		options.CloudWatch.QuoteEmptyFields = value
+	}
+}
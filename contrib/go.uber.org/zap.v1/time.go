@@ -0,0 +1,79 @@
+package zap
+
+import (
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Predefined time formats, any other value is used as a time.Format layout.
+const (
+	TimeFormatISO8601     = "ISO8601"
+	TimeFormatRFC3339     = "RFC3339"
+	TimeFormatRFC3339Nano = "RFC3339NANO"
+	TimeFormatEpoch       = "EPOCH"
+	TimeFormatEpochMillis = "EPOCH_MILLIS"
+	TimeFormatEpochNanos  = "EPOCH_NANOS"
+)
+
+const iso8601Layout = "2006-01-02T15:04:05.000Z0700"
+
+// timeEncoder returns the zapcore.TimeEncoder of format. Epoch formats are
+// encoded as integers.
+func timeEncoder(format string) zapcore.TimeEncoder {
+	switch format {
+	case "", TimeFormatISO8601:
+		return zapcore.ISO8601TimeEncoder
+	case TimeFormatRFC3339:
+		return zapcore.RFC3339TimeEncoder
+	case TimeFormatRFC3339Nano:
+		return zapcore.RFC3339NanoTimeEncoder
+	case TimeFormatEpoch:
+		return func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+			enc.AppendInt64(t.Unix())
+		}
+	case TimeFormatEpochMillis:
+		return func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+			enc.AppendInt64(t.UnixMilli())
+		}
+	case TimeFormatEpochNanos:
+		return func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+			enc.AppendInt64(t.UnixNano())
+		}
+	default:
+		return zapcore.TimeEncoderOfLayout(format)
+	}
+}
+
+// timeCore sets the time of the entries from a clock and converts it to UTC.
+// The time is set when the entry is checked, before it reaches the wrapped
+// cores, so every output shares the same time.
+type timeCore struct {
+	zapcore.Core
+	clock func() time.Time
+	utc   bool
+}
+
+// withTime wraps core when the time options differ from the zap defaults.
+func withTime(core zapcore.Core, options *Options) zapcore.Core {
+	if options.Time.Clock == nil && !options.Time.UTC {
+		return core
+	}
+
+	return &timeCore{Core: core, clock: options.Time.Clock, utc: options.Time.UTC}
+}
+
+func (c *timeCore) With(fields []zapcore.Field) zapcore.Core {
+	return &timeCore{Core: c.Core.With(fields), clock: c.clock, utc: c.utc}
+}
+
+func (c *timeCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.clock != nil {
+		ent.Time = c.clock()
+	}
+	if c.utc {
+		ent.Time = ent.Time.UTC()
+	}
+
+	return c.Core.Check(ent, ce)
+}
@@ -0,0 +1,89 @@
+package zap
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type TimeSuite struct {
+	suite.Suite
+}
+
+func TestTimeSuite(t *testing.T) {
+	suite.Run(t, new(TimeSuite))
+}
+
+func (s *TimeSuite) Test_timeEncoder() {
+	at := time.Date(2021, 1, 2, 3, 4, 5, 123456789, time.UTC)
+
+	tt := []struct {
+		name   string
+		format string
+		want   string
+	}{
+		{name: "when default", format: "", want: `{"ts":"2021-01-02T03:04:05.123Z","msg":""}`},
+		{name: "when ISO8601", format: TimeFormatISO8601, want: `{"ts":"2021-01-02T03:04:05.123Z","msg":""}`},
+		{name: "when RFC3339", format: TimeFormatRFC3339, want: `{"ts":"2021-01-02T03:04:05Z","msg":""}`},
+		{name: "when RFC3339NANO", format: TimeFormatRFC3339Nano, want: `{"ts":"2021-01-02T03:04:05.123456789Z","msg":""}`},
+		{name: "when EPOCH", format: TimeFormatEpoch, want: `{"ts":1609556645,"msg":""}`},
+		{name: "when EPOCH_MILLIS", format: TimeFormatEpochMillis, want: `{"ts":1609556645123,"msg":""}`},
+		{name: "when EPOCH_NANOS", format: TimeFormatEpochNanos, want: `{"ts":1609556645123456789,"msg":""}`},
+		{name: "when layout", format: "2006/01/02 15:04:05", want: `{"ts":"2021/01/02 03:04:05","msg":""}`},
+	}
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			options := defaultOptions()
+			options.Time.Format = t.format
+
+			buf, err := getEncoder("JSON", fieldNames{Time: "ts", Message: "msg"}, options).EncodeEntry(zapcore.Entry{Time: at}, nil)
+			s.Require().NoError(err)
+			s.Assert().Equal(t.want+"\n", buf.String())
+		})
+	}
+}
+
+func (s *TimeSuite) TestTimeCore() {
+	at := time.Date(2021, 1, 2, 3, 4, 5, 0, time.FixedZone("BRT", -3*60*60))
+
+	tt := []struct {
+		name  string
+		clock func() time.Time
+		utc   bool
+		want  string
+	}{
+		{
+			name:  "with clock",
+			clock: func() time.Time { return at },
+			want:  `{"ts":"2021-01-02T03:04:05.000-0300","msg":"Blah","ID":"1"}`,
+		},
+		{
+			name:  "with clock and UTC",
+			clock: func() time.Time { return at },
+			utc:   true,
+			want:  `{"ts":"2021-01-02T06:04:05.000Z","msg":"Blah","ID":"1"}`,
+		},
+	}
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			options := defaultOptions()
+			options.Time.Clock = t.clock
+			options.Time.UTC = t.utc
+
+			buf := &bytes.Buffer{}
+			core := zapcore.NewCore(getEncoder("JSON", fieldNames{Time: "ts", Message: "msg"}, options), zapcore.AddSync(buf), zapcore.DebugLevel)
+			zap.New(withTime(core, options)).Sugar().With("ID", "1").Info("Blah")
+
+			s.Assert().Equal(t.want+"\n", buf.String())
+		})
+	}
+}
+
+func (s *TimeSuite) TestWithTimeKeepsCoreByDefault() {
+	core := zapcore.NewNopCore()
+	s.Assert().Equal(core, withTime(core, defaultOptions()))
+}
@@ -0,0 +1,80 @@
+package zerolog
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+)
+
+type AutoSuite struct {
+	suite.Suite
+}
+
+func TestAutoSuite(t *testing.T) {
+	suite.Run(t, new(AutoSuite))
+}
+
+func (s *AutoSuite) SetupTest() {
+	for _, key := range []string{"NO_COLOR", "FORCE_COLOR", "CLICOLOR", "CLICOLOR_FORCE"} {
+		s.T().Setenv(key, "")
+	}
+}
+
+// logFile logs a warning with an AUTO file output, returning the line.
+func (s *AutoSuite) logFile() string {
+	dir := s.T().TempDir()
+	NewLogger(
+		WithConsoleEnabled(false),
+		WithFileEnabled(true),
+		WithFilePath(dir),
+		WithFileName("app.log"),
+		WithFileFormatter("AUTO"),
+	).WithField("order", 1).Warn("order created")
+
+	data, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	s.Require().NoError(err)
+	return strings.TrimSuffix(string(jsonLines(data)), "\n")
+}
+
+func (s *AutoSuite) TestLoggerNotTerminal() {
+	line := s.logFile()
+	s.Assert().Regexp(`^\{"order":1,.*"log_level":"warn","log_message":"order created"\}$`, line)
+}
+
+func (s *AutoSuite) TestLoggerForceColor() {
+	s.T().Setenv("FORCE_COLOR", "1")
+
+	line := s.logFile()
+	s.Assert().Contains(line, "\x1b[33mWRN\x1b[0m")
+	s.Assert().Contains(line, "order created")
+}
+
+func (s *AutoSuite) TestLoggerNoColor() {
+	s.T().Setenv("CLICOLOR_FORCE", "1")
+	s.T().Setenv("NO_COLOR", "1")
+
+	line := s.logFile()
+	s.Assert().Regexp(`WRN .*order created order=1$`, line)
+	s.Assert().NotContains(line, "\x1b[")
+}
+
+func (s *AutoSuite) Test_consoleOut() {
+	var buf bytes.Buffer
+	s.Assert().Equal(&buf, consoleOut(&buf, "AUTO"))
+	s.Assert().Equal(&buf, consoleOut(&buf, "JSON"))
+	s.Assert().IsType(zerolog.ConsoleWriter{}, consoleOut(&buf, "TEXT"))
+
+	s.T().Setenv("FORCE_COLOR", "1")
+	s.Assert().IsType(zerolog.ConsoleWriter{}, consoleOut(&buf, "AUTO"))
+}
+
+func (s *AutoSuite) Test_colorLevel() {
+	s.Assert().Equal("\x1b[36mINF\x1b[0m", colorLevel("info"))
+	s.Assert().Equal("\x1b[37mTRC\x1b[0m", colorLevel("trace"))
+	s.Assert().Equal("???", colorLevel(nil))
+}
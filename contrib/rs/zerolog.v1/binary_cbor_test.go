@@ -0,0 +1,61 @@
+//go:build binary_log
+
+package zerolog
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/decode"
+)
+
+// TestLoggerNative runs with go test -tags binary_log, the tests of the JSON
+// formatter reading the CBOR of zerolog through jsonLines.
+func (s *BinarySuite) TestLoggerNative() {
+	dir := s.T().TempDir()
+	logger := NewLogger(
+		WithConsoleEnabled(false),
+		WithFileEnabled(true),
+		WithFilePath(dir),
+		WithFileName("app.log"),
+		WithFileFormatter("CBOR"),
+		WithFieldNames("time", "level", "message", "", ""),
+		WithTimeFormat(TimeFormatEpoch),
+		WithTimeClock(func() time.Time { return binaryTime }),
+	)
+	logger.WithField("order", 1).Info("order created")
+
+	data, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	s.Require().NoError(err)
+	s.Require().Equal(byte(0xbf), data[0], "written by zerolog, as an indefinite map")
+
+	fields, err := decode.NewDecoder(bytes.NewReader(data), decode.FormatCBOR).Decode()
+	s.Require().NoError(err)
+	s.Assert().ElementsMatch([]decode.Field{
+		{Key: "order", Value: int64(1)},
+		{Key: "time", Value: binaryTime.Unix()},
+		{Key: "level", Value: "info"},
+		{Key: "message", Value: "order created"},
+	}, fields)
+}
+
+func (s *BinarySuite) TestLoggerNativeContext() {
+	// the entries written by the outputs of the module still get the fields
+	// of the CBOR context
+	entries := s.logFile("MSGPACK", func(logger log.Logger) {
+		logger.WithFields(log.Fields{"order": 1, "tags": []string{"a"}}).WithError(errors.New("bad")).Warn("failed")
+	})
+
+	s.Assert().Equal([][]decode.Field{{
+		{Key: "time", Value: binaryTime.Local()},
+		{Key: "level", Value: "warn"},
+		{Key: "message", Value: "failed"},
+		{Key: "err", Value: "bad"},
+		{Key: "order", Value: int64(1)},
+		{Key: "tags", Value: []interface{}{"a"}},
+	}}, entries)
+}
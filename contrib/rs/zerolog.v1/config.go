@@ -0,0 +1,202 @@
+package zerolog
+
+import (
+	"os"
+	"time"
+
+	"github.com/americanas-go/log"
+)
+
+func init() {
+	log.Register("zerolog", NewLoggerFromConfig)
+}
+
+// NewLoggerFromConfig constructs a new Logger from a backend independent log.Config.
+// The zerolog backend is also available through log.New with the backend "zerolog".
+func NewLoggerFromConfig(cfg *log.Config) (log.Logger, error) {
+	options := optionsFromConfig(cfg)
+	if err := options.validate(optionPath); err != nil {
+		return nil, err
+	}
+
+	return NewLoggerWithOptions(options), nil
+}
+
+func optionsFromConfig(cfg *log.Config) *Options {
+	options := defaultOptions()
+
+	setString(&options.ErrorFieldName, cfg.ErrorFieldName)
+	setStrings(&options.PriorityFields, cfg.PriorityFields)
+
+	setString(&options.FieldNames.Time, cfg.FieldNames.Time)
+	setString(&options.FieldNames.Level, cfg.FieldNames.Level)
+	setString(&options.FieldNames.Message, cfg.FieldNames.Message)
+	setString(&options.FieldNames.Caller, cfg.FieldNames.Caller)
+	setString(&options.FieldNames.Stacktrace, cfg.FieldNames.Stacktrace)
+
+	setString(&options.Time.Format, cfg.Time.Format)
+	options.Time.UTC = cfg.Time.UTC
+
+	// the console level and formatter are the default ones of the logger
+	options.Console.Enabled = cfg.Console.Enabled
+	setString(&options.Level, cfg.Console.Level)
+	setString(&options.Formatter, cfg.Console.Formatter)
+	setString(&options.Console.Writer, cfg.Console.Writer)
+	setString(&options.Console.SplitLevel, cfg.Console.SplitLevel)
+
+	options.File.Enabled = cfg.File.Enabled
+	setString(&options.File.Level, cfg.File.Level)
+	setString(&options.File.Formatter, cfg.File.Formatter)
+	setString(&options.File.Path, cfg.File.Path)
+	setString(&options.File.Name, cfg.File.Name)
+	setInt(&options.File.MaxSize, cfg.File.MaxSize)
+	options.File.Compress = cfg.File.Compress
+	setInt(&options.File.MaxAge, cfg.File.MaxAge)
+	setInt(&options.File.MaxBackups, cfg.File.MaxBackups)
+	setInt(&options.File.MaxTotalSize, cfg.File.MaxTotalSize)
+	setString(&options.File.Rotation, cfg.File.Rotation)
+	setString(&options.File.Pattern, cfg.File.Pattern)
+	options.File.LocalTime = cfg.File.LocalTime
+	options.File.RotateOnStartup = cfg.File.RotateOnStartup
+	options.File.ReopenOnSIGHUP = cfg.File.ReopenOnSIGHUP
+	setFileMode(&options.File.Mode, cfg.File.Mode)
+	setFileMode(&options.File.DirMode, cfg.File.DirMode)
+
+	options.Syslog.Enabled = cfg.Syslog.Enabled
+	setString(&options.Syslog.Level, cfg.Syslog.Level)
+	setString(&options.Syslog.Network, cfg.Syslog.Network)
+	setString(&options.Syslog.Address, cfg.Syslog.Address)
+	setString(&options.Syslog.Format, cfg.Syslog.Format)
+	setString(&options.Syslog.Facility, cfg.Syslog.Facility)
+	setString(&options.Syslog.AppName, cfg.Syslog.AppName)
+	setString(&options.Syslog.Hostname, cfg.Syslog.Hostname)
+	setString(&options.Syslog.ProcID, cfg.Syslog.ProcID)
+
+	options.Network.Enabled = cfg.Network.Enabled
+	setString(&options.Network.Level, cfg.Network.Level)
+	setString(&options.Network.Protocol, cfg.Network.Protocol)
+	setString(&options.Network.Address, cfg.Network.Address)
+	setInt(&options.Network.BufferSize, cfg.Network.BufferSize)
+	setString(&options.Network.SpillPath, cfg.Network.SpillPath)
+	setInt(&options.Network.SpillMaxSize, cfg.Network.SpillMaxSize)
+	setDuration(&options.Network.MinBackoff, cfg.Network.MinBackoff)
+	setDuration(&options.Network.MaxBackoff, cfg.Network.MaxBackoff)
+
+	options.GELF.Enabled = cfg.GELF.Enabled
+	setString(&options.GELF.Level, cfg.GELF.Level)
+	setString(&options.GELF.Protocol, cfg.GELF.Protocol)
+	setString(&options.GELF.Address, cfg.GELF.Address)
+	setString(&options.GELF.Compression, cfg.GELF.Compression)
+	setString(&options.GELF.Host, cfg.GELF.Host)
+	setInt(&options.GELF.ChunkSize, cfg.GELF.ChunkSize)
+
+	options.Forward.Enabled = cfg.Forward.Enabled
+	setString(&options.Forward.Level, cfg.Forward.Level)
+	setString(&options.Forward.Address, cfg.Forward.Address)
+	setString(&options.Forward.Tag, cfg.Forward.Tag)
+	setString(&options.Forward.TagField, cfg.Forward.TagField)
+	setString(&options.Forward.Mode, cfg.Forward.Mode)
+	options.Forward.RequireAck = cfg.Forward.RequireAck
+	setDuration(&options.Forward.AckTimeout, cfg.Forward.AckTimeout)
+	setInt(&options.Forward.BatchSize, cfg.Forward.BatchSize)
+	setDuration(&options.Forward.FlushInterval, cfg.Forward.FlushInterval)
+	setInt(&options.Forward.BufferSize, cfg.Forward.BufferSize)
+	setInt(&options.Forward.MaxRetries, cfg.Forward.MaxRetries)
+
+	options.Loki.Enabled = cfg.Loki.Enabled
+	setString(&options.Loki.Level, cfg.Loki.Level)
+	setString(&options.Loki.URL, cfg.Loki.URL)
+	setString(&options.Loki.TenantID, cfg.Loki.TenantID)
+	setStringMap(&options.Loki.Labels, cfg.Loki.Labels)
+	setStrings(&options.Loki.LabelFields, cfg.Loki.LabelFields)
+	setInt(&options.Loki.MaxStreams, cfg.Loki.MaxStreams)
+	setString(&options.Loki.Encoding, cfg.Loki.Encoding)
+	options.Loki.Gzip = cfg.Loki.Gzip
+	setInt(&options.Loki.BatchSize, cfg.Loki.BatchSize)
+	setDuration(&options.Loki.BatchWait, cfg.Loki.BatchWait)
+	setInt(&options.Loki.BufferSize, cfg.Loki.BufferSize)
+	setDuration(&options.Loki.Timeout, cfg.Loki.Timeout)
+	setInt(&options.Loki.MaxRetries, cfg.Loki.MaxRetries)
+
+	options.Elasticsearch.Enabled = cfg.Elasticsearch.Enabled
+	setString(&options.Elasticsearch.Level, cfg.Elasticsearch.Level)
+	setString(&options.Elasticsearch.URL, cfg.Elasticsearch.URL)
+	setString(&options.Elasticsearch.Index, cfg.Elasticsearch.Index)
+	setString(&options.Elasticsearch.Username, cfg.Elasticsearch.Username)
+	setString(&options.Elasticsearch.Password, cfg.Elasticsearch.Password)
+	setString(&options.Elasticsearch.APIKey, cfg.Elasticsearch.APIKey)
+	options.Elasticsearch.ECS = cfg.Elasticsearch.ECS
+	setInt(&options.Elasticsearch.BatchSize, cfg.Elasticsearch.BatchSize)
+	setDuration(&options.Elasticsearch.BatchAge, cfg.Elasticsearch.BatchAge)
+	setInt(&options.Elasticsearch.BufferSize, cfg.Elasticsearch.BufferSize)
+	setDuration(&options.Elasticsearch.Timeout, cfg.Elasticsearch.Timeout)
+	setInt(&options.Elasticsearch.MaxRetries, cfg.Elasticsearch.MaxRetries)
+
+	options.OTLP.Enabled = cfg.OTLP.Enabled
+	setString(&options.OTLP.Level, cfg.OTLP.Level)
+	setString(&options.OTLP.Protocol, cfg.OTLP.Protocol)
+	setString(&options.OTLP.Endpoint, cfg.OTLP.Endpoint)
+	setStringMap(&options.OTLP.Headers, cfg.OTLP.Headers)
+	setString(&options.OTLP.ServiceName, cfg.OTLP.ServiceName)
+	setString(&options.OTLP.ServiceVersion, cfg.OTLP.ServiceVersion)
+	setStringMap(&options.OTLP.ResourceAttributes, cfg.OTLP.ResourceAttributes)
+	setString(&options.OTLP.TraceIDField, cfg.OTLP.TraceIDField)
+	setString(&options.OTLP.SpanIDField, cfg.OTLP.SpanIDField)
+	setInt(&options.OTLP.BatchSize, cfg.OTLP.BatchSize)
+	setDuration(&options.OTLP.BatchTimeout, cfg.OTLP.BatchTimeout)
+	setInt(&options.OTLP.BufferSize, cfg.OTLP.BufferSize)
+	setDuration(&options.OTLP.Timeout, cfg.OTLP.Timeout)
+	setInt(&options.OTLP.MaxRetries, cfg.OTLP.MaxRetries)
+
+	options.Journald.Enabled = cfg.Journald.Enabled
+	setString(&options.Journald.Level, cfg.Journald.Level)
+	setString(&options.Journald.Socket, cfg.Journald.Socket)
+	setString(&options.Journald.Identifier, cfg.Journald.Identifier)
+
+	setString(&options.GCP.ProjectID, cfg.GCP.ProjectID)
+	setString(&options.GCP.TraceIDField, cfg.GCP.TraceIDField)
+	setString(&options.GCP.SpanIDField, cfg.GCP.SpanIDField)
+	setString(&options.GCP.HTTPRequestField, cfg.GCP.HTTPRequestField)
+
+	setStrings(&options.CloudWatch.PrefixFields, cfg.CloudWatch.PrefixFields)
+	options.CloudWatch.DisableSorting = cfg.CloudWatch.DisableSorting
+	options.CloudWatch.QuoteEmptyFields = cfg.CloudWatch.QuoteEmptyFields
+
+	return options
+}
+
+func setString(dst *string, value string) {
+	if value != "" {
+		*dst = value
+	}
+}
+
+func setInt(dst *int, value int) {
+	if value != 0 {
+		*dst = value
+	}
+}
+
+func setDuration(dst *time.Duration, value time.Duration) {
+	if value != 0 {
+		*dst = value
+	}
+}
+
+func setFileMode(dst *os.FileMode, value os.FileMode) {
+	if value != 0 {
+		*dst = value
+	}
+}
+
+func setStrings(dst *[]string, value []string) {
+	if len(value) > 0 {
+		*dst = value
+	}
+}
+
+func setStringMap(dst *map[string]string, value map[string]string) {
+	if len(value) > 0 {
+		*dst = value
+	}
+}
@@ -0,0 +1,313 @@
+package zerolog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/americanas-go/log"
+	"github.com/stretchr/testify/suite"
+)
+
+type ConfigSuite struct {
+	suite.Suite
+}
+
+func TestConfigSuite(t *testing.T) {
+	suite.Run(t, new(ConfigSuite))
+}
+
+func (s *ConfigSuite) TestOptionsFromConfig() {
+	cfg := log.DefaultConfig()
+	want := defaultOptions()
+	want.File.Level = "INFO"
+	want.File.Formatter = "TEXT"
+	want.Syslog.Level = "INFO"
+	want.Network.Level = "INFO"
+	want.GELF.Level = "INFO"
+	want.Forward.Level = "INFO"
+	want.Loki.Level = "INFO"
+	want.Elasticsearch.Level = "INFO"
+	want.OTLP.Level = "INFO"
+	want.Journald.Level = "INFO"
+	s.Assert().Equal(want, optionsFromConfig(cfg))
+
+	cfg.ErrorFieldName = "error"
+	cfg.PriorityFields = []string{"request_id", "trace_id"}
+	cfg.FieldNames = log.FieldNamesConfig{Time: "@timestamp", Message: "message", Caller: "caller"}
+	cfg.Time = log.TimeConfig{Format: "EPOCH_MILLIS", UTC: true}
+	cfg.Console.Enabled = false
+	cfg.Console.Level = "DEBUG"
+	cfg.Console.Writer = "SPLIT"
+	cfg.Console.SplitLevel = "ERROR"
+	cfg.File = log.FileConfig{
+		Enabled:         true,
+		Level:           "WARN",
+		Formatter:       "JSON",
+		Path:            "/var/log",
+		Name:            "app.log",
+		MaxSize:         10,
+		Compress:        false,
+		MaxAge:          7,
+		MaxBackups:      5,
+		MaxTotalSize:    500,
+		Rotation:        "DAILY",
+		Pattern:         "app-{2006-01-02}.log",
+		LocalTime:       true,
+		RotateOnStartup: true,
+		ReopenOnSIGHUP:  true,
+		Mode:            0o640,
+		DirMode:         0o750,
+	}
+	cfg.Syslog = log.SyslogConfig{
+		Enabled:  true,
+		Level:    "ERROR",
+		Network:  "tcp",
+		Address:  "rsyslog:601",
+		Format:   "RFC3164",
+		Facility: "LOCAL0",
+		AppName:  "app",
+		Hostname: "host",
+		ProcID:   "42",
+	}
+	cfg.Network = log.NetworkConfig{
+		Enabled:      true,
+		Level:        "WARN",
+		Protocol:     "tls",
+		Address:      "vector:6000",
+		BufferSize:   64,
+		SpillPath:    "/var/spool/app.log",
+		SpillMaxSize: 10,
+		MinBackoff:   time.Second,
+		MaxBackoff:   time.Minute,
+	}
+	cfg.GELF = log.GELFConfig{
+		Enabled:     true,
+		Level:       "WARN",
+		Protocol:    "tcp",
+		Address:     "graylog:12201",
+		Compression: "ZLIB",
+		Host:        "host",
+		ChunkSize:   8192,
+	}
+	cfg.Forward = log.ForwardConfig{
+		Enabled:       true,
+		Level:         "WARN",
+		Address:       "fluent-bit:24224",
+		Tag:           "orders",
+		TagField:      "tag",
+		Mode:          "PACKED_FORWARD",
+		RequireAck:    true,
+		AckTimeout:    time.Second,
+		BatchSize:     10,
+		FlushInterval: time.Minute,
+		BufferSize:    64,
+		MaxRetries:    3,
+	}
+	cfg.Loki = log.LokiConfig{
+		Enabled:     true,
+		Level:       "WARN",
+		URL:         "http://loki:3100/loki/api/v1/push",
+		TenantID:    "team-a",
+		Labels:      map[string]string{"app": "orders"},
+		LabelFields: []string{"service"},
+		MaxStreams:  10,
+		Encoding:    "PROTOBUF",
+		Gzip:        true,
+		BatchSize:   10,
+		BatchWait:   time.Minute,
+		BufferSize:  64,
+		Timeout:     time.Second,
+		MaxRetries:  3,
+	}
+	cfg.Elasticsearch = log.ElasticsearchConfig{
+		Enabled:    true,
+		Level:      "WARN",
+		URL:        "https://es:9200",
+		Index:      "app-logs-{2006.01}",
+		Username:   "elastic",
+		Password:   "secret",
+		APIKey:     "a2V5",
+		ECS:        true,
+		BatchSize:  10,
+		BatchAge:   time.Minute,
+		BufferSize: 64,
+		Timeout:    time.Second,
+		MaxRetries: 3,
+	}
+	cfg.OTLP = log.OTLPConfig{
+		Enabled:            true,
+		Level:              "WARN",
+		Protocol:           "GRPC",
+		Endpoint:           "https://otel:4317",
+		Headers:            map[string]string{"Authorization": "Bearer token"},
+		ServiceName:        "orders",
+		ServiceVersion:     "1.2.3",
+		ResourceAttributes: map[string]string{"deployment.environment": "production"},
+		TraceIDField:       "traceId",
+		SpanIDField:        "spanId",
+		BatchSize:          10,
+		BatchTimeout:       time.Minute,
+		BufferSize:         64,
+		Timeout:            time.Second,
+		MaxRetries:         3,
+	}
+	cfg.Journald = log.JournaldConfig{
+		Enabled:    true,
+		Level:      "WARN",
+		Socket:     "/tmp/journal.sock",
+		Identifier: "orders",
+	}
+	cfg.GCP = log.GCPConfig{
+		ProjectID:        "orders-prod",
+		TraceIDField:     "traceID",
+		SpanIDField:      "spanID",
+		HTTPRequestField: "request",
+	}
+	cfg.CloudWatch = log.CloudWatchConfig{
+		PrefixFields:     []string{"RequestId", "TraceId"},
+		DisableSorting:   true,
+		QuoteEmptyFields: false,
+	}
+
+	want = defaultOptions()
+	want.ErrorFieldName = "error"
+	want.PriorityFields = []string{"request_id", "trace_id"}
+	want.FieldNames.Time = "@timestamp"
+	want.FieldNames.Message = "message"
+	want.FieldNames.Caller = "caller"
+	want.Time.Format = "EPOCH_MILLIS"
+	want.Time.UTC = true
+	want.Level = "DEBUG"
+	want.Console.Enabled = false
+	want.Console.Writer = "SPLIT"
+	want.Console.SplitLevel = "ERROR"
+	want.File.Enabled = true
+	want.File.Level = "WARN"
+	want.File.Formatter = "JSON"
+	want.File.Path = "/var/log"
+	want.File.Name = "app.log"
+	want.File.MaxSize = 10
+	want.File.Compress = false
+	want.File.MaxAge = 7
+	want.File.MaxBackups = 5
+	want.File.MaxTotalSize = 500
+	want.File.Rotation = "DAILY"
+	want.File.Pattern = "app-{2006-01-02}.log"
+	want.File.LocalTime = true
+	want.File.RotateOnStartup = true
+	want.File.ReopenOnSIGHUP = true
+	want.File.Mode = 0o640
+	want.File.DirMode = 0o750
+	want.Syslog.Enabled = true
+	want.Syslog.Level = "ERROR"
+	want.Syslog.Network = "tcp"
+	want.Syslog.Address = "rsyslog:601"
+	want.Syslog.Format = "RFC3164"
+	want.Syslog.Facility = "LOCAL0"
+	want.Syslog.AppName = "app"
+	want.Syslog.Hostname = "host"
+	want.Syslog.ProcID = "42"
+	want.Network.Enabled = true
+	want.Network.Level = "WARN"
+	want.Network.Protocol = "tls"
+	want.Network.Address = "vector:6000"
+	want.Network.BufferSize = 64
+	want.Network.SpillPath = "/var/spool/app.log"
+	want.Network.SpillMaxSize = 10
+	want.Network.MinBackoff = time.Second
+	want.Network.MaxBackoff = time.Minute
+	want.GELF.Enabled = true
+	want.GELF.Level = "WARN"
+	want.GELF.Protocol = "tcp"
+	want.GELF.Address = "graylog:12201"
+	want.GELF.Compression = "ZLIB"
+	want.GELF.Host = "host"
+	want.GELF.ChunkSize = 8192
+	want.Forward.Enabled = true
+	want.Forward.Level = "WARN"
+	want.Forward.Address = "fluent-bit:24224"
+	want.Forward.Tag = "orders"
+	want.Forward.TagField = "tag"
+	want.Forward.Mode = "PACKED_FORWARD"
+	want.Forward.RequireAck = true
+	want.Forward.AckTimeout = time.Second
+	want.Forward.BatchSize = 10
+	want.Forward.FlushInterval = time.Minute
+	want.Forward.BufferSize = 64
+	want.Forward.MaxRetries = 3
+	want.Loki.Enabled = true
+	want.Loki.Level = "WARN"
+	want.Loki.URL = "http://loki:3100/loki/api/v1/push"
+	want.Loki.TenantID = "team-a"
+	want.Loki.Labels = map[string]string{"app": "orders"}
+	want.Loki.LabelFields = []string{"service"}
+	want.Loki.MaxStreams = 10
+	want.Loki.Encoding = "PROTOBUF"
+	want.Loki.Gzip = true
+	want.Loki.BatchSize = 10
+	want.Loki.BatchWait = time.Minute
+	want.Loki.BufferSize = 64
+	want.Loki.Timeout = time.Second
+	want.Loki.MaxRetries = 3
+	want.Elasticsearch.Enabled = true
+	want.Elasticsearch.Level = "WARN"
+	want.Elasticsearch.URL = "https://es:9200"
+	want.Elasticsearch.Index = "app-logs-{2006.01}"
+	want.Elasticsearch.Username = "elastic"
+	want.Elasticsearch.Password = "secret"
+	want.Elasticsearch.APIKey = "a2V5"
+	want.Elasticsearch.ECS = true
+	want.Elasticsearch.BatchSize = 10
+	want.Elasticsearch.BatchAge = time.Minute
+	want.Elasticsearch.BufferSize = 64
+	want.Elasticsearch.Timeout = time.Second
+	want.Elasticsearch.MaxRetries = 3
+	want.OTLP.Enabled = true
+	want.OTLP.Level = "WARN"
+	want.OTLP.Protocol = "GRPC"
+	want.OTLP.Endpoint = "https://otel:4317"
+	want.OTLP.Headers = map[string]string{"Authorization": "Bearer token"}
+	want.OTLP.ServiceName = "orders"
+	want.OTLP.ServiceVersion = "1.2.3"
+	want.OTLP.ResourceAttributes = map[string]string{"deployment.environment": "production"}
+	want.OTLP.TraceIDField = "traceId"
+	want.OTLP.SpanIDField = "spanId"
+	want.OTLP.BatchSize = 10
+	want.OTLP.BatchTimeout = time.Minute
+	want.OTLP.BufferSize = 64
+	want.OTLP.Timeout = time.Second
+	want.OTLP.MaxRetries = 3
+	want.Journald.Enabled = true
+	want.Journald.Level = "WARN"
+	want.Journald.Socket = "/tmp/journal.sock"
+	want.Journald.Identifier = "orders"
+	want.GCP.ProjectID = "orders-prod"
+	want.GCP.TraceIDField = "traceID"
+	want.GCP.SpanIDField = "spanID"
+	want.GCP.HTTPRequestField = "request"
+	want.CloudWatch.PrefixFields = []string{"RequestId", "TraceId"}
+	want.CloudWatch.DisableSorting = true
+	want.CloudWatch.QuoteEmptyFields = false
+
+	s.Assert().Equal(want, optionsFromConfig(cfg))
+}
+
+func (s *ConfigSuite) TestNew() {
+	cfg := log.DefaultConfig()
+	cfg.Backend = "zerolog"
+	cfg.ErrorFieldName = "error"
+
+	l, err := log.New(cfg)
+	s.Require().NoError(err)
+	s.Assert().Equal("error", l.(*logger).errorFieldName)
+	s.Assert().Same(l, log.GetLogger())
+
+	cfg.Console.Formatter = "XML"
+	_, err = log.New(cfg)
+	s.Assert().Error(err)
+
+	cfg.Console.Formatter = "TEXT"
+	cfg.File.Level = "LOUD"
+	_, err = log.New(cfg)
+	s.Assert().Error(err)
+}
@@ -0,0 +1,176 @@
+package zerolog
+
+import (
+	"bytes"
+	"io"
+	"os"
+
+	"github.com/americanas-go/log/internal/color"
+	"github.com/americanas-go/log/internal/pretty"
+	"github.com/rs/zerolog"
+)
+
+// Console writers.
+const (
+	ConsoleWriterStdout = "STDOUT" // every event goes to stdout
+	ConsoleWriterStderr = "STDERR" // every event goes to stderr
+	ConsoleWriterSplit  = "SPLIT"  // events from the split level go to stderr, the others to stdout
+)
+
+// consoleSink is a console destination with the levels it accepts, from level
+// up to, but excluding, until.
+type consoleSink struct {
+	writer io.Writer
+	level  zerolog.Level
+	until  zerolog.Level
+}
+
+// getConsoleSinks returns the destinations of the console output, resolving
+// os.Stdout and os.Stderr when called so that they can be redirected.
+func getConsoleSinks(options *Options) []consoleSink {
+	level := logLevel(levelOrDefault(options.Console.Level, options.Level))
+	formatter := consoleFormatter(options)
+	writer := func(out io.Writer) io.Writer {
+		if formatter == "PRETTY" {
+			return prettyOut(out, options)
+		}
+		return consoleOut(out, formatter)
+	}
+
+	switch options.Console.Writer {
+	case ConsoleWriterStderr:
+		return []consoleSink{{writer: writer(os.Stderr), level: level, until: zerolog.Disabled}}
+	case ConsoleWriterSplit:
+		split := logLevel(options.Console.SplitLevel)
+		return []consoleSink{
+			{writer: writer(os.Stdout), level: level, until: split},
+			{writer: writer(os.Stderr), level: max(level, split), until: zerolog.Disabled},
+		}
+	default:
+		return []consoleSink{{writer: writer(os.Stdout), level: level, until: zerolog.Disabled}}
+	}
+}
+
+// consoleOut returns the writer of out for formatter: a console writer for
+// TEXT and for AUTO as resolved by autoOut, and out itself otherwise.
+func consoleOut(out io.Writer, formatter string) io.Writer {
+	switch formatter {
+	case "TEXT":
+		return zerolog.ConsoleWriter{Out: out}
+	case "AUTO":
+		return autoOut(out)
+	default:
+		return out
+	}
+}
+
+// autoOut returns a console writer when out gets text, as decided by
+// color.Auto, with the levels in the colors shared by the backends when the
+// text is colored, and out itself, written as JSON, otherwise.
+func autoOut(out io.Writer) io.Writer {
+	text, colored := color.Auto(out)
+	switch {
+	case !text:
+		return out
+	case colored:
+		return zerolog.ConsoleWriter{Out: out, FormatLevel: colorLevel}
+	default:
+		return zerolog.ConsoleWriter{Out: out, NoColor: true}
+	}
+}
+
+// colorLevel formats the level of an event as the console writer does, in
+// the color of the level shared by the backends.
+func colorLevel(i interface{}) string {
+	s, _ := i.(string)
+	level, err := zerolog.ParseLevel(s)
+	formatted, ok := zerolog.FormattedLevels[level]
+	if err != nil || !ok {
+		return "???"
+	}
+	return color.Level(entryLevel(level), formatted)
+}
+
+// prettyFieldsKey is the key the fields of an event are moved to by the
+// pretty console writer, which writes them below the message.
+const prettyFieldsKey = "\x00fields"
+
+// prettyOut returns a console writer writing the events for the humans reading
+// a console, as the other backends do: the level badge, the time elapsed since
+// the creation of the writer, the message and the caller, followed by the
+// fields, one per line. It is colored when out is a terminal. The events are
+// written with their time in RFC3339Nano, whatever the time format, so that the
+// elapsed time is exact.
+func prettyOut(out io.Writer, options *Options) io.Writer {
+	format := getTimeFormat(options)
+	format.format = TimeFormatRFC3339Nano
+	names := getFieldNames(options)
+	errorKey := options.ErrorFieldName
+	if errorKey == "" {
+		errorKey = defaultErrorFieldName
+	}
+	prettyOptions := pretty.Options{
+		Start:    format.now(),
+		Color:    color.Enabled(out),
+		ErrorKey: errorKey,
+		StackKey: names.Stacktrace,
+
+		PriorityKeys: options.PriorityFields,
+	}
+
+	return zerolog.ConsoleWriter{
+		Out:           out,
+		NoColor:       !prettyOptions.Color,
+		PartsOrder:    []string{zerolog.LevelFieldName, zerolog.TimestampFieldName, zerolog.MessageFieldName, zerolog.CallerFieldName},
+		FieldsExclude: []string{prettyFieldsKey},
+		FormatLevel: func(i interface{}) string {
+			s, _ := i.(string)
+			level, err := zerolog.ParseLevel(s)
+			if err != nil {
+				return "???  "
+			}
+			return pretty.Badge(entryLevel(level), prettyOptions.Color)
+		},
+		FormatTimestamp: func(i interface{}) string {
+			t, ok := format.parse(i)
+			if !ok {
+				return pretty.Since(prettyOptions.Start, prettyOptions)
+			}
+			return pretty.Since(t, prettyOptions)
+		},
+		FormatMessage: func(i interface{}) string {
+			s, _ := i.(string)
+			return s
+		},
+		FormatCaller: func(i interface{}) string {
+			s, _ := i.(string)
+			if s == "" || !prettyOptions.Color {
+				return s
+			}
+			return color.Paint(color.Faint, s)
+		},
+		FormatPrepare: func(evt map[string]interface{}) error {
+			fields := map[string]interface{}{}
+			for k, v := range evt {
+				switch k {
+				case zerolog.LevelFieldName, zerolog.TimestampFieldName, zerolog.MessageFieldName, zerolog.CallerFieldName:
+					continue
+				}
+				fields[k] = v
+				delete(evt, k)
+			}
+			evt[prettyFieldsKey] = fields
+			return nil
+		},
+		FormatExtra: func(evt map[string]interface{}, buf *bytes.Buffer) error {
+			fields, _ := evt[prettyFieldsKey].(map[string]interface{})
+			if len(fields) == 0 {
+				return nil
+			}
+			text := pretty.AppendFields(nil, fields, prettyOptions)
+			buf.WriteByte('\n')
+			buf.Write(text[:len(text)-1])
+			return nil
+		},
+	}
+}
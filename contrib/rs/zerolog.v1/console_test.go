@@ -0,0 +1,19 @@
+package zerolog
+
+import (
+	"testing"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/logtest"
+)
+
+func TestConsole(t *testing.T) {
+	logtest.Console(t, func(writer, splitLevel string) log.Logger {
+		return NewLogger(
+			WithConsoleLevel("DEBUG"),
+			WithConsoleFormatter("JSON"),
+			WithConsoleWriter(writer),
+			WithConsoleSplitLevel(splitLevel),
+		)
+	}, "log_message", jsonLines)
+}
@@ -0,0 +1,37 @@
+//go:build binary_log
+
+package zerolog
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/cbor"
+)
+
+// binaryLog reports whether zerolog is built with the binary_log tag, writing
+// CBOR instead of JSON.
+const binaryLog = true
+
+// decodeContext returns the fields of the CBOR event data. The values zerolog
+// embeds as JSON, such as those of Interface, are decoded as by the JSON build.
+func decodeContext(data []byte) log.Fields {
+	fields := log.Fields{}
+	v, err := cbor.Unmarshal(data)
+	if err != nil {
+		return fields
+	}
+	m, _ := v.(map[string]interface{})
+	for k, v := range m {
+		if raw, ok := v.(json.RawMessage); ok {
+			d := json.NewDecoder(bytes.NewReader(raw))
+			d.UseNumber()
+			if err := d.Decode(&v); err != nil {
+				v = string(raw)
+			}
+		}
+		fields[k] = v
+	}
+	return fields
+}
@@ -0,0 +1,23 @@
+//go:build !binary_log
+
+package zerolog
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/americanas-go/log"
+)
+
+// binaryLog reports whether zerolog is built with the binary_log tag, writing
+// CBOR instead of JSON.
+const binaryLog = false
+
+// decodeContext returns the fields of the JSON event data.
+func decodeContext(data []byte) log.Fields {
+	fields := log.Fields{}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	_ = d.Decode(&fields)
+	return fields
+}
@@ -0,0 +1,50 @@
+package zerolog
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/rs/zerolog"
+)
+
+// contextFields returns the fields of the context of zerologger.
+func contextFields(zerologger zerolog.Logger) log.Fields {
+	buf := &bytes.Buffer{}
+	zerologger = zerologger.Output(buf)
+	zerologger.Log().Send()
+
+	return decodeContext(buf.Bytes())
+}
+
+// writeEntry writes e, reporting the failures the way zerolog does.
+func writeEntry(w entry.Writer, e entry.Entry) {
+	if err := w.WriteEntry(e); err != nil {
+		if zerolog.ErrorHandler != nil {
+			zerolog.ErrorHandler(err)
+		} else {
+			fmt.Fprintf(os.Stderr, "zerolog: could not write event: %v\n", err)
+		}
+	}
+}
+
+func entryLevel(level zerolog.Level) log.Level {
+	switch level {
+	case zerolog.TraceLevel:
+		return log.TraceLevel
+	case zerolog.DebugLevel:
+		return log.DebugLevel
+	case zerolog.InfoLevel:
+		return log.InfoLevel
+	case zerolog.WarnLevel:
+		return log.WarnLevel
+	case zerolog.ErrorLevel:
+		return log.ErrorLevel
+	case zerolog.PanicLevel:
+		return log.PanicLevel
+	default:
+		return log.FatalLevel
+	}
+}
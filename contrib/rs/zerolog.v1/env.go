@@ -0,0 +1,58 @@
+package zerolog
+
+import (
+	"strings"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/env"
+)
+
+// FromEnv returns the Options read from environment variables.
+//
+// Every field of Options is read from a variable named after its path in upper
+// snake case, prefixed by prefix, e.g. with the prefix "LOG":
+// LOG_LEVEL, LOG_FILE_MAX_SIZE or LOG_ERROR_FIELD_NAME. Unset variables
+// keep the default (or previously applied) value. Malformed values, such as an
+// unknown level or a non numeric size, are all reported in the returned error,
+// naming their variables.
+//
+// The variables are read once, by FromEnv: the returned Options set the values
+// read then.
+func FromEnv(prefix string) ([]Option, error) {
+	options := defaultOptions()
+	apply, err := env.Capture(prefix, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := options.validate(envName(prefix)); err != nil {
+		return nil, err
+	}
+
+	return []Option{withEnv(apply)}, nil
+}
+
+// NewLoggerFromEnv constructs a new Logger from the Options read by FromEnv.
+// Options provided after the prefix take precedence over environment variables.
+func NewLoggerFromEnv(prefix string, option ...Option) (log.Logger, error) {
+	options, err := FromEnv(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewLogger(append(options, option...)...), nil
+}
+
+// envName names an option after the variable it is read from with prefix.
+func envName(prefix string) func(path string) string {
+	return func(path string) string {
+		return env.Name(strings.ToUpper(prefix), strings.Split(path, ".")...)
+	}
+}
+
+// withEnv sets the options read by FromEnv, whose values were validated.
+func withEnv(apply func(dst interface{})) Option {
+	return func(options *Options) {
+		apply(options)
+	}
+}
@@ -0,0 +1,214 @@
+package zerolog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type EnvSuite struct {
+	suite.Suite
+}
+
+func TestEnvSuite(t *testing.T) {
+	suite.Run(t, new(EnvSuite))
+}
+
+func (s *EnvSuite) TestFromEnv() {
+	s.T().Setenv("APP_LOG_FORMATTER", "JSON")
+	s.T().Setenv("APP_LOG_LEVEL", "debug")
+	s.T().Setenv("APP_LOG_CONSOLE_ENABLED", "false")
+	s.T().Setenv("APP_LOG_CONSOLE_WRITER", "STDERR")
+	s.T().Setenv("APP_LOG_CONSOLE_SPLIT_LEVEL", "ERROR")
+	s.T().Setenv("APP_LOG_FILE_ENABLED", "true")
+	s.T().Setenv("APP_LOG_FILE_PATH", "/var/log")
+	s.T().Setenv("APP_LOG_FILE_NAME", "app.log")
+	s.T().Setenv("APP_LOG_FILE_MAX_SIZE", "10")
+	s.T().Setenv("APP_LOG_FILE_COMPRESS", "false")
+	s.T().Setenv("APP_LOG_FILE_MAX_AGE", "7")
+	s.T().Setenv("APP_LOG_FILE_MAX_BACKUPS", "5")
+	s.T().Setenv("APP_LOG_FILE_ROTATION", "HOURLY")
+	s.T().Setenv("APP_LOG_FILE_LOCAL_TIME", "true")
+	s.T().Setenv("APP_LOG_FILE_MODE", "0640")
+	s.T().Setenv("APP_LOG_FILE_DIR_MODE", "750")
+	s.T().Setenv("APP_LOG_ERROR_FIELD_NAME", "error")
+	s.T().Setenv("APP_LOG_PRIORITY_FIELDS", "request_id,trace_id")
+	s.T().Setenv("APP_LOG_SYSLOG_ENABLED", "true")
+	s.T().Setenv("APP_LOG_SYSLOG_NETWORK", "unixgram")
+	s.T().Setenv("APP_LOG_SYSLOG_ADDRESS", "/dev/log")
+	s.T().Setenv("APP_LOG_SYSLOG_FACILITY", "LOCAL0")
+	s.T().Setenv("APP_LOG_SYSLOG_PROC_ID", "42")
+	s.T().Setenv("APP_LOG_NETWORK_ENABLED", "true")
+	s.T().Setenv("APP_LOG_NETWORK_PROTOCOL", "udp")
+	s.T().Setenv("APP_LOG_NETWORK_BUFFER_SIZE", "64")
+	s.T().Setenv("APP_LOG_NETWORK_MAX_BACKOFF", "1m")
+	s.T().Setenv("APP_LOG_GELF_ENABLED", "true")
+	s.T().Setenv("APP_LOG_GELF_ADDRESS", "graylog:12201")
+	s.T().Setenv("APP_LOG_GELF_COMPRESSION", "NONE")
+	s.T().Setenv("APP_LOG_GELF_CHUNK_SIZE", "8192")
+	s.T().Setenv("APP_LOG_FORWARD_ENABLED", "true")
+	s.T().Setenv("APP_LOG_FORWARD_TAG", "orders")
+	s.T().Setenv("APP_LOG_FORWARD_MODE", "MESSAGE")
+	s.T().Setenv("APP_LOG_FORWARD_FLUSH_INTERVAL", "5s")
+	s.T().Setenv("APP_LOG_LOKI_ENABLED", "true")
+	s.T().Setenv("APP_LOG_LOKI_LABELS", "app=orders,env=prod")
+	s.T().Setenv("APP_LOG_LOKI_LABEL_FIELDS", "service,region")
+	s.T().Setenv("APP_LOG_LOKI_BATCH_WAIT", "5s")
+	s.T().Setenv("APP_LOG_ELASTICSEARCH_ENABLED", "true")
+	s.T().Setenv("APP_LOG_ELASTICSEARCH_INDEX", "app-logs-{2006.01.02}")
+	s.T().Setenv("APP_LOG_ELASTICSEARCH_API_KEY", "a2V5")
+	s.T().Setenv("APP_LOG_ELASTICSEARCH_BATCH_AGE", "5s")
+	s.T().Setenv("APP_LOG_OTLP_ENABLED", "true")
+	s.T().Setenv("APP_LOG_OTLP_PROTOCOL", "GRPC")
+	s.T().Setenv("APP_LOG_OTLP_ENDPOINT", "https://otel:4317")
+	s.T().Setenv("APP_LOG_OTLP_HEADERS", "Authorization=Bearer token")
+	s.T().Setenv("APP_LOG_OTLP_SERVICE_NAME", "orders")
+	s.T().Setenv("APP_LOG_OTLP_BATCH_TIMEOUT", "5s")
+	s.T().Setenv("APP_LOG_JOURNALD_ENABLED", "true")
+	s.T().Setenv("APP_LOG_JOURNALD_SOCKET", "/tmp/journal.sock")
+	s.T().Setenv("APP_LOG_JOURNALD_IDENTIFIER", "orders")
+	s.T().Setenv("APP_LOG_GCP_PROJECT_ID", "orders-prod")
+	s.T().Setenv("APP_LOG_GCP_HTTP_REQUEST_FIELD", "request")
+	s.T().Setenv("APP_LOG_CLOUD_WATCH_PREFIX_FIELDS", "RequestId,TraceId")
+	s.T().Setenv("APP_LOG_CLOUD_WATCH_DISABLE_SORTING", "true")
+
+	opts, err := FromEnv("APP_LOG")
+	s.Require().NoError(err)
+
+	want := defaultOptions()
+	want.Formatter = "JSON"
+	want.Level = "debug"
+	want.Console.Enabled = false
+	want.Console.Writer = "STDERR"
+	want.Console.SplitLevel = "ERROR"
+	want.File.Enabled = true
+	want.File.Path = "/var/log"
+	want.File.Name = "app.log"
+	want.File.MaxSize = 10
+	want.File.Compress = false
+	want.File.MaxAge = 7
+	want.File.MaxBackups = 5
+	want.File.Rotation = "HOURLY"
+	want.File.LocalTime = true
+	want.File.Mode = 0o640
+	want.File.DirMode = 0o750
+	want.ErrorFieldName = "error"
+	want.PriorityFields = []string{"request_id", "trace_id"}
+	want.Syslog.Enabled = true
+	want.Syslog.Network = "unixgram"
+	want.Syslog.Address = "/dev/log"
+	want.Syslog.Facility = "LOCAL0"
+	want.Syslog.ProcID = "42"
+	want.Network.Enabled = true
+	want.Network.Protocol = "udp"
+	want.Network.BufferSize = 64
+	want.Network.MaxBackoff = time.Minute
+	want.GELF.Enabled = true
+	want.GELF.Address = "graylog:12201"
+	want.GELF.Compression = "NONE"
+	want.GELF.ChunkSize = 8192
+	want.Forward.Enabled = true
+	want.Forward.Tag = "orders"
+	want.Forward.Mode = "MESSAGE"
+	want.Forward.FlushInterval = 5 * time.Second
+	want.Loki.Enabled = true
+	want.Loki.Labels = map[string]string{"app": "orders", "env": "prod"}
+	want.Loki.LabelFields = []string{"service", "region"}
+	want.Loki.BatchWait = 5 * time.Second
+	want.Elasticsearch.Enabled = true
+	want.Elasticsearch.Index = "app-logs-{2006.01.02}"
+	want.Elasticsearch.APIKey = "a2V5"
+	want.Elasticsearch.BatchAge = 5 * time.Second
+	want.OTLP.Enabled = true
+	want.OTLP.Protocol = "GRPC"
+	want.OTLP.Endpoint = "https://otel:4317"
+	want.OTLP.Headers = map[string]string{"Authorization": "Bearer token"}
+	want.OTLP.ServiceName = "orders"
+	want.OTLP.BatchTimeout = 5 * time.Second
+	want.Journald.Enabled = true
+	want.Journald.Socket = "/tmp/journal.sock"
+	want.Journald.Identifier = "orders"
+	want.GCP.ProjectID = "orders-prod"
+	want.GCP.HTTPRequestField = "request"
+	want.CloudWatch.PrefixFields = []string{"RequestId", "TraceId"}
+	want.CloudWatch.DisableSorting = true
+
+	s.Assert().Equal(want, options(opts))
+}
+
+func (s *EnvSuite) TestFromEnvKeepsDefaults() {
+	opts, err := FromEnv("UNSET_LOG")
+	s.Require().NoError(err)
+	s.Assert().Equal(defaultOptions(), options(opts))
+}
+
+func (s *EnvSuite) TestFromEnvOverriddenByOptions() {
+	s.T().Setenv("LOG_LEVEL", "DEBUG")
+
+	opts, err := FromEnv("LOG")
+	s.Require().NoError(err)
+
+	got := options(append(opts, WithLevel("ERROR")))
+	s.Assert().Equal("ERROR", got.Level)
+}
+
+func (s *EnvSuite) TestFromEnvReadsOnce() {
+	s.T().Setenv("LOG_LEVEL", "DEBUG")
+
+	opts, err := FromEnv("LOG")
+	s.Require().NoError(err)
+
+	// the options keep the validated values
+	s.T().Setenv("LOG_LEVEL", "LOUD")
+	s.Assert().Equal("DEBUG", options(opts).Level)
+}
+
+func (s *EnvSuite) TestFromEnvMalformed() {
+	s.T().Setenv("LOG_LEVEL", "LOUD")
+	s.T().Setenv("LOG_FORMATTER", "XML")
+	s.T().Setenv("LOG_FILE_MAX_SIZE", "-1")
+	s.T().Setenv("LOG_CONSOLE_SPLIT_LEVEL", "LOUDER")
+	s.T().Setenv("LOG_SYSLOG_FORMAT", "RFC1")
+	s.T().Setenv("LOG_NETWORK_PROTOCOL", "sctp")
+	s.T().Setenv("LOG_GELF_COMPRESSION", "LZ4")
+	s.T().Setenv("LOG_FORWARD_MODE", "BULK")
+	s.T().Setenv("LOG_LOKI_ENCODING", "XML")
+	s.T().Setenv("LOG_ELASTICSEARCH_MAX_RETRIES", "-1")
+	s.T().Setenv("LOG_OTLP_MAX_RETRIES", "-1")
+	s.T().Setenv("LOG_JOURNALD_LEVEL", "LOUD")
+	s.T().Setenv("LOG_FILE_ROTATION", "WEEKLY")
+
+	_, err := FromEnv("LOG")
+	s.Require().Error(err)
+	s.Assert().Contains(err.Error(), "LOG_LEVEL")
+	s.Assert().Contains(err.Error(), "LOG_FORMATTER")
+	s.Assert().Contains(err.Error(), "LOG_FILE_MAX_SIZE")
+	s.Assert().Contains(err.Error(), "LOG_CONSOLE_SPLIT_LEVEL")
+	s.Assert().Contains(err.Error(), "LOG_SYSLOG_FORMAT")
+	s.Assert().Contains(err.Error(), "LOG_NETWORK_PROTOCOL")
+	s.Assert().Contains(err.Error(), "LOG_GELF_COMPRESSION")
+	s.Assert().Contains(err.Error(), "LOG_FORWARD_MODE")
+	s.Assert().Contains(err.Error(), "LOG_LOKI_ENCODING")
+	s.Assert().Contains(err.Error(), "LOG_ELASTICSEARCH_MAX_RETRIES")
+	s.Assert().Contains(err.Error(), "LOG_OTLP_MAX_RETRIES")
+	s.Assert().Contains(err.Error(), "LOG_JOURNALD_LEVEL")
+	s.Assert().Contains(err.Error(), "LOG_FILE_ROTATION")
+
+	s.T().Setenv("LOG_FILE_MAX_AGE", "a month")
+	_, err = FromEnv("LOG")
+	s.Require().Error(err)
+	s.Assert().Contains(err.Error(), "LOG_FILE_MAX_AGE")
+}
+
+func (s *EnvSuite) TestNewLoggerFromEnv() {
+	s.T().Setenv("LOG_ERROR_FIELD_NAME", "error")
+
+	l, err := NewLoggerFromEnv("LOG", WithConsoleEnabled(true))
+	s.Require().NoError(err)
+	s.Assert().Equal("error", l.(*logger).errorFieldName)
+
+	s.T().Setenv("LOG_CONSOLE_ENABLED", "nope")
+	_, err = NewLoggerFromEnv("LOG")
+	s.Assert().Error(err)
+}
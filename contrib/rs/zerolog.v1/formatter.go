@@ -0,0 +1,96 @@
+package zerolog
+
+import (
+	"io"
+
+	"github.com/americanas-go/log/internal/binlog"
+	"github.com/americanas-go/log/internal/cloudwatch"
+	"github.com/americanas-go/log/internal/ecs"
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/americanas-go/log/internal/gcp"
+	"github.com/americanas-go/log/internal/logfmt"
+)
+
+// formatterWriter returns the entry.Writer of an output whose formatter
+// encodes the entries itself, writing to w: logfmt lines for LOGFMT, the
+// "Key: value" lines of the CloudWatch Logs formatter of logrus for
+// CLOUDWATCH, Elastic Common Schema JSON lines for ECS, the structured JSON
+// lines of Google Cloud Logging for GCP and CBOR or MessagePack maps for CBOR
+// and MSGPACK. It returns nil for the formatters written by zerolog, which
+// include CBOR when zerolog is built with the binary_log tag.
+func formatterWriter(formatter string, w io.Writer, options *Options, names fieldNames) entry.Writer {
+	switch formatter {
+	case "LOGFMT":
+		// the time is written as RFC3339, as by the other backends, unless
+		// another format than the default one is set
+		keys := logfmtKeys(names)
+		logfmtOptions := logfmt.Options{
+			TimeKey:    keys.Time,
+			LevelKey:   keys.Level,
+			MessageKey: keys.Message,
+			CallerKey:  keys.Caller,
+
+			PriorityKeys: options.PriorityFields,
+		}
+		if options.Time.Format != defaultTimeFormat {
+			logfmtOptions.TimeFormat = options.Time.Format
+		}
+		return logfmt.NewWriter(w, logfmtOptions)
+	case "CLOUDWATCH":
+		return cloudwatch.NewWriter(w, cloudwatch.Options{
+			PrefixFields:     options.CloudWatch.PrefixFields,
+			DisableSorting:   options.CloudWatch.DisableSorting,
+			QuoteEmptyFields: options.CloudWatch.QuoteEmptyFields,
+			CallerKey:        names.Caller,
+
+			PriorityKeys: options.PriorityFields,
+		})
+	case "ECS":
+		return ecs.NewWriter(w, ecs.Options{
+			ErrorKey:      options.ErrorFieldName,
+			StacktraceKey: names.Stacktrace,
+			PriorityKeys:  options.PriorityFields,
+		})
+	case "GCP":
+		return gcp.NewWriter(w, gcp.Options{
+			ProjectID:      options.GCP.ProjectID,
+			TraceIDKey:     options.GCP.TraceIDField,
+			SpanIDKey:      options.GCP.SpanIDField,
+			HTTPRequestKey: options.GCP.HTTPRequestField,
+
+			PriorityKeys: options.PriorityFields,
+		})
+	case "CBOR", "MSGPACK":
+		if formatter == "CBOR" && binaryLog {
+			// written by zerolog itself when built with the binary_log tag
+			return nil
+		}
+		return binlog.NewWriter(w, binlog.Options{
+			Format:     formatter,
+			TimeKey:    names.Time,
+			LevelKey:   names.Level,
+			MessageKey: names.Message,
+			CallerKey:  names.Caller,
+
+			PriorityKeys: options.PriorityFields,
+		})
+	default:
+		return nil
+	}
+}
+
+// logfmtKeys returns names with the default time, level and message field
+// names of zerolog replaced by the default keys of logfmt, so that the LOGFMT lines
+// are the same whichever backend writes them.
+func logfmtKeys(names fieldNames) fieldNames {
+	if names.Time == defaultTimeFieldName {
+		names.Time = logfmt.DefaultTimeKey
+	}
+	if names.Level == defaultLevelFieldName {
+		names.Level = logfmt.DefaultLevelKey
+	}
+	if names.Message == defaultMessageFieldName {
+		names.Message = logfmt.DefaultMessageKey
+	}
+	return names
+}
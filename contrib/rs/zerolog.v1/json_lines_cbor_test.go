@@ -0,0 +1,52 @@
+//go:build binary_log
+
+package zerolog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+
+	"github.com/americanas-go/log/internal/cbor"
+)
+
+// jsonLines returns the JSON lines zerolog writes without the binary_log tag
+// for the CBOR maps of b, keeping the order of the keys, so that the tests of
+// the JSON formatter also run with the tag. The outputs not written by zerolog,
+// which are not CBOR, are returned unchanged.
+func jsonLines(b []byte) []byte {
+	if len(b) == 0 || b[0] != 0xbf {
+		return b
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	r := bufio.NewReader(bytes.NewReader(b))
+	d := cbor.NewDecoder(r)
+	for {
+		// the outputs framing the entries as lines add newlines to the maps
+		if c, err := r.ReadByte(); err != nil {
+			break
+		} else if c != '\n' {
+			_ = r.UnreadByte()
+		}
+		pairs, err := d.DecodeMap()
+		if err != nil {
+			break
+		}
+		buf.WriteByte('{')
+		for i, p := range pairs {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			_ = enc.Encode(p.Key)
+			buf.Truncate(buf.Len() - 1)
+			buf.WriteByte(':')
+			_ = enc.Encode(p.Value)
+			buf.Truncate(buf.Len() - 1)
+		}
+		buf.WriteString("}\n")
+	}
+	return buf.Bytes()
+}
@@ -3,30 +3,131 @@ package zerolog
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
 	"io"
 	"os"
 	"reflect"
+	"runtime"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/closer"
+	"github.com/americanas-go/log/internal/cloudwatch"
+	"github.com/americanas-go/log/internal/color"
+	"github.com/americanas-go/log/internal/elasticsearch"
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/americanas-go/log/internal/forward"
+	"github.com/americanas-go/log/internal/gcp"
+	"github.com/americanas-go/log/internal/gelf"
+	"github.com/americanas-go/log/internal/journald"
+	"github.com/americanas-go/log/internal/loki"
+	"github.com/americanas-go/log/internal/network"
+	"github.com/americanas-go/log/internal/order"
+	"github.com/americanas-go/log/internal/otlp"
+	"github.com/americanas-go/log/internal/reopen"
+	"github.com/americanas-go/log/internal/rotate"
+	"github.com/americanas-go/log/internal/syslog"
 	"github.com/rs/zerolog"
-	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 type ctxKey string
 
 const (
-	key                   ctxKey = "ctxfields"
-	defaultFormatter             = "TEXT"
-	defaultLevel                 = "INFO"
-	defaultConsoleEnabled        = true
-	defaultFileEnabled           = false
-	defaultFilePath              = "/tmp"
-	defaultFileName              = "application.log"
-	defaultFileMaxSize           = 100
-	defaultFileCompress          = true
-	defaultFileMaxAge            = 28
-	defaultErrorFieldName        = "err"
+	key                               ctxKey = "ctxfields"
+	defaultFormatter                         = "TEXT"
+	defaultLevel                             = "INFO"
+	defaultConsoleEnabled                    = true
+	defaultConsoleWriter                     = ConsoleWriterStdout
+	defaultConsoleSplitLevel                 = "WARN"
+	defaultFileEnabled                       = false
+	defaultFilePath                          = "/tmp"
+	defaultFileName                          = "application.log"
+	defaultFileMaxSize                       = 100
+	defaultFileCompress                      = true
+	defaultFileMaxAge                        = 28
+	defaultFileMaxBackups                    = 0
+	defaultFileMaxTotalSize                  = 0
+	defaultFileRotation                      = rotate.RotationSize
+	defaultFileLocalTime                     = false
+	defaultFileRotateOnStartup               = false
+	defaultFileMode                          = 0o600
+	defaultFileDirMode                       = 0o755
+	defaultErrorFieldName                    = "err"
+	defaultTimeFormat                        = TimeFormatRFC3339
+	defaultFileFormatter                     = "JSON"
+	defaultSyslogEnabled                     = false
+	defaultSyslogNetwork                     = "udp"
+	defaultSyslogAddress                     = "localhost:514"
+	defaultSyslogFormat                      = syslog.FormatRFC5424
+	defaultSyslogFacility                    = "USER"
+	defaultNetworkEnabled                    = false
+	defaultNetworkProtocol                   = network.ProtocolTCP
+	defaultNetworkAddress                    = "localhost:5170"
+	defaultNetworkBufferSize                 = 1024
+	defaultNetworkSpillMaxSize               = 100
+	defaultNetworkMinBackoff                 = 100 * time.Millisecond
+	defaultNetworkMaxBackoff                 = 30 * time.Second
+	defaultGELFEnabled                       = false
+	defaultGELFProtocol                      = gelf.ProtocolUDP
+	defaultGELFAddress                       = "localhost:12201"
+	defaultGELFCompression                   = gelf.CompressionGzip
+	defaultGELFChunkSize                     = 1420
+	defaultForwardEnabled                    = false
+	defaultForwardAddress                    = "localhost:24224"
+	defaultForwardTag                        = "app"
+	defaultForwardMode                       = forward.ModeForward
+	defaultForwardAckTimeout                 = 5 * time.Second
+	defaultForwardBatchSize                  = 100
+	defaultForwardFlushInterval              = time.Second
+	defaultForwardBufferSize                 = 1024
+	defaultForwardMaxRetries                 = 10
+	defaultLokiEnabled                       = false
+	defaultLokiURL                           = "http://localhost:3100/loki/api/v1/push"
+	defaultLokiMaxStreams                    = 100
+	defaultLokiEncoding                      = loki.EncodingJSON
+	defaultLokiBatchSize                     = 100
+	defaultLokiBatchWait                     = time.Second
+	defaultLokiBufferSize                    = 1024
+	defaultLokiTimeout                       = 10 * time.Second
+	defaultLokiMaxRetries                    = 10
+	defaultElasticsearchEnabled              = false
+	defaultElasticsearchURL                  = "http://localhost:9200"
+	defaultElasticsearchIndex                = "logs-{2006.01.02}"
+	defaultElasticsearchECS                  = true
+	defaultElasticsearchBatchSize            = 100
+	defaultElasticsearchBatchAge             = time.Second
+	defaultElasticsearchBufferSize           = 1024
+	defaultElasticsearchTimeout              = 10 * time.Second
+	defaultElasticsearchMaxRetries           = 5
+	defaultOTLPEnabled                       = false
+	defaultOTLPProtocol                      = otlp.ProtocolHTTP
+	defaultOTLPTraceIDField                  = "trace_id"
+	defaultOTLPSpanIDField                   = "span_id"
+	defaultOTLPBatchSize                     = 512
+	defaultOTLPBatchTimeout                  = time.Second
+	defaultOTLPBufferSize                    = 2048
+	defaultOTLPTimeout                       = 10 * time.Second
+	defaultOTLPMaxRetries                    = 5
+	defaultJournaldEnabled                   = false
+	defaultJournaldSocket                    = journald.DefaultSocket
+	defaultGCPTraceIDField                   = gcp.DefaultTraceIDKey
+	defaultGCPSpanIDField                    = gcp.DefaultSpanIDKey
+	defaultGCPHTTPRequestField               = gcp.DefaultHTTPRequestKey
+	defaultCloudWatchDisableSorting          = false
+	defaultCloudWatchQuoteEmptyFields        = true
+
+	defaultTimeFieldName       = "time"
+	defaultLevelFieldName      = "log_level"
+	defaultMessageFieldName    = "log_message"
+	defaultCallerFieldName     = ""
+	defaultStacktraceFieldName = "stack"
+
+	// callerSkip is the number of frames between log and the caller of the
+	// package level functions of the log package.
+	callerSkip = 3
 )
 
 // NewLogger constructs a new Logger from provided variadic Option.
@@ -37,23 +138,32 @@ func NewLogger(option ...Option) log.Logger {
 
 // NewLoggerWithOptions constructs a new Logger from provided Options.
 func NewLoggerWithOptions(options *Options) log.Logger {
-	writer := getWriter(options)
-	if writer == nil {
+	names := getFieldNames(options)
+	outputs := getOutputs(options, names)
+	if len(outputs) == 0 {
 		zerologger := zerolog.Nop()
 		logger := &logger{
 			logger: zerologger,
+			level:  zerolog.Disabled,
 		}
 
 		log.SetGlobalLogger(logger)
 		return logger
 	}
 
-	zerolog.MessageFieldName = "log_message"
-	zerolog.LevelFieldName = "log_level"
+	// the outputs encoding the entries themselves, such as syslog, are not
+	// io.Writers, Output discards when they are the only ones
+	writer := outputWriter(outputs)
+	if writer == nil {
+		writer = io.Discard
+	}
+
+	resources := &closer.Group{}
+	for _, o := range outputs {
+		resources.Add(o.entries, o.owned)
+	}
 
-	zerologger := zerolog.New(writer).With().Timestamp().Logger()
-	level := logLevel(options.Level)
-	zerologger = zerologger.Level(level)
+	zerologger := zerolog.New(writer).With().Logger()
 
 	// Default options are only applied if this is called via NewLogger
 	// If called direct, the options passed to this function may be empty.
@@ -65,42 +175,161 @@ func NewLoggerWithOptions(options *Options) log.Logger {
 
 	logger := &logger{
 		logger:         zerologger,
+		context:        log.Fields{},
 		writer:         writer,
 		fields:         log.Fields{},
 		errorFieldName: errorField,
+		level:          mostVerbose(outputs),
+		names:          names,
+		time:           getTimeFormat(options),
+		outputs:        outputs,
+		priorityFields: options.PriorityFields,
+		resources:      resources,
+		spans: &otlp.SpanContext{
+			Func:         options.OTLP.SpanContext,
+			TraceIDField: options.OTLP.TraceIDField,
+			SpanIDField:  options.OTLP.SpanIDField,
+		},
 	}
+	logger.loggers = outputLoggers(zerologger, outputs)
 
 	log.SetGlobalLogger(logger)
 	return logger
 }
 
+// outputLoggers returns the logger of each output, zerologger writing to it,
+// built once per logger rather than on every event. The entry writers, which
+// zerolog does not write to, get the zero logger.
+func outputLoggers(zerologger zerolog.Logger, outputs []output) []zerolog.Logger {
+	var loggers []zerolog.Logger
+	for _, o := range outputs {
+		var l zerolog.Logger
+		if o.entries == nil {
+			l = zerologger.Output(o.writer)
+		}
+		loggers = append(loggers, l)
+	}
+	return loggers
+}
+
+// derive returns a logger like l writing with zerologger, whose context holds
+// context, with fields as latest fields and err as error of WithError.
+func (l *logger) derive(zerologger zerolog.Logger, context log.Fields, fields log.Fields, err error) *logger {
+	derived := *l
+	derived.logger = zerologger
+	derived.loggers = outputLoggers(zerologger, l.outputs)
+	derived.context = context
+	derived.fields = fields
+	derived.err = err
+	return &derived
+}
+
 func defaultOptions() *Options {
-	return &Options{
+	options := &Options{
 		Formatter:      defaultFormatter,
 		Level:          defaultLevel,
 		ErrorFieldName: defaultErrorFieldName,
-
-		Console: struct {
-			Enabled bool
-		}{
-			Enabled: defaultConsoleEnabled,
-		},
-		File: struct {
-			Enabled  bool
-			Path     string
-			Name     string
-			MaxSize  int
-			Compress bool
-			MaxAge   int
-		}{
-			Enabled:  defaultFileEnabled,
-			Path:     defaultFilePath,
-			Name:     defaultFileName,
-			MaxSize:  defaultFileMaxSize,
-			Compress: defaultFileCompress,
-			MaxAge:   defaultFileMaxAge,
-		},
 	}
+
+	options.Time.Format = defaultTimeFormat
+
+	options.Console.Enabled = defaultConsoleEnabled
+	options.Console.Writer = defaultConsoleWriter
+	options.Console.SplitLevel = defaultConsoleSplitLevel
+
+	options.File.Enabled = defaultFileEnabled
+	options.File.Path = defaultFilePath
+	options.File.Name = defaultFileName
+	options.File.MaxSize = defaultFileMaxSize
+	options.File.Compress = defaultFileCompress
+	options.File.MaxAge = defaultFileMaxAge
+	options.File.MaxBackups = defaultFileMaxBackups
+	options.File.MaxTotalSize = defaultFileMaxTotalSize
+	options.File.Rotation = defaultFileRotation
+	options.File.LocalTime = defaultFileLocalTime
+	options.File.RotateOnStartup = defaultFileRotateOnStartup
+	options.File.Mode = defaultFileMode
+	options.File.DirMode = defaultFileDirMode
+	options.File.Formatter = defaultFileFormatter
+
+	options.Syslog.Enabled = defaultSyslogEnabled
+	options.Syslog.Network = defaultSyslogNetwork
+	options.Syslog.Address = defaultSyslogAddress
+	options.Syslog.Format = defaultSyslogFormat
+	options.Syslog.Facility = defaultSyslogFacility
+
+	options.Network.Enabled = defaultNetworkEnabled
+	options.Network.Protocol = defaultNetworkProtocol
+	options.Network.Address = defaultNetworkAddress
+	options.Network.BufferSize = defaultNetworkBufferSize
+	options.Network.SpillMaxSize = defaultNetworkSpillMaxSize
+	options.Network.MinBackoff = defaultNetworkMinBackoff
+	options.Network.MaxBackoff = defaultNetworkMaxBackoff
+
+	options.GELF.Enabled = defaultGELFEnabled
+	options.GELF.Protocol = defaultGELFProtocol
+	options.GELF.Address = defaultGELFAddress
+	options.GELF.Compression = defaultGELFCompression
+	options.GELF.ChunkSize = defaultGELFChunkSize
+
+	options.Forward.Enabled = defaultForwardEnabled
+	options.Forward.Address = defaultForwardAddress
+	options.Forward.Tag = defaultForwardTag
+	options.Forward.Mode = defaultForwardMode
+	options.Forward.AckTimeout = defaultForwardAckTimeout
+	options.Forward.BatchSize = defaultForwardBatchSize
+	options.Forward.FlushInterval = defaultForwardFlushInterval
+	options.Forward.BufferSize = defaultForwardBufferSize
+	options.Forward.MaxRetries = defaultForwardMaxRetries
+
+	options.Loki.Enabled = defaultLokiEnabled
+	options.Loki.URL = defaultLokiURL
+	options.Loki.MaxStreams = defaultLokiMaxStreams
+	options.Loki.Encoding = defaultLokiEncoding
+	options.Loki.BatchSize = defaultLokiBatchSize
+	options.Loki.BatchWait = defaultLokiBatchWait
+	options.Loki.BufferSize = defaultLokiBufferSize
+	options.Loki.Timeout = defaultLokiTimeout
+	options.Loki.MaxRetries = defaultLokiMaxRetries
+
+	options.Elasticsearch.Enabled = defaultElasticsearchEnabled
+	options.Elasticsearch.URL = defaultElasticsearchURL
+	options.Elasticsearch.Index = defaultElasticsearchIndex
+	options.Elasticsearch.ECS = defaultElasticsearchECS
+	options.Elasticsearch.BatchSize = defaultElasticsearchBatchSize
+	options.Elasticsearch.BatchAge = defaultElasticsearchBatchAge
+	options.Elasticsearch.BufferSize = defaultElasticsearchBufferSize
+	options.Elasticsearch.Timeout = defaultElasticsearchTimeout
+	options.Elasticsearch.MaxRetries = defaultElasticsearchMaxRetries
+
+	options.OTLP.Enabled = defaultOTLPEnabled
+	options.OTLP.Protocol = defaultOTLPProtocol
+	options.OTLP.TraceIDField = defaultOTLPTraceIDField
+	options.OTLP.SpanIDField = defaultOTLPSpanIDField
+	options.OTLP.BatchSize = defaultOTLPBatchSize
+	options.OTLP.BatchTimeout = defaultOTLPBatchTimeout
+	options.OTLP.BufferSize = defaultOTLPBufferSize
+	options.OTLP.Timeout = defaultOTLPTimeout
+	options.OTLP.MaxRetries = defaultOTLPMaxRetries
+
+	options.Journald.Enabled = defaultJournaldEnabled
+	options.Journald.Socket = defaultJournaldSocket
+
+	options.GCP.TraceIDField = defaultGCPTraceIDField
+	options.GCP.SpanIDField = defaultGCPSpanIDField
+	options.GCP.HTTPRequestField = defaultGCPHTTPRequestField
+
+	options.CloudWatch.PrefixFields = []string{cloudwatch.DefaultPrefixField}
+	options.CloudWatch.DisableSorting = defaultCloudWatchDisableSorting
+	options.CloudWatch.QuoteEmptyFields = defaultCloudWatchQuoteEmptyFields
+
+	options.FieldNames.Time = defaultTimeFieldName
+	options.FieldNames.Level = defaultLevelFieldName
+	options.FieldNames.Message = defaultMessageFieldName
+	options.FieldNames.Caller = defaultCallerFieldName
+	options.FieldNames.Stacktrace = defaultStacktraceFieldName
+
+	return options
 }
 
 func options(option []Option) *Options {
@@ -114,13 +343,44 @@ func options(option []Option) *Options {
 
 type logger struct {
 	logger         zerolog.Logger
+	loggers        []zerolog.Logger // logger of each output, writing to it, the zero logger for the entry writers
+	context        log.Fields       // every field of the logger, those of the context of logger
 	writer         io.Writer
 	fields         log.Fields
 	errorFieldName string
+	level          zerolog.Level
+	names          fieldNames
+	time           timeFormat
+	outputs        []output
+	priorityFields []string
+	resources      *closer.Group
+	spans          *otlp.SpanContext // ids of the span of the contexts of FromContext
+	err            error             // error of WithError, whose message is in the fields, given as is to the entry writers
+}
+
+// fieldNames holds the keys of the fields written on every event. An empty
+// key omits the field.
+type fieldNames struct {
+	Time       string
+	Level      string
+	Message    string
+	Caller     string
+	Stacktrace string
+}
+
+func getFieldNames(options *Options) fieldNames {
+	names := fieldNames(options.FieldNames)
+	if names == (fieldNames{}) {
+		return fieldNames(defaultOptions().FieldNames)
+	}
+	if names.Message == "" {
+		names.Message = defaultMessageFieldName
+	}
+	return names
 }
 
 func logLevel(level string) zerolog.Level {
-	switch level {
+	switch strings.ToUpper(level) {
 	case "TRACE":
 		return zerolog.TraceLevel
 	case "DEBUG":
@@ -138,143 +398,547 @@ func logLevel(level string) zerolog.Level {
 	}
 }
 
-func getWriter(options *Options) io.Writer {
-	var writer io.Writer
-	switch options.Formatter {
+// outputWriter returns the writer of Output, combining the console and file
+// writers, nil when both are disabled.
+func outputWriter(outputs []output) io.Writer {
+	var writers []io.Writer
+	for _, o := range outputs {
+		writers = append(writers, o.out)
+	}
+	return multiWriter(writers...)
+}
+
+// failedWriter is the writer of a file output which could not be created,
+// failing every write with the error, reported as the other write errors.
+type failedWriter struct {
+	err error
+}
+
+func (w failedWriter) Write([]byte) (int, error) {
+	return 0, w.err
+}
+
+// getFileWriter returns the writer of the file output: the file is rotated
+// unless its rotation is NONE, it is reopened after an external rotation then.
+func getFileWriter(options *Options) io.Writer {
+	s := []string{options.File.Path, "/", options.File.Name}
+	fileLocation := strings.Join(s, "")
+
+	if options.File.Rotation == rotate.RotationNone {
+		if options.File.ReopenOnSIGHUP {
+			reopen.NotifySIGHUP()
+		}
+		writer, err := reopen.New(reopen.Options{
+			Filename: fileLocation,
+			FileMode: options.File.Mode,
+			DirMode:  options.File.DirMode,
+		})
+		if err != nil {
+			return failedWriter{err: err}
+		}
+		return writer
+	}
+	return rotate.New(rotate.Options{
+		Filename:        fileLocation,
+		Rotation:        options.File.Rotation,
+		Pattern:         options.File.Pattern,
+		MaxSize:         options.File.MaxSize,
+		MaxAge:          options.File.MaxAge,
+		MaxBackups:      options.File.MaxBackups,
+		MaxTotalSize:    options.File.MaxTotalSize,
+		Compress:        options.File.Compress,
+		LocalTime:       options.File.LocalTime,
+		RotateOnStartup: options.File.RotateOnStartup,
+		FileMode:        options.File.Mode,
+		DirMode:         options.File.DirMode,
+	})
+}
+
+// fileOut returns the writer of file for the formatter of the file output: a
+// console writer without colors for TEXT, and for AUTO as resolved by autoOut,
+// the pretty console writer for PRETTY, and file itself otherwise.
+func fileOut(file io.Writer, options *Options) io.Writer {
+	switch options.File.Formatter {
 	case "TEXT":
-		writer = zerolog.ConsoleWriter{Out: os.Stdout}
-	default:
-		writer = os.Stdout
+		file = zerolog.ConsoleWriter{Out: file, NoColor: true}
+	case "AUTO":
+		file = autoOut(file)
+	case "PRETTY":
+		file = prettyOut(file, options)
 	}
+	return file
+}
+
+// Reopen reopens the files of the file outputs whose rotation is NONE, as on
+// SIGHUP, after they were rotated by an external tool such as logrotate.
+func Reopen() error {
+	return reopen.Reopen()
+}
+
+// consoleFormatter returns the formatter of the console, Options.Formatter
+// when it has none. The file output has no fallback and is written as JSON
+// unless its formatter is TEXT or PRETTY, or AUTO resolved to text.
+func consoleFormatter(options *Options) string {
+	if options.Console.Formatter != "" {
+		return options.Console.Formatter
+	}
+	return options.Formatter
+}
 
+// output is a destination of the events, with its own levels: events from
+// level up to, but excluding, until. The outputs with an entry.Writer encode
+// the events themselves instead of writing the zerolog JSON.
+type output struct {
+	writer  io.Writer
+	entries entry.Writer
+	level   zerolog.Level
+	until   zerolog.Level
+	out     io.Writer // writer of Output, set for the console and the file
+	owned   io.Writer // writer flushed and closed with the logger, other than entries
+	pretty  bool      // writer is the pretty console writer, reading the time in RFC3339Nano
+}
+
+func (o output) accepts(level zerolog.Level) bool {
+	return level >= o.level && level < o.until
+}
+
+// getOutputs returns the enabled outputs. Outputs without a level use
+// Options.Level.
+func getOutputs(options *Options, names fieldNames) []output {
+	format := getTimeFormat(options)
+
+	var outputs []output
+	if options.Console.Enabled {
+		for _, sink := range getConsoleSinks(options) {
+			o := output{
+				writer: consoleWriterFor(sink.writer, names, format, options.PriorityFields),
+				level:  sink.level,
+				until:  sink.until,
+				out:    sink.writer,
+				pretty: consoleFormatter(options) == "PRETTY",
+			}
+			if entries := formatterWriter(consoleFormatter(options), sink.writer, options, names); entries != nil {
+				o.entries, o.writer = entries, nil
+			}
+			outputs = append(outputs, o)
+		}
+	}
 	if options.File.Enabled {
-		s := []string{options.File.Path, "/", options.File.Name}
-		fileLocation := strings.Join(s, "")
+		file := getFileWriter(options)
+		out := fileOut(file, options)
+		o := output{
+			writer: consoleWriterFor(out, names, format, options.PriorityFields),
+			level:  logLevel(levelOrDefault(options.File.Level, options.Level)),
+			until:  zerolog.Disabled,
+			out:    out,
+			owned:  file,
+			pretty: options.File.Formatter == "PRETTY",
+		}
+		if entries := formatterWriter(options.File.Formatter, out, options, names); entries != nil {
+			o.entries, o.writer = entries, nil
+		}
+		outputs = append(outputs, o)
+	}
+	if options.Syslog.Enabled {
+		outputs = append(outputs, output{
+			entries: syslog.New(syslog.Options{
+				Network:  options.Syslog.Network,
+				Address:  options.Syslog.Address,
+				Format:   options.Syslog.Format,
+				Facility: options.Syslog.Facility,
+				AppName:  options.Syslog.AppName,
+				Hostname: options.Syslog.Hostname,
+				ProcID:   options.Syslog.ProcID,
 
-		fileHandler := &lumberjack.Logger{
-			Filename: fileLocation,
-			MaxSize:  options.File.MaxSize,
-			Compress: options.File.Compress,
-			MaxAge:   options.File.MaxAge,
+				PriorityKeys: options.PriorityFields,
+			}),
+			level: logLevel(levelOrDefault(options.Syslog.Level, options.Level)),
+			until: zerolog.Disabled,
+		})
+	}
+	if options.GELF.Enabled {
+		outputs = append(outputs, output{
+			entries: gelf.New(gelf.Options{
+				Protocol:    options.GELF.Protocol,
+				Address:     options.GELF.Address,
+				Compression: options.GELF.Compression,
+				Host:        options.GELF.Host,
+				ChunkSize:   options.GELF.ChunkSize,
+			}),
+			level: logLevel(levelOrDefault(options.GELF.Level, options.Level)),
+			until: zerolog.Disabled,
+		})
+	}
+	if options.Forward.Enabled {
+		outputs = append(outputs, output{
+			entries: forward.New(forward.Options{
+				Address:       options.Forward.Address,
+				Tag:           options.Forward.Tag,
+				TagField:      options.Forward.TagField,
+				Mode:          options.Forward.Mode,
+				RequireAck:    options.Forward.RequireAck,
+				AckTimeout:    options.Forward.AckTimeout,
+				BatchSize:     options.Forward.BatchSize,
+				FlushInterval: options.Forward.FlushInterval,
+				BufferSize:    options.Forward.BufferSize,
+				MaxRetries:    options.Forward.MaxRetries,
+				MessageKey:    names.Message,
+				LevelKey:      names.Level,
+				CallerKey:     names.Caller,
+
+				PriorityKeys: options.PriorityFields,
+			}),
+			level: logLevel(levelOrDefault(options.Forward.Level, options.Level)),
+			until: zerolog.Disabled,
+		})
+	}
+	if options.Loki.Enabled {
+		outputs = append(outputs, output{
+			entries: loki.New(loki.Options{
+				URL:         options.Loki.URL,
+				TenantID:    options.Loki.TenantID,
+				Labels:      options.Loki.Labels,
+				LabelFields: options.Loki.LabelFields,
+				MaxStreams:  options.Loki.MaxStreams,
+				Encoding:    options.Loki.Encoding,
+				Gzip:        options.Loki.Gzip,
+				BatchSize:   options.Loki.BatchSize,
+				BatchWait:   options.Loki.BatchWait,
+				BufferSize:  options.Loki.BufferSize,
+				Timeout:     options.Loki.Timeout,
+				MaxRetries:  options.Loki.MaxRetries,
+				MessageKey:  names.Message,
+				LevelKey:    names.Level,
+				CallerKey:   names.Caller,
+			}),
+			level: logLevel(levelOrDefault(options.Loki.Level, options.Level)),
+			until: zerolog.Disabled,
+		})
+	}
+	if options.Elasticsearch.Enabled {
+		outputs = append(outputs, output{
+			entries: elasticsearch.New(elasticsearch.Options{
+				URL:        options.Elasticsearch.URL,
+				Index:      options.Elasticsearch.Index,
+				Username:   options.Elasticsearch.Username,
+				Password:   options.Elasticsearch.Password,
+				APIKey:     options.Elasticsearch.APIKey,
+				ECS:        options.Elasticsearch.ECS,
+				BatchSize:  options.Elasticsearch.BatchSize,
+				BatchAge:   options.Elasticsearch.BatchAge,
+				BufferSize: options.Elasticsearch.BufferSize,
+				Timeout:    options.Elasticsearch.Timeout,
+				MaxRetries: options.Elasticsearch.MaxRetries,
+				TimeKey:    names.Time,
+				MessageKey: names.Message,
+				LevelKey:   names.Level,
+				CallerKey:  names.Caller,
+			}),
+			level: logLevel(levelOrDefault(options.Elasticsearch.Level, options.Level)),
+			until: zerolog.Disabled,
+		})
+	}
+
+	if options.OTLP.Enabled {
+		outputs = append(outputs, output{
+			entries: otlp.New(otlp.Options{
+				Protocol:           options.OTLP.Protocol,
+				Endpoint:           options.OTLP.Endpoint,
+				Headers:            options.OTLP.Headers,
+				ServiceName:        options.OTLP.ServiceName,
+				ServiceVersion:     options.OTLP.ServiceVersion,
+				ResourceAttributes: options.OTLP.ResourceAttributes,
+				TraceIDField:       options.OTLP.TraceIDField,
+				SpanIDField:        options.OTLP.SpanIDField,
+				BatchSize:          options.OTLP.BatchSize,
+				BatchTimeout:       options.OTLP.BatchTimeout,
+				BufferSize:         options.OTLP.BufferSize,
+				Timeout:            options.OTLP.Timeout,
+				MaxRetries:         options.OTLP.MaxRetries,
+
+				PriorityKeys: options.PriorityFields,
+			}),
+			level: logLevel(levelOrDefault(options.OTLP.Level, options.Level)),
+			until: zerolog.Disabled,
+		})
+	}
+
+	if options.Journald.Enabled {
+		outputs = append(outputs, output{
+			entries: journald.New(journald.Options{
+				Socket:     options.Journald.Socket,
+				Identifier: options.Journald.Identifier,
+
+				PriorityKeys: options.PriorityFields,
+			}),
+			level: logLevel(levelOrDefault(options.Journald.Level, options.Level)),
+			until: zerolog.Disabled,
+		})
+	}
+	// the network output is always JSON lines
+	if options.Network.Enabled {
+		writer := network.New(network.Options{
+			Protocol:     options.Network.Protocol,
+			Address:      options.Network.Address,
+			TLSConfig:    options.Network.TLSConfig,
+			BufferSize:   options.Network.BufferSize,
+			SpillPath:    options.Network.SpillPath,
+			SpillMaxSize: options.Network.SpillMaxSize,
+			MinBackoff:   options.Network.MinBackoff,
+			MaxBackoff:   options.Network.MaxBackoff,
+			Metrics:      options.Network.Metrics,
+		})
+		outputs = append(outputs, output{
+			writer: writer,
+			level:  logLevel(levelOrDefault(options.Network.Level, options.Level)),
+			until:  zerolog.Disabled,
+			owned:  writer,
+		})
+	}
+	return outputs
+}
+
+func levelOrDefault(level string, defaultLevel string) string {
+	if level == "" {
+		return defaultLevel
+	}
+	return level
+}
+
+// mostVerbose returns the most verbose level of outputs.
+func mostVerbose(outputs []output) zerolog.Level {
+	level := zerolog.Disabled
+	for _, o := range outputs {
+		if o.level < level {
+			level = o.level
 		}
+	}
+	return level
+}
 
-		if options.Console.Enabled {
-			return io.MultiWriter(writer, fileHandler)
+// multiWriter combines the non nil writers, returning nil when there is none.
+func multiWriter(writers ...io.Writer) io.Writer {
+	var enabled []io.Writer
+	for _, w := range writers {
+		if w != nil {
+			enabled = append(enabled, w)
 		}
-		return fileHandler
-	} else if options.Console.Enabled {
-		return writer
 	}
 
-	return nil
+	switch len(enabled) {
+	case 0:
+		return nil
+	case 1:
+		return enabled[0]
+	default:
+		return io.MultiWriter(enabled...)
+	}
 }
 
 func (l *logger) Printf(format string, args ...interface{}) {
-	l.logger.Printf(format, args...)
+	l.log(zerolog.DebugLevel, fmt.Sprintf(format, args...))
 }
 
 func (l *logger) Tracef(format string, args ...interface{}) {
-	l.logger.Trace().Msgf(format, args...)
+	l.log(zerolog.TraceLevel, fmt.Sprintf(format, args...))
 }
 
 func (l *logger) Trace(args ...interface{}) {
-	format := bytes.NewBufferString("")
-	for range args {
-		format.WriteString("%v")
-	}
-
-	l.logger.Trace().Msgf(format.String(), args...)
+	l.log(zerolog.TraceLevel, concat(args))
 }
 
 func (l *logger) Debugf(format string, args ...interface{}) {
-	l.logger.Debug().Msgf(format, args...)
+	l.log(zerolog.DebugLevel, fmt.Sprintf(format, args...))
 }
 
 func (l *logger) Debug(args ...interface{}) {
-	format := bytes.NewBufferString("")
-	for range args {
-		format.WriteString("%v")
-	}
-
-	l.logger.Debug().Msgf(format.String(), args...)
+	l.log(zerolog.DebugLevel, concat(args))
 }
 
 func (l *logger) Infof(format string, args ...interface{}) {
-	l.logger.Info().Msgf(format, args...)
+	l.log(zerolog.InfoLevel, fmt.Sprintf(format, args...))
 }
 
 func (l *logger) Info(args ...interface{}) {
-	format := bytes.NewBufferString("")
-	for range args {
-		format.WriteString("%v")
-	}
-
-	l.logger.Info().Msgf(format.String(), args...)
+	l.log(zerolog.InfoLevel, concat(args))
 }
 
 func (l *logger) Warnf(format string, args ...interface{}) {
-	l.logger.Warn().Msgf(format, args...)
+	l.log(zerolog.WarnLevel, fmt.Sprintf(format, args...))
 }
 
 func (l *logger) Warn(args ...interface{}) {
-	format := bytes.NewBufferString("")
-	for range args {
-		format.WriteString("%v")
-	}
-
-	l.logger.Warn().Msgf(format.String(), args...)
+	l.log(zerolog.WarnLevel, concat(args))
 }
 
 func (l *logger) Errorf(format string, args ...interface{}) {
-	l.logger.Error().Msgf(format, args...)
+	l.log(zerolog.ErrorLevel, fmt.Sprintf(format, args...))
 }
 
 func (l *logger) Error(args ...interface{}) {
-	format := bytes.NewBufferString("")
-	for range args {
-		format.WriteString("%v")
-	}
-
-	l.logger.Error().Msgf(format.String(), args...)
+	l.log(zerolog.ErrorLevel, concat(args))
 }
 
+// Fatalf logs a templated message, sends the entries buffered by the outputs
+// and calls os.Exit(1).
 func (l *logger) Fatalf(format string, args ...interface{}) {
-	l.logger.Fatal().Msgf(format, args...)
+	l.log(zerolog.FatalLevel, fmt.Sprintf(format, args...))
+	_ = l.Sync()
+	os.Exit(1)
 }
 
+// Fatal logs a message, sends the entries buffered by the outputs and calls
+// os.Exit(1).
 func (l *logger) Fatal(args ...interface{}) {
-	format := bytes.NewBufferString("")
-	for range args {
-		format.WriteString("%v")
-	}
-
-	l.logger.Fatal().Msgf(format.String(), args...)
+	l.log(zerolog.FatalLevel, concat(args))
+	_ = l.Sync()
+	os.Exit(1)
 }
 
 func (l *logger) Panicf(format string, args ...interface{}) {
-	l.logger.Panic().Msgf(format, args...)
+	msg := fmt.Sprintf(format, args...)
+	l.log(zerolog.PanicLevel, msg)
+	_ = l.Sync()
+	panic(msg)
 }
 
 func (l *logger) Panic(args ...interface{}) {
+	msg := concat(args)
+	l.log(zerolog.PanicLevel, msg)
+	_ = l.Sync()
+	panic(msg)
+}
+
+// Sync sends the entries buffered by the outputs, such as the batches of the
+// loki output, and waits for them to be sent.
+func (l *logger) Sync() error {
+	return l.resources.Flush()
+}
+
+// Close syncs the logger then closes its outputs, which l shares with the
+// loggers it was derived from and those derived from it.
+func (l *logger) Close() error {
+	return l.resources.Close()
+}
+
+// log writes msg at level to every output enabled for it.
+// zerolog writes the level, message and timestamp fields with the keys of its
+// package level variables, so they are written here instead, which keeps the
+// field names of each logger independent. An event is built for each output,
+// so that each one has its own level, like the cores of zap.
+func (l *logger) log(level zerolog.Level, msg string) {
+	if level < l.level {
+		return
+	}
+
+	t := l.time.now()
+
+	var caller, function string
+	if l.names.Caller != "" {
+		if pc, file, line, ok := runtime.Caller(callerSkip); ok {
+			caller = zerolog.CallerMarshalFunc(pc, file, line)
+			if f := runtime.FuncForPC(pc); f != nil {
+				function = f.Name()
+			}
+		}
+	}
+
+	var fields log.Fields
+	for i, o := range l.outputs {
+		if !o.accepts(level) {
+			continue
+		}
+
+		if o.entries != nil {
+			if fields == nil {
+				fields = make(log.Fields, len(l.context)+1)
+				for k, v := range l.context {
+					fields[k] = v
+				}
+				if l.err != nil {
+					fields[l.errorFieldName] = l.err
+				}
+			}
+			writeEntry(o.entries, entry.Entry{Time: t, Level: entryLevel(level), Message: msg, Caller: caller, Function: function, Fields: fields})
+			continue
+		}
+
+		e := l.loggers[i].Log()
+		if e == nil {
+			continue
+		}
+
+		if l.names.Time != "" {
+			format := l.time
+			if o.pretty {
+				format.format = TimeFormatRFC3339Nano
+			}
+			format.append(e, l.names.Time, t)
+		}
+		if l.names.Level != "" {
+			e.Str(l.names.Level, zerolog.LevelFieldMarshalFunc(level))
+		}
+		if caller != "" {
+			e.Str(l.names.Caller, caller)
+		}
+
+		e.Str(l.names.Message, msg).Send()
+	}
+}
+
+// concat formats args the way zerolog formats the Msgf("%v%v...") calls, i.e.
+// without spaces between operands.
+func concat(args []interface{}) string {
 	format := bytes.NewBufferString("")
 	for range args {
 		format.WriteString("%v")
 	}
 
-	l.logger.Panic().Msgf(format.String(), args...)
+	return fmt.Sprintf(format.String(), args...)
 }
 
 func (l *logger) WithField(key string, value interface{}) log.Logger {
 	newField := make(map[string]interface{})
 	newField[key] = value
 
-	newLogger := l.logger.With().Fields(newField).Logger()
-	return &logger{newLogger, l.writer, newField, l.errorFieldName}
+	err := l.err
+	if key == l.errorFieldName {
+		err = nil
+	}
+
+	newLogger, context := l.withContext(newField)
+	return l.derive(newLogger, context, newField, err)
 }
 
 func (l *logger) WithFields(fields map[string]interface{}) log.Logger {
-	newLogger := l.logger.With().Fields(fields).Logger()
-	return &logger{newLogger, l.writer, fields, l.errorFieldName}
+	err := l.err
+	if _, ok := fields[l.errorFieldName]; ok {
+		err = nil
+	}
+
+	newLogger, context := l.withContext(fields)
+	return l.derive(newLogger, context, fields, err)
+}
+
+// withContext returns the zerolog logger of l with fields added to its
+// context, and the fields of the context. zerolog writes the context fields in
+// the order they were added, so the context is rebuilt with the priority fields
+// first and the others sorted by key, which writes the fields in the same order
+// on every event.
+func (l *logger) withContext(fields map[string]interface{}) (zerolog.Logger, log.Fields) {
+	merged := make(log.Fields, len(l.context)+len(fields))
+	for k, v := range l.context {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+
+	list := make([]interface{}, 0, 2*len(merged))
+	for _, k := range order.Keys(merged, l.priorityFields) {
+		list = append(list, k, merged[k])
+	}
+	return zerolog.New(l.writer).Level(l.logger.GetLevel()).With().Fields(list).Logger(), merged
 }
 
 func (l *logger) WithTypeOf(obj interface{}) log.Logger {
@@ -288,7 +952,16 @@ func (l *logger) WithTypeOf(obj interface{}) log.Logger {
 }
 
 func (l *logger) WithError(err error) log.Logger {
-	return l.WithField(l.errorFieldName, err.Error())
+	fields := map[string]interface{}{
+		l.errorFieldName: err.Error(),
+	}
+	if zerolog.ErrorStackMarshaler != nil && l.names.Stacktrace != "" {
+		fields[l.names.Stacktrace] = zerolog.ErrorStackMarshaler(err)
+	}
+
+	newLogger := l.WithFields(fields).(*logger)
+	newLogger.err = err
+	return newLogger
 }
 
 func (l *logger) Fields() log.Fields {
@@ -303,18 +976,132 @@ func (l *logger) ToContext(ctx context.Context) context.Context {
 	return l.logger.WithContext(context.WithValue(ctx, key, l.fields))
 }
 
+// FromContext returns the logger of ctx, or l when ctx holds none, with the ids
+// of the span of ctx when a span context function is set.
 func (l *logger) FromContext(ctx context.Context) log.Logger {
+	from := l
 	zerologger := zerolog.Ctx(ctx)
-	if zerologger.GetLevel() == zerolog.Disabled {
-		return l
-	}
-	rawFields := ctx.Value(key)
-	fields := log.Fields{}
-	if rawFields != nil {
-		switch v := rawFields.(type) {
-		case log.Fields:
-			fields = v
+	if zerologger.GetLevel() != zerolog.Disabled {
+		rawFields := ctx.Value(key)
+		fields := log.Fields{}
+		if rawFields != nil {
+			switch v := rawFields.(type) {
+			case log.Fields:
+				fields = v
+			}
 		}
+		// the fields of the context of a logger of another package are decoded
+		from = l.derive(*zerologger, contextFields(*zerologger), fields, nil)
+	}
+
+	if fields := l.spans.Fields(ctx); fields != nil {
+		return from.WithFields(fields)
+	}
+	return from
+}
+
+// consoleWriter is a zerolog.ConsoleWriter aware of the field names, time
+// format and priority fields of a logger.
+type consoleWriter struct {
+	zerolog.ConsoleWriter
+	names    fieldNames
+	time     timeFormat
+	priority []string
+}
+
+// consoleWriterFor wraps writer when it is a zerolog.ConsoleWriter, so that it
+// recognizes the configured field names and time format, and writes the
+// priority fields first.
+func consoleWriterFor(writer io.Writer, names fieldNames, format timeFormat, priority []string) io.Writer {
+	if w, ok := writer.(zerolog.ConsoleWriter); ok {
+		return consoleWriter{w, names, format, priority}
+	}
+	return writer
+}
+
+func (w consoleWriter) Write(p []byte) (int, error) {
+	cw := w.ConsoleWriter
+	cw.FormatPrepare = w.prepare(cw.FormatPrepare)
+	if cw.FormatTimestamp == nil {
+		cw.FormatTimestamp = w.time.formatTimestamp(cw)
+	}
+	if len(w.priority) > 0 {
+		// the console writer sorts the fields, the priority ones are written
+		// as parts following the message instead
+		parts := cw.PartsOrder
+		if parts == nil {
+			parts = []string{zerolog.TimestampFieldName, zerolog.LevelFieldName, zerolog.CallerFieldName, zerolog.MessageFieldName}
+		}
+		cw.PartsOrder = append(parts[:len(parts):len(parts)], w.priority...)
+		cw.FieldsExclude = append(cw.FieldsExclude[:len(cw.FieldsExclude):len(cw.FieldsExclude)], w.priority...)
+	}
+	return cw.Write(p)
+}
+
+// prepare returns a preparation renaming the fields of the event to the
+// zerolog field names, which are the ones the console writer formats, before
+// the preparation of the console writer, if any. The priority fields are then
+// replaced by their key=value text, written as parts.
+func (w consoleWriter) prepare(next func(map[string]interface{}) error) func(map[string]interface{}) error {
+	return func(evt map[string]interface{}) error {
+		rename(evt, w.names.Time, zerolog.TimestampFieldName)
+		rename(evt, w.names.Level, zerolog.LevelFieldName)
+		rename(evt, w.names.Message, zerolog.MessageFieldName)
+		rename(evt, w.names.Caller, zerolog.CallerFieldName)
+		if next != nil {
+			if err := next(evt); err != nil {
+				return err
+			}
+		}
+		for _, k := range w.priority {
+			evt[k] = w.priorityField(evt, k)
+		}
+		return nil
+	}
+}
+
+// priorityField returns the key=value text of the field k of the event, as
+// the console writer writes the other fields, or an empty string, omitting
+// the part, when the event has no such field.
+func (w consoleWriter) priorityField(evt map[string]interface{}, k string) string {
+	v, ok := evt[k]
+	if !ok {
+		return ""
+	}
+
+	var value string
+	switch v := v.(type) {
+	case string:
+		value = v
+		if strings.ContainsFunc(v, func(r rune) bool { return r < 0x20 || r > 0x7e || r == ' ' || r == '\\' || r == '"' }) {
+			value = strconv.Quote(v)
+		}
+	case json.Number:
+		value = v.String()
+	default:
+		b, err := zerolog.InterfaceMarshalFunc(v)
+		if err != nil {
+			return ""
+		}
+		value = string(b)
+	}
+
+	switch {
+	case w.FormatFieldName != nil:
+		return w.FormatFieldName(k) + value
+	case w.NoColor || os.Getenv("NO_COLOR") != "":
+		return k + "=" + value
+	default:
+		return color.Paint(color.Cyan, k+"=") + value
+	}
+}
+
+func rename(evt map[string]interface{}, from string, to string) {
+	if from == "" || from == to {
+		return
+	}
+	if v, ok := evt[from]; ok {
+		delete(evt, from)
+		evt[to] = v
 	}
-	return &logger{*zerologger, l.writer, fields, l.errorFieldName}
 }
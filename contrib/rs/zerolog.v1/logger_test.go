@@ -4,11 +4,13 @@ import (
 	// "github.com/stretchr/testify/mock"
 
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -269,6 +271,71 @@ func (s *LoggerSuite) TestLogger() {
 	}
 }
 
+func (s *LoggerSuite) TestLoggerFieldNames() {
+	tt := []struct {
+		name      string
+		formatter string
+		names     [5]string
+		want      func(out string)
+	}{
+		{
+			name:      "json formatter with custom field names",
+			formatter: "JSON",
+			names:     [5]string{"@timestamp", "level", "message", "caller", "stacktrace"},
+			want: func(out string) {
+				entry := map[string]interface{}{}
+				s.Require().NoError(json.Unmarshal([]byte(out), &entry))
+				s.Assert().Contains(entry, "@timestamp")
+				s.Assert().Equal("info", entry["level"])
+				s.Assert().Equal("Blah", entry["message"])
+				s.Assert().Contains(entry["caller"], "logger_test.go")
+				s.Assert().NotContains(entry, "log_message")
+				s.Assert().NotContains(entry, "log_level")
+			},
+		},
+		{
+			name:      "json formatter omitting time and level",
+			formatter: "JSON",
+			names:     [5]string{"", "", "message", "", ""},
+			want: func(out string) {
+				s.Assert().Equal(`{"message":"Blah"}`+"\n", out)
+			},
+		},
+		{
+			name:      "text formatter with custom field names",
+			formatter: "TEXT",
+			names:     [5]string{"@timestamp", "level", "message", "", ""},
+			want: func(out string) {
+				s.Assert().Contains(out, "INF")
+				s.Assert().Contains(out, "Blah")
+				s.Assert().NotContains(out, "message=")
+			},
+		},
+	}
+
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			original := os.Stdout
+			r, w, _ := os.Pipe()
+			os.Stdout = w
+			NewLogger(
+				WithFormatter(t.formatter),
+				WithFieldNames(t.names[0], t.names[1], t.names[2], t.names[3], t.names[4]),
+			)
+			os.Stdout = original
+
+			// the caller is reported for the package level functions
+			log.Info("Blah")
+			w.Close()
+			b, _ := io.ReadAll(r)
+
+			t.want(string(jsonLines(b)))
+			s.Assert().Equal("message", zerolog.MessageFieldName)
+			s.Assert().Equal("level", zerolog.LevelFieldName)
+		})
+	}
+}
+
 func (s *LoggerSuite) TestLoggerFatal() {
 	tt := []struct {
 		name   string
@@ -333,6 +400,7 @@ func (s *LoggerSuite) TestLoggerWithMethods() {
 				}
 				return &logger{
 					logger:         zerolog.New(os.Stdout).With().Fields(fields).Logger(),
+					context:        fields,
 					fields:         fields,
 					writer:         os.Stdout,
 					errorFieldName: l.errorFieldName,
@@ -354,6 +422,7 @@ func (s *LoggerSuite) TestLoggerWithMethods() {
 				}
 				return &logger{
 					logger:         zerolog.New(os.Stdout).With().Fields(fields).Logger(),
+					context:        fields,
 					fields:         fields,
 					writer:         os.Stdout,
 					errorFieldName: l.errorFieldName,
@@ -373,6 +442,7 @@ func (s *LoggerSuite) TestLoggerWithMethods() {
 				}
 				return &logger{
 					logger:         zerolog.New(os.Stdout).With().Fields(fields).Logger(),
+					context:        fields,
 					fields:         fields,
 					writer:         os.Stdout,
 					errorFieldName: l.errorFieldName,
@@ -390,9 +460,11 @@ func (s *LoggerSuite) TestLoggerWithMethods() {
 				}
 				return &logger{
 					logger:         zerolog.New(os.Stdout).With().Fields(fields).Logger(),
+					context:        fields,
 					fields:         fields,
 					writer:         os.Stdout,
 					errorFieldName: l.errorFieldName,
+					err:            errors.New("something bad"),
 				}
 			},
 		},
@@ -493,3 +565,30 @@ func (s *LoggerSuite) TestLoggerFromContext() {
 		})
 	}
 }
+
+func (s *LoggerSuite) TestLoggerFileReopen() {
+	dir := filepath.Join(s.T().TempDir(), "logs")
+	logger := NewLogger(
+		WithConsoleEnabled(false),
+		WithFileEnabled(true),
+		WithFilePath(dir),
+		WithFileName("app.log"),
+		WithFileRotation("NONE"),
+	)
+
+	logger.Info("before")
+	// logrotate renames the file, then sends SIGHUP
+	s.Require().NoError(os.Rename(filepath.Join(dir, "app.log"), filepath.Join(dir, "app.log.1")))
+	s.Require().NoError(Reopen())
+	logger.Info("after")
+
+	rotated, err := os.ReadFile(filepath.Join(dir, "app.log.1"))
+	s.Require().NoError(err)
+	s.Assert().Contains(string(rotated), "before")
+	s.Assert().NotContains(string(rotated), "after")
+
+	reopened, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	s.Require().NoError(err)
+	s.Assert().Contains(string(reopened), "after")
+	s.Assert().NotContains(string(reopened), "before")
+}
@@ -0,0 +1,21 @@
+package zerolog
+
+import (
+	"testing"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/logtest"
+)
+
+func TestNetwork(t *testing.T) {
+	logtest.Network(t, func(address string, metrics *NetworkMetrics) log.Logger {
+		return NewLogger(
+			WithConsoleEnabled(false),
+			WithFieldNames("ts", "level", "msg", "", ""),
+			WithNetworkEnabled(true),
+			WithNetworkLevel("WARN"),
+			WithNetworkAddress(address),
+			WithNetworkMetrics(metrics),
+		)
+	}, jsonLines)
+}
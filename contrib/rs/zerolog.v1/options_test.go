@@ -1,8 +1,12 @@
 package zerolog
 
 import (
+	"context"
+	"crypto/tls"
+	"os"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 )
@@ -29,6 +33,605 @@ func (s *OptionsSuite) TestOptionsWithMethods() {
 			got:    func(o *Options) interface{} { return o.Console.Enabled },
 			method: WithConsoleEnabled(true),
 		},
+		{
+			name:   "Options with console level",
+			want:   "DEBUG",
+			got:    func(o *Options) interface{} { return o.Console.Level },
+			method: WithConsoleLevel("DEBUG"),
+		},
+		{
+			name:   "Options with console formatter",
+			want:   "JSON",
+			got:    func(o *Options) interface{} { return o.Console.Formatter },
+			method: WithConsoleFormatter("JSON"),
+		},
+		{
+			name:   "Options with console writer",
+			want:   "SPLIT",
+			got:    func(o *Options) interface{} { return o.Console.Writer },
+			method: WithConsoleWriter("SPLIT"),
+		},
+		{
+			name:   "Options with console split level",
+			want:   "ERROR",
+			got:    func(o *Options) interface{} { return o.Console.SplitLevel },
+			method: WithConsoleSplitLevel("ERROR"),
+		},
+		{
+			name:   "Options with syslog enabled",
+			want:   true,
+			got:    func(o *Options) interface{} { return o.Syslog.Enabled },
+			method: WithSyslogEnabled(true),
+		},
+		{
+			name:   "Options with syslog level",
+			want:   "WARN",
+			got:    func(o *Options) interface{} { return o.Syslog.Level },
+			method: WithSyslogLevel("WARN"),
+		},
+		{
+			name:   "Options with syslog network",
+			want:   "tcp",
+			got:    func(o *Options) interface{} { return o.Syslog.Network },
+			method: WithSyslogNetwork("tcp"),
+		},
+		{
+			name:   "Options with syslog address",
+			want:   "/dev/log",
+			got:    func(o *Options) interface{} { return o.Syslog.Address },
+			method: WithSyslogAddress("/dev/log"),
+		},
+		{
+			name:   "Options with syslog format",
+			want:   "RFC3164",
+			got:    func(o *Options) interface{} { return o.Syslog.Format },
+			method: WithSyslogFormat("RFC3164"),
+		},
+		{
+			name:   "Options with syslog facility",
+			want:   "LOCAL0",
+			got:    func(o *Options) interface{} { return o.Syslog.Facility },
+			method: WithSyslogFacility("LOCAL0"),
+		},
+		{
+			name:   "Options with syslog app name",
+			want:   "app",
+			got:    func(o *Options) interface{} { return o.Syslog.AppName },
+			method: WithSyslogAppName("app"),
+		},
+		{
+			name:   "Options with syslog hostname",
+			want:   "host",
+			got:    func(o *Options) interface{} { return o.Syslog.Hostname },
+			method: WithSyslogHostname("host"),
+		},
+		{
+			name:   "Options with syslog proc id",
+			want:   "42",
+			got:    func(o *Options) interface{} { return o.Syslog.ProcID },
+			method: WithSyslogProcID("42"),
+		},
+		{
+			name:   "Options with network enabled",
+			want:   true,
+			got:    func(o *Options) interface{} { return o.Network.Enabled },
+			method: WithNetworkEnabled(true),
+		},
+		{
+			name:   "Options with network level",
+			want:   "WARN",
+			got:    func(o *Options) interface{} { return o.Network.Level },
+			method: WithNetworkLevel("WARN"),
+		},
+		{
+			name:   "Options with network protocol",
+			want:   "udp",
+			got:    func(o *Options) interface{} { return o.Network.Protocol },
+			method: WithNetworkProtocol("udp"),
+		},
+		{
+			name:   "Options with network address",
+			want:   "vector:6000",
+			got:    func(o *Options) interface{} { return o.Network.Address },
+			method: WithNetworkAddress("vector:6000"),
+		},
+		{
+			name:   "Options with network buffer size",
+			want:   64,
+			got:    func(o *Options) interface{} { return o.Network.BufferSize },
+			method: WithNetworkBufferSize(64),
+		},
+		{
+			name:   "Options with network spill path",
+			want:   "/var/spool/app.log",
+			got:    func(o *Options) interface{} { return o.Network.SpillPath },
+			method: WithNetworkSpillPath("/var/spool/app.log"),
+		},
+		{
+			name:   "Options with network spill max size",
+			want:   10,
+			got:    func(o *Options) interface{} { return o.Network.SpillMaxSize },
+			method: WithNetworkSpillMaxSize(10),
+		},
+		{
+			name:   "Options with network min backoff",
+			want:   time.Second,
+			got:    func(o *Options) interface{} { return o.Network.MinBackoff },
+			method: WithNetworkBackoff(time.Second, time.Minute),
+		},
+		{
+			name:   "Options with network max backoff",
+			want:   time.Minute,
+			got:    func(o *Options) interface{} { return o.Network.MaxBackoff },
+			method: WithNetworkBackoff(time.Second, time.Minute),
+		},
+		{
+			name:   "Options with network tls config",
+			want:   &tls.Config{ServerName: "vector"},
+			got:    func(o *Options) interface{} { return o.Network.TLSConfig },
+			method: WithNetworkTLSConfig(&tls.Config{ServerName: "vector"}),
+		},
+		{
+			name:   "Options with network metrics",
+			want:   &NetworkMetrics{},
+			got:    func(o *Options) interface{} { return o.Network.Metrics },
+			method: WithNetworkMetrics(&NetworkMetrics{}),
+		},
+		{
+			name:   "Options with gelf enabled",
+			want:   true,
+			got:    func(o *Options) interface{} { return o.GELF.Enabled },
+			method: WithGELFEnabled(true),
+		},
+		{
+			name:   "Options with gelf level",
+			want:   "WARN",
+			got:    func(o *Options) interface{} { return o.GELF.Level },
+			method: WithGELFLevel("WARN"),
+		},
+		{
+			name:   "Options with gelf protocol",
+			want:   "tcp",
+			got:    func(o *Options) interface{} { return o.GELF.Protocol },
+			method: WithGELFProtocol("tcp"),
+		},
+		{
+			name:   "Options with gelf address",
+			want:   "graylog:12201",
+			got:    func(o *Options) interface{} { return o.GELF.Address },
+			method: WithGELFAddress("graylog:12201"),
+		},
+		{
+			name:   "Options with gelf compression",
+			want:   "ZLIB",
+			got:    func(o *Options) interface{} { return o.GELF.Compression },
+			method: WithGELFCompression("ZLIB"),
+		},
+		{
+			name:   "Options with gelf host",
+			want:   "host",
+			got:    func(o *Options) interface{} { return o.GELF.Host },
+			method: WithGELFHost("host"),
+		},
+		{
+			name:   "Options with gelf chunk size",
+			want:   8192,
+			got:    func(o *Options) interface{} { return o.GELF.ChunkSize },
+			method: WithGELFChunkSize(8192),
+		},
+		{
+			name:   "Options with forward enabled",
+			want:   true,
+			got:    func(o *Options) interface{} { return o.Forward.Enabled },
+			method: WithForwardEnabled(true),
+		},
+		{
+			name:   "Options with forward level",
+			want:   "WARN",
+			got:    func(o *Options) interface{} { return o.Forward.Level },
+			method: WithForwardLevel("WARN"),
+		},
+		{
+			name:   "Options with forward address",
+			want:   "fluent-bit:24224",
+			got:    func(o *Options) interface{} { return o.Forward.Address },
+			method: WithForwardAddress("fluent-bit:24224"),
+		},
+		{
+			name:   "Options with forward tag",
+			want:   "orders",
+			got:    func(o *Options) interface{} { return o.Forward.Tag },
+			method: WithForwardTag("orders"),
+		},
+		{
+			name:   "Options with forward tag field",
+			want:   "tag",
+			got:    func(o *Options) interface{} { return o.Forward.TagField },
+			method: WithForwardTagField("tag"),
+		},
+		{
+			name:   "Options with forward mode",
+			want:   "MESSAGE",
+			got:    func(o *Options) interface{} { return o.Forward.Mode },
+			method: WithForwardMode("MESSAGE"),
+		},
+		{
+			name:   "Options with forward require ack",
+			want:   true,
+			got:    func(o *Options) interface{} { return o.Forward.RequireAck },
+			method: WithForwardRequireAck(true, time.Second),
+		},
+		{
+			name:   "Options with forward ack timeout",
+			want:   time.Second,
+			got:    func(o *Options) interface{} { return o.Forward.AckTimeout },
+			method: WithForwardRequireAck(true, time.Second),
+		},
+		{
+			name:   "Options with forward batch size",
+			want:   10,
+			got:    func(o *Options) interface{} { return o.Forward.BatchSize },
+			method: WithForwardBatch(10, time.Minute),
+		},
+		{
+			name:   "Options with forward flush interval",
+			want:   time.Minute,
+			got:    func(o *Options) interface{} { return o.Forward.FlushInterval },
+			method: WithForwardBatch(10, time.Minute),
+		},
+		{
+			name:   "Options with forward buffer size",
+			want:   64,
+			got:    func(o *Options) interface{} { return o.Forward.BufferSize },
+			method: WithForwardBufferSize(64),
+		},
+		{
+			name:   "Options with forward max retries",
+			want:   3,
+			got:    func(o *Options) interface{} { return o.Forward.MaxRetries },
+			method: WithForwardMaxRetries(3),
+		},
+		{
+			name:   "Options with loki enabled",
+			want:   true,
+			got:    func(o *Options) interface{} { return o.Loki.Enabled },
+			method: WithLokiEnabled(true),
+		},
+		{
+			name:   "Options with loki level",
+			want:   "WARN",
+			got:    func(o *Options) interface{} { return o.Loki.Level },
+			method: WithLokiLevel("WARN"),
+		},
+		{
+			name:   "Options with loki url",
+			want:   "http://loki:3100/loki/api/v1/push",
+			got:    func(o *Options) interface{} { return o.Loki.URL },
+			method: WithLokiURL("http://loki:3100/loki/api/v1/push"),
+		},
+		{
+			name:   "Options with loki tenant id",
+			want:   "team-a",
+			got:    func(o *Options) interface{} { return o.Loki.TenantID },
+			method: WithLokiTenantID("team-a"),
+		},
+		{
+			name:   "Options with loki labels",
+			want:   map[string]string{"app": "orders"},
+			got:    func(o *Options) interface{} { return o.Loki.Labels },
+			method: WithLokiLabels(map[string]string{"app": "orders"}),
+		},
+		{
+			name:   "Options with loki label fields",
+			want:   []string{"service", "region"},
+			got:    func(o *Options) interface{} { return o.Loki.LabelFields },
+			method: WithLokiLabelFields("service", "region"),
+		},
+		{
+			name:   "Options with loki max streams",
+			want:   10,
+			got:    func(o *Options) interface{} { return o.Loki.MaxStreams },
+			method: WithLokiMaxStreams(10),
+		},
+		{
+			name:   "Options with loki encoding",
+			want:   "PROTOBUF",
+			got:    func(o *Options) interface{} { return o.Loki.Encoding },
+			method: WithLokiEncoding("PROTOBUF"),
+		},
+		{
+			name:   "Options with loki gzip",
+			want:   true,
+			got:    func(o *Options) interface{} { return o.Loki.Gzip },
+			method: WithLokiGzip(true),
+		},
+		{
+			name:   "Options with loki batch size",
+			want:   10,
+			got:    func(o *Options) interface{} { return o.Loki.BatchSize },
+			method: WithLokiBatch(10, time.Minute),
+		},
+		{
+			name:   "Options with loki batch wait",
+			want:   time.Minute,
+			got:    func(o *Options) interface{} { return o.Loki.BatchWait },
+			method: WithLokiBatch(10, time.Minute),
+		},
+		{
+			name:   "Options with loki buffer size",
+			want:   64,
+			got:    func(o *Options) interface{} { return o.Loki.BufferSize },
+			method: WithLokiBufferSize(64),
+		},
+		{
+			name:   "Options with loki timeout",
+			want:   time.Second,
+			got:    func(o *Options) interface{} { return o.Loki.Timeout },
+			method: WithLokiTimeout(time.Second),
+		},
+		{
+			name:   "Options with loki max retries",
+			want:   3,
+			got:    func(o *Options) interface{} { return o.Loki.MaxRetries },
+			method: WithLokiMaxRetries(3),
+		},
+		{
+			name:   "Options with elasticsearch enabled",
+			want:   true,
+			got:    func(o *Options) interface{} { return o.Elasticsearch.Enabled },
+			method: WithElasticsearchEnabled(true),
+		},
+		{
+			name:   "Options with elasticsearch level",
+			want:   "WARN",
+			got:    func(o *Options) interface{} { return o.Elasticsearch.Level },
+			method: WithElasticsearchLevel("WARN"),
+		},
+		{
+			name:   "Options with elasticsearch url",
+			want:   "https://es:9200",
+			got:    func(o *Options) interface{} { return o.Elasticsearch.URL },
+			method: WithElasticsearchURL("https://es:9200"),
+		},
+		{
+			name:   "Options with elasticsearch index",
+			want:   "app-logs-{2006.01}",
+			got:    func(o *Options) interface{} { return o.Elasticsearch.Index },
+			method: WithElasticsearchIndex("app-logs-{2006.01}"),
+		},
+		{
+			name:   "Options with elasticsearch username",
+			want:   "elastic",
+			got:    func(o *Options) interface{} { return o.Elasticsearch.Username },
+			method: WithElasticsearchBasicAuth("elastic", "secret"),
+		},
+		{
+			name:   "Options with elasticsearch password",
+			want:   "secret",
+			got:    func(o *Options) interface{} { return o.Elasticsearch.Password },
+			method: WithElasticsearchBasicAuth("elastic", "secret"),
+		},
+		{
+			name:   "Options with elasticsearch api key",
+			want:   "a2V5",
+			got:    func(o *Options) interface{} { return o.Elasticsearch.APIKey },
+			method: WithElasticsearchAPIKey("a2V5"),
+		},
+		{
+			name:   "Options with elasticsearch ecs",
+			want:   false,
+			got:    func(o *Options) interface{} { return o.Elasticsearch.ECS },
+			method: WithElasticsearchECS(false),
+		},
+		{
+			name:   "Options with elasticsearch batch size",
+			want:   10,
+			got:    func(o *Options) interface{} { return o.Elasticsearch.BatchSize },
+			method: WithElasticsearchBatch(10, time.Minute),
+		},
+		{
+			name:   "Options with elasticsearch batch age",
+			want:   time.Minute,
+			got:    func(o *Options) interface{} { return o.Elasticsearch.BatchAge },
+			method: WithElasticsearchBatch(10, time.Minute),
+		},
+		{
+			name:   "Options with elasticsearch buffer size",
+			want:   64,
+			got:    func(o *Options) interface{} { return o.Elasticsearch.BufferSize },
+			method: WithElasticsearchBufferSize(64),
+		},
+		{
+			name:   "Options with elasticsearch timeout",
+			want:   time.Second,
+			got:    func(o *Options) interface{} { return o.Elasticsearch.Timeout },
+			method: WithElasticsearchTimeout(time.Second),
+		},
+		{
+			name:   "Options with elasticsearch max retries",
+			want:   3,
+			got:    func(o *Options) interface{} { return o.Elasticsearch.MaxRetries },
+			method: WithElasticsearchMaxRetries(3),
+		},
+		{
+			name:   "Options with otlp enabled",
+			want:   true,
+			got:    func(o *Options) interface{} { return o.OTLP.Enabled },
+			method: WithOTLPEnabled(true),
+		},
+		{
+			name:   "Options with otlp level",
+			want:   "WARN",
+			got:    func(o *Options) interface{} { return o.OTLP.Level },
+			method: WithOTLPLevel("WARN"),
+		},
+		{
+			name:   "Options with otlp protocol",
+			want:   "GRPC",
+			got:    func(o *Options) interface{} { return o.OTLP.Protocol },
+			method: WithOTLPProtocol("GRPC"),
+		},
+		{
+			name:   "Options with otlp endpoint",
+			want:   "http://otel:4317",
+			got:    func(o *Options) interface{} { return o.OTLP.Endpoint },
+			method: WithOTLPEndpoint("http://otel:4317"),
+		},
+		{
+			name:   "Options with otlp headers",
+			want:   map[string]string{"Authorization": "Bearer token"},
+			got:    func(o *Options) interface{} { return o.OTLP.Headers },
+			method: WithOTLPHeaders(map[string]string{"Authorization": "Bearer token"}),
+		},
+		{
+			name:   "Options with otlp service name",
+			want:   "orders",
+			got:    func(o *Options) interface{} { return o.OTLP.ServiceName },
+			method: WithOTLPService("orders", "1.2.3"),
+		},
+		{
+			name:   "Options with otlp service version",
+			want:   "1.2.3",
+			got:    func(o *Options) interface{} { return o.OTLP.ServiceVersion },
+			method: WithOTLPService("orders", "1.2.3"),
+		},
+		{
+			name:   "Options with otlp resource attributes",
+			want:   map[string]string{"deployment.environment": "production"},
+			got:    func(o *Options) interface{} { return o.OTLP.ResourceAttributes },
+			method: WithOTLPResourceAttributes(map[string]string{"deployment.environment": "production"}),
+		},
+		{
+			name:   "Options with otlp trace id field",
+			want:   "traceId",
+			got:    func(o *Options) interface{} { return o.OTLP.TraceIDField },
+			method: WithOTLPTraceFields("traceId", "spanId"),
+		},
+		{
+			name:   "Options with otlp span id field",
+			want:   "spanId",
+			got:    func(o *Options) interface{} { return o.OTLP.SpanIDField },
+			method: WithOTLPTraceFields("traceId", "spanId"),
+		},
+		{
+			name:   "Options with otlp batch size",
+			want:   10,
+			got:    func(o *Options) interface{} { return o.OTLP.BatchSize },
+			method: WithOTLPBatch(10, time.Minute),
+		},
+		{
+			name:   "Options with otlp batch timeout",
+			want:   time.Minute,
+			got:    func(o *Options) interface{} { return o.OTLP.BatchTimeout },
+			method: WithOTLPBatch(10, time.Minute),
+		},
+		{
+			name:   "Options with otlp buffer size",
+			want:   64,
+			got:    func(o *Options) interface{} { return o.OTLP.BufferSize },
+			method: WithOTLPBufferSize(64),
+		},
+		{
+			name:   "Options with otlp timeout",
+			want:   time.Second,
+			got:    func(o *Options) interface{} { return o.OTLP.Timeout },
+			method: WithOTLPTimeout(time.Second),
+		},
+		{
+			name:   "Options with otlp max retries",
+			want:   3,
+			got:    func(o *Options) interface{} { return o.OTLP.MaxRetries },
+			method: WithOTLPMaxRetries(3),
+		},
+		{
+			name: "Options with otlp span context",
+			want: []string{"4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"},
+			got: func(o *Options) interface{} {
+				traceID, spanID := o.OTLP.SpanContext(context.Background())
+				return []string{traceID, spanID}
+			},
+			method: WithOTLPSpanContext(func(context.Context) (string, string) {
+				return "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"
+			}),
+		},
+		{
+			name:   "Options with journald enabled",
+			want:   true,
+			got:    func(o *Options) interface{} { return o.Journald.Enabled },
+			method: WithJournaldEnabled(true),
+		},
+		{
+			name:   "Options with journald level",
+			want:   "WARN",
+			got:    func(o *Options) interface{} { return o.Journald.Level },
+			method: WithJournaldLevel("WARN"),
+		},
+		{
+			name:   "Options with journald socket",
+			want:   "/tmp/journal.sock",
+			got:    func(o *Options) interface{} { return o.Journald.Socket },
+			method: WithJournaldSocket("/tmp/journal.sock"),
+		},
+		{
+			name:   "Options with journald identifier",
+			want:   "orders",
+			got:    func(o *Options) interface{} { return o.Journald.Identifier },
+			method: WithJournaldIdentifier("orders"),
+		},
+		{
+			name:   "Options with gcp project id",
+			want:   "orders-prod",
+			got:    func(o *Options) interface{} { return o.GCP.ProjectID },
+			method: WithGCPProjectID("orders-prod"),
+		},
+		{
+			name:   "Options with gcp trace id field",
+			want:   "traceID",
+			got:    func(o *Options) interface{} { return o.GCP.TraceIDField },
+			method: WithGCPTraceIDField("traceID"),
+		},
+		{
+			name:   "Options with gcp span id field",
+			want:   "spanID",
+			got:    func(o *Options) interface{} { return o.GCP.SpanIDField },
+			method: WithGCPSpanIDField("spanID"),
+		},
+		{
+			name:   "Options with gcp http request field",
+			want:   "request",
+			got:    func(o *Options) interface{} { return o.GCP.HTTPRequestField },
+			method: WithGCPHTTPRequestField("request"),
+		},
+		{
+			name:   "Options with cloudwatch prefix fields",
+			want:   []string{"RequestId", "TraceId"},
+			got:    func(o *Options) interface{} { return o.CloudWatch.PrefixFields },
+			method: WithCloudWatchPrefixFields("RequestId", "TraceId"),
+		},
+		{
+			name:   "Options with cloudwatch disable sorting",
+			want:   true,
+			got:    func(o *Options) interface{} { return o.CloudWatch.DisableSorting },
+			method: WithCloudWatchDisableSorting(true),
+		},
+		{
+			name:   "Options with cloudwatch quote empty fields",
+			want:   false,
+			got:    func(o *Options) interface{} { return o.CloudWatch.QuoteEmptyFields },
+			method: WithCloudWatchQuoteEmptyFields(false),
+		},
+		{
+			name:   "Options with file level",
+			want:   "WARN",
+			got:    func(o *Options) interface{} { return o.File.Level },
+			method: WithFileLevel("WARN"),
+		},
+		{
+			name:   "Options with file formatter",
+			want:   "TEXT",
+			got:    func(o *Options) interface{} { return o.File.Formatter },
+			method: WithFileFormatter("TEXT"),
+		},
 		{
 			name:   "Options with level",
 			want:   "TRACE",
@@ -53,6 +656,60 @@ func (s *OptionsSuite) TestOptionsWithMethods() {
 			got:    func(o *Options) interface{} { return o.File.MaxAge },
 			method: WithFileMaxAge(7),
 		},
+		{
+			name:   "Options with file max backups",
+			want:   5,
+			got:    func(o *Options) interface{} { return o.File.MaxBackups },
+			method: WithFileMaxBackups(5),
+		},
+		{
+			name:   "Options with file max total size",
+			want:   500,
+			got:    func(o *Options) interface{} { return o.File.MaxTotalSize },
+			method: WithFileMaxTotalSize(500),
+		},
+		{
+			name:   "Options with file rotation",
+			want:   "DAILY",
+			got:    func(o *Options) interface{} { return o.File.Rotation },
+			method: WithFileRotation("DAILY"),
+		},
+		{
+			name:   "Options with file pattern",
+			want:   "app-{2006-01-02}.log",
+			got:    func(o *Options) interface{} { return o.File.Pattern },
+			method: WithFilePattern("app-{2006-01-02}.log"),
+		},
+		{
+			name:   "Options with file local time",
+			want:   true,
+			got:    func(o *Options) interface{} { return o.File.LocalTime },
+			method: WithFileLocalTime(true),
+		},
+		{
+			name:   "Options with file rotate on startup",
+			want:   true,
+			got:    func(o *Options) interface{} { return o.File.RotateOnStartup },
+			method: WithFileRotateOnStartup(true),
+		},
+		{
+			name:   "Options with file mode",
+			want:   os.FileMode(0o640),
+			got:    func(o *Options) interface{} { return o.File.Mode },
+			method: WithFileMode(0o640),
+		},
+		{
+			name:   "Options with file dir mode",
+			want:   os.FileMode(0o750),
+			got:    func(o *Options) interface{} { return o.File.DirMode },
+			method: WithFileDirMode(0o750),
+		},
+		{
+			name:   "Options with file reopen on sighup",
+			want:   true,
+			got:    func(o *Options) interface{} { return o.File.ReopenOnSIGHUP },
+			method: WithFileReopenOnSIGHUP(true),
+		},
 		{
 			name:   "Options with file max size",
 			want:   50,
@@ -77,6 +734,34 @@ func (s *OptionsSuite) TestOptionsWithMethods() {
 			got:    func(o *Options) interface{} { return o.Formatter },
 			method: WithFormatter("JSON"),
 		},
+		{
+			name:   "Options with time format",
+			want:   "EPOCH_MILLIS",
+			got:    func(o *Options) interface{} { return o.Time.Format },
+			method: WithTimeFormat("EPOCH_MILLIS"),
+		},
+		{
+			name:   "Options with time UTC",
+			want:   true,
+			got:    func(o *Options) interface{} { return o.Time.UTC },
+			method: WithTimeUTC(true),
+		},
+		{
+			name: "Options with time clock",
+			want: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+			got: func(o *Options) interface{} {
+				return o.Time.Clock()
+			},
+			method: WithTimeClock(func() time.Time { return time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC) }),
+		},
+		{
+			name: "Options with field names",
+			want: fieldNames{Time: "@timestamp", Level: "level", Message: "message", Caller: "caller", Stacktrace: "stacktrace"},
+			got: func(o *Options) interface{} {
+				return fieldNames(o.FieldNames)
+			},
+			method: WithFieldNames("@timestamp", "level", "message", "caller", "stacktrace"),
+		},
 		{
 			name:   "Options with error field name",
 			want:   "error",
@@ -0,0 +1,88 @@
+package zerolog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/americanas-go/log"
+	"github.com/stretchr/testify/suite"
+)
+
+type OrderSuite struct {
+	suite.Suite
+}
+
+func TestOrderSuite(t *testing.T) {
+	suite.Run(t, new(OrderSuite))
+}
+
+// logFile logs with a file output of formatter, returning the lines.
+func (s *OrderSuite) logFile(formatter string, option ...Option) []string {
+	dir := s.T().TempDir()
+	logger := NewLogger(append([]Option{
+		WithConsoleEnabled(false),
+		WithFileEnabled(true),
+		WithFilePath(dir),
+		WithFileName("app.log"),
+		WithFileFormatter(formatter),
+		WithFieldNames("time", "level", "msg", "", ""),
+		WithTimeFormat(TimeFormatEpoch),
+		WithTimeUTC(true),
+		WithTimeClock(func() time.Time { return time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC) }),
+	}, option...)...)
+
+	fields := log.Fields{"b": 1, "user_id": 2, "a": 3, "request_id": 4, "c": 5}
+	for i := 0; i < 5; i++ {
+		logger.WithField("trace_id", 6).WithFields(fields).Info("hello")
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	s.Require().NoError(err)
+	return strings.Split(strings.TrimSuffix(string(jsonLines(data)), "\n"), "\n")
+}
+
+func (s *OrderSuite) TestLogger() {
+	s.T().Setenv("NO_COLOR", "")
+
+	tt := []struct {
+		name      string
+		formatter string
+		options   []Option
+		want      string
+	}{
+		{
+			name:      "JSON sorted",
+			formatter: "JSON",
+			want:      `{"a":3,"b":1,"c":5,"request_id":4,"trace_id":6,"user_id":2,"time":1609556645,"level":"info","msg":"hello"}`,
+		},
+		{
+			name:      "JSON with priority fields",
+			formatter: "JSON",
+			options:   []Option{WithPriorityFields("request_id", "trace_id", "user_id")},
+			want:      `{"request_id":4,"trace_id":6,"user_id":2,"a":3,"b":1,"c":5,"time":1609556645,"level":"info","msg":"hello"}`,
+		},
+		{
+			name:      "TEXT with priority fields",
+			formatter: "TEXT",
+			options:   []Option{WithPriorityFields("request_id", "trace_id", "user_id")},
+			want:      `3:04AM INF hello request_id=4 trace_id=6 user_id=2 a=3 b=1 c=5`,
+		},
+		{
+			name:      "LOGFMT with priority fields",
+			formatter: "LOGFMT",
+			options:   []Option{WithPriorityFields("request_id", "trace_id", "user_id")},
+			want:      `time=1609556645 level=info msg=hello request_id=4 trace_id=6 user_id=2 a=3 b=1 c=5`,
+		},
+	}
+
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			for _, line := range s.logFile(t.formatter, t.options...) {
+				s.Assert().Equal(t.want, line)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package zerolog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+)
+
+type OutputSuite struct {
+	suite.Suite
+}
+
+func TestOutputSuite(t *testing.T) {
+	suite.Run(t, new(OutputSuite))
+}
+
+func (s *OutputSuite) TestIndependentOutputs() {
+	dir := s.T().TempDir()
+	at := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	console := captureStdout(func() {
+		l := NewLogger(
+			WithTimeClock(func() time.Time { return at }),
+			WithTimeUTC(true),
+			WithConsoleLevel("INFO"),
+			WithConsoleFormatter("JSON"),
+			WithFileEnabled(true),
+			WithFilePath(dir),
+			WithFileName("app.log"),
+			WithFileLevel("DEBUG"),
+			WithFileFormatter("TEXT"),
+		)
+
+		l.Debug("debug message")
+		l.WithField("ID", "1").Info("info message")
+	})
+
+	s.Assert().Equal(`{"ID":"1","time":"2021-01-02T03:04:05Z","log_level":"info","log_message":"info message"}`+"\n", string(jsonLines([]byte(console))))
+
+	file, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	s.Require().NoError(err)
+	s.Assert().Equal("3:04AM DBG debug message\n3:04AM INF info message ID=1\n", string(file))
+}
+
+func (s *OutputSuite) TestOutputsFallBackToLoggerOptions() {
+	options := defaultOptions()
+	options.Level = "WARN"
+	options.Formatter = "JSON"
+	options.File.Enabled = true
+	options.File.Level = "TRACE"
+
+	outputs := getOutputs(options, getFieldNames(options))
+	s.Require().Len(outputs, 2)
+	s.Assert().Equal(os.Stdout, outputs[0].writer)
+	s.Assert().Equal(zerolog.WarnLevel, outputs[0].level)
+	s.Assert().Equal(zerolog.TraceLevel, outputs[1].level)
+	s.Assert().Equal(zerolog.TraceLevel, mostVerbose(outputs))
+}
+
+func (s *OutputSuite) Test_mostVerbose() {
+	s.Assert().Equal(zerolog.Disabled, mostVerbose(nil))
+	s.Assert().Equal(zerolog.DebugLevel, mostVerbose([]output{{level: zerolog.InfoLevel}, {level: zerolog.DebugLevel}}))
+}
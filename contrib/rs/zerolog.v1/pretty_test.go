@@ -0,0 +1,11 @@
+package zerolog
+
+import (
+	"testing"
+
+	"github.com/americanas-go/log/internal/logtest"
+)
+
+func TestPretty(t *testing.T) {
+	logtest.Pretty(t, newFileLogger)
+}
@@ -0,0 +1,128 @@
+package zerolog
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Predefined time formats, any other value is used as a time.Format layout.
+const (
+	TimeFormatISO8601     = "ISO8601"
+	TimeFormatRFC3339     = "RFC3339"
+	TimeFormatRFC3339Nano = "RFC3339NANO"
+	TimeFormatEpoch       = "EPOCH"
+	TimeFormatEpochMillis = "EPOCH_MILLIS"
+	TimeFormatEpochNanos  = "EPOCH_NANOS"
+)
+
+const iso8601Layout = "2006-01-02T15:04:05.000Z0700"
+
+// timeFormat writes the time field of the events of a logger.
+type timeFormat struct {
+	format string
+	utc    bool
+	clock  func() time.Time
+}
+
+func getTimeFormat(options *Options) timeFormat {
+	return timeFormat{
+		format: options.Time.Format,
+		utc:    options.Time.UTC,
+		clock:  options.Time.Clock,
+	}
+}
+
+// now returns the time of a new event, zerolog.TimestampFunc when there is no clock.
+func (f timeFormat) now() time.Time {
+	clock := f.clock
+	if clock == nil {
+		clock = zerolog.TimestampFunc
+	}
+
+	t := clock()
+	if f.utc {
+		t = t.UTC()
+	}
+	return t
+}
+
+// layout returns the time.Format layout of the non epoch formats.
+func (f timeFormat) layout() string {
+	switch f.format {
+	case "", TimeFormatRFC3339:
+		return time.RFC3339
+	case TimeFormatRFC3339Nano:
+		return time.RFC3339Nano
+	case TimeFormatISO8601:
+		return iso8601Layout
+	default:
+		return f.format
+	}
+}
+
+// append writes t to e, epoch formats as integers.
+func (f timeFormat) append(e *zerolog.Event, key string, t time.Time) {
+	switch f.format {
+	case TimeFormatEpoch:
+		e.Int64(key, t.Unix())
+	case TimeFormatEpochMillis:
+		e.Int64(key, t.UnixMilli())
+	case TimeFormatEpochNanos:
+		e.Int64(key, t.UnixNano())
+	default:
+		e.Str(key, t.Format(f.layout()))
+	}
+}
+
+// parse reads back a value written by append, as decoded by the console writer.
+func (f timeFormat) parse(v interface{}) (time.Time, bool) {
+	switch tt := v.(type) {
+	case string:
+		t, err := time.ParseInLocation(f.layout(), tt, time.Local)
+		return t, err == nil
+	case json.Number:
+		i, err := tt.Int64()
+		if err != nil {
+			return time.Time{}, false
+		}
+
+		switch f.format {
+		case TimeFormatEpoch:
+			return time.Unix(i, 0), true
+		case TimeFormatEpochMillis:
+			return time.UnixMilli(i), true
+		case TimeFormatEpochNanos:
+			return time.Unix(0, i), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// formatTimestamp is the zerolog.ConsoleWriter timestamp formatter of the
+// time format, shown in the console time format and zone.
+func (f timeFormat) formatTimestamp(w zerolog.ConsoleWriter) zerolog.Formatter {
+	layout := w.TimeFormat
+	if layout == "" {
+		layout = time.Kitchen
+	}
+
+	return func(v interface{}) string {
+		s := fmt.Sprint(v)
+		if t, ok := f.parse(v); ok {
+			if f.utc {
+				t = t.UTC()
+			} else {
+				t = t.Local()
+			}
+			s = t.Format(layout)
+		}
+
+		if w.NoColor {
+			return s
+		}
+		return fmt.Sprintf("\x1b[90m%s\x1b[0m", s)
+	}
+}
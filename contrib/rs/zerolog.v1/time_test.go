@@ -0,0 +1,116 @@
+package zerolog
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+)
+
+type TimeSuite struct {
+	suite.Suite
+}
+
+func TestTimeSuite(t *testing.T) {
+	suite.Run(t, new(TimeSuite))
+}
+
+func (s *TimeSuite) TestTimeFormatAppend() {
+	at := time.Date(2021, 1, 2, 3, 4, 5, 123456789, time.UTC)
+
+	tt := []struct {
+		name   string
+		format string
+		want   string
+	}{
+		{name: "when default", format: "", want: `{"time":"2021-01-02T03:04:05Z"}`},
+		{name: "when ISO8601", format: TimeFormatISO8601, want: `{"time":"2021-01-02T03:04:05.123Z"}`},
+		{name: "when RFC3339", format: TimeFormatRFC3339, want: `{"time":"2021-01-02T03:04:05Z"}`},
+		{name: "when RFC3339NANO", format: TimeFormatRFC3339Nano, want: `{"time":"2021-01-02T03:04:05.123456789Z"}`},
+		{name: "when EPOCH", format: TimeFormatEpoch, want: `{"time":1609556645}`},
+		{name: "when EPOCH_MILLIS", format: TimeFormatEpochMillis, want: `{"time":1609556645123}`},
+		{name: "when EPOCH_NANOS", format: TimeFormatEpochNanos, want: `{"time":1609556645123456789}`},
+		{name: "when layout", format: "2006/01/02 15:04:05", want: `{"time":"2021/01/02 03:04:05"}`},
+	}
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			buf := &bytes.Buffer{}
+			zl := zerolog.New(buf)
+			e := zl.Log()
+			f := timeFormat{format: t.format}
+			f.append(e, "time", at)
+			e.Send()
+			s.Assert().Equal(t.want+"\n", string(jsonLines(buf.Bytes())))
+
+			// the console writer decodes numbers as json.Number
+			evt := map[string]interface{}{}
+			dec := json.NewDecoder(bytes.NewBufferString(t.want))
+			dec.UseNumber()
+			s.Require().NoError(dec.Decode(&evt))
+			got, ok := f.parse(evt["time"])
+			s.Assert().True(ok)
+			s.Assert().Equal(at.Format(f.layout()), got.UTC().Format(f.layout()))
+		})
+	}
+}
+
+func (s *TimeSuite) TestTimeFormatNow() {
+	at := time.Date(2021, 1, 2, 3, 4, 5, 0, time.FixedZone("BRT", -3*60*60))
+	clock := func() time.Time { return at }
+
+	s.Assert().Equal(at, timeFormat{clock: clock}.now())
+	s.Assert().Equal(at.UTC(), timeFormat{clock: clock, utc: true}.now())
+	s.Assert().WithinDuration(time.Now(), timeFormat{}.now(), time.Second)
+}
+
+func (s *TimeSuite) TestLoggerTime() {
+	at := time.Date(2021, 1, 2, 3, 4, 5, 0, time.FixedZone("BRT", -3*60*60))
+
+	tt := []struct {
+		name    string
+		options []Option
+		want    string
+	}{
+		{
+			name:    "json with clock",
+			options: []Option{WithFormatter("JSON"), WithTimeClock(func() time.Time { return at })},
+			want:    `{"time":"2021-01-02T03:04:05-03:00","log_level":"info","log_message":"Blah"}`,
+		},
+		{
+			name:    "json with clock, UTC and epoch millis",
+			options: []Option{WithFormatter("JSON"), WithTimeClock(func() time.Time { return at }), WithTimeUTC(true), WithTimeFormat(TimeFormatEpochMillis)},
+			want:    `{"time":1609567445000,"log_level":"info","log_message":"Blah"}`,
+		},
+		{
+			name:    "text with clock and UTC",
+			options: []Option{WithTimeClock(func() time.Time { return at }), WithTimeUTC(true), WithTimeFormat(TimeFormatEpoch)},
+			want:    "\x1b[90m6:04AM\x1b[0m \x1b[32mINF\x1b[0m \x1b[1mBlah\x1b[0m",
+		},
+	}
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			out := captureStdout(func() {
+				NewLogger(t.options...).Info("Blah")
+			})
+			s.Assert().Equal(t.want+"\n", string(jsonLines([]byte(out))))
+		})
+	}
+}
+
+// captureStdout returns what the loggers created by f write to os.Stdout.
+func captureStdout(f func()) string {
+	original := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	defer func() { os.Stdout = original }()
+
+	f()
+	w.Close()
+	b, _ := io.ReadAll(r)
+	return string(b)
+}
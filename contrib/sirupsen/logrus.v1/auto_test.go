@@ -0,0 +1,78 @@
+package logrus
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/auto"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/suite"
+)
+
+type AutoSuite struct {
+	suite.Suite
+}
+
+func TestAutoSuite(t *testing.T) {
+	suite.Run(t, new(AutoSuite))
+}
+
+func (s *AutoSuite) SetupTest() {
+	for _, key := range []string{"NO_COLOR", "FORCE_COLOR", "CLICOLOR", "CLICOLOR_FORCE"} {
+		s.T().Setenv(key, "")
+	}
+}
+
+// logFile logs a warning with an AUTO file output, returning the line.
+func (s *AutoSuite) logFile() string {
+	dir := s.T().TempDir()
+	NewLogger(
+		WithConsoleEnabled(false),
+		WithFileEnabled(true),
+		WithFilePath(dir),
+		WithFileName("app.log"),
+		WithFileFormatter(auto.New()),
+	).WithField("order", 1).Warn("order created")
+
+	data, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	s.Require().NoError(err)
+	return strings.TrimSuffix(string(data), "\n")
+}
+
+func (s *AutoSuite) TestLoggerNotTerminal() {
+	line := s.logFile()
+	s.Assert().Regexp(`^\{.*"level":"warning","msg":"order created","order":1.*\}$`, line)
+}
+
+func (s *AutoSuite) TestLoggerForceColor() {
+	s.T().Setenv("FORCE_COLOR", "1")
+
+	line := s.logFile()
+	s.Assert().Contains(line, "\x1b[33mWARNING\x1b[0m")
+	s.Assert().Contains(line, "order created")
+}
+
+func (s *AutoSuite) TestLoggerNoColor() {
+	s.T().Setenv("CLICOLOR_FORCE", "1")
+	s.T().Setenv("NO_COLOR", "1")
+
+	line := s.logFile()
+	s.Assert().Regexp(`level=warning msg="order created" order=1`, line)
+	s.Assert().NotContains(line, "\x1b[")
+}
+
+func (s *AutoSuite) Test_getFormatter() {
+	options := defaultOptions()
+	names := getFieldNames(options)
+
+	got := getFormatter(auto.New(), &bytes.Buffer{}, options, names)
+	s.Assert().IsType(&logrus.JSONFormatter{}, got)
+
+	s.T().Setenv("FORCE_COLOR", "1")
+	got = getFormatter(auto.New(), &bytes.Buffer{}, options, names)
+	s.Require().IsType(&logrus.TextFormatter{}, got)
+	s.Assert().True(got.(*logrus.TextFormatter).ForceColors)
+}
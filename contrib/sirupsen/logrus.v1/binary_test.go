@@ -0,0 +1,94 @@
+package logrus
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/cbor"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/msgpack"
+	"github.com/americanas-go/log/decode"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/suite"
+)
+
+type BinarySuite struct {
+	suite.Suite
+}
+
+func TestBinarySuite(t *testing.T) {
+	suite.Run(t, new(BinarySuite))
+}
+
+var binaryTime = time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+var binaryFormatters = map[string]logrus.Formatter{
+	"CBOR":    cbor.New(),
+	"MSGPACK": msgpack.New(),
+}
+
+// logFile logs with a file output of formatter at a fixed time, returning the
+// decoded entries.
+func (s *BinarySuite) logFile(formatter string, logWith func(logger log.Logger)) [][]decode.Field {
+	dir := s.T().TempDir()
+	logWith(NewLogger(
+		WithConsoleEnabled(false),
+		WithFileEnabled(true),
+		WithFilePath(dir),
+		WithFileName("app.log"),
+		WithFileFormatter(binaryFormatters[formatter]),
+		WithFileLevel("DEBUG"),
+		WithFieldNames("time", "level", "message", "", ""),
+		WithTimeClock(func() time.Time { return binaryTime }),
+	))
+
+	data, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	s.Require().NoError(err)
+
+	var entries [][]decode.Field
+	d := decode.NewDecoder(bytes.NewReader(data), formatter)
+	for {
+		fields, err := d.Decode()
+		if err == io.EOF {
+			return entries
+		}
+		s.Require().NoError(err)
+		entries = append(entries, fields)
+	}
+}
+
+func (s *BinarySuite) TestLogger() {
+	for _, formatter := range []string{"CBOR", "MSGPACK"} {
+		s.Run(formatter, func() {
+			entries := s.logFile(formatter, func(logger log.Logger) {
+				logger.Debugf("hello %s", "world")
+				logger.
+					WithFields(log.Fields{"user.name": "John Doe", "order": 1}).
+					WithError(errors.New("something bad")).
+					Warn("order created")
+			})
+
+			// the entries are the same for every backend
+			s.Assert().Equal([][]decode.Field{
+				{
+					{Key: "time", Value: binaryTime.Local()},
+					{Key: "level", Value: "debug"},
+					{Key: "message", Value: "hello world"},
+				},
+				{
+					{Key: "time", Value: binaryTime.Local()},
+					{Key: "level", Value: "warn"},
+					{Key: "message", Value: "order created"},
+					{Key: "err", Value: "something bad"},
+					{Key: "order", Value: int64(1)},
+					{Key: "user.name", Value: "John Doe"},
+				},
+			}, entries)
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package logrus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/logtest"
+)
+
+func TestClose(t *testing.T) {
+	logtest.Close(t, func(url string) log.Logger {
+		return NewLogger(
+			WithConsoleEnabled(false),
+			WithLokiEnabled(true),
+			WithLokiURL(url),
+			WithLokiBatch(100, time.Hour),
+		)
+	})
+}
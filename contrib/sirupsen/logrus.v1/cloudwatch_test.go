@@ -0,0 +1,70 @@
+package logrus
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/cloudwatch"
+	jsonformatter "github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/json"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/suite"
+)
+
+type CloudWatchSuite struct {
+	suite.Suite
+}
+
+func TestCloudWatchSuite(t *testing.T) {
+	suite.Run(t, new(CloudWatchSuite))
+}
+
+// logFile logs with a file output using formatter, returning the lines.
+func (s *CloudWatchSuite) logFile(formatter logrus.Formatter, logWith func(logger log.Logger), options ...Option) []string {
+	dir := s.T().TempDir()
+	logWith(NewLogger(append([]Option{
+		WithConsoleEnabled(false),
+		WithFileEnabled(true),
+		WithFilePath(dir),
+		WithFileName("app.log"),
+		WithFileFormatter(formatter),
+	}, options...)...))
+
+	data, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	s.Require().NoError(err)
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+func (s *CloudWatchSuite) TestLogger() {
+	lines := s.logFile(cloudwatch.New(), func(logger log.Logger) {
+		logger.
+			WithFields(log.Fields{"RequestId": "8f5e0c4e", "order": 1, "empty": ""}).
+			Warn("order created")
+	}, WithFieldNames("time", "level", "msg", "", ""))
+
+	s.Assert().Equal([]string{`WARNING RequestId: 8f5e0c4e Message: "order created" empty: "" order: 1 `}, lines)
+}
+
+func (s *CloudWatchSuite) TestLoggerEMF() {
+	lines := s.logFile(jsonformatter.New(), func(logger log.Logger) {
+		logger.
+			WithFields(log.EMF("orders", log.Fields{"service": "checkout"},
+				log.Metric{Name: "latency", Unit: "Milliseconds", Value: 12.5},
+			)).
+			Info("order created")
+	})
+
+	s.Require().Len(lines, 1)
+	var line map[string]interface{}
+	s.Require().NoError(json.Unmarshal([]byte(lines[0]), &line))
+	s.Assert().Equal("checkout", line["service"])
+	s.Assert().Equal(12.5, line["latency"])
+
+	metrics := line[log.EMFKey].(map[string]interface{})["CloudWatchMetrics"].([]interface{})[0].(map[string]interface{})
+	s.Assert().Equal("orders", metrics["Namespace"])
+	s.Assert().Equal([]interface{}{[]interface{}{"service"}}, metrics["Dimensions"])
+	s.Assert().Equal([]interface{}{map[string]interface{}{"Name": "latency", "Unit": "Milliseconds"}}, metrics["Metrics"])
+}
@@ -0,0 +1,20 @@
+package logrus
+
+import (
+	"testing"
+
+	"github.com/americanas-go/log"
+	logrusjson "github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/json"
+	"github.com/americanas-go/log/internal/logtest"
+)
+
+func TestConsole(t *testing.T) {
+	logtest.Console(t, func(writer, splitLevel string) log.Logger {
+		return NewLogger(
+			WithConsoleLevel("DEBUG"),
+			WithConsoleFormatter(logrusjson.New()),
+			WithConsoleWriter(writer),
+			WithConsoleSplitLevel(splitLevel),
+		)
+	}, "msg", nil)
+}
@@ -0,0 +1,109 @@
+package logrus
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/ecs"
+	jsonformatter "github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/json"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/suite"
+)
+
+type EcsSuite struct {
+	suite.Suite
+}
+
+func TestEcsSuite(t *testing.T) {
+	suite.Run(t, new(EcsSuite))
+}
+
+// logFile logs with a file output using formatter, returning the decoded
+// lines.
+func logFile(s *suite.Suite, formatter logrus.Formatter, logWith func(logger log.Logger), options ...Option) []map[string]interface{} {
+	dir := s.T().TempDir()
+	logWith(NewLogger(append([]Option{
+		WithConsoleEnabled(false),
+		WithFileEnabled(true),
+		WithFilePath(dir),
+		WithFileName("app.log"),
+		WithFileFormatter(formatter),
+	}, options...)...))
+
+	data, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	s.Require().NoError(err)
+
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		var m map[string]interface{}
+		s.Require().NoError(json.Unmarshal([]byte(line), &m), line)
+		lines = append(lines, m)
+	}
+	return lines
+}
+
+func (s *EcsSuite) TestLogger() {
+	lines := logFile(&s.Suite, ecs.New(), func(logger log.Logger) {
+		logger.
+			WithFields(map[string]interface{}{"http.request.method": "GET", "trace_id": "abc"}).
+			WithError(errors.New("something bad")).
+			Error("failed")
+	}, WithFieldNames("time", "level", "message", "caller", ""))
+
+	s.Require().Len(lines, 1)
+	line := lines[0]
+	s.Assert().NotEmpty(line["@timestamp"])
+	s.Assert().Equal("error", line["log.level"])
+	s.Assert().Equal("failed", line["message"])
+	s.Assert().Equal("8.11.0", line["ecs.version"])
+	s.Assert().Equal(map[string]interface{}{"request": map[string]interface{}{"method": "GET"}}, line["http"])
+	s.Assert().Equal(map[string]interface{}{"id": "abc"}, line["trace"])
+
+	ecsError := line["error"].(map[string]interface{})
+	s.Assert().Equal("something bad", ecsError["message"])
+	s.Assert().Equal("*errors.errorString", ecsError["type"])
+
+	origin := line["log"].(map[string]interface{})["origin"].(map[string]interface{})
+	s.Assert().True(strings.HasSuffix(origin["file"].(map[string]interface{})["name"].(string), "logrus.v1/ecs_test.go"))
+	s.Assert().NotZero(origin["file"].(map[string]interface{})["line"])
+	s.Assert().NotEmpty(origin["function"])
+}
+
+func (s *EcsSuite) TestLoggerFields() {
+	lines := logFile(&s.Suite, ecs.New(), func(logger log.Logger) {
+		logger.
+			WithError(errors.New("something bad")).
+			WithField("user.id", 1).
+			Info("hello")
+	})
+
+	s.Require().Len(lines, 1)
+	s.Assert().Equal(map[string]interface{}{"id": float64(1)}, lines[0]["user"])
+	s.Assert().Equal(map[string]interface{}{"message": "something bad", "type": "*errors.errorString"}, lines[0]["error"])
+}
+
+func (s *EcsSuite) TestLoggerReplacedError() {
+	lines := logFile(&s.Suite, ecs.New(), func(logger log.Logger) {
+		logger.
+			WithError(errors.New("something bad")).
+			WithField("err", "replaced").
+			Info("hello")
+	})
+
+	s.Require().Len(lines, 1)
+	s.Assert().Equal(map[string]interface{}{"message": "replaced"}, lines[0]["error"])
+}
+
+func (s *EcsSuite) TestLoggerJSONError() {
+	lines := logFile(&s.Suite, jsonformatter.New(), func(logger log.Logger) {
+		logger.WithError(errors.New("something bad")).Info("failed")
+	})
+
+	s.Require().Len(lines, 1)
+	s.Assert().Equal("something bad", lines[0]["err"])
+}
@@ -0,0 +1,51 @@
+package logrus
+
+import (
+	"strconv"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/sirupsen/logrus"
+)
+
+// toEntry converts e to the entries of the outputs which encode them
+// themselves. The caller set by the caller hook is moved from the callerKey
+// field of the data to the caller of the entry.
+func toEntry(e *logrus.Entry, callerKey string) entry.Entry {
+	fields := convertToFields(e.Data)
+
+	var caller, function string
+	if e.Caller != nil {
+		caller = e.Caller.File + ":" + strconv.Itoa(e.Caller.Line)
+		function = e.Caller.Function
+		delete(fields, callerKey)
+	}
+
+	return entry.Entry{
+		Time:     e.Time,
+		Level:    entryLevel(e.Level),
+		Message:  e.Message,
+		Caller:   caller,
+		Function: function,
+		Fields:   fields,
+	}
+}
+
+func entryLevel(level logrus.Level) log.Level {
+	switch level {
+	case logrus.TraceLevel:
+		return log.TraceLevel
+	case logrus.DebugLevel:
+		return log.DebugLevel
+	case logrus.InfoLevel:
+		return log.InfoLevel
+	case logrus.WarnLevel:
+		return log.WarnLevel
+	case logrus.ErrorLevel:
+		return log.ErrorLevel
+	case logrus.PanicLevel:
+		return log.PanicLevel
+	default:
+		return log.FatalLevel
+	}
+}
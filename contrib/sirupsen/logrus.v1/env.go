@@ -0,0 +1,132 @@
+package logrus
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/auto"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/cbor"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/cloudwatch"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/ecs"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/gcp"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/json"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/logfmt"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/msgpack"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/pretty"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/text"
+	"github.com/americanas-go/log/internal/env"
+	"github.com/sirupsen/logrus"
+)
+
+// FromEnv returns the Options read from environment variables.
+//
+// Every field of Options is read from a variable named after its path in upper
+// snake case, prefixed by prefix, e.g. with the prefix "LOG":
+// LOG_CONSOLE_LEVEL, LOG_FILE_MAX_SIZE or LOG_TIME_FORMAT. The formatters are
+// chosen by name (TEXT, JSON, LOGFMT, CLOUDWATCH, ECS, GCP, CBOR, MSGPACK, AUTO
+// or PRETTY) with LOG_FORMATTER, LOG_CONSOLE_FORMATTER and LOG_FILE_FORMATTER,
+// and hooks can only be set in code. Unset variables keep the default (or
+// previously applied) value. Malformed values, such as an unknown level or a
+// non numeric size, are all reported in the returned error, naming their
+// variables.
+//
+// The variables are read once, by FromEnv: the returned Options set the values
+// read then.
+func FromEnv(prefix string) ([]Option, error) {
+	options := defaultOptions()
+	formatter, err := formatterFromEnv(prefix, "Formatter")
+	consoleFormatter, consoleErr := formatterFromEnv(prefix, "Console", "Formatter")
+	fileFormatter, fileErr := formatterFromEnv(prefix, "File", "Formatter")
+	apply, loadErr := env.Capture(prefix, options)
+	if err = errors.Join(err, consoleErr, fileErr, loadErr); err != nil {
+		return nil, err
+	}
+
+	if err := options.validate(envName(prefix)); err != nil {
+		return nil, err
+	}
+
+	option := []Option{withEnv(apply)}
+	if formatter != nil {
+		option = append(option, WithFormatter(formatter))
+	}
+	if consoleFormatter != nil {
+		option = append(option, WithConsoleFormatter(consoleFormatter))
+	}
+	if fileFormatter != nil {
+		option = append(option, WithFileFormatter(fileFormatter))
+	}
+
+	return option, nil
+}
+
+// NewLoggerFromEnv constructs a new Logger from the Options read by FromEnv.
+// Options provided after the prefix take precedence over environment variables.
+func NewLoggerFromEnv(prefix string, option ...Option) (log.Logger, error) {
+	options, err := FromEnv(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewLogger(append(options, option...)...), nil
+}
+
+// envName names an option after the variable it is read from with prefix.
+func envName(prefix string) func(path string) string {
+	return func(path string) string {
+		return env.Name(strings.ToUpper(prefix), strings.Split(path, ".")...)
+	}
+}
+
+// withEnv sets the options read by FromEnv, whose values were validated.
+func withEnv(apply func(dst interface{})) Option {
+	return func(options *Options) {
+		apply(options)
+	}
+}
+
+func formatterFromEnv(prefix string, path ...string) (logrus.Formatter, error) {
+	name := env.Name(strings.ToUpper(prefix), path...)
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return nil, nil
+	}
+
+	formatter, err := formatterByName(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid value %q: %w", name, value, err)
+	}
+	return formatter, nil
+}
+
+// formatterByName returns a formatter with default options from its name:
+// TEXT, JSON, LOGFMT, CLOUDWATCH, ECS, GCP, CBOR, MSGPACK, AUTO or PRETTY.
+func formatterByName(name string) (logrus.Formatter, error) {
+	switch strings.ToUpper(strings.TrimSpace(name)) {
+	case "TEXT":
+		return text.New(), nil
+	case "JSON":
+		return json.New(), nil
+	case "LOGFMT":
+		return logfmt.New(), nil
+	case "CLOUDWATCH":
+		return cloudwatch.New(), nil
+	case "ECS":
+		return ecs.New(), nil
+	case "GCP":
+		return gcp.New(), nil
+	case "CBOR":
+		return cbor.New(), nil
+	case "MSGPACK":
+		return msgpack.New(), nil
+	case "AUTO":
+		return auto.New(), nil
+	case "PRETTY":
+		return pretty.New(), nil
+	default:
+		return nil, fmt.Errorf("unknown formatter %q, expected one of [TEXT JSON LOGFMT CLOUDWATCH ECS GCP CBOR MSGPACK AUTO PRETTY]", name)
+	}
+}
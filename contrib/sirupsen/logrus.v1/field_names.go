@@ -0,0 +1,206 @@
+package logrus
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/cbor"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/ecs"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/gcp"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/logfmt"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/msgpack"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/pretty"
+	"github.com/sirupsen/logrus"
+)
+
+// fieldNames holds the keys of the fields written on every entry.
+type fieldNames struct {
+	Time       string
+	Level      string
+	Message    string
+	Caller     string
+	Stacktrace string
+}
+
+func getFieldNames(options *Options) fieldNames {
+	names := fieldNames(options.FieldNames)
+	if names == (fieldNames{}) {
+		return fieldNames(defaultOptions().FieldNames)
+	}
+	if names.Level == "" {
+		names.Level = defaultLevelFieldName
+	}
+	if names.Message == "" {
+		names.Message = defaultMessageFieldName
+	}
+	return names
+}
+
+// withFieldNames sets the field map of the logrus text and JSON formatters.
+// Other formatters write their own field names and are returned unchanged.
+func withFieldNames(formatter logrus.Formatter, names fieldNames) logrus.Formatter {
+	if names.Time == defaultTimeFieldName && names.Level == defaultLevelFieldName && names.Message == defaultMessageFieldName {
+		return formatter
+	}
+
+	fieldMap := logrus.FieldMap{
+		logrus.FieldKeyTime:  names.Time,
+		logrus.FieldKeyLevel: names.Level,
+		logrus.FieldKeyMsg:   names.Message,
+	}
+
+	switch f := formatter.(type) {
+	case *logrus.TextFormatter:
+		f.FieldMap = fieldMap
+		f.DisableTimestamp = f.DisableTimestamp || names.Time == ""
+	case *logrus.JSONFormatter:
+		f.FieldMap = fieldMap
+		f.DisableTimestamp = f.DisableTimestamp || names.Time == ""
+	}
+
+	return formatter
+}
+
+// withECSKeys sets the keys of the error and caller fields of the ECS
+// formatter to those of the logger, so that they are written as the ECS error
+// and log.origin fields. Other formatters are returned unchanged.
+func withECSKeys(formatter logrus.Formatter, errorFieldName string, names fieldNames) logrus.Formatter {
+	if f, ok := formatter.(*ecs.Formatter); ok {
+		if errorFieldName != "" {
+			f.ErrorKey = errorFieldName
+		}
+		f.CallerKey = names.Caller
+	}
+	return formatter
+}
+
+// withGCPKeys sets the project and the keys of the caller, trace id, span id
+// and HTTP request fields of the GCP formatter to those of the logger. Other
+// formatters are returned unchanged.
+func withGCPKeys(formatter logrus.Formatter, options *Options, names fieldNames) logrus.Formatter {
+	if f, ok := formatter.(*gcp.Formatter); ok {
+		f.ProjectID = options.GCP.ProjectID
+		f.CallerKey = names.Caller
+		if options.GCP.TraceIDField != "" {
+			f.TraceIDKey = options.GCP.TraceIDField
+		}
+		if options.GCP.SpanIDField != "" {
+			f.SpanIDKey = options.GCP.SpanIDField
+		}
+		if options.GCP.HTTPRequestField != "" {
+			f.HTTPRequestKey = options.GCP.HTTPRequestField
+		}
+	}
+	return formatter
+}
+
+// withLogfmtKeys sets the core keys of the logfmt formatter to the field names
+// of the logger, and its time format to the one of the logger when it is not
+// the default one. Other formatters are returned unchanged.
+func withLogfmtKeys(formatter logrus.Formatter, options *Options, names fieldNames) logrus.Formatter {
+	if f, ok := formatter.(*logfmt.Formatter); ok {
+		f.TimeKey = names.Time
+		f.LevelKey = names.Level
+		f.MessageKey = names.Message
+		f.CallerKey = names.Caller
+		if options.Time.Format != defaultTimeFormat {
+			f.TimeFormat = options.Time.Format
+		}
+	}
+	return formatter
+}
+
+// withBinaryKeys sets the core keys of the CBOR and MessagePack formatters to
+// the field names of the logger. Other formatters are returned unchanged.
+func withBinaryKeys(formatter logrus.Formatter, names fieldNames) logrus.Formatter {
+	switch f := formatter.(type) {
+	case *cbor.Formatter:
+		f.TimeKey, f.LevelKey, f.MessageKey, f.CallerKey = names.Time, names.Level, names.Message, names.Caller
+	case *msgpack.Formatter:
+		f.TimeKey, f.LevelKey, f.MessageKey, f.CallerKey = names.Time, names.Level, names.Message, names.Caller
+	}
+	return formatter
+}
+
+// withPrettyKeys sets the keys of the error, stack trace and caller fields of
+// the pretty formatter to those of the logger, and the start of its times to
+// the time of the logger clock, if any. Other formatters are returned
+// unchanged.
+func withPrettyKeys(formatter logrus.Formatter, options *Options, names fieldNames) logrus.Formatter {
+	if f, ok := formatter.(*pretty.Formatter); ok {
+		f.ErrorKey = options.ErrorFieldName
+		if f.ErrorKey == "" {
+			f.ErrorKey = defaultErrorFieldName
+		}
+		f.StackKey = names.Stacktrace
+		f.CallerKey = names.Caller
+		if options.Time.Clock != nil {
+			f.Start = options.Time.Clock()
+		}
+	}
+	return formatter
+}
+
+// callerHook adds the caller of the logging method to every entry, as a field
+// and as the Caller of the entry, which logrus' formatters only write with
+// ReportCaller. logrus' own ReportCaller can not be used, since it reports this
+// package.
+type callerHook struct {
+	fieldName string
+}
+
+// skippedPackages are the packages between the caller and the hook.
+var skippedPackages = []string{
+	"github.com/sirupsen/logrus",
+	"github.com/americanas-go/log",
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1",
+}
+
+func (h *callerHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *callerHook) Fire(entry *logrus.Entry) error {
+	pcs := make([]uintptr, 25)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	for {
+		frame, more := frames.Next()
+		if !skipped(frame) {
+			entry.Data[h.fieldName] = frame.File + ":" + strconv.Itoa(frame.Line)
+			entry.Caller = &frame
+			return nil
+		}
+		if !more {
+			return nil
+		}
+	}
+}
+
+// skipped reports whether frame belongs to the logging packages. Test files
+// are never skipped, so that the caller can be checked from this package.
+func skipped(frame runtime.Frame) bool {
+	if strings.HasSuffix(frame.File, "_test.go") {
+		return false
+	}
+
+	pkg := packageOf(frame.Function)
+	for _, p := range skippedPackages {
+		if pkg == p {
+			return true
+		}
+	}
+	return false
+}
+
+// packageOf returns the package path of a fully qualified function name,
+// e.g. github.com/sirupsen/logrus for github.com/sirupsen/logrus.(*Entry).Log.
+func packageOf(function string) string {
+	slash := strings.LastIndex(function, "/")
+	if dot := strings.Index(function[slash+1:], "."); dot >= 0 {
+		function = function[:slash+1+dot]
+	}
+	return strings.ReplaceAll(function, "%2e", ".")
+}
@@ -0,0 +1,92 @@
+package logrus
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/americanas-go/log"
+	jsonformatter "github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/json"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/text"
+	"github.com/stretchr/testify/suite"
+)
+
+type FieldNamesSuite struct {
+	suite.Suite
+}
+
+func TestFieldNamesSuite(t *testing.T) {
+	suite.Run(t, new(FieldNamesSuite))
+}
+
+func (s *FieldNamesSuite) capture(option ...Option) (log.Logger, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+	l := NewLogger(option...)
+	l.(*logger).logger.SetOutput(buf)
+	return l, buf
+}
+
+func (s *FieldNamesSuite) TestJSONFieldNames() {
+	l, buf := s.capture(
+		WithFormatter(jsonformatter.New()),
+		WithFieldNames("@timestamp", "severity", "message", "caller", ""),
+	)
+
+	l.WithField("ID", "1").Info("Blah")
+
+	entry := map[string]interface{}{}
+	s.Require().NoError(json.Unmarshal(buf.Bytes(), &entry))
+	s.Assert().Contains(entry, "@timestamp")
+	s.Assert().Equal("info", entry["severity"])
+	s.Assert().Equal("Blah", entry["message"])
+	s.Assert().Equal("1", entry["ID"])
+	s.Assert().Contains(entry["caller"], "field_names_test.go:")
+	s.Assert().NotContains(entry, "msg")
+	s.Assert().NotContains(entry, "time")
+}
+
+func (s *FieldNamesSuite) TestTextFieldNames() {
+	l, buf := s.capture(
+		WithFormatter(text.New()),
+		WithFieldNames("", "severity", "message", "", ""),
+	)
+
+	l.Info("Blah")
+
+	s.Assert().Equal("severity=info message=Blah\n", buf.String())
+}
+
+func (s *FieldNamesSuite) TestDefaultFieldNames() {
+	l, buf := s.capture(WithFormatter(text.New()), WithFieldNames("", "", "", "", ""))
+
+	l.Info("Blah")
+
+	s.Assert().Contains(buf.String(), "time=")
+	s.Assert().Contains(buf.String(), "level=info msg=Blah")
+	s.Assert().NotContains(buf.String(), "caller")
+}
+
+func (s *FieldNamesSuite) TestCallerFromPackageFunctions() {
+	_, buf := s.capture(WithFormatter(jsonformatter.New()), WithFieldNames("time", "level", "msg", "caller", ""))
+
+	log.Info("Blah")
+
+	entry := map[string]interface{}{}
+	s.Require().NoError(json.Unmarshal(buf.Bytes(), &entry))
+	s.Assert().Contains(entry["caller"], "field_names_test.go:")
+}
+
+func (s *FieldNamesSuite) Test_packageOf() {
+	tt := []struct {
+		in   string
+		want string
+	}{
+		{in: "github.com/sirupsen/logrus.(*Entry).Log", want: "github.com/sirupsen/logrus"},
+		{in: "github.com/americanas-go/log.Info", want: "github.com/americanas-go/log"},
+		{in: "github.com/americanas-go/log/contrib/sirupsen/logrus%2ev1.(*logger).Info", want: "github.com/americanas-go/log/contrib/sirupsen/logrus.v1"},
+		{in: "main.main", want: "main"},
+	}
+	for _, t := range tt {
+		s.Assert().Equal(t.want, packageOf(t.in))
+	}
+}
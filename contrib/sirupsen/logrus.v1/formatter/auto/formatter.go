@@ -0,0 +1,42 @@
+package auto
+
+import (
+	"io"
+
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/json"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/text"
+	"github.com/americanas-go/log/internal/color"
+	"github.com/sirupsen/logrus"
+)
+
+// Formatter is a logrus formatter choosing, for each output, colored text when
+// the output is a terminal and JSON otherwise. It follows the NO_COLOR,
+// FORCE_COLOR and CLICOLOR conventions, and the levels are in the colors of
+// the AUTO formatter of the other backends.
+//
+// The logger resolves it with For when creating its outputs. Used as is, it
+// formats the entries as JSON.
+type Formatter struct {
+	json logrus.Formatter
+}
+
+// New returns a new logrus formatter choosing between text and JSON.
+func New() logrus.Formatter {
+	return &Formatter{json: json.New()}
+}
+
+// For returns the formatter of the entries written to w: the text formatter
+// when w gets text, as decided by color.Auto, with colors forced or disabled,
+// and the JSON formatter otherwise.
+func (f *Formatter) For(w io.Writer) logrus.Formatter {
+	isText, colored := color.Auto(w)
+	if !isText {
+		return json.New()
+	}
+	return text.New(text.WithForceColors(colored), text.WithDisableColors(!colored))
+}
+
+// Format renders a single log entry, as JSON.
+func (f *Formatter) Format(e *logrus.Entry) ([]byte, error) {
+	return f.json.Format(e)
+}
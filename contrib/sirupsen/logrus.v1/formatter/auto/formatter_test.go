@@ -0,0 +1,77 @@
+package auto
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/json"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/text"
+	"github.com/sirupsen/logrus"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FormatterSuite struct {
+	suite.Suite
+}
+
+func TestFormatterSuite(t *testing.T) {
+	suite.Run(t, new(FormatterSuite))
+}
+
+func (s *FormatterSuite) SetupTest() {
+	for _, key := range []string{"NO_COLOR", "FORCE_COLOR", "CLICOLOR", "CLICOLOR_FORCE"} {
+		s.T().Setenv(key, "")
+	}
+}
+
+func (s *FormatterSuite) TestFor() {
+
+	tt := []struct {
+		name string
+		env  map[string]string
+		want logrus.Formatter
+	}{
+		{
+			name: "For an output which is not a terminal",
+			want: json.New(),
+		},
+		{
+			name: "For an output with forced colors",
+			env:  map[string]string{"FORCE_COLOR": "1"},
+			want: text.New(text.WithForceColors(true)),
+		},
+		{
+			name: "For an output with forced and disabled colors",
+			env:  map[string]string{"CLICOLOR_FORCE": "1", "NO_COLOR": "1"},
+			want: text.New(text.WithDisableColors(true)),
+		},
+	}
+
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			for k, v := range t.env {
+				s.T().Setenv(k, v)
+			}
+
+			got := New().(*Formatter).For(&bytes.Buffer{})
+			s.Assert().Equal(t.want, got)
+		})
+	}
+}
+
+func (s *FormatterSuite) TestFormat() {
+	entry := &logrus.Entry{
+		Time:    time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+		Level:   logrus.InfoLevel,
+		Message: "hello",
+		Data:    logrus.Fields{},
+	}
+
+	got, err := New().Format(entry)
+	s.Require().NoError(err)
+	want, err := json.New().Format(entry)
+	s.Require().NoError(err)
+	s.Assert().Equal(string(want), string(got))
+}
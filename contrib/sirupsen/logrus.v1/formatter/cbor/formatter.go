@@ -0,0 +1,126 @@
+package cbor
+
+import (
+	"strconv"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/binlog"
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/sirupsen/logrus"
+)
+
+// Formatter is a logrus formatter writing each entry as a CBOR map: the
+// time, level, message and caller keys, followed by the fields sorted by
+// key. The maps are those of the CBOR formatter of the other backends, read
+// back by the decode package of the module.
+type Formatter struct {
+	TimeKey    string // key of the time, omitted when empty
+	LevelKey   string // key of the level, omitted when empty
+	MessageKey string // key of the message, "message" when empty
+	CallerKey  string // key of the caller, omitted when empty
+
+	PriorityKeys []string // fields written first, in this order, the others being sorted by key
+}
+
+// Option represents a cbor formatter option.
+type Option func(formatter *Formatter)
+
+// New returns a new logrus formatter for CBOR.
+func New(options ...Option) logrus.Formatter {
+	fmt := &Formatter{
+		TimeKey:    binlog.DefaultTimeKey,
+		LevelKey:   binlog.DefaultLevelKey,
+		MessageKey: binlog.DefaultMessageKey,
+		CallerKey:  binlog.DefaultCallerKey,
+	}
+
+	for _, option := range options {
+		option(fmt)
+	}
+
+	return fmt
+}
+
+// WithTimeKey sets formatter's time key to value.
+func WithTimeKey(value string) Option {
+	return func(formatter *Formatter) {
+		formatter.TimeKey = value
+	}
+}
+
+// WithLevelKey sets formatter's level key to value.
+func WithLevelKey(value string) Option {
+	return func(formatter *Formatter) {
+		formatter.LevelKey = value
+	}
+}
+
+// WithMessageKey sets formatter's message key to value.
+func WithMessageKey(value string) Option {
+	return func(formatter *Formatter) {
+		formatter.MessageKey = value
+	}
+}
+
+// WithCallerKey sets formatter's caller key to value.
+func WithCallerKey(value string) Option {
+	return func(formatter *Formatter) {
+		formatter.CallerKey = value
+	}
+}
+
+// WithPriorityKeys sets formatter's priority keys to value.
+func WithPriorityKeys(value ...string) Option {
+	return func(formatter *Formatter) {
+		formatter.PriorityKeys = value
+	}
+}
+
+// Format renders a single log entry.
+func (f *Formatter) Format(e *logrus.Entry) ([]byte, error) {
+	fields := make(log.Fields, len(e.Data))
+	for k, v := range e.Data {
+		fields[k] = v
+	}
+
+	var caller string
+	if e.Caller != nil {
+		caller = e.Caller.File + ":" + strconv.Itoa(e.Caller.Line)
+		delete(fields, f.CallerKey)
+	}
+
+	return binlog.Append(nil, entry.Entry{
+		Time:    e.Time,
+		Level:   level(e.Level),
+		Message: e.Message,
+		Caller:  caller,
+		Fields:  fields,
+	}, binlog.Options{
+		Format:     binlog.FormatCBOR,
+		TimeKey:    f.TimeKey,
+		LevelKey:   f.LevelKey,
+		MessageKey: f.MessageKey,
+		CallerKey:  f.CallerKey,
+
+		PriorityKeys: f.PriorityKeys,
+	}), nil
+}
+
+func level(level logrus.Level) log.Level {
+	switch level {
+	case logrus.TraceLevel:
+		return log.TraceLevel
+	case logrus.DebugLevel:
+		return log.DebugLevel
+	case logrus.InfoLevel:
+		return log.InfoLevel
+	case logrus.WarnLevel:
+		return log.WarnLevel
+	case logrus.ErrorLevel:
+		return log.ErrorLevel
+	case logrus.PanicLevel:
+		return log.PanicLevel
+	default:
+		return log.FatalLevel
+	}
+}
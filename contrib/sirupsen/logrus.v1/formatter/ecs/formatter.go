@@ -0,0 +1,135 @@
+package ecs
+
+import (
+	"strconv"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/ecs"
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/sirupsen/logrus"
+)
+
+// Formatter is a logrus formatter writing Elastic Common Schema JSON lines:
+// @timestamp, log.level, message and ecs.version, followed by the fields, whose
+// dotted keys are nested. The error field is written as error.message,
+// error.type and error.stack_trace, the caller as log.origin, and the trace and
+// span ids as trace.id and span.id.
+type Formatter struct {
+	ErrorKey      string // key of the error field, "err" when empty
+	StacktraceKey string // key of the stack trace field, none when empty
+	CallerKey     string // key of the caller field, replaced by log.origin
+	TraceIDKey    string // key of the trace id field, "trace_id" when empty
+	SpanIDKey     string // key of the span id field, "span_id" when empty
+
+	PriorityKeys []string // fields written first, in this order, the others being sorted by key
+}
+
+// Option represents an ECS formatter option.
+type Option func(formatter *Formatter)
+
+// New returns a new logrus formatter for ECS.
+func New(options ...Option) logrus.Formatter {
+	fmt := &Formatter{
+		ErrorKey:   ecs.DefaultErrorKey,
+		CallerKey:  "caller",
+		TraceIDKey: ecs.DefaultTraceIDKey,
+		SpanIDKey:  ecs.DefaultSpanIDKey,
+	}
+
+	for _, option := range options {
+		option(fmt)
+	}
+
+	return fmt
+}
+
+// WithErrorKey sets formatter's error key to value.
+func WithErrorKey(value string) Option {
+	return func(formatter *Formatter) {
+		formatter.ErrorKey = value
+	}
+}
+
+// WithStacktraceKey sets formatter's stack trace key to value.
+func WithStacktraceKey(value string) Option {
+	return func(formatter *Formatter) {
+		formatter.StacktraceKey = value
+	}
+}
+
+// WithCallerKey sets formatter's caller key to value.
+func WithCallerKey(value string) Option {
+	return func(formatter *Formatter) {
+		formatter.CallerKey = value
+	}
+}
+
+// WithTraceIDKey sets formatter's trace id key to value.
+func WithTraceIDKey(value string) Option {
+	return func(formatter *Formatter) {
+		formatter.TraceIDKey = value
+	}
+}
+
+// WithSpanIDKey sets formatter's span id key to value.
+func WithSpanIDKey(value string) Option {
+	return func(formatter *Formatter) {
+		formatter.SpanIDKey = value
+	}
+}
+
+// WithPriorityKeys sets formatter's priority keys to value.
+func WithPriorityKeys(value ...string) Option {
+	return func(formatter *Formatter) {
+		formatter.PriorityKeys = value
+	}
+}
+
+// Format renders a single log entry.
+func (f *Formatter) Format(e *logrus.Entry) ([]byte, error) {
+	fields := make(log.Fields, len(e.Data))
+	for k, v := range e.Data {
+		fields[k] = v
+	}
+
+	var caller, function string
+	if e.Caller != nil {
+		caller = e.Caller.File + ":" + strconv.Itoa(e.Caller.Line)
+		function = e.Caller.Function
+		delete(fields, f.CallerKey)
+	}
+
+	return ecs.Append(nil, entry.Entry{
+		Time:     e.Time,
+		Level:    level(e.Level),
+		Message:  e.Message,
+		Caller:   caller,
+		Function: function,
+		Fields:   fields,
+	}, ecs.Options{
+		ErrorKey:      f.ErrorKey,
+		StacktraceKey: f.StacktraceKey,
+		TraceIDKey:    f.TraceIDKey,
+		SpanIDKey:     f.SpanIDKey,
+		PriorityKeys:  f.PriorityKeys,
+	}), nil
+}
+
+func level(level logrus.Level) log.Level {
+	switch level {
+	case logrus.TraceLevel:
+		return log.TraceLevel
+	case logrus.DebugLevel:
+		return log.DebugLevel
+	case logrus.InfoLevel:
+		return log.InfoLevel
+	case logrus.WarnLevel:
+		return log.WarnLevel
+	case logrus.ErrorLevel:
+		return log.ErrorLevel
+	case logrus.PanicLevel:
+		return log.PanicLevel
+	default:
+		return log.FatalLevel
+	}
+}
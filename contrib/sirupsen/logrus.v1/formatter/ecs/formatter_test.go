@@ -0,0 +1,144 @@
+package ecs
+
+import (
+	"errors"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FormatterSuite struct {
+	suite.Suite
+}
+
+func TestFormatterSuite(t *testing.T) {
+	suite.Run(t, new(FormatterSuite))
+}
+
+func buildBasicFormatterForTesting() *Formatter {
+	return &Formatter{
+		ErrorKey:   "err",
+		CallerKey:  "caller",
+		TraceIDKey: "trace_id",
+		SpanIDKey:  "span_id",
+	}
+}
+
+func (s *FormatterSuite) TestNew() {
+
+	tt := []struct {
+		name string
+		want func() logrus.Formatter
+		opts []Option
+	}{
+		{
+			name: "New Formatter with default options",
+			want: func() logrus.Formatter {
+				return buildBasicFormatterForTesting()
+			},
+			opts: []Option{},
+		},
+		{
+			name: "New Formatter with error key",
+			want: func() logrus.Formatter {
+				fmt := buildBasicFormatterForTesting()
+				WithErrorKey("error")(fmt)
+				return fmt
+			},
+			opts: []Option{
+				WithErrorKey("error"),
+			},
+		},
+		{
+			name: "New Formatter with stacktrace key",
+			want: func() logrus.Formatter {
+				fmt := buildBasicFormatterForTesting()
+				WithStacktraceKey("stack")(fmt)
+				return fmt
+			},
+			opts: []Option{
+				WithStacktraceKey("stack"),
+			},
+		},
+		{
+			name: "New Formatter with caller key",
+			want: func() logrus.Formatter {
+				fmt := buildBasicFormatterForTesting()
+				WithCallerKey("source")(fmt)
+				return fmt
+			},
+			opts: []Option{
+				WithCallerKey("source"),
+			},
+		},
+		{
+			name: "New Formatter with trace and span id keys",
+			want: func() logrus.Formatter {
+				fmt := buildBasicFormatterForTesting()
+				WithTraceIDKey("traceID")(fmt)
+				WithSpanIDKey("spanID")(fmt)
+				return fmt
+			},
+			opts: []Option{
+				WithTraceIDKey("traceID"),
+				WithSpanIDKey("spanID"),
+			},
+		},
+	}
+
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			got := New(t.opts...)
+			want := t.want()
+			s.Assert().True(reflect.DeepEqual(got, want), "got  %v\nwant %v", got, want)
+		})
+	}
+}
+
+func (s *FormatterSuite) TestFormat() {
+	at := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tt := []struct {
+		name  string
+		entry *logrus.Entry
+		want  string
+	}{
+		{
+			name:  "without fields",
+			entry: &logrus.Entry{Time: at, Level: logrus.InfoLevel, Message: "hello", Data: logrus.Fields{}},
+			want:  `{"@timestamp":"2021-01-02T03:04:05.000Z","log.level":"info","message":"hello","ecs.version":"8.11.0"}`,
+		},
+		{
+			name: "with error, ids and caller",
+			entry: &logrus.Entry{
+				Time:    at,
+				Level:   logrus.ErrorLevel,
+				Message: "failed",
+				Data: logrus.Fields{
+					"err":                 errors.New("bad"),
+					"trace_id":            "abc",
+					"caller":              "/src/main.go:10",
+					"http.request.method": "GET",
+				},
+				Caller: &runtime.Frame{File: "/src/main.go", Line: 10, Function: "main.main"},
+			},
+			want: `{"@timestamp":"2021-01-02T03:04:05.000Z","log.level":"error","message":"failed","ecs.version":"8.11.0",
+				"error":{"message":"bad","type":"*errors.errorString"},"trace":{"id":"abc"},
+				"http":{"request":{"method":"GET"}},
+				"log":{"origin":{"file":{"name":"/src/main.go","line":10},"function":"main.main"}}}`,
+		},
+	}
+
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			got, err := New().Format(t.entry)
+			s.Require().NoError(err)
+			s.Assert().JSONEq(t.want, string(got))
+		})
+	}
+}
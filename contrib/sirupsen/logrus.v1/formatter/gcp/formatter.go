@@ -0,0 +1,136 @@
+package gcp
+
+import (
+	"strconv"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/americanas-go/log/internal/gcp"
+	"github.com/sirupsen/logrus"
+)
+
+// Formatter is a logrus formatter writing the structured JSON lines of Google
+// Cloud Logging: timestamp, severity and message, followed by the caller as
+// logging.googleapis.com/sourceLocation, the trace and span ids as
+// logging.googleapis.com/trace and logging.googleapis.com/spanId, and the
+// other fields, the HTTP request one being written as httpRequest.
+type Formatter struct {
+	ProjectID      string // project of the traces, the trace id is written as is when empty
+	CallerKey      string // key of the caller field, replaced by the source location
+	TraceIDKey     string // key of the trace id field, "trace_id" when empty
+	SpanIDKey      string // key of the span id field, "span_id" when empty
+	HTTPRequestKey string // key of the HTTP request field, "httpRequest" when empty
+
+	PriorityKeys []string // fields written first, in this order, the others being sorted by key
+}
+
+// Option represents a GCP formatter option.
+type Option func(formatter *Formatter)
+
+// New returns a new logrus formatter for Google Cloud Logging.
+func New(options ...Option) logrus.Formatter {
+	fmt := &Formatter{
+		CallerKey:      "caller",
+		TraceIDKey:     gcp.DefaultTraceIDKey,
+		SpanIDKey:      gcp.DefaultSpanIDKey,
+		HTTPRequestKey: gcp.DefaultHTTPRequestKey,
+	}
+
+	for _, option := range options {
+		option(fmt)
+	}
+
+	return fmt
+}
+
+// WithProjectID sets formatter's project id to value.
+func WithProjectID(value string) Option {
+	return func(formatter *Formatter) {
+		formatter.ProjectID = value
+	}
+}
+
+// WithCallerKey sets formatter's caller key to value.
+func WithCallerKey(value string) Option {
+	return func(formatter *Formatter) {
+		formatter.CallerKey = value
+	}
+}
+
+// WithTraceIDKey sets formatter's trace id key to value.
+func WithTraceIDKey(value string) Option {
+	return func(formatter *Formatter) {
+		formatter.TraceIDKey = value
+	}
+}
+
+// WithSpanIDKey sets formatter's span id key to value.
+func WithSpanIDKey(value string) Option {
+	return func(formatter *Formatter) {
+		formatter.SpanIDKey = value
+	}
+}
+
+// WithHTTPRequestKey sets formatter's HTTP request key to value.
+func WithHTTPRequestKey(value string) Option {
+	return func(formatter *Formatter) {
+		formatter.HTTPRequestKey = value
+	}
+}
+
+// WithPriorityKeys sets formatter's priority keys to value.
+func WithPriorityKeys(value ...string) Option {
+	return func(formatter *Formatter) {
+		formatter.PriorityKeys = value
+	}
+}
+
+// Format renders a single log entry.
+func (f *Formatter) Format(e *logrus.Entry) ([]byte, error) {
+	fields := make(log.Fields, len(e.Data))
+	for k, v := range e.Data {
+		fields[k] = v
+	}
+
+	var caller, function string
+	if e.Caller != nil {
+		caller = e.Caller.File + ":" + strconv.Itoa(e.Caller.Line)
+		function = e.Caller.Function
+		delete(fields, f.CallerKey)
+	}
+
+	return gcp.Append(nil, entry.Entry{
+		Time:     e.Time,
+		Level:    level(e.Level),
+		Message:  e.Message,
+		Caller:   caller,
+		Function: function,
+		Fields:   fields,
+	}, gcp.Options{
+		ProjectID:      f.ProjectID,
+		TraceIDKey:     f.TraceIDKey,
+		SpanIDKey:      f.SpanIDKey,
+		HTTPRequestKey: f.HTTPRequestKey,
+
+		PriorityKeys: f.PriorityKeys,
+	}), nil
+}
+
+func level(level logrus.Level) log.Level {
+	switch level {
+	case logrus.TraceLevel:
+		return log.TraceLevel
+	case logrus.DebugLevel:
+		return log.DebugLevel
+	case logrus.InfoLevel:
+		return log.InfoLevel
+	case logrus.WarnLevel:
+		return log.WarnLevel
+	case logrus.ErrorLevel:
+		return log.ErrorLevel
+	case logrus.PanicLevel:
+		return log.PanicLevel
+	default:
+		return log.FatalLevel
+	}
+}
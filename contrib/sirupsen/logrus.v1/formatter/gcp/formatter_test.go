@@ -0,0 +1,136 @@
+package gcp
+
+import (
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FormatterSuite struct {
+	suite.Suite
+}
+
+func TestFormatterSuite(t *testing.T) {
+	suite.Run(t, new(FormatterSuite))
+}
+
+func buildBasicFormatterForTesting() *Formatter {
+	return &Formatter{
+		CallerKey:      "caller",
+		TraceIDKey:     "trace_id",
+		SpanIDKey:      "span_id",
+		HTTPRequestKey: "httpRequest",
+	}
+}
+
+func (s *FormatterSuite) TestNew() {
+
+	tt := []struct {
+		name string
+		want func() logrus.Formatter
+		opts []Option
+	}{
+		{
+			name: "New Formatter with default options",
+			want: func() logrus.Formatter {
+				return buildBasicFormatterForTesting()
+			},
+			opts: []Option{},
+		},
+		{
+			name: "New Formatter with project id",
+			want: func() logrus.Formatter {
+				fmt := buildBasicFormatterForTesting()
+				WithProjectID("orders-prod")(fmt)
+				return fmt
+			},
+			opts: []Option{
+				WithProjectID("orders-prod"),
+			},
+		},
+		{
+			name: "New Formatter with caller key",
+			want: func() logrus.Formatter {
+				fmt := buildBasicFormatterForTesting()
+				WithCallerKey("source")(fmt)
+				return fmt
+			},
+			opts: []Option{
+				WithCallerKey("source"),
+			},
+		},
+		{
+			name: "New Formatter with trace, span id and HTTP request keys",
+			want: func() logrus.Formatter {
+				fmt := buildBasicFormatterForTesting()
+				WithTraceIDKey("traceID")(fmt)
+				WithSpanIDKey("spanID")(fmt)
+				WithHTTPRequestKey("request")(fmt)
+				return fmt
+			},
+			opts: []Option{
+				WithTraceIDKey("traceID"),
+				WithSpanIDKey("spanID"),
+				WithHTTPRequestKey("request"),
+			},
+		},
+	}
+
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			got := New(t.opts...)
+			want := t.want()
+			s.Assert().True(reflect.DeepEqual(got, want), "got  %v\nwant %v", got, want)
+		})
+	}
+}
+
+func (s *FormatterSuite) TestFormat() {
+	at := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tt := []struct {
+		name  string
+		entry *logrus.Entry
+		opts  []Option
+		want  string
+	}{
+		{
+			name:  "without fields",
+			entry: &logrus.Entry{Time: at, Level: logrus.WarnLevel, Message: "hello", Data: logrus.Fields{}},
+			want:  `{"timestamp":"2021-01-02T03:04:05Z","severity":"WARNING","message":"hello"}`,
+		},
+		{
+			name: "with ids and caller",
+			entry: &logrus.Entry{
+				Time:    at,
+				Level:   logrus.ErrorLevel,
+				Message: "failed",
+				Data: logrus.Fields{
+					"trace_id": "abc",
+					"span_id":  "def",
+					"caller":   "/src/main.go:10",
+					"order":    1,
+				},
+				Caller: &runtime.Frame{File: "/src/main.go", Line: 10, Function: "main.main"},
+			},
+			opts: []Option{WithProjectID("orders-prod")},
+			want: `{"timestamp":"2021-01-02T03:04:05Z","severity":"ERROR","message":"failed",
+				"logging.googleapis.com/sourceLocation":{"file":"/src/main.go","line":"10","function":"main.main"},
+				"logging.googleapis.com/trace":"projects/orders-prod/traces/abc",
+				"logging.googleapis.com/spanId":"def","order":1}`,
+		},
+	}
+
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			got, err := New(t.opts...).Format(t.entry)
+			s.Require().NoError(err)
+			s.Assert().JSONEq(t.want, string(got))
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package logfmt
+
+import (
+	"strconv"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/americanas-go/log/internal/logfmt"
+	"github.com/sirupsen/logrus"
+)
+
+// Formatter is a logrus formatter writing strict logfmt lines: the time,
+// level, message and caller keys, followed by the fields sorted by key, with
+// the keys and values quoted and escaped as needed. The lines are those of
+// the LOGFMT formatter of the other backends.
+type Formatter struct {
+	TimeKey    string // key of the time, omitted when empty
+	LevelKey   string // key of the level, omitted when empty
+	MessageKey string // key of the message, "msg" when empty
+	CallerKey  string // key of the caller, omitted when empty
+	TimeFormat string // format of the time, such as RFC3339 or EPOCH_MILLIS
+
+	PriorityKeys []string // fields written first, in this order, the others being sorted by key
+}
+
+// Option represents a logfmt formatter option.
+type Option func(formatter *Formatter)
+
+// New returns a new logrus formatter for logfmt.
+func New(options ...Option) logrus.Formatter {
+	fmt := &Formatter{
+		TimeKey:    logfmt.DefaultTimeKey,
+		LevelKey:   logfmt.DefaultLevelKey,
+		MessageKey: logfmt.DefaultMessageKey,
+		CallerKey:  logfmt.DefaultCallerKey,
+		TimeFormat: logfmt.TimeFormatRFC3339,
+	}
+
+	for _, option := range options {
+		option(fmt)
+	}
+
+	return fmt
+}
+
+// WithTimeKey sets formatter's time key to value.
+func WithTimeKey(value string) Option {
+	return func(formatter *Formatter) {
+		formatter.TimeKey = value
+	}
+}
+
+// WithLevelKey sets formatter's level key to value.
+func WithLevelKey(value string) Option {
+	return func(formatter *Formatter) {
+		formatter.LevelKey = value
+	}
+}
+
+// WithMessageKey sets formatter's message key to value.
+func WithMessageKey(value string) Option {
+	return func(formatter *Formatter) {
+		formatter.MessageKey = value
+	}
+}
+
+// WithCallerKey sets formatter's caller key to value.
+func WithCallerKey(value string) Option {
+	return func(formatter *Formatter) {
+		formatter.CallerKey = value
+	}
+}
+
+// WithTimeFormat sets formatter's time format to value.
+func WithTimeFormat(value string) Option {
+	return func(formatter *Formatter) {
+		formatter.TimeFormat = value
+	}
+}
+
+// WithPriorityKeys sets formatter's priority keys to value.
+func WithPriorityKeys(value ...string) Option {
+	return func(formatter *Formatter) {
+		formatter.PriorityKeys = value
+	}
+}
+
+// Format renders a single log entry.
+func (f *Formatter) Format(e *logrus.Entry) ([]byte, error) {
+	fields := make(log.Fields, len(e.Data))
+	for k, v := range e.Data {
+		fields[k] = v
+	}
+
+	var caller string
+	if e.Caller != nil {
+		caller = e.Caller.File + ":" + strconv.Itoa(e.Caller.Line)
+		delete(fields, f.CallerKey)
+	}
+
+	return logfmt.Append(nil, entry.Entry{
+		Time:    e.Time,
+		Level:   level(e.Level),
+		Message: e.Message,
+		Caller:  caller,
+		Fields:  fields,
+	}, logfmt.Options{
+		TimeKey:    f.TimeKey,
+		LevelKey:   f.LevelKey,
+		MessageKey: f.MessageKey,
+		CallerKey:  f.CallerKey,
+		TimeFormat: f.TimeFormat,
+
+		PriorityKeys: f.PriorityKeys,
+	}), nil
+}
+
+func level(level logrus.Level) log.Level {
+	switch level {
+	case logrus.TraceLevel:
+		return log.TraceLevel
+	case logrus.DebugLevel:
+		return log.DebugLevel
+	case logrus.InfoLevel:
+		return log.InfoLevel
+	case logrus.WarnLevel:
+		return log.WarnLevel
+	case logrus.ErrorLevel:
+		return log.ErrorLevel
+	case logrus.PanicLevel:
+		return log.PanicLevel
+	default:
+		return log.FatalLevel
+	}
+}
@@ -0,0 +1,125 @@
+package logfmt
+
+import (
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FormatterSuite struct {
+	suite.Suite
+}
+
+func TestFormatterSuite(t *testing.T) {
+	suite.Run(t, new(FormatterSuite))
+}
+
+func buildBasicFormatterForTesting() *Formatter {
+	return &Formatter{
+		TimeKey:    "time",
+		LevelKey:   "level",
+		MessageKey: "msg",
+		CallerKey:  "caller",
+		TimeFormat: "RFC3339",
+	}
+}
+
+func (s *FormatterSuite) TestNew() {
+
+	tt := []struct {
+		name string
+		want func() logrus.Formatter
+		opts []Option
+	}{
+		{
+			name: "New Formatter with default options",
+			want: func() logrus.Formatter {
+				return buildBasicFormatterForTesting()
+			},
+			opts: []Option{},
+		},
+		{
+			name: "New Formatter with core keys",
+			want: func() logrus.Formatter {
+				fmt := buildBasicFormatterForTesting()
+				WithTimeKey("ts")(fmt)
+				WithLevelKey("lvl")(fmt)
+				WithMessageKey("message")(fmt)
+				WithCallerKey("source")(fmt)
+				return fmt
+			},
+			opts: []Option{
+				WithTimeKey("ts"),
+				WithLevelKey("lvl"),
+				WithMessageKey("message"),
+				WithCallerKey("source"),
+			},
+		},
+		{
+			name: "New Formatter with time format",
+			want: func() logrus.Formatter {
+				fmt := buildBasicFormatterForTesting()
+				WithTimeFormat("EPOCH_MILLIS")(fmt)
+				return fmt
+			},
+			opts: []Option{
+				WithTimeFormat("EPOCH_MILLIS"),
+			},
+		},
+	}
+
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			got := New(t.opts...)
+			want := t.want()
+			s.Assert().True(reflect.DeepEqual(got, want), "got  %v\nwant %v", got, want)
+		})
+	}
+}
+
+func (s *FormatterSuite) TestFormat() {
+	at := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tt := []struct {
+		name  string
+		entry *logrus.Entry
+		opts  []Option
+		want  string
+	}{
+		{
+			name:  "without fields",
+			entry: &logrus.Entry{Time: at, Level: logrus.InfoLevel, Message: "hello", Data: logrus.Fields{}},
+			want:  "time=2021-01-02T03:04:05Z level=info msg=hello\n",
+		},
+		{
+			name: "with caller and fields",
+			entry: &logrus.Entry{
+				Time:    at,
+				Level:   logrus.WarnLevel,
+				Message: "order created",
+				Data:    logrus.Fields{"caller": "/src/main.go:10", "order": 1, "user": "John Doe"},
+				Caller:  &runtime.Frame{File: "/src/main.go", Line: 10, Function: "main.main"},
+			},
+			want: `time=2021-01-02T03:04:05Z level=warn msg="order created" caller=/src/main.go:10 order=1 user="John Doe"` + "\n",
+		},
+		{
+			name:  "with custom keys",
+			entry: &logrus.Entry{Time: at, Level: logrus.ErrorLevel, Message: "failed", Data: logrus.Fields{}},
+			opts:  []Option{WithTimeKey(""), WithLevelKey("lvl"), WithMessageKey("message")},
+			want:  "lvl=error message=failed\n",
+		},
+	}
+
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			got, err := New(t.opts...).Format(t.entry)
+			s.Require().NoError(err)
+			s.Assert().Equal(t.want, string(got))
+		})
+	}
+}
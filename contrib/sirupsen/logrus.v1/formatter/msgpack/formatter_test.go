@@ -0,0 +1,132 @@
+package msgpack
+
+import (
+	"bytes"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/americanas-go/log/internal/msgpack"
+	"github.com/sirupsen/logrus"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FormatterSuite struct {
+	suite.Suite
+}
+
+func TestFormatterSuite(t *testing.T) {
+	suite.Run(t, new(FormatterSuite))
+}
+
+func buildBasicFormatterForTesting() *Formatter {
+	return &Formatter{
+		TimeKey:    "time",
+		LevelKey:   "level",
+		MessageKey: "message",
+		CallerKey:  "caller",
+	}
+}
+
+func (s *FormatterSuite) TestNew() {
+
+	tt := []struct {
+		name string
+		want func() logrus.Formatter
+		opts []Option
+	}{
+		{
+			name: "New Formatter with default options",
+			want: func() logrus.Formatter {
+				return buildBasicFormatterForTesting()
+			},
+			opts: []Option{},
+		},
+		{
+			name: "New Formatter with core keys",
+			want: func() logrus.Formatter {
+				fmt := buildBasicFormatterForTesting()
+				WithTimeKey("ts")(fmt)
+				WithLevelKey("lvl")(fmt)
+				WithMessageKey("msg")(fmt)
+				WithCallerKey("source")(fmt)
+				return fmt
+			},
+			opts: []Option{
+				WithTimeKey("ts"),
+				WithLevelKey("lvl"),
+				WithMessageKey("msg"),
+				WithCallerKey("source"),
+			},
+		},
+	}
+
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			got := New(t.opts...)
+			want := t.want()
+			s.Assert().True(reflect.DeepEqual(got, want), "got  %v\nwant %v", got, want)
+		})
+	}
+}
+
+func (s *FormatterSuite) TestFormat() {
+	at := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tt := []struct {
+		name  string
+		entry *logrus.Entry
+		opts  []Option
+		want  []msgpack.Pair
+	}{
+		{
+			name:  "without fields",
+			entry: &logrus.Entry{Time: at, Level: logrus.InfoLevel, Message: "hello", Data: logrus.Fields{}},
+			want: []msgpack.Pair{
+				{Key: "time", Value: at.Local()},
+				{Key: "level", Value: "info"},
+				{Key: "message", Value: "hello"},
+			},
+		},
+		{
+			name: "with caller and fields",
+			entry: &logrus.Entry{
+				Time:    at,
+				Level:   logrus.WarnLevel,
+				Message: "order created",
+				Data:    logrus.Fields{"caller": "/src/main.go:10", "order": 1, "user": "John Doe"},
+				Caller:  &runtime.Frame{File: "/src/main.go", Line: 10, Function: "main.main"},
+			},
+			want: []msgpack.Pair{
+				{Key: "time", Value: at.Local()},
+				{Key: "level", Value: "warn"},
+				{Key: "message", Value: "order created"},
+				{Key: "caller", Value: "/src/main.go:10"},
+				{Key: "order", Value: int64(1)},
+				{Key: "user", Value: "John Doe"},
+			},
+		},
+		{
+			name:  "with custom keys",
+			entry: &logrus.Entry{Time: at, Level: logrus.ErrorLevel, Message: "failed", Data: logrus.Fields{}},
+			opts:  []Option{WithTimeKey(""), WithLevelKey("lvl"), WithMessageKey("msg")},
+			want: []msgpack.Pair{
+				{Key: "lvl", Value: "error"},
+				{Key: "msg", Value: "failed"},
+			},
+		},
+	}
+
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			got, err := New(t.opts...).Format(t.entry)
+			s.Require().NoError(err)
+
+			pairs, err := msgpack.NewDecoder(bytes.NewReader(got)).DecodeMap()
+			s.Require().NoError(err)
+			s.Assert().Equal(t.want, pairs)
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package pretty
+
+import (
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/color"
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/americanas-go/log/internal/pretty"
+	"github.com/sirupsen/logrus"
+)
+
+// Formatter is a logrus formatter writing the entries for the humans reading
+// a console: the level badge, the time elapsed since the start, the message
+// and the caller, followed by the fields, one per line, nested maps and
+// structs being indented and the error and stack trace written last. The
+// lines are those of the PRETTY formatter of the other backends.
+//
+// The logger resolves it with For when creating its outputs, so that it is
+// colored when the output is a terminal.
+type Formatter struct {
+	Start     time.Time // start of the relative times, written as clock times when zero
+	Color     bool      // whether the levels, times, keys and errors are colored
+	Width     int       // width at which the values are wrapped, 100 when zero
+	ErrorKey  string    // key of the error, written last
+	StackKey  string    // key of the stack trace, written after the error
+	CallerKey string    // key of the caller, written after the message
+
+	PriorityKeys []string // fields written first, in this order, the others being sorted by key
+}
+
+// Option represents a pretty formatter option.
+type Option func(formatter *Formatter)
+
+// New returns a new logrus formatter for the console, whose times are
+// relative to its creation.
+func New(options ...Option) logrus.Formatter {
+	fmt := &Formatter{
+		Start:    time.Now(),
+		Width:    pretty.DefaultWidth,
+		ErrorKey: logrus.ErrorKey,
+	}
+
+	for _, option := range options {
+		option(fmt)
+	}
+
+	return fmt
+}
+
+// WithStart sets formatter's start of the relative times to value.
+func WithStart(value time.Time) Option {
+	return func(formatter *Formatter) {
+		formatter.Start = value
+	}
+}
+
+// WithColor sets whether formatter colors its output to value.
+func WithColor(value bool) Option {
+	return func(formatter *Formatter) {
+		formatter.Color = value
+	}
+}
+
+// WithWidth sets formatter's wrap width to value.
+func WithWidth(value int) Option {
+	return func(formatter *Formatter) {
+		formatter.Width = value
+	}
+}
+
+// WithErrorKey sets formatter's error key to value.
+func WithErrorKey(value string) Option {
+	return func(formatter *Formatter) {
+		formatter.ErrorKey = value
+	}
+}
+
+// WithStackKey sets formatter's stack trace key to value.
+func WithStackKey(value string) Option {
+	return func(formatter *Formatter) {
+		formatter.StackKey = value
+	}
+}
+
+// WithCallerKey sets formatter's caller key to value.
+func WithCallerKey(value string) Option {
+	return func(formatter *Formatter) {
+		formatter.CallerKey = value
+	}
+}
+
+// WithPriorityKeys sets formatter's priority keys to value.
+func WithPriorityKeys(value ...string) Option {
+	return func(formatter *Formatter) {
+		formatter.PriorityKeys = value
+	}
+}
+
+// For returns a copy of the formatter for the entries written to w, colored
+// when w is a terminal, as decided by color.Enabled.
+func (f *Formatter) For(w io.Writer) logrus.Formatter {
+	formatter := *f
+	formatter.Color = color.Enabled(w)
+	return &formatter
+}
+
+// Format renders a single log entry.
+func (f *Formatter) Format(e *logrus.Entry) ([]byte, error) {
+	fields := make(log.Fields, len(e.Data))
+	for k, v := range e.Data {
+		fields[k] = v
+	}
+
+	var caller string
+	if e.Caller != nil {
+		caller = e.Caller.File + ":" + strconv.Itoa(e.Caller.Line)
+		delete(fields, f.CallerKey)
+	}
+
+	return pretty.Append(nil, entry.Entry{
+		Time:    e.Time,
+		Level:   level(e.Level),
+		Message: e.Message,
+		Caller:  caller,
+		Fields:  fields,
+	}, pretty.Options{
+		Start:    f.Start,
+		Color:    f.Color,
+		Width:    f.Width,
+		ErrorKey: f.ErrorKey,
+		StackKey: f.StackKey,
+
+		PriorityKeys: f.PriorityKeys,
+	}), nil
+}
+
+func level(level logrus.Level) log.Level {
+	switch level {
+	case logrus.TraceLevel:
+		return log.TraceLevel
+	case logrus.DebugLevel:
+		return log.DebugLevel
+	case logrus.InfoLevel:
+		return log.InfoLevel
+	case logrus.WarnLevel:
+		return log.WarnLevel
+	case logrus.ErrorLevel:
+		return log.ErrorLevel
+	case logrus.PanicLevel:
+		return log.PanicLevel
+	default:
+		return log.FatalLevel
+	}
+}
@@ -0,0 +1,126 @@
+package pretty
+
+import (
+	"bytes"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FormatterSuite struct {
+	suite.Suite
+}
+
+func TestFormatterSuite(t *testing.T) {
+	suite.Run(t, new(FormatterSuite))
+}
+
+var start = time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func buildBasicFormatterForTesting() *Formatter {
+	return &Formatter{
+		Start:    start,
+		Width:    100,
+		ErrorKey: "error",
+	}
+}
+
+func (s *FormatterSuite) TestNew() {
+
+	tt := []struct {
+		name string
+		want func() logrus.Formatter
+		opts []Option
+	}{
+		{
+			name: "New Formatter with default options",
+			want: func() logrus.Formatter {
+				return buildBasicFormatterForTesting()
+			},
+			opts: []Option{},
+		},
+		{
+			name: "New Formatter with options",
+			want: func() logrus.Formatter {
+				fmt := buildBasicFormatterForTesting()
+				WithColor(true)(fmt)
+				WithWidth(80)(fmt)
+				WithErrorKey("err")(fmt)
+				WithStackKey("stacktrace")(fmt)
+				WithCallerKey("caller")(fmt)
+				return fmt
+			},
+			opts: []Option{
+				WithColor(true),
+				WithWidth(80),
+				WithErrorKey("err"),
+				WithStackKey("stacktrace"),
+				WithCallerKey("caller"),
+			},
+		},
+	}
+
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			got := New(append([]Option{WithStart(start)}, t.opts...)...)
+			want := t.want()
+			s.Assert().True(reflect.DeepEqual(got, want), "got  %v\nwant %v", got, want)
+		})
+	}
+}
+
+func (s *FormatterSuite) TestFor() {
+	for _, key := range []string{"NO_COLOR", "FORCE_COLOR", "CLICOLOR", "CLICOLOR_FORCE"} {
+		s.T().Setenv(key, "")
+	}
+
+	f := New(WithColor(true)).(*Formatter)
+	s.Assert().False(f.For(&bytes.Buffer{}).(*Formatter).Color)
+	s.Assert().True(f.Color)
+
+	s.T().Setenv("FORCE_COLOR", "1")
+	s.Assert().True(f.For(&bytes.Buffer{}).(*Formatter).Color)
+}
+
+func (s *FormatterSuite) TestFormat() {
+	tt := []struct {
+		name  string
+		entry *logrus.Entry
+		opts  []Option
+		want  string
+	}{
+		{
+			name:  "without fields",
+			entry: &logrus.Entry{Time: start.Add(1204 * time.Millisecond), Level: logrus.InfoLevel, Message: "hello", Data: logrus.Fields{}},
+			want:  "INFO  +1.204s   hello\n",
+		},
+		{
+			name: "with caller and fields",
+			entry: &logrus.Entry{
+				Time:    start,
+				Level:   logrus.WarnLevel,
+				Message: "order created",
+				Data:    logrus.Fields{"caller": "/src/main.go:10", "order": 1, "tags": []string{"new"}},
+				Caller:  &runtime.Frame{File: "/src/main.go", Line: 10, Function: "main.main"},
+			},
+			opts: []Option{WithCallerKey("caller")},
+			want: "WARN  +0.000s   order created /src/main.go:10\n" +
+				"    order: 1\n" +
+				"    tags:\n" +
+				"      - new\n",
+		},
+	}
+
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			got, err := New(append([]Option{WithStart(start)}, t.opts...)...).Format(t.entry)
+			s.Require().NoError(err)
+			s.Assert().Equal(t.want, string(got))
+		})
+	}
+}
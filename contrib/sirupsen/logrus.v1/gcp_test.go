@@ -0,0 +1,53 @@
+package logrus
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/gcp"
+	"github.com/stretchr/testify/suite"
+)
+
+type GcpSuite struct {
+	suite.Suite
+}
+
+func TestGcpSuite(t *testing.T) {
+	suite.Run(t, new(GcpSuite))
+}
+
+func (s *GcpSuite) TestLogger() {
+	request := httptest.NewRequest("POST", "/orders", nil)
+	lines := logFile(&s.Suite, gcp.New(), func(logger log.Logger) {
+		logger.Debug("started")
+		logger.
+			WithFields(map[string]interface{}{"traceID": "abc", "span_id": "def", "httpRequest": request}).
+			WithError(errors.New("something bad")).
+			Error("failed")
+	},
+		WithFileLevel("DEBUG"),
+		WithFieldNames("time", "level", "message", "caller", ""),
+		WithGCPProjectID("orders-prod"),
+		WithGCPTraceIDField("traceID"),
+	)
+
+	s.Require().Len(lines, 2)
+	s.Assert().Equal("DEBUG", lines[0]["severity"])
+	s.Assert().Equal("started", lines[0]["message"])
+	s.Assert().NotEmpty(lines[0]["timestamp"])
+
+	line := lines[1]
+	s.Assert().Equal("ERROR", line["severity"])
+	s.Assert().Equal("projects/orders-prod/traces/abc", line["logging.googleapis.com/trace"])
+	s.Assert().Equal("def", line["logging.googleapis.com/spanId"])
+	s.Assert().Equal("something bad", line["err"])
+	s.Assert().Equal("POST", line["httpRequest"].(map[string]interface{})["requestMethod"])
+	s.Assert().NotContains(line, "caller")
+
+	location := line["logging.googleapis.com/sourceLocation"].(map[string]interface{})
+	s.Assert().NotEmpty(location["file"])
+	s.Assert().NotEmpty(location["line"])
+	s.Assert().NotEmpty(location["function"])
+}
@@ -0,0 +1,38 @@
+package logrus
+
+import (
+	"testing"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/logfmt"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/pretty"
+	"github.com/americanas-go/log/internal/logtest"
+	"github.com/sirupsen/logrus"
+)
+
+func TestLogfmt(t *testing.T) {
+	logtest.Logfmt(t, newFileLogger)
+}
+
+// newFileLogger returns the logger of the tests of the file formatters.
+func newFileLogger(file logtest.File) log.Logger {
+	formatters := map[string]logrus.Formatter{
+		"LOGFMT": logfmt.New(),
+		"PRETTY": pretty.New(),
+	}
+
+	options := []Option{
+		WithConsoleEnabled(false),
+		WithFileEnabled(true),
+		WithFilePath(file.Dir),
+		WithFileName("app.log"),
+		WithFileFormatter(formatters[file.Formatter]),
+		WithFileLevel("DEBUG"),
+		WithFieldNames(file.Names.Time, file.Names.Level, file.Names.Message, file.Names.Caller, ""),
+		WithTimeClock(file.Clock),
+	}
+	if file.TimeFormat != "" {
+		options = append(options, WithTimeFormat(file.TimeFormat))
+	}
+	return NewLogger(options...)
+}
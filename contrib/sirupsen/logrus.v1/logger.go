@@ -4,31 +4,121 @@ import (
 	"context"
 	"io"
 	"io/ioutil"
-	"os"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/americanas-go/log"
 	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/text"
+	"github.com/americanas-go/log/internal/closer"
+	"github.com/americanas-go/log/internal/forward"
+	"github.com/americanas-go/log/internal/gcp"
+	"github.com/americanas-go/log/internal/gelf"
+	"github.com/americanas-go/log/internal/journald"
+	"github.com/americanas-go/log/internal/loki"
+	"github.com/americanas-go/log/internal/network"
+	"github.com/americanas-go/log/internal/otlp"
+	"github.com/americanas-go/log/internal/rotate"
+	"github.com/americanas-go/log/internal/syslog"
 	"github.com/sirupsen/logrus"
-	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 type ctxKey string
 
 const (
-	key                   ctxKey = "ctxfields"
-	defaultConsoleEnabled        = true
-	defaultConsoleLevel          = "INFO"
-	defaultFileEnabled           = false
-	defaultFileLevel             = "INFO"
-	defaultFilePath              = "/tmp"
-	defaultFileName              = "application.log"
-	defaultFileMaxSize           = 100
-	defaultFileCompress          = true
-	defaultFileMaxAge            = 28
-	defaultTimeFormat            = "2006/01/02 15:04:05.000"
-	defaultErrorFieldName        = "err"
+	key                            ctxKey = "ctxfields"
+	defaultConsoleEnabled                 = true
+	defaultConsoleLevel                   = "INFO"
+	defaultConsoleWriter                  = ConsoleWriterStdout
+	defaultConsoleSplitLevel              = "WARN"
+	defaultFileEnabled                    = false
+	defaultFileLevel                      = "INFO"
+	defaultFilePath                       = "/tmp"
+	defaultFileName                       = "application.log"
+	defaultFileMaxSize                    = 100
+	defaultFileCompress                   = true
+	defaultFileMaxAge                     = 28
+	defaultFileMaxBackups                 = 0
+	defaultFileMaxTotalSize               = 0
+	defaultFileRotation                   = rotate.RotationSize
+	defaultFileLocalTime                  = false
+	defaultFileRotateOnStartup            = false
+	defaultFileMode                       = 0o600
+	defaultFileDirMode                    = 0o755
+	defaultSyslogEnabled                  = false
+	defaultSyslogLevel                    = "INFO"
+	defaultSyslogNetwork                  = "udp"
+	defaultSyslogAddress                  = "localhost:514"
+	defaultSyslogFormat                   = syslog.FormatRFC5424
+	defaultSyslogFacility                 = "USER"
+	defaultNetworkEnabled                 = false
+	defaultNetworkLevel                   = "INFO"
+	defaultNetworkProtocol                = network.ProtocolTCP
+	defaultNetworkAddress                 = "localhost:5170"
+	defaultNetworkBufferSize              = 1024
+	defaultNetworkSpillMaxSize            = 100
+	defaultNetworkMinBackoff              = 100 * time.Millisecond
+	defaultNetworkMaxBackoff              = 30 * time.Second
+	defaultGELFEnabled                    = false
+	defaultGELFLevel                      = "INFO"
+	defaultGELFProtocol                   = gelf.ProtocolUDP
+	defaultGELFAddress                    = "localhost:12201"
+	defaultGELFCompression                = gelf.CompressionGzip
+	defaultGELFChunkSize                  = 1420
+	defaultForwardEnabled                 = false
+	defaultForwardLevel                   = "INFO"
+	defaultForwardAddress                 = "localhost:24224"
+	defaultForwardTag                     = "app"
+	defaultForwardMode                    = forward.ModeForward
+	defaultForwardAckTimeout              = 5 * time.Second
+	defaultForwardBatchSize               = 100
+	defaultForwardFlushInterval           = time.Second
+	defaultForwardBufferSize              = 1024
+	defaultForwardMaxRetries              = 10
+	defaultLokiEnabled                    = false
+	defaultLokiLevel                      = "INFO"
+	defaultLokiURL                        = "http://localhost:3100/loki/api/v1/push"
+	defaultLokiMaxStreams                 = 100
+	defaultLokiEncoding                   = loki.EncodingJSON
+	defaultLokiBatchSize                  = 100
+	defaultLokiBatchWait                  = time.Second
+	defaultLokiBufferSize                 = 1024
+	defaultLokiTimeout                    = 10 * time.Second
+	defaultLokiMaxRetries                 = 10
+	defaultElasticsearchEnabled           = false
+	defaultElasticsearchLevel             = "INFO"
+	defaultElasticsearchURL               = "http://localhost:9200"
+	defaultElasticsearchIndex             = "logs-{2006.01.02}"
+	defaultElasticsearchECS               = true
+	defaultElasticsearchBatchSize         = 100
+	defaultElasticsearchBatchAge          = time.Second
+	defaultElasticsearchBufferSize        = 1024
+	defaultElasticsearchTimeout           = 10 * time.Second
+	defaultElasticsearchMaxRetries        = 5
+	defaultOTLPEnabled                    = false
+	defaultOTLPLevel                      = "INFO"
+	defaultOTLPProtocol                   = otlp.ProtocolHTTP
+	defaultOTLPTraceIDField               = "trace_id"
+	defaultOTLPSpanIDField                = "span_id"
+	defaultOTLPBatchSize                  = 512
+	defaultOTLPBatchTimeout               = time.Second
+	defaultOTLPBufferSize                 = 2048
+	defaultOTLPTimeout                    = 10 * time.Second
+	defaultOTLPMaxRetries                 = 5
+	defaultJournaldEnabled                = false
+	defaultJournaldLevel                  = "INFO"
+	defaultJournaldSocket                 = journald.DefaultSocket
+	defaultGCPTraceIDField                = gcp.DefaultTraceIDKey
+	defaultGCPSpanIDField                 = gcp.DefaultSpanIDKey
+	defaultGCPHTTPRequestField            = gcp.DefaultHTTPRequestKey
+	defaultTimeFormat                     = "2006/01/02 15:04:05.000"
+	defaultErrorFieldName                 = "err"
+
+	defaultTimeFieldName       = logrus.FieldKeyTime
+	defaultLevelFieldName      = logrus.FieldKeyLevel
+	defaultMessageFieldName    = logrus.FieldKeyMsg
+	defaultCallerFieldName     = ""
+	defaultStacktraceFieldName = ""
 )
 
 // NewLogger constructs a new Logger from provided variadic Option.
@@ -42,42 +132,53 @@ func NewLoggerWithOptions(options *Options) log.Logger {
 
 	lLogger := new(logrus.Logger)
 
+	// init level hooks
+	lLogger.Hooks = logrus.LevelHooks{}
 	for _, hook := range options.Hooks {
-		// init level hooks
-		lLogger.Hooks = logrus.LevelHooks{}
 		lLogger.AddHook(hook)
 	}
 
-	var fileHandler *lumberjack.Logger
-
-	lLogger.SetOutput(ioutil.Discard)
+	if options.Time.Clock != nil || options.Time.UTC {
+		lLogger.AddHook(&timeHook{clock: options.Time.Clock, utc: options.Time.UTC})
+	}
 
-	if options.File.Enabled {
+	names := getFieldNames(options)
+	if names.Caller != "" {
+		lLogger.AddHook(&callerHook{fieldName: names.Caller})
+	}
 
-		s := []string{options.File.Path, "/", options.File.Name}
-		fileLocation := strings.Join(s, "")
+	lLogger.SetOutput(ioutil.Discard)
+	lLogger.SetLevel(logLevel(options.Console.Level))
+	lLogger.SetFormatter(options.Formatter)
 
-		fileHandler = &lumberjack.Logger{
-			Filename: fileLocation,
-			MaxSize:  options.File.MaxSize,
-			Compress: options.File.Compress,
-			MaxAge:   options.File.MaxAge,
+	// the first output is written by the logger itself and the others by
+	// hooks, so that each one has its own level and formatter
+	outputs := getOutputs(options, names)
+	if len(outputs) > 0 {
+		level := mostVerbose(outputs)
+		lLogger.SetLevel(level)
+
+		hooked := outputs
+		if outputs[0].writer != nil {
+			lLogger.SetOutput(outputs[0].writer)
+			lLogger.SetFormatter(outputs[0].formatter)
+			if outputs[0].level < level || outputs[0].severest > logrus.PanicLevel {
+				lLogger.SetFormatter(&levelFormatter{Formatter: outputs[0].formatter, output: outputs[0]})
+			}
+			hooked = outputs[1:]
 		}
 
+		for _, o := range hooked {
+			lLogger.AddHook(newOutputHook(o, names.Caller))
+		}
 	}
 
-	if options.Console.Enabled && options.File.Enabled {
-		lLogger.SetOutput(io.MultiWriter(os.Stdout, fileHandler))
-	} else if options.File.Enabled {
-		lLogger.SetOutput(fileHandler)
-	} else if options.Console.Enabled {
-		lLogger.SetOutput(os.Stdout)
+	resources := &closer.Group{}
+	for _, o := range outputs {
+		resources.Add(o.entries, o.owned)
 	}
-
-	level := logLevel(options.Console.Level)
-	lLogger.SetLevel(level)
-
-	lLogger.SetFormatter(options.Formatter)
+	// added last, so that the hooked outputs already have the entry
+	lLogger.AddHook(&syncHook{resources: resources})
 
 	// Default options are only applied if this is called via NewLogger
 	// If called direct, the options passed to this function may be empty.
@@ -91,6 +192,12 @@ func NewLoggerWithOptions(options *Options) log.Logger {
 		logger:         lLogger,
 		fields:         log.Fields{},
 		errorFieldName: errorField,
+		resources:      resources,
+		spans: &otlp.SpanContext{
+			Func:         options.OTLP.SpanContext,
+			TraceIDField: options.OTLP.TraceIDField,
+			SpanIDField:  options.OTLP.SpanIDField,
+		},
 	}
 
 	log.SetGlobalLogger(logger)
@@ -98,39 +205,115 @@ func NewLoggerWithOptions(options *Options) log.Logger {
 }
 
 func defaultOptions() *Options {
-	return &Options{
+	options := &Options{
 		Formatter:      text.New(),
 		ErrorFieldName: defaultErrorFieldName,
-		Time: struct {
-			Format string
-		}{
-			Format: defaultTimeFormat,
-		},
-		Console: struct {
-			Enabled bool
-			Level   string
-		}{
-			Enabled: defaultConsoleEnabled,
-			Level:   defaultConsoleLevel,
-		},
-		File: struct {
-			Enabled  bool
-			Level    string
-			Path     string
-			Name     string
-			MaxSize  int
-			Compress bool
-			MaxAge   int
-		}{
-			Enabled:  defaultFileEnabled,
-			Level:    defaultFileLevel,
-			Path:     defaultFilePath,
-			Name:     defaultFileName,
-			MaxSize:  defaultFileMaxSize,
-			Compress: defaultFileCompress,
-			MaxAge:   defaultFileMaxAge,
-		},
 	}
+
+	options.Time.Format = defaultTimeFormat
+
+	options.Console.Enabled = defaultConsoleEnabled
+	options.Console.Level = defaultConsoleLevel
+	options.Console.Writer = defaultConsoleWriter
+	options.Console.SplitLevel = defaultConsoleSplitLevel
+
+	options.File.Enabled = defaultFileEnabled
+	options.File.Level = defaultFileLevel
+	options.File.Path = defaultFilePath
+	options.File.Name = defaultFileName
+	options.File.MaxSize = defaultFileMaxSize
+	options.File.Compress = defaultFileCompress
+	options.File.MaxAge = defaultFileMaxAge
+	options.File.MaxBackups = defaultFileMaxBackups
+	options.File.MaxTotalSize = defaultFileMaxTotalSize
+	options.File.Rotation = defaultFileRotation
+	options.File.LocalTime = defaultFileLocalTime
+	options.File.RotateOnStartup = defaultFileRotateOnStartup
+	options.File.Mode = defaultFileMode
+	options.File.DirMode = defaultFileDirMode
+
+	options.Syslog.Enabled = defaultSyslogEnabled
+	options.Syslog.Level = defaultSyslogLevel
+	options.Syslog.Network = defaultSyslogNetwork
+	options.Syslog.Address = defaultSyslogAddress
+	options.Syslog.Format = defaultSyslogFormat
+	options.Syslog.Facility = defaultSyslogFacility
+
+	options.Network.Enabled = defaultNetworkEnabled
+	options.Network.Level = defaultNetworkLevel
+	options.Network.Protocol = defaultNetworkProtocol
+	options.Network.Address = defaultNetworkAddress
+	options.Network.BufferSize = defaultNetworkBufferSize
+	options.Network.SpillMaxSize = defaultNetworkSpillMaxSize
+	options.Network.MinBackoff = defaultNetworkMinBackoff
+	options.Network.MaxBackoff = defaultNetworkMaxBackoff
+
+	options.GELF.Enabled = defaultGELFEnabled
+	options.GELF.Level = defaultGELFLevel
+	options.GELF.Protocol = defaultGELFProtocol
+	options.GELF.Address = defaultGELFAddress
+	options.GELF.Compression = defaultGELFCompression
+	options.GELF.ChunkSize = defaultGELFChunkSize
+
+	options.Forward.Enabled = defaultForwardEnabled
+	options.Forward.Level = defaultForwardLevel
+	options.Forward.Address = defaultForwardAddress
+	options.Forward.Tag = defaultForwardTag
+	options.Forward.Mode = defaultForwardMode
+	options.Forward.AckTimeout = defaultForwardAckTimeout
+	options.Forward.BatchSize = defaultForwardBatchSize
+	options.Forward.FlushInterval = defaultForwardFlushInterval
+	options.Forward.BufferSize = defaultForwardBufferSize
+	options.Forward.MaxRetries = defaultForwardMaxRetries
+
+	options.Loki.Enabled = defaultLokiEnabled
+	options.Loki.Level = defaultLokiLevel
+	options.Loki.URL = defaultLokiURL
+	options.Loki.MaxStreams = defaultLokiMaxStreams
+	options.Loki.Encoding = defaultLokiEncoding
+	options.Loki.BatchSize = defaultLokiBatchSize
+	options.Loki.BatchWait = defaultLokiBatchWait
+	options.Loki.BufferSize = defaultLokiBufferSize
+	options.Loki.Timeout = defaultLokiTimeout
+	options.Loki.MaxRetries = defaultLokiMaxRetries
+
+	options.Elasticsearch.Enabled = defaultElasticsearchEnabled
+	options.Elasticsearch.Level = defaultElasticsearchLevel
+	options.Elasticsearch.URL = defaultElasticsearchURL
+	options.Elasticsearch.Index = defaultElasticsearchIndex
+	options.Elasticsearch.ECS = defaultElasticsearchECS
+	options.Elasticsearch.BatchSize = defaultElasticsearchBatchSize
+	options.Elasticsearch.BatchAge = defaultElasticsearchBatchAge
+	options.Elasticsearch.BufferSize = defaultElasticsearchBufferSize
+	options.Elasticsearch.Timeout = defaultElasticsearchTimeout
+	options.Elasticsearch.MaxRetries = defaultElasticsearchMaxRetries
+
+	options.OTLP.Enabled = defaultOTLPEnabled
+	options.OTLP.Level = defaultOTLPLevel
+	options.OTLP.Protocol = defaultOTLPProtocol
+	options.OTLP.TraceIDField = defaultOTLPTraceIDField
+	options.OTLP.SpanIDField = defaultOTLPSpanIDField
+	options.OTLP.BatchSize = defaultOTLPBatchSize
+	options.OTLP.BatchTimeout = defaultOTLPBatchTimeout
+	options.OTLP.BufferSize = defaultOTLPBufferSize
+	options.OTLP.Timeout = defaultOTLPTimeout
+	options.OTLP.MaxRetries = defaultOTLPMaxRetries
+
+	options.Journald.Enabled = defaultJournaldEnabled
+	options.Journald.Level = defaultJournaldLevel
+	options.Journald.Socket = defaultJournaldSocket
+
+	options.GCP.TraceIDField = defaultGCPTraceIDField
+	options.GCP.SpanIDField = defaultGCPSpanIDField
+	options.GCP.HTTPRequestField = defaultGCPHTTPRequestField
+
+	options.FieldNames.Time = defaultTimeFieldName
+	options.FieldNames.Level = defaultLevelFieldName
+	options.FieldNames.Message = defaultMessageFieldName
+	options.FieldNames.Caller = defaultCallerFieldName
+	options.FieldNames.Stacktrace = defaultStacktraceFieldName
+
+	return options
 }
 
 func options(option []Option) *Options {
@@ -144,7 +327,7 @@ func options(option []Option) *Options {
 
 func logLevel(level string) logrus.Level {
 
-	switch level {
+	switch strings.ToUpper(level) {
 
 	case "DEBUG":
 		return logrus.DebugLevel
@@ -168,6 +351,8 @@ type logger struct {
 	logger         *logrus.Logger
 	fields         log.Fields
 	errorFieldName string
+	resources      *closer.Group
+	spans          *otlp.SpanContext // ids of the span of the contexts of FromContext
 }
 
 func (l *logger) Trace(args ...interface{}) {
@@ -235,15 +420,21 @@ func (l *logger) WithField(key string, value interface{}) log.Logger {
 	entry := l.logger.WithField(key, value)
 
 	return &logEntry{
-		entry:  entry,
-		fields: convertToFields(entry.Data),
+		entry:          entry,
+		fields:         convertToFields(entry.Data),
+		errorFieldName: l.errorFieldName,
+		resources:      l.resources,
+		spans:          l.spans,
 	}
 }
 
 func (l *logger) WithFields(fields map[string]interface{}) log.Logger {
 	return &logEntry{
-		entry:  l.logger.WithFields(convertToLogrusFields(fields)),
-		fields: fields,
+		entry:          l.logger.WithFields(convertToLogrusFields(fields)),
+		fields:         fields,
+		errorFieldName: l.errorFieldName,
+		resources:      l.resources,
+		spans:          l.spans,
 	}
 }
 
@@ -258,7 +449,9 @@ func (l *logger) WithTypeOf(obj interface{}) log.Logger {
 }
 
 func (l *logger) WithError(err error) log.Logger {
-	return l.WithField(l.errorFieldName, err.Error())
+	entry := l.WithField(l.errorFieldName, err.Error()).(*logEntry)
+	entry.err = err
+	return entry
 }
 
 func (l *logger) Fields() log.Fields {
@@ -273,53 +466,91 @@ func (l *logger) ToContext(ctx context.Context) context.Context {
 	return toContext(ctx, l.fields)
 }
 
+// FromContext returns a Logger from ctx, with the ids of its span when a span
+// context function is set.
 func (l *logger) FromContext(ctx context.Context) log.Logger {
 	fields := fieldsFromContext(ctx)
+	for k, v := range l.spans.Fields(ctx) {
+		fields[k] = v
+	}
 	return l.WithFields(fields)
 }
 
+// Sync sends the entries buffered by the outputs, such as the batches of the
+// loki output, and waits for them to be sent.
+func (l *logger) Sync() error {
+	return l.resources.Flush()
+}
+
+// Close syncs the logger then closes its outputs, which l shares with the
+// loggers derived from it.
+func (l *logger) Close() error {
+	return l.resources.Close()
+}
+
 type logEntry struct {
 	entry          *logrus.Entry
 	fields         map[string]interface{}
 	errorFieldName string
+	resources      *closer.Group
+	spans          *otlp.SpanContext // ids of the span of the contexts of FromContext
+	err            error             // error of WithError, whose message is in the fields, see withError
+}
+
+// withError returns the entry of l with the error of WithError in place of its
+// message, so that the formatters and outputs using its type, such as ECS,
+// have it. The JSON and text formatters write it as its message.
+func (l *logEntry) withError() *logrus.Entry {
+	if l.err == nil {
+		return l.entry
+	}
+	return l.entry.WithField(l.errorFieldName, l.err)
 }
 
 func (l *logEntry) Trace(args ...interface{}) {
-	l.entry.Trace(args...)
+	l.withError().Trace(args...)
 }
 
 func (l *logEntry) Debug(args ...interface{}) {
-	l.entry.Debug(args...)
+	l.withError().Debug(args...)
 }
 
 func (l *logEntry) Info(args ...interface{}) {
-	l.entry.Info(args...)
+	l.withError().Info(args...)
 }
 
 func (l *logEntry) Warn(args ...interface{}) {
-	l.entry.Warn(args...)
+	l.withError().Warn(args...)
 }
 
 func (l *logEntry) Error(args ...interface{}) {
-	l.entry.Error(args...)
+	l.withError().Error(args...)
 }
 
 func (l *logEntry) Fatal(args ...interface{}) {
-	l.entry.Fatal(args...)
+	l.withError().Fatal(args...)
 }
 
 func (l *logEntry) Panic(args ...interface{}) {
-	l.entry.Panic(args...)
+	l.withError().Panic(args...)
 }
 
 func (l *logEntry) WithField(key string, value interface{}) log.Logger {
 
 	entry := l.entry.WithField(key, value)
 
+	err := l.err
+	if key == l.errorFieldName {
+		err = nil
+	}
+
 	return &logEntry{
 		entry:          entry,
 		fields:         convertToFields(entry.Data),
 		errorFieldName: l.errorFieldName,
+		resources:      l.resources,
+		spans:          l.spans,
+		err:            err,
 	}
 }
 
@@ -332,43 +563,51 @@ func (l *logEntry) Output() io.Writer {
 }
 
 func (l *logEntry) Printf(format string, args ...interface{}) {
-	l.entry.Printf(format, args...)
+	l.withError().Printf(format, args...)
 }
 
 func (l *logEntry) Tracef(format string, args ...interface{}) {
-	l.entry.Tracef(format, args...)
+	l.withError().Tracef(format, args...)
 }
 
 func (l *logEntry) Debugf(format string, args ...interface{}) {
-	l.entry.Debugf(format, args...)
+	l.withError().Debugf(format, args...)
 }
 
 func (l *logEntry) Infof(format string, args ...interface{}) {
-	l.entry.Infof(format, args...)
+	l.withError().Infof(format, args...)
 }
 
 func (l *logEntry) Warnf(format string, args ...interface{}) {
-	l.entry.Warnf(format, args...)
+	l.withError().Warnf(format, args...)
 }
 
 func (l *logEntry) Errorf(format string, args ...interface{}) {
-	l.entry.Errorf(format, args...)
+	l.withError().Errorf(format, args...)
 }
 
 func (l *logEntry) Panicf(format string, args ...interface{}) {
-	l.entry.Panicf(format, args...)
+	l.withError().Panicf(format, args...)
 }
 
 func (l *logEntry) Fatalf(format string, args ...interface{}) {
-	l.entry.Fatalf(format, args...)
+	l.withError().Fatalf(format, args...)
 }
 
 func (l *logEntry) WithFields(fields map[string]interface{}) log.Logger {
+	err := l.err
+	if _, ok := fields[l.errorFieldName]; ok {
+		err = nil
+	}
+
 	entry := l.entry.WithFields(convertToLogrusFields(fields))
 	return &logEntry{
 		entry:          entry,
 		fields:         convertToFields(entry.Data),
 		errorFieldName: l.errorFieldName,
+		resources:      l.resources,
+		spans:          l.spans,
+		err:            err,
 	}
 }
 
@@ -383,15 +622,22 @@ func (l *logEntry) WithTypeOf(obj interface{}) log.Logger {
 }
 
 func (l *logEntry) WithError(err error) log.Logger {
-	return l.WithField(l.errorFieldName, err.Error())
+	entry := l.WithField(l.errorFieldName, err.Error()).(*logEntry)
+	entry.err = err
+	return entry
 }
 
 func (l *logEntry) ToContext(ctx context.Context) context.Context {
 	return toContext(ctx, l.fields)
 }
 
+// FromContext returns a Logger from ctx, with the ids of its span when a span
+// context function is set.
 func (l *logEntry) FromContext(ctx context.Context) log.Logger {
 	fields := fieldsFromContext(ctx)
+	for k, v := range l.spans.Fields(ctx) {
+		fields[k] = v
+	}
 	return l.WithFields(fields)
 }
 
@@ -436,3 +682,15 @@ func convertToFields(logrusFields logrus.Fields) log.Fields {
 	}
 	return fields
 }
+
+// Sync sends the entries buffered by the outputs, such as the batches of the
+// loki output, and waits for them to be sent.
+func (l *logEntry) Sync() error {
+	return l.resources.Flush()
+}
+
+// Close syncs the logger then closes its outputs, which l shares with the
+// logger it was derived from and the loggers derived from it.
+func (l *logEntry) Close() error {
+	return l.resources.Close()
+}
@@ -8,6 +8,7 @@ import (
 	"log/syslog"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -336,7 +337,9 @@ func (s *LoggerSuite) TestLoggerWithMethods() {
 					entry: l.logger.WithField("ID", "1"),
 					fields: log.Fields{
 						"ID": "1",
-					}}
+					},
+					errorFieldName: "err",
+				}
 				return l2
 			},
 		},
@@ -357,7 +360,9 @@ func (s *LoggerSuite) TestLoggerWithMethods() {
 					fields: log.Fields{
 						"ID":   "12",
 						"Name": "Stockton",
-					}}
+					},
+					errorFieldName: "err",
+				}
 				return l2
 			},
 		},
@@ -376,7 +381,9 @@ func (s *LoggerSuite) TestLoggerWithMethods() {
 					fields: log.Fields{
 						"reflect.type.name":    t.Name(),
 						"reflect.type.package": t.PkgPath(),
-					}}
+					},
+					errorFieldName: "err",
+				}
 				return l2
 			},
 		},
@@ -392,7 +399,10 @@ func (s *LoggerSuite) TestLoggerWithMethods() {
 					}),
 					fields: log.Fields{
 						"err": "something bad",
-					}}
+					},
+					errorFieldName: "err",
+					err:            errors.New("something bad"),
+				}
 				return l2
 			},
 		},
@@ -909,3 +919,30 @@ func (s *LoggerSuite) Test_convertToFields() {
 		})
 	}
 }
+
+func (s *LoggerSuite) TestLoggerFileReopen() {
+	dir := filepath.Join(s.T().TempDir(), "logs")
+	logger := NewLogger(
+		WithConsoleEnabled(false),
+		WithFileEnabled(true),
+		WithFilePath(dir),
+		WithFileName("app.log"),
+		WithFileRotation("NONE"),
+	)
+
+	logger.Info("before")
+	// logrotate renames the file, then sends SIGHUP
+	s.Require().NoError(os.Rename(filepath.Join(dir, "app.log"), filepath.Join(dir, "app.log.1")))
+	s.Require().NoError(Reopen())
+	logger.Info("after")
+
+	rotated, err := os.ReadFile(filepath.Join(dir, "app.log.1"))
+	s.Require().NoError(err)
+	s.Assert().Contains(string(rotated), "before")
+	s.Assert().NotContains(string(rotated), "after")
+
+	reopened, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	s.Require().NoError(err)
+	s.Assert().Contains(string(reopened), "after")
+	s.Assert().NotContains(string(reopened), "before")
+}
@@ -0,0 +1,178 @@
+package logrus
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/cbor"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/ecs"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/gcp"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/logfmt"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/msgpack"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/pretty"
+	"github.com/americanas-go/log/internal/cloudwatch"
+	"github.com/americanas-go/log/internal/order"
+	"github.com/ravernkoh/cwlogsfmt"
+	"github.com/sirupsen/logrus"
+)
+
+// withPriorityFields makes the formatter write the priority fields first, in
+// their order, the other fields being sorted by key as logrus does. The text
+// formatter keeps its time, level and message first, the JSON formatter, also
+// after withTimeFormat, writes the priority fields before every other key, and
+// the CloudWatch formatter writes them first after the message unless its
+// sorting is disabled. Other formatters are returned unchanged.
+func withPriorityFields(formatter logrus.Formatter, priority []string) logrus.Formatter {
+	if len(priority) == 0 {
+		return formatter
+	}
+
+	switch f := formatter.(type) {
+	case *logrus.TextFormatter:
+		if f.SortingFunc == nil && !f.DisableSorting {
+			f.SortingFunc = textSortingFunc(f, priority)
+		}
+	case *logrus.JSONFormatter:
+		return &priorityJSONFormatter{Formatter: f, priority: priority, indent: f.PrettyPrint}
+	case *epochFormatter:
+		if j, ok := f.Formatter.(*logrus.JSONFormatter); ok && f.json {
+			return &priorityJSONFormatter{Formatter: f, priority: priority, indent: j.PrettyPrint}
+		}
+		return withPriorityFields(f.Formatter, priority)
+	case *logfmt.Formatter:
+		f.PriorityKeys = priority
+	case *pretty.Formatter:
+		f.PriorityKeys = priority
+	case *ecs.Formatter:
+		f.PriorityKeys = priority
+	case *gcp.Formatter:
+		f.PriorityKeys = priority
+	case *cbor.Formatter:
+		f.PriorityKeys = priority
+	case *msgpack.Formatter:
+		f.PriorityKeys = priority
+	case *cwlogsfmt.CloudWatchLogsFormatter:
+		if !f.DisableSorting {
+			return &priorityCloudWatchFormatter{CloudWatchLogsFormatter: f, priority: priority}
+		}
+	}
+	return formatter
+}
+
+// priorityCloudWatchFormatter writes the lines of a CloudWatch formatter with
+// the encoder of the other backends, which writes the same lines, the priority
+// fields first after the message.
+type priorityCloudWatchFormatter struct {
+	*cwlogsfmt.CloudWatchLogsFormatter
+	priority []string
+}
+
+func (f *priorityCloudWatchFormatter) Format(e *logrus.Entry) ([]byte, error) {
+	// the caller is one of the fields, as cwlogsfmt writes it
+	return cloudwatch.Append(nil, toEntry(e, ""), cloudwatch.Options{
+		PrefixFields:     f.PrefixFields,
+		QuoteEmptyFields: f.QuoteEmptyFields,
+		PriorityKeys:     f.priority,
+	}), nil
+}
+
+// textSortingFunc returns the sorting of the keys of the text formatter f,
+// which, when it is not colored, sorts the time, level, message, error and
+// caller keys of logrus with the fields. Those keys are kept first.
+func textSortingFunc(f *logrus.TextFormatter, priority []string) func([]string) {
+	return func(keys []string) {
+		fixed := map[string]bool{
+			logrus.FieldKeyTime:        true,
+			logrus.FieldKeyLevel:       true,
+			logrus.FieldKeyMsg:         true,
+			logrus.FieldKeyLogrusError: true,
+			logrus.FieldKeyFunc:        true,
+			logrus.FieldKeyFile:        true,
+		}
+		for key, name := range f.FieldMap {
+			delete(fixed, string(key))
+			fixed[name] = true
+		}
+
+		n := 0
+		for n < len(keys) && fixed[keys[n]] {
+			n++
+		}
+		order.Sort(keys[n:], priority)
+	}
+}
+
+// priorityJSONFormatter moves the priority fields of the JSON objects written
+// by a formatter to the front, keeping the order of the other keys.
+type priorityJSONFormatter struct {
+	logrus.Formatter
+	priority []string
+	indent   bool
+}
+
+func (f *priorityJSONFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	b, err := f.Formatter.Format(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	var pairs []jsonPair
+	d := json.NewDecoder(bytes.NewReader(b))
+	if t, err := d.Token(); err != nil || t != json.Delim('{') {
+		return b, nil
+	}
+	for d.More() {
+		t, err := d.Token()
+		if err != nil {
+			return b, nil
+		}
+		var value json.RawMessage
+		if err := d.Decode(&value); err != nil {
+			return b, nil
+		}
+		pairs = append(pairs, jsonPair{key: t.(string), value: value})
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	written := make(map[string]bool, len(f.priority))
+	for _, k := range f.priority {
+		for _, p := range pairs {
+			if p.key == k && !written[k] {
+				writeJSONPair(buf, p)
+				written[k] = true
+			}
+		}
+	}
+	for _, p := range pairs {
+		if !written[p.key] {
+			writeJSONPair(buf, p)
+		}
+	}
+	buf.WriteByte('}')
+
+	if f.indent {
+		indented := &bytes.Buffer{}
+		if err := json.Indent(indented, buf.Bytes(), "", "  "); err == nil {
+			buf = indented
+		}
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// jsonPair is a key of a JSON object with its raw value.
+type jsonPair struct {
+	key   string
+	value json.RawMessage
+}
+
+func writeJSONPair(buf *bytes.Buffer, p jsonPair) {
+	if buf.Len() > 1 {
+		buf.WriteByte(',')
+	}
+	key, _ := json.Marshal(p.key)
+	buf.Write(key)
+	buf.WriteByte(':')
+	buf.Write(p.value)
+}
@@ -0,0 +1,99 @@
+package logrus
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/cloudwatch"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/json"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/logfmt"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/text"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/suite"
+)
+
+type OrderSuite struct {
+	suite.Suite
+}
+
+func TestOrderSuite(t *testing.T) {
+	suite.Run(t, new(OrderSuite))
+}
+
+// logFile logs with a file output of formatter, returning the lines.
+func (s *OrderSuite) logFile(formatter logrus.Formatter, option ...Option) []string {
+	dir := s.T().TempDir()
+	logger := NewLogger(append([]Option{
+		WithConsoleEnabled(false),
+		WithFileEnabled(true),
+		WithFilePath(dir),
+		WithFileName("app.log"),
+		WithFileFormatter(formatter),
+		WithFieldNames("", "level", "msg", "", ""),
+	}, option...)...)
+
+	fields := log.Fields{"b": 1, "user_id": 2, "a": 3, "request_id": 4, "c": 5}
+	for i := 0; i < 5; i++ {
+		logger.WithField("trace_id", 6).WithFields(fields).Info("hello")
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	s.Require().NoError(err)
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+func (s *OrderSuite) TestLogger() {
+	tt := []struct {
+		name      string
+		formatter func() logrus.Formatter
+		options   []Option
+		want      string
+	}{
+		{
+			name:      "JSON sorted",
+			formatter: func() logrus.Formatter { return json.New() },
+			want:      `{"a":3,"b":1,"c":5,"level":"info","msg":"hello","request_id":4,"trace_id":6,"user_id":2}`,
+		},
+		{
+			name:      "JSON with priority fields",
+			formatter: func() logrus.Formatter { return json.New() },
+			options:   []Option{WithPriorityFields("request_id", "trace_id", "user_id")},
+			want:      `{"request_id":4,"trace_id":6,"user_id":2,"a":3,"b":1,"c":5,"level":"info","msg":"hello"}`,
+		},
+		{
+			name:      "JSON with priority fields and epoch time",
+			formatter: func() logrus.Formatter { return json.New() },
+			options:   []Option{WithPriorityFields("request_id", "trace_id", "user_id"), WithFieldNames("time", "level", "msg", "", ""), WithTimeFormat(TimeFormatEpoch)},
+			want:      `{"request_id":4,"trace_id":6,"user_id":2,"time":\d+,"a":3,"b":1,"c":5,"level":"info","msg":"hello"}`,
+		},
+		{
+			name:      "TEXT with priority fields",
+			formatter: func() logrus.Formatter { return text.New(text.WithDisableColors(true)) },
+			options:   []Option{WithPriorityFields("request_id", "trace_id", "user_id")},
+			want:      `level=info msg=hello request_id=4 trace_id=6 user_id=2 a=3 b=1 c=5`,
+		},
+		{
+			name:      "LOGFMT with priority fields",
+			formatter: func() logrus.Formatter { return logfmt.New() },
+			options:   []Option{WithPriorityFields("request_id", "trace_id", "user_id")},
+			want:      `level=info msg=hello request_id=4 trace_id=6 user_id=2 a=3 b=1 c=5`,
+		},
+		{
+			name:      "CLOUDWATCH with priority fields",
+			formatter: func() logrus.Formatter { return cloudwatch.New() },
+			options:   []Option{WithPriorityFields("request_id", "trace_id", "user_id")},
+			want:      `INFO Message: hello request_id: 4 trace_id: 6 user_id: 2 a: 3 b: 1 c: 5 `,
+		},
+	}
+
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			for _, line := range s.logFile(t.formatter(), t.options...) {
+				s.Assert().Regexp("^"+t.want+"$", line)
+			}
+		})
+	}
+}
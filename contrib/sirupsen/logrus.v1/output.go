@@ -0,0 +1,392 @@
+package logrus
+
+import (
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/auto"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/json"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/pretty"
+	"github.com/americanas-go/log/internal/closer"
+	"github.com/americanas-go/log/internal/elasticsearch"
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/americanas-go/log/internal/forward"
+	"github.com/americanas-go/log/internal/gelf"
+	"github.com/americanas-go/log/internal/journald"
+	"github.com/americanas-go/log/internal/loki"
+	"github.com/americanas-go/log/internal/network"
+	"github.com/americanas-go/log/internal/otlp"
+	"github.com/americanas-go/log/internal/reopen"
+	"github.com/americanas-go/log/internal/rotate"
+	"github.com/americanas-go/log/internal/syslog"
+	"github.com/sirupsen/logrus"
+)
+
+// output is a destination of the entries, with its own level and formatter.
+// It writes the entries from level up to severest, PanicLevel when unset. The
+// outputs with an entry.Writer encode the entries themselves and have neither
+// writer nor formatter.
+type output struct {
+	writer    io.Writer
+	entries   entry.Writer
+	formatter logrus.Formatter
+	level     logrus.Level
+	severest  logrus.Level
+	owned     io.Writer // writer flushed and closed with the logger, other than entries
+}
+
+func (o output) accepts(level logrus.Level) bool {
+	return level <= o.level && level >= o.severest
+}
+
+// getOutputs returns the enabled outputs, console first. Outputs without a
+// formatter use Options.Formatter.
+func getOutputs(options *Options, names fieldNames) []output {
+	var outputs []output
+
+	if options.Console.Enabled {
+		formatter := func(w io.Writer) logrus.Formatter {
+			return getFormatter(options.Console.Formatter, w, options, names)
+		}
+		level := logLevel(options.Console.Level)
+
+		switch options.Console.Writer {
+		case ConsoleWriterStderr:
+			outputs = append(outputs, output{writer: os.Stderr, formatter: formatter(os.Stderr), level: level})
+		case ConsoleWriterSplit:
+			split := logLevel(options.Console.SplitLevel)
+			outputs = append(outputs,
+				output{writer: os.Stdout, formatter: formatter(os.Stdout), level: level, severest: split + 1},
+				output{writer: os.Stderr, formatter: formatter(os.Stderr), level: min(level, split)},
+			)
+		default:
+			outputs = append(outputs, output{writer: os.Stdout, formatter: formatter(os.Stdout), level: level})
+		}
+	}
+
+	if options.File.Enabled {
+		writer := getFileWriter(options)
+		outputs = append(outputs, output{
+			writer:    writer,
+			formatter: getFormatter(options.File.Formatter, writer, options, names),
+			level:     logLevel(options.File.Level),
+			owned:     writer,
+		})
+	}
+
+	if options.Syslog.Enabled {
+		outputs = append(outputs, output{
+			entries: syslog.New(syslog.Options{
+				Network:  options.Syslog.Network,
+				Address:  options.Syslog.Address,
+				Format:   options.Syslog.Format,
+				Facility: options.Syslog.Facility,
+				AppName:  options.Syslog.AppName,
+				Hostname: options.Syslog.Hostname,
+				ProcID:   options.Syslog.ProcID,
+
+				PriorityKeys: options.PriorityFields,
+			}),
+			level: logLevel(options.Syslog.Level),
+		})
+	}
+
+	if options.GELF.Enabled {
+		outputs = append(outputs, output{
+			entries: gelf.New(gelf.Options{
+				Protocol:    options.GELF.Protocol,
+				Address:     options.GELF.Address,
+				Compression: options.GELF.Compression,
+				Host:        options.GELF.Host,
+				ChunkSize:   options.GELF.ChunkSize,
+			}),
+			level: logLevel(options.GELF.Level),
+		})
+	}
+
+	if options.Forward.Enabled {
+		outputs = append(outputs, output{
+			entries: forward.New(forward.Options{
+				Address:       options.Forward.Address,
+				Tag:           options.Forward.Tag,
+				TagField:      options.Forward.TagField,
+				Mode:          options.Forward.Mode,
+				RequireAck:    options.Forward.RequireAck,
+				AckTimeout:    options.Forward.AckTimeout,
+				BatchSize:     options.Forward.BatchSize,
+				FlushInterval: options.Forward.FlushInterval,
+				BufferSize:    options.Forward.BufferSize,
+				MaxRetries:    options.Forward.MaxRetries,
+				MessageKey:    names.Message,
+				LevelKey:      names.Level,
+				CallerKey:     names.Caller,
+
+				PriorityKeys: options.PriorityFields,
+			}),
+			level: logLevel(options.Forward.Level),
+		})
+	}
+
+	if options.Loki.Enabled {
+		outputs = append(outputs, output{
+			entries: loki.New(loki.Options{
+				URL:         options.Loki.URL,
+				TenantID:    options.Loki.TenantID,
+				Labels:      options.Loki.Labels,
+				LabelFields: options.Loki.LabelFields,
+				MaxStreams:  options.Loki.MaxStreams,
+				Encoding:    options.Loki.Encoding,
+				Gzip:        options.Loki.Gzip,
+				BatchSize:   options.Loki.BatchSize,
+				BatchWait:   options.Loki.BatchWait,
+				BufferSize:  options.Loki.BufferSize,
+				Timeout:     options.Loki.Timeout,
+				MaxRetries:  options.Loki.MaxRetries,
+				MessageKey:  names.Message,
+				LevelKey:    names.Level,
+				CallerKey:   names.Caller,
+			}),
+			level: logLevel(options.Loki.Level),
+		})
+	}
+
+	if options.Elasticsearch.Enabled {
+		outputs = append(outputs, output{
+			entries: elasticsearch.New(elasticsearch.Options{
+				URL:        options.Elasticsearch.URL,
+				Index:      options.Elasticsearch.Index,
+				Username:   options.Elasticsearch.Username,
+				Password:   options.Elasticsearch.Password,
+				APIKey:     options.Elasticsearch.APIKey,
+				ECS:        options.Elasticsearch.ECS,
+				BatchSize:  options.Elasticsearch.BatchSize,
+				BatchAge:   options.Elasticsearch.BatchAge,
+				BufferSize: options.Elasticsearch.BufferSize,
+				Timeout:    options.Elasticsearch.Timeout,
+				MaxRetries: options.Elasticsearch.MaxRetries,
+				TimeKey:    names.Time,
+				MessageKey: names.Message,
+				LevelKey:   names.Level,
+				CallerKey:  names.Caller,
+			}),
+			level: logLevel(options.Elasticsearch.Level),
+		})
+	}
+
+	if options.OTLP.Enabled {
+		outputs = append(outputs, output{
+			entries: otlp.New(otlp.Options{
+				Protocol:           options.OTLP.Protocol,
+				Endpoint:           options.OTLP.Endpoint,
+				Headers:            options.OTLP.Headers,
+				ServiceName:        options.OTLP.ServiceName,
+				ServiceVersion:     options.OTLP.ServiceVersion,
+				ResourceAttributes: options.OTLP.ResourceAttributes,
+				TraceIDField:       options.OTLP.TraceIDField,
+				SpanIDField:        options.OTLP.SpanIDField,
+				BatchSize:          options.OTLP.BatchSize,
+				BatchTimeout:       options.OTLP.BatchTimeout,
+				BufferSize:         options.OTLP.BufferSize,
+				Timeout:            options.OTLP.Timeout,
+				MaxRetries:         options.OTLP.MaxRetries,
+
+				PriorityKeys: options.PriorityFields,
+			}),
+			level: logLevel(options.OTLP.Level),
+		})
+	}
+
+	if options.Journald.Enabled {
+		outputs = append(outputs, output{
+			entries: journald.New(journald.Options{
+				Socket:     options.Journald.Socket,
+				Identifier: options.Journald.Identifier,
+
+				PriorityKeys: options.PriorityFields,
+			}),
+			level: logLevel(options.Journald.Level),
+		})
+	}
+
+	// the network output is always JSON lines
+	if options.Network.Enabled {
+		writer := network.New(network.Options{
+			Protocol:     options.Network.Protocol,
+			Address:      options.Network.Address,
+			TLSConfig:    options.Network.TLSConfig,
+			BufferSize:   options.Network.BufferSize,
+			SpillPath:    options.Network.SpillPath,
+			SpillMaxSize: options.Network.SpillMaxSize,
+			MinBackoff:   options.Network.MinBackoff,
+			MaxBackoff:   options.Network.MaxBackoff,
+			Metrics:      options.Network.Metrics,
+		})
+		outputs = append(outputs, output{
+			writer:    writer,
+			formatter: getFormatter(json.New(), nil, options, names),
+			level:     logLevel(options.Network.Level),
+			owned:     writer,
+		})
+	}
+
+	return outputs
+}
+
+// failedWriter is the writer of a file output which could not be created,
+// failing every write with the error, reported as the other write errors.
+type failedWriter struct {
+	err error
+}
+
+func (w failedWriter) Write([]byte) (int, error) {
+	return 0, w.err
+}
+
+// getFileWriter returns the writer of the file output: the file is rotated
+// unless its rotation is NONE, it is reopened after an external rotation then.
+func getFileWriter(options *Options) io.Writer {
+	s := []string{options.File.Path, "/", options.File.Name}
+	fileLocation := strings.Join(s, "")
+
+	if options.File.Rotation == rotate.RotationNone {
+		if options.File.ReopenOnSIGHUP {
+			reopen.NotifySIGHUP()
+		}
+		writer, err := reopen.New(reopen.Options{
+			Filename: fileLocation,
+			FileMode: options.File.Mode,
+			DirMode:  options.File.DirMode,
+		})
+		if err != nil {
+			return failedWriter{err: err}
+		}
+		return writer
+	}
+	return rotate.New(rotate.Options{
+		Filename:        fileLocation,
+		Rotation:        options.File.Rotation,
+		Pattern:         options.File.Pattern,
+		MaxSize:         options.File.MaxSize,
+		MaxAge:          options.File.MaxAge,
+		MaxBackups:      options.File.MaxBackups,
+		MaxTotalSize:    options.File.MaxTotalSize,
+		Compress:        options.File.Compress,
+		LocalTime:       options.File.LocalTime,
+		RotateOnStartup: options.File.RotateOnStartup,
+		FileMode:        options.File.Mode,
+		DirMode:         options.File.DirMode,
+	})
+}
+
+// Reopen reopens the files of the file outputs whose rotation is NONE, as on
+// SIGHUP, after they were rotated by an external tool such as logrotate.
+func Reopen() error {
+	return reopen.Reopen()
+}
+
+// getFormatter returns the formatter of an output writing to w, resolving the
+// AUTO and PRETTY formatters for w.
+func getFormatter(formatter logrus.Formatter, w io.Writer, options *Options, names fieldNames) logrus.Formatter {
+	if formatter == nil {
+		formatter = options.Formatter
+	}
+	switch f := formatter.(type) {
+	case *auto.Formatter:
+		formatter = f.For(w)
+	case *pretty.Formatter:
+		formatter = f.For(w)
+	}
+
+	formatter = withFieldNames(formatter, names)
+	formatter = withECSKeys(formatter, options.ErrorFieldName, names)
+	formatter = withGCPKeys(formatter, options, names)
+	formatter = withLogfmtKeys(formatter, options, names)
+	formatter = withBinaryKeys(formatter, names)
+	formatter = withPrettyKeys(formatter, options, names)
+	formatter = withTimeFormat(formatter, options.Time.Format, names.Time)
+	return withPriorityFields(formatter, options.PriorityFields)
+}
+
+// mostVerbose returns the most verbose level of outputs.
+func mostVerbose(outputs []output) logrus.Level {
+	level := logrus.PanicLevel
+	for _, o := range outputs {
+		if o.level > level {
+			level = o.level
+		}
+	}
+	return level
+}
+
+// levelFormatter skips the entries its output does not accept. It formats the
+// output written by the logger itself, whose level is the most verbose one of
+// every output.
+type levelFormatter struct {
+	logrus.Formatter
+	output output
+}
+
+func (f *levelFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	if !f.output.accepts(entry.Level) {
+		return nil, nil
+	}
+	return f.Formatter.Format(entry)
+}
+
+// outputHook writes the entries of its levels to an output.
+type outputHook struct {
+	mu        sync.Mutex
+	writer    io.Writer
+	entries   entry.Writer
+	formatter logrus.Formatter
+	levels    []logrus.Level
+	callerKey string
+}
+
+func newOutputHook(o output, callerKey string) *outputHook {
+	var levels []logrus.Level
+	for _, level := range logrus.AllLevels {
+		if o.accepts(level) {
+			levels = append(levels, level)
+		}
+	}
+
+	return &outputHook{writer: o.writer, entries: o.entries, formatter: o.formatter, levels: levels, callerKey: callerKey}
+}
+
+func (h *outputHook) Levels() []logrus.Level {
+	return h.levels
+}
+
+func (h *outputHook) Fire(e *logrus.Entry) error {
+	if h.entries != nil {
+		return h.entries.WriteEntry(toEntry(e, h.callerKey))
+	}
+
+	b, err := h.formatter.Format(e)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	_, err = h.writer.Write(b)
+	return err
+}
+
+// syncHook sends the entries buffered by the outputs on the fatal and panic
+// entries, before logrus exits or panics.
+type syncHook struct {
+	resources *closer.Group
+}
+
+func (h *syncHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.PanicLevel, logrus.FatalLevel}
+}
+
+func (h *syncHook) Fire(*logrus.Entry) error {
+	return h.resources.Flush()
+}
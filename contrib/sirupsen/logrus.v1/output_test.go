@@ -0,0 +1,88 @@
+package logrus
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	jsonformatter "github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/json"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/text"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/suite"
+)
+
+type OutputSuite struct {
+	suite.Suite
+}
+
+func TestOutputSuite(t *testing.T) {
+	suite.Run(t, new(OutputSuite))
+}
+
+func (s *OutputSuite) TestIndependentOutputs() {
+	dir := s.T().TempDir()
+
+	original := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	l := NewLogger(
+		WithFormatter(text.New(text.WithDisableTimestamp(true))),
+		WithConsoleLevel("INFO"),
+		WithFileEnabled(true),
+		WithFilePath(dir),
+		WithFileName("app.log"),
+		WithFileLevel("DEBUG"),
+		WithFileFormatter(jsonformatter.New(jsonformatter.WithDisableTimestamp(true))),
+	)
+	os.Stdout = original
+
+	l.Debug("debug message")
+	l.WithField("ID", "1").Info("info message")
+	w.Close()
+
+	console, _ := io.ReadAll(r)
+	s.Assert().Equal("level=info msg=\"info message\" ID=1\n", string(console))
+
+	file, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	s.Require().NoError(err)
+	s.Assert().Equal(`{"level":"debug","msg":"debug message"}`+"\n"+`{"ID":"1","level":"info","msg":"info message"}`+"\n", string(file))
+}
+
+func (s *OutputSuite) TestMoreVerboseConsole() {
+	dir := s.T().TempDir()
+
+	original := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	l := NewLogger(
+		WithFormatter(text.New(text.WithDisableTimestamp(true))),
+		WithConsoleLevel("TRACE"),
+		WithFileEnabled(true),
+		WithFilePath(dir),
+		WithFileName("app.log"),
+		WithFileLevel("WARN"),
+	)
+	os.Stdout = original
+
+	l.Trace("trace message")
+	l.Warn("warn message")
+	w.Close()
+
+	console, _ := io.ReadAll(r)
+	s.Assert().Equal("level=trace msg=\"trace message\"\nlevel=warning msg=\"warn message\"\n", string(console))
+
+	file, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	s.Require().NoError(err)
+	s.Assert().Equal("level=warning msg=\"warn message\"\n", string(file))
+}
+
+func (s *OutputSuite) Test_mostVerbose() {
+	s.Assert().Equal(logrus.PanicLevel, mostVerbose(nil))
+	s.Assert().Equal(logrus.DebugLevel, mostVerbose([]output{{level: logrus.InfoLevel}, {level: logrus.DebugLevel}}))
+}
+
+func (s *OutputSuite) Test_newOutputHook() {
+	h := newOutputHook(output{level: logrus.WarnLevel}, "")
+	s.Assert().Equal([]logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel, logrus.WarnLevel}, h.Levels())
+}
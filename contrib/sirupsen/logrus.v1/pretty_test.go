@@ -0,0 +1,46 @@
+package logrus
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/pretty"
+	"github.com/americanas-go/log/internal/logtest"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestPretty(t *testing.T) {
+	logtest.Pretty(t, newFileLogger)
+}
+
+type PrettySuite struct {
+	suite.Suite
+}
+
+func TestPrettySuite(t *testing.T) {
+	suite.Run(t, new(PrettySuite))
+}
+
+func (s *PrettySuite) SetupTest() {
+	for _, key := range []string{"NO_COLOR", "FORCE_COLOR", "CLICOLOR", "CLICOLOR_FORCE"} {
+		s.T().Setenv(key, "")
+	}
+}
+
+func (s *PrettySuite) TestLoggerForceColor() {
+	s.T().Setenv("FORCE_COLOR", "1")
+
+	dir := s.T().TempDir()
+	NewLogger(
+		WithConsoleEnabled(false),
+		WithFileEnabled(true),
+		WithFilePath(dir),
+		WithFileName("app.log"),
+		WithFileFormatter(pretty.New()),
+	).Warn("order created")
+
+	data, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	s.Require().NoError(err)
+	s.Assert().Contains(string(data), "\x1b[33mWARN \x1b[0m")
+}
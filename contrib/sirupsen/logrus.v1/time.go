@@ -0,0 +1,137 @@
+package logrus
+
+import (
+	"bytes"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Predefined time formats, any other value is used as a time.Format layout.
+const (
+	TimeFormatISO8601     = "ISO8601"
+	TimeFormatRFC3339     = "RFC3339"
+	TimeFormatRFC3339Nano = "RFC3339NANO"
+	TimeFormatEpoch       = "EPOCH"
+	TimeFormatEpochMillis = "EPOCH_MILLIS"
+	TimeFormatEpochNanos  = "EPOCH_NANOS"
+)
+
+const iso8601Layout = "2006-01-02T15:04:05.000Z0700"
+
+// layoutOf returns the time.Format layout of the non epoch formats.
+func layoutOf(format string) string {
+	switch format {
+	case TimeFormatISO8601:
+		return iso8601Layout
+	case TimeFormatRFC3339:
+		return time.RFC3339
+	case TimeFormatRFC3339Nano:
+		return time.RFC3339Nano
+	default:
+		return format
+	}
+}
+
+// epochOf returns the integer epoch of t in format, or false for the non epoch formats.
+func epochOf(format string, t time.Time) (int64, bool) {
+	switch format {
+	case TimeFormatEpoch:
+		return t.Unix(), true
+	case TimeFormatEpochMillis:
+		return t.UnixMilli(), true
+	case TimeFormatEpochNanos:
+		return t.UnixNano(), true
+	default:
+		return 0, false
+	}
+}
+
+// withTimeFormat sets the timestamp format of the logrus text and JSON
+// formatters. The default format keeps the one of the formatter, so that
+// formatters built with their own timestamp format are left unchanged.
+// Epoch formats are written by epochFormatter, since logrus formats the time
+// as a string.
+func withTimeFormat(formatter logrus.Formatter, format string, key string) logrus.Formatter {
+	if format == "" || format == defaultTimeFormat || key == "" {
+		return formatter
+	}
+
+	if _, epoch := epochOf(format, time.Time{}); epoch {
+		switch f := formatter.(type) {
+		case *logrus.TextFormatter:
+			f.DisableTimestamp = true
+			return &epochFormatter{Formatter: f, format: format, key: key}
+		case *logrus.JSONFormatter:
+			f.DisableTimestamp = true
+			return &epochFormatter{Formatter: f, format: format, key: key, json: true}
+		}
+		return formatter
+	}
+
+	switch f := formatter.(type) {
+	case *logrus.TextFormatter:
+		f.TimestampFormat = layoutOf(format)
+	case *logrus.JSONFormatter:
+		f.TimestampFormat = layoutOf(format)
+	}
+
+	return formatter
+}
+
+// epochFormatter writes the time as an integer epoch before the fields of a
+// text or JSON formatter whose timestamp is disabled.
+type epochFormatter struct {
+	logrus.Formatter
+	format string
+	key    string
+	json   bool
+}
+
+func (f *epochFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	b, err := f.Formatter.Format(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	epoch, _ := epochOf(f.format, entry.Time)
+
+	buf := &bytes.Buffer{}
+	if f.json && len(b) > 0 && b[0] == '{' {
+		buf.WriteString("{")
+		buf.WriteString(strconv.Quote(f.key))
+		buf.WriteString(":")
+		buf.WriteString(strconv.FormatInt(epoch, 10))
+		buf.WriteString(",")
+		buf.Write(b[1:])
+		return buf.Bytes(), nil
+	}
+
+	buf.WriteString(f.key)
+	buf.WriteString("=")
+	buf.WriteString(strconv.FormatInt(epoch, 10))
+	buf.WriteString(" ")
+	buf.Write(b)
+	return buf.Bytes(), nil
+}
+
+// timeHook sets the time of the entries from a clock and converts it to UTC.
+type timeHook struct {
+	clock func() time.Time
+	utc   bool
+}
+
+func (h *timeHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *timeHook) Fire(entry *logrus.Entry) error {
+	if h.clock != nil {
+		entry.Time = h.clock()
+	}
+	if h.utc {
+		entry.Time = entry.Time.UTC()
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package logrus
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	jsonformatter "github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/json"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/text"
+	"github.com/stretchr/testify/suite"
+)
+
+type TimeSuite struct {
+	suite.Suite
+}
+
+func TestTimeSuite(t *testing.T) {
+	suite.Run(t, new(TimeSuite))
+}
+
+func (s *TimeSuite) TestLoggerTime() {
+	at := time.Date(2021, 1, 2, 3, 4, 5, 123456789, time.FixedZone("BRT", -3*60*60))
+	clock := WithTimeClock(func() time.Time { return at })
+
+	tt := []struct {
+		name    string
+		options []Option
+		want    string
+	}{
+		{
+			name:    "text with default format",
+			options: []Option{WithFormatter(text.New()), clock},
+			want:    "time=\"2021/01/02 03:04:05.123\" level=info msg=Blah\n",
+		},
+		{
+			name:    "text with UTC",
+			options: []Option{WithFormatter(text.New()), clock, WithTimeUTC(true)},
+			want:    "time=\"2021/01/02 06:04:05.123\" level=info msg=Blah\n",
+		},
+		{
+			name:    "text with layout",
+			options: []Option{WithFormatter(text.New()), clock, WithTimeFormat("15:04:05")},
+			want:    "time=\"03:04:05\" level=info msg=Blah\n",
+		},
+		{
+			name:    "text with epoch",
+			options: []Option{WithFormatter(text.New()), clock, WithTimeFormat(TimeFormatEpoch)},
+			want:    "time=1609567445 level=info msg=Blah\n",
+		},
+		{
+			name:    "json with RFC3339NANO and UTC",
+			options: []Option{WithFormatter(jsonformatter.New()), clock, WithTimeUTC(true), WithTimeFormat(TimeFormatRFC3339Nano)},
+			want:    `{"level":"info","msg":"Blah","time":"2021-01-02T06:04:05.123456789Z"}` + "\n",
+		},
+		{
+			name:    "json with ISO8601",
+			options: []Option{WithFormatter(jsonformatter.New()), clock, WithTimeFormat(TimeFormatISO8601)},
+			want:    `{"level":"info","msg":"Blah","time":"2021-01-02T03:04:05.123-0300"}` + "\n",
+		},
+		{
+			name:    "json with epoch millis and custom time field",
+			options: []Option{WithFormatter(jsonformatter.New()), clock, WithTimeFormat(TimeFormatEpochMillis), WithFieldNames("@timestamp", "level", "msg", "", "")},
+			want:    `{"@timestamp":1609567445123,"level":"info","msg":"Blah"}` + "\n",
+		},
+		{
+			name:    "json with epoch nanos",
+			options: []Option{WithFormatter(jsonformatter.New()), clock, WithTimeFormat(TimeFormatEpochNanos)},
+			want:    `{"time":1609567445123456789,"level":"info","msg":"Blah"}` + "\n",
+		},
+	}
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			buf := &bytes.Buffer{}
+			l := NewLogger(t.options...)
+			l.(*logger).logger.SetOutput(buf)
+
+			l.Info("Blah")
+
+			s.Assert().Equal(t.want, buf.String())
+		})
+	}
+}
+
+func (s *TimeSuite) TestWithTimeFormatKeepsFormatterByDefault() {
+	formatter := text.New(text.WithTimestampFormat("15:04"))
+
+	got := withTimeFormat(formatter, defaultTimeFormat, defaultTimeFieldName)
+
+	s.Assert().Same(formatter, got)
+	s.Assert().Equal(text.New(text.WithTimestampFormat("15:04")), got)
+}
@@ -0,0 +1,175 @@
+// Package decode converts the binary logs written by the CBOR and MSGPACK
+// formatters back into JSON or logfmt lines, for humans.
+//
+// A stream is a sequence of maps, one per entry, as written by the formatters
+// of the contribs or by zerolog built with the binary_log tag. The format of
+// each entry is detected from its first byte unless it is given:
+//
+//	f, _ := os.Open("app.log")
+//	err := decode.JSON(os.Stdout, f, decode.FormatAuto)
+//
+// The command cmd/logdecode of the module does the same from the shell.
+package decode
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/americanas-go/log/internal/cbor"
+	"github.com/americanas-go/log/internal/logfmt"
+	"github.com/americanas-go/log/internal/msgpack"
+)
+
+// Formats of the streams.
+const (
+	FormatAuto    = ""
+	FormatCBOR    = "CBOR"
+	FormatMsgpack = "MSGPACK"
+)
+
+// Field is a key and value of an entry.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Decoder reads the entries of a stream, keeping the order of their fields.
+// Values are decoded as by encoding/json into an interface{}, but integers,
+// which are int64 or uint64, times, which are time.Time, and byte strings,
+// which are []byte.
+type Decoder struct {
+	format  string
+	r       *bufio.Reader
+	cbor    *cbor.Decoder
+	msgpack *msgpack.Decoder
+}
+
+// NewDecoder returns a Decoder reading the entries of format from r.
+func NewDecoder(r io.Reader, format string) *Decoder {
+	br := bufio.NewReader(r)
+	return &Decoder{
+		format:  format,
+		r:       br,
+		cbor:    cbor.NewDecoder(br),
+		msgpack: msgpack.NewDecoder(br),
+	}
+}
+
+// Decode reads the next entry. It returns io.EOF at the end of the stream.
+func (d *Decoder) Decode() ([]Field, error) {
+	format := d.format
+	if format == FormatAuto {
+		b, err := d.r.Peek(1)
+		if err != nil {
+			return nil, err
+		}
+		if format = detect(b[0]); format == "" {
+			return nil, fmt.Errorf("decode: 0x%x does not start a CBOR or MessagePack map", b[0])
+		}
+	}
+
+	var fields []Field
+	switch format {
+	case FormatCBOR:
+		pairs, err := d.cbor.DecodeMap()
+		if err != nil {
+			return nil, err
+		}
+		fields = make([]Field, 0, len(pairs))
+		for _, p := range pairs {
+			fields = append(fields, Field{Key: p.Key, Value: p.Value})
+		}
+	case FormatMsgpack:
+		pairs, err := d.msgpack.DecodeMap()
+		if err != nil {
+			return nil, err
+		}
+		fields = make([]Field, 0, len(pairs))
+		for _, p := range pairs {
+			fields = append(fields, Field{Key: p.Key, Value: p.Value})
+		}
+	default:
+		return nil, fmt.Errorf("decode: unknown format %s", format)
+	}
+	return fields, nil
+}
+
+// detect returns the format of the map starting with c, or an empty string.
+// The first bytes of the maps of both formats are disjoint.
+func detect(c byte) string {
+	switch {
+	case c >= 0xa0 && c <= 0xbb, c == 0xbf:
+		return FormatCBOR
+	case c >= 0x80 && c <= 0x8f, c == 0xde, c == 0xdf:
+		return FormatMsgpack
+	}
+	return ""
+}
+
+// JSON writes the entries of format read from r to w, as JSON lines.
+func JSON(w io.Writer, r io.Reader, format string) error {
+	return convert(w, r, format, appendJSON)
+}
+
+// Text writes the entries of format read from r to w, as logfmt lines.
+func Text(w io.Writer, r io.Reader, format string) error {
+	return convert(w, r, format, appendText)
+}
+
+func convert(w io.Writer, r io.Reader, format string, appendLine func([]byte, []Field) []byte) error {
+	d := NewDecoder(r, format)
+	var line []byte
+	for {
+		fields, err := d.Decode()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		line = appendLine(line[:0], fields)
+		if _, err := w.Write(line); err != nil {
+			return err
+		}
+	}
+}
+
+func appendJSON(dst []byte, fields []Field) []byte {
+	dst = append(dst, '{')
+	for i, f := range fields {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = appendJSONValue(dst, f.Key)
+		dst = append(dst, ':')
+		dst = appendJSONValue(dst, f.Value)
+	}
+	return append(dst, '}', '\n')
+}
+
+// appendJSONValue appends the JSON of v, or of its text when v has none, as
+// NaN.
+func appendJSONValue(dst []byte, v interface{}) []byte {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		buf.Reset()
+		enc.Encode(fmt.Sprint(v))
+	}
+	return append(dst, bytes.TrimSuffix(buf.Bytes(), []byte{'\n'})...)
+}
+
+func appendText(dst []byte, fields []Field) []byte {
+	for i, f := range fields {
+		if i > 0 {
+			dst = append(dst, ' ')
+		}
+		dst = logfmt.AppendPair(dst, f.Key, f.Value, logfmt.TimeFormatRFC3339Nano)
+	}
+	return append(dst, '\n')
+}
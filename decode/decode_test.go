@@ -0,0 +1,111 @@
+package decode
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/binlog"
+	"github.com/americanas-go/log/internal/cbor"
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/stretchr/testify/suite"
+)
+
+type DecodeSuite struct {
+	suite.Suite
+}
+
+func TestDecodeSuite(t *testing.T) {
+	suite.Run(t, new(DecodeSuite))
+}
+
+var at = time.Date(2021, 1, 2, 3, 4, 5, 123000000, time.UTC)
+
+// stream returns two entries, the first in CBOR and the second in
+// MessagePack.
+func stream() []byte {
+	options := binlog.Options{
+		TimeKey:    binlog.DefaultTimeKey,
+		LevelKey:   binlog.DefaultLevelKey,
+		MessageKey: binlog.DefaultMessageKey,
+	}
+	b := binlog.Append(nil, entry.Entry{
+		Time:    at,
+		Level:   log.InfoLevel,
+		Message: "order created",
+		Fields:  log.Fields{"order": 1, "user": map[string]interface{}{"name": "John Doe"}},
+	}, options)
+
+	options.Format = binlog.FormatMsgpack
+	return binlog.Append(b, entry.Entry{
+		Time:    at,
+		Level:   log.ErrorLevel,
+		Message: "failed",
+		Fields:  log.Fields{"error": errors.New("bad")},
+	}, options)
+}
+
+func (s *DecodeSuite) TestJSON() {
+	var buf bytes.Buffer
+	s.Require().NoError(JSON(&buf, bytes.NewReader(stream()), FormatAuto))
+
+	t := at.Local().Format(time.RFC3339Nano)
+	s.Assert().Equal(
+		`{"time":"`+t+`","level":"info","message":"order created","order":1,"user":{"name":"John Doe"}}`+"\n"+
+			`{"time":"`+t+`","level":"error","message":"failed","error":"bad"}`+"\n",
+		buf.String())
+}
+
+func (s *DecodeSuite) TestText() {
+	var buf bytes.Buffer
+	s.Require().NoError(Text(&buf, bytes.NewReader(stream()), FormatAuto))
+
+	t := at.Local().Format(time.RFC3339Nano)
+	s.Assert().Equal(
+		`time=`+t+` level=info message="order created" order=1 user="{\"name\":\"John Doe\"}"`+"\n"+
+			`time=`+t+` level=error message=failed error=bad`+"\n",
+		buf.String())
+}
+
+func (s *DecodeSuite) TestZerologBinary() {
+	// an event as written by zerolog built with the binary_log tag
+	b := []byte{0xbf}
+	b = cbor.AppendString(b, "level")
+	b = cbor.AppendString(b, "info")
+	b = cbor.AppendString(b, "raw")
+	b = cbor.AppendTag(b, cbor.TagEmbeddedJSON)
+	b = cbor.AppendBytes(b, []byte(`{"a":1}`))
+	b = cbor.AppendString(b, "ratio")
+	b = cbor.AppendFloat(b, math.NaN())
+	b = append(b, 0xff)
+
+	var buf bytes.Buffer
+	s.Require().NoError(JSON(&buf, bytes.NewReader(b), FormatCBOR))
+	s.Assert().Equal(`{"level":"info","raw":{"a":1},"ratio":"NaN"}`+"\n", buf.String())
+}
+
+func (s *DecodeSuite) TestDecoder() {
+	d := NewDecoder(bytes.NewReader(stream()), FormatAuto)
+
+	fields, err := d.Decode()
+	s.Require().NoError(err)
+	s.Assert().Equal(Field{Key: "message", Value: "order created"}, fields[2])
+	fields, err = d.Decode()
+	s.Require().NoError(err)
+	s.Assert().Equal(Field{Key: "message", Value: "failed"}, fields[2])
+	_, err = d.Decode()
+	s.Assert().Equal(io.EOF, err)
+}
+
+func (s *DecodeSuite) TestErrors() {
+	var buf bytes.Buffer
+	s.Assert().Error(JSON(&buf, strings.NewReader(`{"a":1}`), FormatAuto))
+	s.Assert().Error(JSON(&buf, bytes.NewReader(stream()), FormatMsgpack))
+	s.Assert().Error(JSON(&buf, bytes.NewReader(stream()), "XML"))
+	s.Assert().Equal(io.ErrUnexpectedEOF, JSON(&buf, bytes.NewReader(stream()[:10]), FormatAuto))
+}
@@ -0,0 +1,57 @@
+package log
+
+import (
+	"sort"
+	"time"
+)
+
+// EMFKey is the key of the metadata of the CloudWatch Embedded Metric Format.
+const EMFKey = "_aws"
+
+// Metric is a metric of the CloudWatch Embedded Metric Format.
+type Metric struct {
+	Name  string      // name of the metric, also the key of its value
+	Unit  string      // unit of the metric, such as Milliseconds or Count, none when empty
+	Value interface{} // number or slice of numbers
+}
+
+// EMF returns the fields of an entry publishing metrics with the CloudWatch
+// Embedded Metric Format, https://docs.aws.amazon.com/AmazonCloudWatch/latest/monitoring/CloudWatch_Embedded_Metric_Format_Specification.html:
+// the _aws metadata, defining the metrics of namespace and their dimensions,
+// the values of the dimensions and the values of the metrics. CloudWatch Logs
+// extracts the metrics of the JSON lines, so the entry is written with a JSON
+// formatter, as the stdout of a Lambda function.
+//
+//	logger.WithFields(log.EMF("orders", log.Fields{"service": "checkout"},
+//		log.Metric{Name: "latency", Unit: "Milliseconds", Value: 12.5},
+//	)).Info("order created")
+func EMF(namespace string, dimensions Fields, metrics ...Metric) Fields {
+	fields := make(Fields, 1+len(dimensions)+len(metrics))
+
+	keys := make([]string, 0, len(dimensions))
+	for k, v := range dimensions {
+		keys = append(keys, k)
+		fields[k] = v
+	}
+	sort.Strings(keys)
+
+	definitions := make([]map[string]interface{}, 0, len(metrics))
+	for _, m := range metrics {
+		definition := map[string]interface{}{"Name": m.Name}
+		if m.Unit != "" {
+			definition["Unit"] = m.Unit
+		}
+		definitions = append(definitions, definition)
+		fields[m.Name] = m.Value
+	}
+
+	fields[EMFKey] = map[string]interface{}{
+		"Timestamp": time.Now().UnixMilli(),
+		"CloudWatchMetrics": []map[string]interface{}{{
+			"Namespace":  namespace,
+			"Dimensions": [][]string{keys},
+			"Metrics":    definitions,
+		}},
+	}
+	return fields
+}
@@ -0,0 +1,62 @@
+package log
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type EMFSuite struct {
+	suite.Suite
+}
+
+func TestEMFSuite(t *testing.T) {
+	suite.Run(t, new(EMFSuite))
+}
+
+func (s *EMFSuite) TestEMF() {
+	before := time.Now().UnixMilli()
+	fields := EMF("orders", Fields{"service": "checkout", "env": "prod"},
+		Metric{Name: "latency", Unit: "Milliseconds", Value: 12.5},
+		Metric{Name: "items", Value: []int{1, 2}},
+	)
+
+	b, err := json.Marshal(fields)
+	s.Require().NoError(err)
+
+	var got map[string]interface{}
+	s.Require().NoError(json.Unmarshal(b, &got))
+
+	aws := got[EMFKey].(map[string]interface{})
+	timestamp := int64(aws["Timestamp"].(float64))
+	s.Assert().True(timestamp >= before && timestamp <= time.Now().UnixMilli())
+	delete(aws, "Timestamp")
+
+	s.Assert().JSONEq(`{
+		"_aws": {"CloudWatchMetrics": [{
+			"Namespace": "orders",
+			"Dimensions": [["env", "service"]],
+			"Metrics": [{"Name": "latency", "Unit": "Milliseconds"}, {"Name": "items"}]
+		}]},
+		"service": "checkout",
+		"env": "prod",
+		"latency": 12.5,
+		"items": [1, 2]
+	}`, mustJSON(s, got))
+}
+
+func (s *EMFSuite) TestEMFWithoutDimensions() {
+	fields := EMF("orders", nil, Metric{Name: "created", Unit: "Count", Value: 1})
+
+	definition := fields[EMFKey].(map[string]interface{})["CloudWatchMetrics"].([]map[string]interface{})[0]
+	s.Assert().Equal([][]string{{}}, definition["Dimensions"])
+	s.Assert().Equal(1, fields["created"])
+}
+
+func mustJSON(s *EMFSuite, v interface{}) string {
+	b, err := json.Marshal(v)
+	s.Require().NoError(err)
+	return string(b)
+}
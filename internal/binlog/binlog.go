@@ -0,0 +1,147 @@
+// Package binlog encodes entries as binary maps, in CBOR or in MessagePack,
+// the same whichever backend writes them.
+//
+// Each entry is a single map, with no delimiter between the entries: the core
+// keys come first, in the order time, level, message and caller, followed by
+// the fields sorted by key. Times are written as CBOR epoch times or
+// MessagePack timestamps and levels in lower case, as by zerolog. A field whose
+// key is one of the core keys is written with a "fields." prefix. Priority
+// fields can be written before the other fields.
+//
+// The streams are read back by the decode package of the module.
+package binlog
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/americanas-go/log/internal/cbor"
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/americanas-go/log/internal/logfmt"
+	"github.com/americanas-go/log/internal/msgpack"
+	"github.com/americanas-go/log/internal/order"
+)
+
+// Formats.
+const (
+	FormatCBOR    = "CBOR"
+	FormatMsgpack = "MSGPACK"
+)
+
+// Default core keys, those of zerolog.
+const (
+	DefaultTimeKey    = "time"
+	DefaultLevelKey   = "level"
+	DefaultMessageKey = "message"
+	DefaultCallerKey  = "caller"
+)
+
+// Options configures the encoding. An empty time, level or caller key omits
+// the key, and an empty message key takes the default.
+type Options struct {
+	Format     string // CBOR or MSGPACK, CBOR when empty
+	TimeKey    string
+	LevelKey   string
+	MessageKey string
+	CallerKey  string
+
+	PriorityKeys []string // fields written first, in this order, the others being sorted by key
+}
+
+// encoding holds the functions appending the values of a format.
+type encoding struct {
+	mapHeader func([]byte, int) []byte
+	str       func([]byte, string) []byte
+	time      func([]byte, time.Time) []byte
+	value     func([]byte, interface{}) []byte
+}
+
+var (
+	cborEncoding = encoding{
+		mapHeader: cbor.AppendMapHeader,
+		str:       cbor.AppendString,
+		time:      cbor.AppendTime,
+		value:     cbor.AppendValue,
+	}
+	msgpackEncoding = encoding{
+		mapHeader: msgpack.AppendMapHeader,
+		str:       msgpack.AppendString,
+		time:      msgpack.AppendTime,
+		value:     msgpack.AppendValue,
+	}
+)
+
+// Writer writes entries as binary maps to an io.Writer.
+type Writer struct {
+	mu      sync.Mutex
+	w       io.Writer
+	options Options
+}
+
+// NewWriter returns a Writer writing to w.
+func NewWriter(w io.Writer, options Options) *Writer {
+	return &Writer{w: w, options: options}
+}
+
+// WriteEntry implements entry.Writer.
+func (w *Writer) WriteEntry(e entry.Entry) error {
+	data := Append(nil, e, w.options)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	_, err := w.w.Write(data)
+	return err
+}
+
+// Append appends the binary map of e to dst.
+func Append(dst []byte, e entry.Entry, options Options) []byte {
+	if options.MessageKey == "" {
+		options.MessageKey = DefaultMessageKey
+	}
+	enc := cborEncoding
+	if options.Format == FormatMsgpack {
+		enc = msgpackEncoding
+	}
+
+	core := make(map[string]bool, 4)
+	core[options.MessageKey] = true
+	if options.TimeKey != "" {
+		core[options.TimeKey] = true
+	}
+	if options.LevelKey != "" {
+		core[options.LevelKey] = true
+	}
+	withCaller := options.CallerKey != "" && e.Caller != ""
+	if withCaller {
+		core[options.CallerKey] = true
+	}
+
+	keys := order.Keys(e.Fields, options.PriorityKeys)
+
+	dst = enc.mapHeader(dst, len(core)+len(keys))
+	if options.TimeKey != "" {
+		t := e.Time
+		if t.IsZero() {
+			t = time.Now()
+		}
+		dst = enc.time(enc.str(dst, options.TimeKey), t)
+	}
+	if options.LevelKey != "" {
+		dst = enc.str(enc.str(dst, options.LevelKey), logfmt.Level(e.Level))
+	}
+	dst = enc.str(enc.str(dst, options.MessageKey), e.Message)
+	if withCaller {
+		dst = enc.str(enc.str(dst, options.CallerKey), e.Caller)
+	}
+
+	for _, k := range keys {
+		v := e.Fields[k]
+		if core[k] {
+			k = "fields." + k
+		}
+		dst = enc.value(enc.str(dst, k), v)
+	}
+	return dst
+}
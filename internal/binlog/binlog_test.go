@@ -0,0 +1,106 @@
+package binlog
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/cbor"
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/americanas-go/log/internal/msgpack"
+	"github.com/stretchr/testify/suite"
+)
+
+type BinlogSuite struct {
+	suite.Suite
+}
+
+func TestBinlogSuite(t *testing.T) {
+	suite.Run(t, new(BinlogSuite))
+}
+
+var at = time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+var defaults = Options{
+	TimeKey:    DefaultTimeKey,
+	LevelKey:   DefaultLevelKey,
+	MessageKey: DefaultMessageKey,
+	CallerKey:  DefaultCallerKey,
+}
+
+var e = entry.Entry{
+	Time:    at,
+	Level:   log.WarnLevel,
+	Message: "order created",
+	Caller:  "app/main.go:10",
+	Fields:  log.Fields{"order": 1, "error": errors.New("bad"), "level": "x"},
+}
+
+func (s *BinlogSuite) TestAppendCBOR() {
+	d := cbor.NewDecoder(bytes.NewReader(Append(nil, e, defaults)))
+	got, err := d.DecodeMap()
+	s.Require().NoError(err)
+	s.Assert().Equal([]cbor.Pair{
+		{Key: "time", Value: at.Local()},
+		{Key: "level", Value: "warn"},
+		{Key: "message", Value: "order created"},
+		{Key: "caller", Value: "app/main.go:10"},
+		{Key: "error", Value: "bad"},
+		{Key: "fields.level", Value: "x"},
+		{Key: "order", Value: int64(1)},
+	}, got)
+}
+
+func (s *BinlogSuite) TestAppendMsgpack() {
+	options := defaults
+	options.Format = FormatMsgpack
+	options.TimeKey = ""
+	options.CallerKey = ""
+
+	d := msgpack.NewDecoder(bytes.NewReader(Append(nil, e, options)))
+	got, err := d.DecodeMap()
+	s.Require().NoError(err)
+	s.Assert().Equal([]msgpack.Pair{
+		{Key: "level", Value: "warn"},
+		{Key: "message", Value: "order created"},
+		{Key: "error", Value: "bad"},
+		{Key: "fields.level", Value: "x"},
+		{Key: "order", Value: int64(1)},
+	}, got)
+}
+
+func (s *BinlogSuite) TestPriorityKeys() {
+	options := defaults
+	options.Format = FormatMsgpack
+	options.PriorityKeys = []string{"order", "missing", "error"}
+
+	d := msgpack.NewDecoder(bytes.NewReader(Append(nil, e, options)))
+	got, err := d.DecodeMap()
+	s.Require().NoError(err)
+	s.Assert().Equal([]msgpack.Pair{
+		{Key: "time", Value: at.Local()},
+		{Key: "level", Value: "warn"},
+		{Key: "message", Value: "order created"},
+		{Key: "caller", Value: "app/main.go:10"},
+		{Key: "order", Value: int64(1)},
+		{Key: "error", Value: "bad"},
+		{Key: "fields.level", Value: "x"},
+	}, got)
+}
+
+func (s *BinlogSuite) TestWriter() {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, Options{})
+
+	s.Require().NoError(w.WriteEntry(entry.Entry{Message: "a"}))
+	s.Require().NoError(w.WriteEntry(entry.Entry{Message: "b"}))
+
+	d := cbor.NewDecoder(&buf)
+	for _, msg := range []string{"a", "b"} {
+		got, err := d.Decode()
+		s.Require().NoError(err)
+		s.Assert().Equal(map[string]interface{}{"message": msg}, got)
+	}
+}
@@ -0,0 +1,226 @@
+// Package cbor encodes and decodes the CBOR values written by the binary
+// formatters, as described by RFC 8949.
+//
+// It only covers what the formatters need: the Append functions add a value
+// to a buffer and the Decoder reads the values back as plain Go values, for
+// the decoding of the logs and for tests. The Decoder also reads the events of
+// zerolog built with the binary_log tag, whose maps have an indefinite length
+// and whose raw JSON values are tagged byte strings.
+package cbor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+// Major types, in the high 3 bits of the initial byte.
+const (
+	majorUint   = 0 << 5
+	majorNegInt = 1 << 5
+	majorBytes  = 2 << 5
+	majorString = 3 << 5
+	majorArray  = 4 << 5
+	majorMap    = 5 << 5
+	majorTag    = 6 << 5
+	majorSimple = 7 << 5
+)
+
+// Tags read and written.
+const (
+	TagTimeString   = 0   // RFC 3339 time string
+	TagTimeEpoch    = 1   // seconds since the epoch, integer or float
+	TagEmbeddedJSON = 262 // byte string holding JSON, written by zerolog
+)
+
+// appendHead appends the initial byte of major and its argument v, in its
+// shortest form.
+func appendHead(b []byte, major byte, v uint64) []byte {
+	switch {
+	case v < 24:
+		return append(b, major|byte(v))
+	case v <= math.MaxUint8:
+		return append(b, major|24, byte(v))
+	case v <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, major|25), uint16(v))
+	case v <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, major|26), uint32(v))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, major|27), v)
+	}
+}
+
+// AppendNil appends null to b.
+func AppendNil(b []byte) []byte {
+	return append(b, majorSimple|22)
+}
+
+// AppendBool appends v to b.
+func AppendBool(b []byte, v bool) []byte {
+	if v {
+		return append(b, majorSimple|21)
+	}
+	return append(b, majorSimple|20)
+}
+
+// AppendInt appends v to b, in its shortest form.
+func AppendInt(b []byte, v int64) []byte {
+	if v >= 0 {
+		return appendHead(b, majorUint, uint64(v))
+	}
+	return appendHead(b, majorNegInt, uint64(-1-v))
+}
+
+// AppendUint appends v to b, in its shortest form.
+func AppendUint(b []byte, v uint64) []byte {
+	return appendHead(b, majorUint, v)
+}
+
+// AppendFloat appends v to b as a float 64.
+func AppendFloat(b []byte, v float64) []byte {
+	return binary.BigEndian.AppendUint64(append(b, majorSimple|27), math.Float64bits(v))
+}
+
+// AppendString appends v to b as a text string.
+func AppendString(b []byte, v string) []byte {
+	return append(appendHead(b, majorString, uint64(len(v))), v...)
+}
+
+// AppendBytes appends v to b as a byte string.
+func AppendBytes(b []byte, v []byte) []byte {
+	return append(appendHead(b, majorBytes, uint64(len(v))), v...)
+}
+
+// AppendArrayHeader appends the header of an array of n values to b.
+func AppendArrayHeader(b []byte, n int) []byte {
+	return appendHead(b, majorArray, uint64(n))
+}
+
+// AppendMapHeader appends the header of a map of n pairs to b.
+func AppendMapHeader(b []byte, n int) []byte {
+	return appendHead(b, majorMap, uint64(n))
+}
+
+// AppendTag appends tag to b, which applies to the next value.
+func AppendTag(b []byte, tag uint64) []byte {
+	return appendHead(b, majorTag, tag)
+}
+
+// AppendTime appends t to b as an epoch time, an integer when it has no
+// fraction of second and a float otherwise.
+func AppendTime(b []byte, t time.Time) []byte {
+	b = AppendTag(b, TagTimeEpoch)
+	if t.Nanosecond() == 0 {
+		return AppendInt(b, t.Unix())
+	}
+	return AppendFloat(b, float64(t.Unix())+float64(t.Nanosecond())/float64(time.Second))
+}
+
+// AppendValue appends v to b. Errors and fmt.Stringers are written as
+// strings, json.Numbers as numbers and the values of other types as they
+// would be encoded to JSON.
+func AppendValue(b []byte, v interface{}) []byte {
+	switch v := v.(type) {
+	case nil:
+		return AppendNil(b)
+	case bool:
+		return AppendBool(b, v)
+	case int:
+		return AppendInt(b, int64(v))
+	case int8:
+		return AppendInt(b, int64(v))
+	case int16:
+		return AppendInt(b, int64(v))
+	case int32:
+		return AppendInt(b, int64(v))
+	case int64:
+		return AppendInt(b, v)
+	case uint:
+		return AppendUint(b, uint64(v))
+	case uint8:
+		return AppendUint(b, uint64(v))
+	case uint16:
+		return AppendUint(b, uint64(v))
+	case uint32:
+		return AppendUint(b, uint64(v))
+	case uint64:
+		return AppendUint(b, v)
+	case float32:
+		return AppendFloat(b, float64(v))
+	case float64:
+		return AppendFloat(b, v)
+	case string:
+		return AppendString(b, v)
+	case []byte:
+		return AppendBytes(b, v)
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return AppendInt(b, i)
+		}
+		if f, err := v.Float64(); err == nil {
+			return AppendFloat(b, f)
+		}
+		return AppendString(b, v.String())
+	case time.Time:
+		return AppendTime(b, v)
+	case time.Duration:
+		return AppendInt(b, int64(v))
+	case error:
+		return AppendString(b, v.Error())
+	case fmt.Stringer:
+		return AppendString(b, v.String())
+	case map[string]interface{}:
+		b = AppendMapHeader(b, len(v))
+		for k, e := range v {
+			b = AppendString(b, k)
+			b = AppendValue(b, e)
+		}
+		return b
+	case []interface{}:
+		b = AppendArrayHeader(b, len(v))
+		for _, e := range v {
+			b = AppendValue(b, e)
+		}
+		return b
+	}
+
+	return appendReflected(b, reflect.ValueOf(v))
+}
+
+func appendReflected(b []byte, v reflect.Value) []byte {
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() == reflect.String {
+			b = AppendMapHeader(b, v.Len())
+			iter := v.MapRange()
+			for iter.Next() {
+				b = AppendString(b, iter.Key().String())
+				b = AppendValue(b, iter.Value().Interface())
+			}
+			return b
+		}
+	case reflect.Slice, reflect.Array:
+		b = AppendArrayHeader(b, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			b = AppendValue(b, v.Index(i).Interface())
+		}
+		return b
+	}
+
+	// other values are written as their JSON representation
+	data, err := json.Marshal(v.Interface())
+	if err != nil {
+		return AppendString(b, fmt.Sprint(v.Interface()))
+	}
+	var decoded interface{}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	if err := d.Decode(&decoded); err != nil {
+		return AppendString(b, string(data))
+	}
+	return AppendValue(b, decoded)
+}
@@ -0,0 +1,166 @@
+package cbor
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CBORSuite struct {
+	suite.Suite
+}
+
+func TestCBORSuite(t *testing.T) {
+	suite.Run(t, new(CBORSuite))
+}
+
+func (s *CBORSuite) TestAppendValue() {
+	at := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tt := []struct {
+		name  string
+		value interface{}
+		want  interface{}
+		bytes []byte
+	}{
+		{name: "nil", value: nil, want: nil, bytes: []byte{0xf6}},
+		{name: "true", value: true, want: true, bytes: []byte{0xf5}},
+		{name: "small int", value: 7, want: int64(7), bytes: []byte{0x07}},
+		{name: "negative int", value: -3, want: int64(-3), bytes: []byte{0x22}},
+		{name: "uint 8", value: uint8(200), want: int64(200), bytes: []byte{0x18, 0xc8}},
+		{name: "int 16", value: int16(-300), want: int64(-300), bytes: []byte{0x39, 0x01, 0x2b}},
+		{name: "int 64", value: int64(math.MinInt64), want: int64(math.MinInt64)},
+		{name: "uint 64", value: uint64(math.MaxUint64), want: uint64(math.MaxUint64)},
+		{name: "float", value: 1.5, want: 1.5},
+		{name: "string", value: "abc", want: "abc", bytes: []byte{0x63, 'a', 'b', 'c'}},
+		{name: "long string", value: strings.Repeat("a", 300), want: strings.Repeat("a", 300)},
+		{name: "bytes", value: []byte{1, 2}, want: []byte{1, 2}, bytes: []byte{0x42, 0x01, 0x02}},
+		{name: "json number", value: json.Number("12"), want: int64(12)},
+		{name: "json float", value: json.Number("1.25"), want: 1.25},
+		{name: "error", value: errors.New("bad"), want: "bad"},
+		{name: "duration", value: time.Second, want: int64(time.Second)},
+		{name: "time", value: at, want: at.Local(), bytes: []byte{0xc1, 0x1a, 0x5f, 0xef, 0xe2, 0xa5}},
+		{name: "map", value: map[string]interface{}{"a": 1}, want: map[string]interface{}{"a": int64(1)}},
+		{name: "array", value: []interface{}{"a", 1}, want: []interface{}{"a", int64(1)}},
+		{name: "typed slice", value: []string{"a", "b"}, want: []interface{}{"a", "b"}},
+		{name: "typed map", value: map[string]int{"a": 1}, want: map[string]interface{}{"a": int64(1)}},
+		{name: "struct", value: struct{ A int }{A: 1}, want: map[string]interface{}{"A": int64(1)}},
+	}
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			b := AppendValue(nil, t.value)
+			if t.bytes != nil {
+				s.Assert().Equal(t.bytes, b)
+			}
+
+			got, err := Unmarshal(b)
+			s.Require().NoError(err)
+			s.Assert().Equal(t.want, got)
+		})
+	}
+}
+
+func (s *CBORSuite) TestAppendTimeFraction() {
+	at := time.Date(2021, 1, 2, 3, 4, 5, 250000000, time.UTC)
+
+	got, err := Unmarshal(AppendTime(nil, at))
+	s.Require().NoError(err)
+	s.Assert().Equal(at.UnixMilli(), got.(time.Time).UnixMilli())
+}
+
+func (s *CBORSuite) TestDecodeIndefinite() {
+	// an event as written by zerolog built with the binary_log tag
+	b := []byte{0xbf}
+	b = AppendString(b, "level")
+	b = AppendString(b, "info")
+	b = AppendString(b, "time")
+	b = AppendTag(b, TagTimeEpoch)
+	b = AppendInt(b, 1609556645)
+	b = AppendString(b, "raw")
+	b = AppendTag(b, TagEmbeddedJSON)
+	b = AppendBytes(b, []byte(`{"a":1}`))
+	b = AppendString(b, "list")
+	b = append(b, 0x9f, 0x01, 0x02, 0xff)
+	b = AppendString(b, "chunks")
+	b = append(b, 0x7f, 0x61, 'a', 0x62, 'b', 'c', 0xff)
+	b = AppendString(b, "half")
+	b = append(b, 0xf9, 0x3e, 0x00)
+	b = append(b, 0xff)
+
+	got, err := Unmarshal(b)
+	s.Require().NoError(err)
+	s.Assert().Equal(map[string]interface{}{
+		"level":  "info",
+		"time":   time.Unix(1609556645, 0),
+		"raw":    json.RawMessage(`{"a":1}`),
+		"list":   []interface{}{int64(1), int64(2)},
+		"chunks": "abc",
+		"half":   1.5,
+	}, got)
+}
+
+func (s *CBORSuite) TestDecodeTagged() {
+	got, err := Unmarshal(AppendInt(AppendTag(nil, 32), 1))
+	s.Require().NoError(err)
+	s.Assert().Equal(Tagged{Tag: 32, Value: int64(1)}, got)
+}
+
+func (s *CBORSuite) TestDecoder() {
+	b := AppendString(nil, "a")
+	b = AppendInt(b, 1)
+
+	d := NewDecoder(strings.NewReader(string(b)))
+	v, err := d.Decode()
+	s.Require().NoError(err)
+	s.Assert().Equal("a", v)
+	v, err = d.Decode()
+	s.Require().NoError(err)
+	s.Assert().Equal(int64(1), v)
+	_, err = d.Decode()
+	s.Assert().Equal(io.EOF, err)
+}
+
+func (s *CBORSuite) TestDecoderErrors() {
+	_, err := Unmarshal([]byte{0x82, 0x01})
+	s.Assert().Equal(io.ErrUnexpectedEOF, err)
+
+	_, err = Unmarshal([]byte{0xff})
+	s.Assert().Error(err)
+
+	_, err = Unmarshal([]byte{0x1c})
+	s.Assert().Error(err)
+
+	_, err = Unmarshal([]byte{0x01, 0x02})
+	s.Assert().Error(err)
+}
+
+func (s *CBORSuite) TestDecodeMap() {
+	b := []byte{0xbf}
+	b = AppendString(b, "b")
+	b = AppendInt(b, 1)
+	b = AppendString(b, "a")
+	b = AppendString(b, "x")
+	b = append(b, 0xff)
+	b = AppendMapHeader(b, 1)
+	b = AppendString(b, "c")
+	b = AppendNil(b)
+
+	d := NewDecoder(strings.NewReader(string(b)))
+	got, err := d.DecodeMap()
+	s.Require().NoError(err)
+	s.Assert().Equal([]Pair{{Key: "b", Value: int64(1)}, {Key: "a", Value: "x"}}, got)
+	got, err = d.DecodeMap()
+	s.Require().NoError(err)
+	s.Assert().Equal([]Pair{{Key: "c", Value: nil}}, got)
+	_, err = d.DecodeMap()
+	s.Assert().Equal(io.EOF, err)
+
+	_, err = NewDecoder(strings.NewReader(string(AppendInt(nil, 1)))).DecodeMap()
+	s.Assert().Error(err)
+}
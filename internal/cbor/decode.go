@@ -0,0 +1,371 @@
+package cbor
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// Pair is a key and value of a map decoded by DecodeMap.
+type Pair struct {
+	Key   string
+	Value interface{}
+}
+
+// Tagged is a decoded tagged value, other than a time or embedded JSON.
+type Tagged struct {
+	Tag   uint64
+	Value interface{}
+}
+
+// errBreak is returned by next when it reads the break stop code of an
+// indefinite length item.
+var errBreak = errors.New("cbor: unexpected break")
+
+// Decoder reads values from a stream. Integers are decoded as int64, or uint64
+// when they do not fit, floats as float64, byte strings as []byte, arrays as
+// []interface{}, maps as map[string]interface{}, times as time.Time, embedded
+// JSON as json.RawMessage and the other tagged values as Tagged.
+type Decoder struct {
+	r *bufio.Reader
+}
+
+// NewDecoder returns a Decoder reading from r.
+func NewDecoder(r io.Reader) *Decoder {
+	if br, ok := r.(*bufio.Reader); ok {
+		return &Decoder{r: br}
+	}
+	return &Decoder{r: bufio.NewReader(r)}
+}
+
+// Unmarshal decodes the single value of data.
+func Unmarshal(data []byte) (interface{}, error) {
+	d := NewDecoder(bytes.NewReader(data))
+	v, err := d.Decode()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := d.r.ReadByte(); err != io.EOF {
+		return nil, errors.New("cbor: trailing data")
+	}
+	return v, nil
+}
+
+// Decode reads the next value. It returns io.EOF when the stream ends between
+// two values and io.ErrUnexpectedEOF when it ends within one.
+func (d *Decoder) Decode() (interface{}, error) {
+	c, err := d.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := d.decode(c)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return v, err
+}
+
+// DecodeMap reads the next value, which must be a map, and returns its pairs
+// in the order of the stream.
+func (d *Decoder) DecodeMap() ([]Pair, error) {
+	c, err := d.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	pairs, err := d.decodePairs(c)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return pairs, err
+}
+
+func (d *Decoder) decodePairs(c byte) ([]Pair, error) {
+	if c&0xe0 != majorMap {
+		return nil, fmt.Errorf("cbor: 0x%x is not a map", c)
+	}
+	n := -1
+	if info := c & 0x1f; info != 31 {
+		u, err := d.argument(info)
+		if err != nil {
+			return nil, err
+		}
+		n = int(u)
+	}
+
+	pairs := make([]Pair, 0, min(max(n, 0), 1024))
+	for i := 0; n < 0 || i < n; i++ {
+		k, err := d.next()
+		if err == errBreak && n < 0 {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.next()
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, Pair{Key: key(k), Value: v})
+	}
+	return pairs, nil
+}
+
+func (d *Decoder) decode(c byte) (interface{}, error) {
+	major, info := c&0xe0, c&0x1f
+
+	if info == 31 {
+		switch major {
+		case majorBytes, majorString:
+			return d.decodeChunks(major)
+		case majorArray:
+			return d.decodeArray(-1)
+		case majorMap:
+			return d.decodeMap(-1)
+		case majorSimple:
+			return nil, errBreak
+		}
+		return nil, fmt.Errorf("cbor: invalid indefinite length 0x%x", c)
+	}
+
+	if major == majorSimple {
+		return d.decodeSimple(info)
+	}
+
+	n, err := d.argument(info)
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case majorUint:
+		if n > math.MaxInt64 {
+			return n, nil
+		}
+		return int64(n), nil
+	case majorNegInt:
+		if n > math.MaxInt64 {
+			return nil, errors.New("cbor: negative integer overflow")
+		}
+		return -1 - int64(n), nil
+	case majorBytes:
+		return d.read(n)
+	case majorString:
+		b, err := d.read(n)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	case majorArray:
+		return d.decodeArray(int(n))
+	case majorMap:
+		return d.decodeMap(int(n))
+	default:
+		return d.decodeTag(n)
+	}
+}
+
+// argument reads the argument of an initial byte with additional info.
+func (d *Decoder) argument(info byte) (uint64, error) {
+	switch {
+	case info < 24:
+		return uint64(info), nil
+	case info <= 27:
+		b, err := d.read(1 << (info - 24))
+		if err != nil {
+			return 0, err
+		}
+		return bigEndian(b), nil
+	}
+	return 0, fmt.Errorf("cbor: invalid additional info %d", info)
+}
+
+func (d *Decoder) read(n uint64) ([]byte, error) {
+	if n > math.MaxInt32 {
+		return nil, errors.New("cbor: length overflow")
+	}
+	b := make([]byte, n)
+	_, err := io.ReadFull(d.r, b)
+	return b, err
+}
+
+func (d *Decoder) decodeSimple(info byte) (interface{}, error) {
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+	case 25:
+		b, err := d.read(2)
+		if err != nil {
+			return nil, err
+		}
+		return halfFloat(binary.BigEndian.Uint16(b)), nil
+	case 26:
+		b, err := d.read(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), nil
+	case 27:
+		b, err := d.read(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+	}
+	return nil, fmt.Errorf("cbor: invalid simple value %d", info)
+}
+
+// decodeChunks reads the definite length chunks of an indefinite length byte
+// or text string, up to the break.
+func (d *Decoder) decodeChunks(major byte) (interface{}, error) {
+	var buf []byte
+	for {
+		c, err := d.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if c == 0xff {
+			break
+		}
+		if c&0xe0 != major || c&0x1f == 31 {
+			return nil, errors.New("cbor: invalid string chunk")
+		}
+		n, err := d.argument(c & 0x1f)
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.read(n)
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, b...)
+	}
+	if major == majorString {
+		return string(buf), nil
+	}
+	return buf, nil
+}
+
+// decodeArray reads n values, or values up to the break when n is negative.
+func (d *Decoder) decodeArray(n int) (interface{}, error) {
+	a := make([]interface{}, 0, min(max(n, 0), 1024))
+	for i := 0; n < 0 || i < n; i++ {
+		v, err := d.next()
+		if err == errBreak && n < 0 {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		a = append(a, v)
+	}
+	return a, nil
+}
+
+// decodeMap reads n pairs, or pairs up to the break when n is negative.
+func (d *Decoder) decodeMap(n int) (interface{}, error) {
+	m := make(map[string]interface{}, min(max(n, 0), 1024))
+	for i := 0; n < 0 || i < n; i++ {
+		k, err := d.next()
+		if err == errBreak && n < 0 {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.next()
+		if err != nil {
+			return nil, err
+		}
+		m[key(k)] = v
+	}
+	return m, nil
+}
+
+func (d *Decoder) decodeTag(tag uint64) (interface{}, error) {
+	v, err := d.next()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tag {
+	case TagTimeString:
+		if s, ok := v.(string); ok {
+			if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+				return t, nil
+			}
+		}
+	case TagTimeEpoch:
+		switch v := v.(type) {
+		case int64:
+			return time.Unix(v, 0), nil
+		case float64:
+			// a float 64 holds the current times to about 0.2 microsecond
+			sec, frac := math.Modf(v)
+			return time.Unix(int64(sec), int64(math.Round(frac*1e6))*1e3), nil
+		}
+	case TagEmbeddedJSON:
+		if b, ok := v.([]byte); ok && json.Valid(b) {
+			return json.RawMessage(b), nil
+		}
+	}
+	return Tagged{Tag: tag, Value: v}, nil
+}
+
+func (d *Decoder) next() (interface{}, error) {
+	c, err := d.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	return d.decode(c)
+}
+
+// key returns the string of the map key k.
+func key(k interface{}) string {
+	if s, ok := k.(string); ok {
+		return s
+	}
+	return fmt.Sprint(k)
+}
+
+// halfFloat returns the float 64 of the half precision float h.
+func halfFloat(h uint16) float64 {
+	exp, mant := int(h>>10)&0x1f, float64(h&0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 31:
+		if mant == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		return -f
+	}
+	return f
+}
+
+// bigEndian returns the big endian unsigned integer of b.
+func bigEndian(b []byte) uint64 {
+	var u uint64
+	for _, c := range b {
+		u = u<<8 | uint64(c)
+	}
+	return u
+}
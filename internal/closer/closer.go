@@ -0,0 +1,87 @@
+// Package closer flushes and closes the outputs owned by a logger, such as its
+// files and the outputs sending the entries from a background goroutine, so
+// that the entries they buffer are not lost when the process exits.
+package closer
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// Flusher is implemented by the outputs buffering entries. Flush sends the
+// buffered entries and waits for them to be sent.
+type Flusher interface {
+	Flush() error
+}
+
+// Group is the outputs of a logger. The zero value is an empty group, and the
+// methods of a nil group do nothing.
+type Group struct {
+	mu      sync.Mutex
+	outputs []interface{}
+	closed  bool
+}
+
+// Add adds the outputs implementing Flusher or io.Closer, ignoring the others.
+func (g *Group) Add(outputs ...interface{}) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, o := range outputs {
+		switch o.(type) {
+		case Flusher, io.Closer:
+			g.outputs = append(g.outputs, o)
+		}
+	}
+}
+
+// Flush flushes the outputs implementing Flusher.
+func (g *Group) Flush() error {
+	if g == nil {
+		return nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.flush()
+}
+
+// Close flushes the outputs then closes the ones implementing io.Closer. The
+// outputs are closed once, later calls do nothing.
+func (g *Group) Close() error {
+	if g == nil {
+		return nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.closed {
+		return nil
+	}
+	errs := []error{g.flush()}
+	g.closed = true
+
+	for _, o := range g.outputs {
+		if c, ok := o.(io.Closer); ok {
+			errs = append(errs, c.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (g *Group) flush() error {
+	if g.closed {
+		return nil
+	}
+
+	var errs []error
+	for _, o := range g.outputs {
+		if f, ok := o.(Flusher); ok {
+			errs = append(errs, f.Flush())
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,73 @@
+package closer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CloserSuite struct {
+	suite.Suite
+}
+
+func TestCloserSuite(t *testing.T) {
+	suite.Run(t, new(CloserSuite))
+}
+
+// output records the calls it receives in calls.
+type output struct {
+	name  string
+	calls *[]string
+	err   error
+}
+
+func (o output) Flush() error {
+	*o.calls = append(*o.calls, o.name+".Flush")
+	return o.err
+}
+
+func (o output) Close() error {
+	*o.calls = append(*o.calls, o.name+".Close")
+	return nil
+}
+
+// file is an output which is only closed.
+type file struct {
+	output
+}
+
+func (f file) Flush() {}
+
+func (s *CloserSuite) TestGroup() {
+	var calls []string
+	g := &Group{}
+	g.Add(output{name: "loki", calls: &calls}, "not an output", file{output{name: "file", calls: &calls}})
+
+	s.Assert().NoError(g.Flush())
+	s.Assert().Equal([]string{"loki.Flush"}, calls)
+
+	calls = nil
+	s.Assert().NoError(g.Close())
+	s.Assert().Equal([]string{"loki.Flush", "loki.Close", "file.Close"}, calls)
+
+	calls = nil
+	s.Assert().NoError(g.Close())
+	s.Assert().NoError(g.Flush())
+	s.Assert().Empty(calls)
+}
+
+func (s *CloserSuite) TestGroupErrors() {
+	var calls []string
+	g := &Group{}
+	g.Add(output{name: "loki", calls: &calls, err: errors.New("timed out")})
+
+	s.Assert().EqualError(g.Flush(), "timed out")
+	s.Assert().EqualError(g.Close(), "timed out")
+}
+
+func (s *CloserSuite) TestNilGroup() {
+	var g *Group
+	s.Assert().NoError(g.Flush())
+	s.Assert().NoError(g.Close())
+}
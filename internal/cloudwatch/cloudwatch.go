@@ -0,0 +1,154 @@
+// Package cloudwatch encodes entries as the text lines of the CloudWatch Logs
+// formatter of logrus, github.com/ravernkoh/cwlogsfmt, so that every backend
+// writes the same lines: the upper case level, the prefix fields, such as the
+// RequestId of a Lambda invocation, the message and the other fields, each as
+// a "Key: value" pair.
+//
+//	INFO RequestId: 8f5e0c4e Message: "order created" order: 1
+//
+// Values are quoted, as by %q, when they hold other characters than letters,
+// digits and -._/@^+.
+package cloudwatch
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/americanas-go/log/internal/order"
+)
+
+// DefaultPrefixField is the prefix field of the Lambda request id.
+const DefaultPrefixField = "RequestId"
+
+// Options configures the encoding.
+type Options struct {
+	PrefixFields     []string // fields written before the message, in this order
+	DisableSorting   bool     // write the other fields in the order of the map instead of sorted
+	QuoteEmptyFields bool     // quote empty values, which are written as nothing otherwise
+	CallerKey        string   // key of the caller, written as a field, none when empty
+
+	PriorityKeys []string // fields written first after the message, in this order, the others being sorted by key unless DisableSorting
+}
+
+// Level returns the name of level written by logrus.
+func Level(level log.Level) string {
+	switch level {
+	case log.TraceLevel:
+		return "TRACE"
+	case log.DebugLevel:
+		return "DEBUG"
+	case log.InfoLevel:
+		return "INFO"
+	case log.WarnLevel:
+		return "WARNING"
+	case log.ErrorLevel:
+		return "ERROR"
+	case log.PanicLevel:
+		return "PANIC"
+	default:
+		return "FATAL"
+	}
+}
+
+// Writer writes entries as CloudWatch lines to an io.Writer.
+type Writer struct {
+	mu      sync.Mutex
+	w       io.Writer
+	options Options
+}
+
+// NewWriter returns a Writer writing to w.
+func NewWriter(w io.Writer, options Options) *Writer {
+	return &Writer{w: w, options: options}
+}
+
+// WriteEntry implements entry.Writer.
+func (w *Writer) WriteEntry(e entry.Entry) error {
+	line := Append(nil, e, w.options)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	_, err := w.w.Write(line)
+	return err
+}
+
+// Append appends the CloudWatch line of e to dst.
+func Append(dst []byte, e entry.Entry, options Options) []byte {
+	buf := bytes.NewBuffer(dst)
+	buf.WriteString(Level(e.Level))
+	buf.WriteByte(' ')
+
+	prefixed := make(map[string]bool, len(options.PrefixFields))
+	for _, k := range options.PrefixFields {
+		if v, ok := e.Fields[k]; ok {
+			appendKeyValue(buf, k, v, options)
+			prefixed[k] = true
+		}
+	}
+
+	// the caller is one of the other fields, as written by the caller hook of
+	// logrus
+	withCaller := options.CallerKey != "" && e.Caller != ""
+	keys := make([]string, 0, len(e.Fields)+1)
+	for k := range e.Fields {
+		if !prefixed[k] && !(withCaller && k == options.CallerKey) {
+			keys = append(keys, k)
+		}
+	}
+	if withCaller {
+		keys = append(keys, options.CallerKey)
+	}
+	if !options.DisableSorting {
+		order.Sort(keys, options.PriorityKeys)
+	}
+
+	appendKeyValue(buf, "Message", e.Message, options)
+	for _, k := range keys {
+		if withCaller && k == options.CallerKey {
+			appendKeyValue(buf, k, e.Caller, options)
+			continue
+		}
+		appendKeyValue(buf, k, e.Fields[k], options)
+	}
+
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+func appendKeyValue(buf *bytes.Buffer, key string, value interface{}, options Options) {
+	buf.WriteString(key)
+	buf.WriteString(": ")
+
+	s, ok := value.(string)
+	if !ok {
+		s = fmt.Sprint(value)
+	}
+	if needsQuoting(s, options.QuoteEmptyFields) {
+		buf.WriteString(strconv.Quote(s))
+	} else {
+		buf.WriteString(s)
+	}
+	buf.WriteByte(' ')
+}
+
+func needsQuoting(s string, quoteEmpty bool) bool {
+	if quoteEmpty && s == "" {
+		return true
+	}
+	for _, ch := range s {
+		if !((ch >= 'a' && ch <= 'z') ||
+			(ch >= 'A' && ch <= 'Z') ||
+			(ch >= '0' && ch <= '9') ||
+			ch == '-' || ch == '.' || ch == '_' || ch == '/' ||
+			ch == '@' || ch == '^' || ch == '+') {
+			return true
+		}
+	}
+	return false
+}
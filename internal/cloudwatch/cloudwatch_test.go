@@ -0,0 +1,127 @@
+package cloudwatch
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/ravernkoh/cwlogsfmt"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/suite"
+)
+
+type CloudWatchSuite struct {
+	suite.Suite
+}
+
+func TestCloudWatchSuite(t *testing.T) {
+	suite.Run(t, new(CloudWatchSuite))
+}
+
+func (s *CloudWatchSuite) TestLevel() {
+	tt := []struct {
+		level log.Level
+		want  string
+	}{
+		{level: log.TraceLevel, want: "TRACE"},
+		{level: log.DebugLevel, want: "DEBUG"},
+		{level: log.InfoLevel, want: "INFO"},
+		{level: log.WarnLevel, want: "WARNING"},
+		{level: log.ErrorLevel, want: "ERROR"},
+		{level: log.PanicLevel, want: "PANIC"},
+		{level: log.FatalLevel, want: "FATAL"},
+	}
+	for _, t := range tt {
+		s.Run(t.level.String(), func() {
+			s.Assert().Equal(t.want, Level(t.level))
+		})
+	}
+}
+
+func (s *CloudWatchSuite) TestAppend() {
+	tt := []struct {
+		name    string
+		entry   entry.Entry
+		options Options
+		want    string
+	}{
+		{
+			name:  "without fields",
+			entry: entry.Entry{Level: log.InfoLevel, Message: "hello"},
+			want:  "INFO Message: hello \n",
+		},
+		{
+			name: "with prefix fields",
+			entry: entry.Entry{Level: log.WarnLevel, Message: "order created", Fields: log.Fields{
+				"RequestId": "8f5e0c4e",
+				"order":     1,
+				"empty":     "",
+				"err":       errors.New("bad"),
+			}},
+			options: Options{PrefixFields: []string{DefaultPrefixField}, QuoteEmptyFields: true},
+			want:    `WARNING RequestId: 8f5e0c4e Message: "order created" empty: "" err: bad order: 1 ` + "\n",
+		},
+		{
+			name:    "with caller",
+			entry:   entry.Entry{Level: log.ErrorLevel, Message: "failed", Caller: "app/main.go:10", Fields: log.Fields{"b": 1, "d": 2}},
+			options: Options{CallerKey: "caller"},
+			want:    `ERROR Message: failed b: 1 caller: "app/main.go:10" d: 2 ` + "\n",
+		},
+		{
+			name: "with priority keys",
+			entry: entry.Entry{Level: log.InfoLevel, Message: "hello", Fields: log.Fields{
+				"RequestId": "8f5e0c4e",
+				"a":         1,
+				"b":         2,
+				"c":         3,
+			}},
+			options: Options{PrefixFields: []string{DefaultPrefixField}, PriorityKeys: []string{"c", "RequestId", "b"}},
+			want:    "INFO RequestId: 8f5e0c4e Message: hello c: 3 b: 2 a: 1 \n",
+		},
+	}
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			s.Assert().Equal(t.want, string(Append(nil, t.entry, t.options)))
+		})
+	}
+}
+
+// TestAppendLogrus checks that the lines are those of the logrus formatter.
+func (s *CloudWatchSuite) TestAppendLogrus() {
+	fields := log.Fields{"RequestId": "8f5e0c4e", "path": "/orders", "quoted": `a "b"`, "empty": "", "n": 1.5}
+	formatter := &cwlogsfmt.CloudWatchLogsFormatter{PrefixFields: []string{"RequestId"}, QuoteEmptyFields: true}
+
+	for _, level := range []log.Level{log.TraceLevel, log.DebugLevel, log.InfoLevel, log.WarnLevel, log.ErrorLevel} {
+		s.Run(level.String(), func() {
+			data := logrus.Fields{}
+			for k, v := range fields {
+				data[k] = v
+			}
+			lvl, err := logrus.ParseLevel(level.String())
+			s.Require().NoError(err)
+			want, err := formatter.Format(&logrus.Entry{Level: lvl, Message: "order created", Data: data, Time: time.Now()})
+			s.Require().NoError(err)
+
+			got := Append(nil, entry.Entry{Level: level, Message: "order created", Fields: fields}, Options{
+				PrefixFields:     formatter.PrefixFields,
+				QuoteEmptyFields: formatter.QuoteEmptyFields,
+			})
+			s.Assert().Equal(string(want), string(got))
+		})
+	}
+}
+
+func (s *CloudWatchSuite) TestWriter() {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, Options{})
+
+	s.Require().NoError(w.WriteEntry(entry.Entry{Level: log.InfoLevel, Message: "a"}))
+	s.Require().NoError(w.WriteEntry(entry.Entry{Level: log.InfoLevel, Message: "b"}))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	s.Require().Equal([]string{"INFO Message: a ", "INFO Message: b "}, lines)
+}
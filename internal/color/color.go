@@ -0,0 +1,108 @@
+// Package color chooses between colored text and JSON for the AUTO formatter
+// and holds the colors of the levels, the same for every backend.
+//
+// Text is chosen when the output is a terminal, or when colors are forced by
+// FORCE_COLOR or CLICOLOR_FORCE, and JSON otherwise. The text is colored unless
+// NO_COLOR is set, following https://no-color.org, or FORCE_COLOR or CLICOLOR
+// is 0, following https://bixense.com/clicolors.
+package color
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/americanas-go/log"
+	"github.com/mattn/go-isatty"
+)
+
+// Level colors, as ANSI codes. They are those of the text formatter of
+// logrus, which can not be changed.
+const (
+	Gray   = 37
+	Red    = 31
+	Yellow = 33
+	Cyan   = 36
+)
+
+// Faint is the color of the secondary parts of the text, such as the times.
+const Faint = 90
+
+// Code returns the ANSI color code of level.
+func Code(level log.Level) int {
+	switch level {
+	case log.TraceLevel, log.DebugLevel:
+		return Gray
+	case log.InfoLevel:
+		return Cyan
+	case log.WarnLevel:
+		return Yellow
+	default:
+		return Red
+	}
+}
+
+// Level returns s in the color of level.
+func Level(level log.Level, s string) string {
+	return Paint(Code(level), s)
+}
+
+// Paint returns s in the color of the ANSI code.
+func Paint(code int, s string) string {
+	return fmt.Sprintf("\x1b[%dm%s\x1b[0m", code, s)
+}
+
+// Auto returns whether the AUTO formatter writes text to w, instead of JSON,
+// and whether the text is colored.
+func Auto(w io.Writer) (text bool, colored bool) {
+	text = isForced() || IsTerminal(w)
+	return text, text && !isDisabled()
+}
+
+// Enabled reports whether the text written to w is colored: when w is a
+// terminal or colors are forced, and they are not disabled.
+func Enabled(w io.Writer) bool {
+	_, colored := Auto(w)
+	return colored
+}
+
+// IsTerminal reports whether w is a terminal, including the Cygwin and MSYS2
+// ones.
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(interface{ Fd() uintptr })
+	if !ok {
+		return false
+	}
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// isForced reports whether FORCE_COLOR, or else CLICOLOR_FORCE, is set and
+// not 0.
+func isForced() bool {
+	if v := os.Getenv("FORCE_COLOR"); v != "" {
+		return !isOff(v)
+	}
+	v := os.Getenv("CLICOLOR_FORCE")
+	return v != "" && v != "0"
+}
+
+// isDisabled reports whether NO_COLOR is set, or whether FORCE_COLOR or
+// CLICOLOR is 0.
+func isDisabled() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return true
+	}
+	if v := os.Getenv("FORCE_COLOR"); v != "" && isOff(v) {
+		return true
+	}
+	return os.Getenv("CLICOLOR") == "0" && !isForced()
+}
+
+func isOff(v string) bool {
+	switch strings.ToLower(v) {
+	case "0", "false", "no", "off":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package color
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/americanas-go/log"
+	"github.com/stretchr/testify/suite"
+)
+
+type ColorSuite struct {
+	suite.Suite
+}
+
+func TestColorSuite(t *testing.T) {
+	suite.Run(t, new(ColorSuite))
+}
+
+func (s *ColorSuite) SetupTest() {
+	for _, key := range []string{"NO_COLOR", "FORCE_COLOR", "CLICOLOR", "CLICOLOR_FORCE"} {
+		s.T().Setenv(key, "")
+	}
+}
+
+func (s *ColorSuite) TestLevel() {
+	s.Assert().Equal("\x1b[37mDEBUG\x1b[0m", Level(log.DebugLevel, "DEBUG"))
+	s.Assert().Equal("\x1b[36mINFO\x1b[0m", Level(log.InfoLevel, "INFO"))
+	s.Assert().Equal("\x1b[33mWARN\x1b[0m", Level(log.WarnLevel, "WARN"))
+	s.Assert().Equal("\x1b[31mFATAL\x1b[0m", Level(log.FatalLevel, "FATAL"))
+}
+
+func (s *ColorSuite) TestAuto() {
+	tt := []struct {
+		name    string
+		env     map[string]string
+		text    bool
+		colored bool
+	}{
+		{name: "not a terminal", text: false, colored: false},
+		{name: "FORCE_COLOR", env: map[string]string{"FORCE_COLOR": "1"}, text: true, colored: true},
+		{name: "FORCE_COLOR 0", env: map[string]string{"FORCE_COLOR": "0", "CLICOLOR_FORCE": "1"}, text: false, colored: false},
+		{name: "CLICOLOR_FORCE", env: map[string]string{"CLICOLOR_FORCE": "1", "CLICOLOR": "0"}, text: true, colored: true},
+		{name: "CLICOLOR_FORCE 0", env: map[string]string{"CLICOLOR_FORCE": "0"}, text: false, colored: false},
+		{name: "NO_COLOR", env: map[string]string{"FORCE_COLOR": "1", "NO_COLOR": "1"}, text: true, colored: false},
+	}
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			for k, v := range t.env {
+				s.T().Setenv(k, v)
+			}
+
+			text, colored := Auto(&bytes.Buffer{})
+			s.Assert().Equal(t.text, text)
+			s.Assert().Equal(t.colored, colored)
+		})
+	}
+}
+
+func (s *ColorSuite) TestIsTerminal() {
+	s.Assert().False(IsTerminal(&bytes.Buffer{}))
+
+	f, err := os.CreateTemp(s.T().TempDir(), "out")
+	s.Require().NoError(err)
+	defer f.Close()
+	s.Assert().False(IsTerminal(f))
+}
+
+func (s *ColorSuite) TestEnabled() {
+	s.Assert().False(Enabled(&bytes.Buffer{}))
+
+	s.T().Setenv("CLICOLOR_FORCE", "1")
+	s.Assert().True(Enabled(&bytes.Buffer{}))
+
+	s.T().Setenv("NO_COLOR", "1")
+	s.Assert().False(Enabled(&bytes.Buffer{}))
+}
@@ -0,0 +1,295 @@
+// Package ecs encodes entries as Elastic Common Schema JSON, described by
+// https://www.elastic.co/guide/en/ecs/current/index.html.
+//
+// Each entry is a line starting with @timestamp, log.level, message and
+// ecs.version, as required by the ECS logging libraries, followed by the
+// objects of the other fields. The error of the entry is written as
+// error.message, error.type and error.stack_trace, the caller as
+// log.origin.file.name, log.origin.file.line and log.origin.function, and the
+// trace and span ids as trace.id and span.id. The dotted keys of the fields are
+// nested, {"http.request.method": "GET"} being written as
+// {"http":{"request":{"method":"GET"}}}.
+package ecs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/americanas-go/log/internal/order"
+)
+
+// Version is the version of ECS the entries follow.
+const Version = "8.11.0"
+
+const timeLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// Default keys of the fields moved to their ECS fields.
+const (
+	DefaultErrorKey   = "err"
+	DefaultTraceIDKey = "trace_id"
+	DefaultSpanIDKey  = "span_id"
+)
+
+// keys of the fields written first, the fields with the same keys are dropped.
+var reserved = map[string]bool{
+	"@timestamp":  true,
+	"log.level":   true,
+	"message":     true,
+	"ecs.version": true,
+}
+
+// Options configures the encoding. Empty keys take a default, except
+// StacktraceKey.
+type Options struct {
+	ErrorKey      string // key of the error, written as error.message and error.type
+	StacktraceKey string // key of the stack trace of the error, written as error.stack_trace
+	TraceIDKey    string // key of the trace id, written as trace.id
+	SpanIDKey     string // key of the span id, written as span.id
+
+	PriorityKeys []string // fields written first, in this order, the others being sorted by key, the objects of dotted keys taking the place of their first key
+}
+
+func (o Options) withDefaults() Options {
+	if o.ErrorKey == "" {
+		o.ErrorKey = DefaultErrorKey
+	}
+	if o.TraceIDKey == "" {
+		o.TraceIDKey = DefaultTraceIDKey
+	}
+	if o.SpanIDKey == "" {
+		o.SpanIDKey = DefaultSpanIDKey
+	}
+	return o
+}
+
+// Writer writes entries as ECS JSON lines to an io.Writer.
+type Writer struct {
+	mu      sync.Mutex
+	w       io.Writer
+	options Options
+}
+
+// NewWriter returns a Writer writing to w.
+func NewWriter(w io.Writer, options Options) *Writer {
+	return &Writer{w: w, options: options}
+}
+
+// WriteEntry implements entry.Writer.
+func (w *Writer) WriteEntry(e entry.Entry) error {
+	line := Append(nil, e, w.options)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	_, err := w.w.Write(line)
+	return err
+}
+
+// Append appends the ECS JSON line of e to dst.
+func Append(dst []byte, e entry.Entry, options Options) []byte {
+	options = options.withDefaults()
+
+	t := e.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+
+	buf := bytes.NewBuffer(dst)
+	buf.WriteString(`{"@timestamp":`)
+	appendJSON(buf, t.UTC().Format(timeLayout))
+	buf.WriteString(`,"log.level":`)
+	appendJSON(buf, strings.ToLower(e.Level.String()))
+	buf.WriteString(`,"message":`)
+	appendJSON(buf, e.Message)
+	buf.WriteString(`,"ecs.version":`)
+	appendJSON(buf, Version)
+
+	for _, kv := range fields(e, options) {
+		buf.WriteByte(',')
+		appendJSON(buf, kv.key)
+		buf.WriteByte(':')
+		appendJSON(buf, kv.value)
+	}
+
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+type keyValue struct {
+	key   string
+	value interface{}
+}
+
+// fields returns the objects written after the first fields, the priority ones
+// first and the others sorted by key.
+// The fields are inserted sorted by key, so that a key and the dotted keys it
+// prefixes collide the same way every time, see object.insert.
+func fields(e entry.Entry, options Options) []keyValue {
+	tree := object{}
+
+	for _, k := range order.Keys(e.Fields, nil) {
+		v := e.Fields[k]
+		switch {
+		case reserved[k]:
+		case k == options.ErrorKey:
+			insertError(tree, v)
+		case k == options.StacktraceKey:
+			tree.insert([]string{"error", "stack_trace"}, stackTrace(v))
+		case k == options.TraceIDKey:
+			tree.insert([]string{"trace", "id"}, v)
+		case k == options.SpanIDKey:
+			tree.insert([]string{"span", "id"}, v)
+		default:
+			tree.insert(strings.Split(k, "."), v)
+		}
+	}
+
+	if e.Caller != "" {
+		file, line := e.Caller, ""
+		if i := strings.LastIndexByte(e.Caller, ':'); i > 0 {
+			file, line = e.Caller[:i], e.Caller[i+1:]
+		}
+		tree.insert([]string{"log", "origin", "file", "name"}, file)
+		if n, err := strconv.Atoi(line); err == nil {
+			tree.insert([]string{"log", "origin", "file", "line"}, n)
+		}
+	}
+	if e.Function != "" {
+		tree.insert([]string{"log", "origin", "function"}, e.Function)
+	}
+
+	// a dotted priority key orders the object of its first key
+	priority := make([]string, 0, len(options.PriorityKeys))
+	for _, k := range options.PriorityKeys {
+		priority = append(priority, strings.SplitN(k, ".", 2)[0])
+	}
+
+	kvs := make([]keyValue, 0, len(tree))
+	for _, k := range order.Keys(tree, priority) {
+		kvs = append(kvs, keyValue{key: k, value: tree[k]})
+	}
+	return kvs
+}
+
+// insertError inserts the error.message and error.type of v, and its
+// error.stack_trace when it formats one with %+v, as the errors of
+// github.com/pkg/errors do.
+func insertError(tree object, v interface{}) {
+	err, ok := v.(error)
+	if !ok {
+		tree.insert([]string{"error", "message"}, fmt.Sprint(v))
+		return
+	}
+
+	tree.insert([]string{"error", "message"}, err.Error())
+	tree.insert([]string{"error", "type"}, fmt.Sprintf("%T", err))
+	if f, ok := err.(fmt.Formatter); ok {
+		if verbose := fmt.Sprintf("%+v", f); verbose != err.Error() {
+			tree.insert([]string{"error", "stack_trace"}, verbose)
+		}
+	}
+}
+
+// stackTrace returns v as a string, stack traces being arrays of frames for
+// some backends.
+func stackTrace(v interface{}) interface{} {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	if data, err := json.Marshal(v); err == nil {
+		return string(data)
+	}
+	return fmt.Sprint(v)
+}
+
+// object is a JSON object under construction.
+type object map[string]interface{}
+
+// insert sets the value at path, creating the objects on the way. Maps are
+// merged into the objects, their keys being nested too, in sorted order. When
+// the path meets a value which is not an object, the rest of the path is kept
+// as a dotted key, a value meeting an object is dropped and a value set twice
+// keeps the last one. Since a key sorts before the keys it prefixes,
+// {"url": "/", "url.path": "/a"} is always written as
+// {"url":"/","url.path":"/a"}.
+func (o object) insert(path []string, v interface{}) {
+	key := path[0]
+	if len(path) > 1 {
+		if child, ok := o[key].(object); ok {
+			child.insert(path[1:], v)
+			return
+		}
+		if _, taken := o[key]; !taken {
+			child := object{}
+			o[key] = child
+			child.insert(path[1:], v)
+			return
+		}
+		o[strings.Join(path, ".")] = value(v)
+		return
+	}
+
+	m, isMap := asMap(v)
+	if !isMap {
+		if _, isObject := o[key].(object); !isObject {
+			o[key] = value(v)
+		}
+		return
+	}
+
+	child, ok := o[key].(object)
+	if !ok {
+		if _, taken := o[key]; taken {
+			return
+		}
+		child = object{}
+		o[key] = child
+	}
+	for _, k := range order.Keys(m, nil) {
+		child.insert(strings.Split(k, "."), m[k])
+	}
+}
+
+func asMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case map[string]string:
+		converted := make(map[string]interface{}, len(m))
+		for k, e := range m {
+			converted[k] = e
+		}
+		return converted, true
+	}
+	return nil, false
+}
+
+// value returns v as it is written: errors as their message, and other values
+// as their JSON encoding, or as formatted by fmt when they have none.
+func value(v interface{}) interface{} {
+	switch v := v.(type) {
+	case error:
+		return v.Error()
+	case nil, string, bool, int, int64, float64, json.Number:
+		return v
+	}
+	if _, err := json.Marshal(v); err != nil {
+		return fmt.Sprint(v)
+	}
+	return v
+}
+
+// appendJSON appends the JSON encoding of v, without escaping HTML.
+func appendJSON(buf *bytes.Buffer, v interface{}) {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(v)
+	buf.Truncate(buf.Len() - 1) // the newline of Encode
+}
@@ -0,0 +1,180 @@
+package ecs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/stretchr/testify/suite"
+)
+
+type EcsSuite struct {
+	suite.Suite
+}
+
+func TestEcsSuite(t *testing.T) {
+	suite.Run(t, new(EcsSuite))
+}
+
+var at = time.Date(2021, 1, 2, 3, 4, 5, 123456789, time.FixedZone("", -3*60*60))
+
+// stackError formats a stack trace with %+v, as the errors of
+// github.com/pkg/errors do.
+type stackError struct{}
+
+func (stackError) Error() string { return "bad" }
+
+func (e stackError) Format(s fmt.State, verb rune) {
+	if s.Flag('+') {
+		fmt.Fprint(s, "bad\nmain.main\n\tmain.go:10")
+		return
+	}
+	fmt.Fprint(s, e.Error())
+}
+
+func (s *EcsSuite) TestAppend() {
+	tt := []struct {
+		name    string
+		entry   entry.Entry
+		options Options
+		want    string
+	}{
+		{
+			name:  "without fields",
+			entry: entry.Entry{Time: at, Level: log.InfoLevel, Message: "hello"},
+			want:  `{"@timestamp":"2021-01-02T06:04:05.123Z","log.level":"info","message":"hello","ecs.version":"8.11.0"}`,
+		},
+		{
+			name: "with error and caller",
+			entry: entry.Entry{
+				Time:     at,
+				Level:    log.ErrorLevel,
+				Message:  "failed",
+				Caller:   "app/main.go:10",
+				Function: "main.main",
+				Fields:   log.Fields{"err": errors.New("bad"), "stacktrace": "main.main\n\tmain.go:10"},
+			},
+			options: Options{StacktraceKey: "stacktrace"},
+			want: `{"@timestamp":"2021-01-02T06:04:05.123Z","log.level":"error","message":"failed","ecs.version":"8.11.0",
+				"error":{"message":"bad","type":"*errors.errorString","stack_trace":"main.main\n\tmain.go:10"},
+				"log":{"origin":{"file":{"name":"app/main.go","line":10},"function":"main.main"}}}`,
+		},
+		{
+			name:    "with error formatting a stack trace",
+			entry:   entry.Entry{Time: at, Level: log.ErrorLevel, Message: "failed", Fields: log.Fields{"error": stackError{}}},
+			options: Options{ErrorKey: "error"},
+			want: `{"@timestamp":"2021-01-02T06:04:05.123Z","log.level":"error","message":"failed","ecs.version":"8.11.0",
+				"error":{"message":"bad","type":"ecs.stackError","stack_trace":"bad\nmain.main\n\tmain.go:10"}}`,
+		},
+		{
+			name:  "with error as string",
+			entry: entry.Entry{Time: at, Level: log.WarnLevel, Message: "failed", Fields: log.Fields{"err": "bad"}},
+			want: `{"@timestamp":"2021-01-02T06:04:05.123Z","log.level":"warn","message":"failed","ecs.version":"8.11.0",
+				"error":{"message":"bad"}}`,
+		},
+		{
+			name: "with trace and span ids",
+			entry: entry.Entry{
+				Time:    at,
+				Level:   log.DebugLevel,
+				Message: "hello",
+				Fields:  log.Fields{"trace_id": "abc", "span_id": "def", "trace.flags": "01"},
+			},
+			want: `{"@timestamp":"2021-01-02T06:04:05.123Z","log.level":"debug","message":"hello","ecs.version":"8.11.0",
+				"trace":{"id":"abc","flags":"01"},"span":{"id":"def"}}`,
+		},
+		{
+			name: "with dotted keys",
+			entry: entry.Entry{
+				Time:    at,
+				Level:   log.InfoLevel,
+				Message: "hello",
+				Fields: log.Fields{
+					"http.request.method": "GET",
+					"http.response":       map[string]interface{}{"status_code": 200, "body.bytes": 10},
+					"url":                 "/",
+					"url.path":            "/a",
+					"message":             "dropped",
+					"labels":              map[string]string{"env": "prod"},
+					"event.duration":      time.Second,
+					"event.unmarshalable": complex(1, 2),
+				},
+			},
+			want: `{"@timestamp":"2021-01-02T06:04:05.123Z","log.level":"info","message":"hello","ecs.version":"8.11.0",
+				"http":{"request":{"method":"GET"},"response":{"status_code":200,"body":{"bytes":10}}},
+				"url":"/","url.path":"/a","labels":{"env":"prod"},
+				"event":{"duration":1000000000,"unmarshalable":"(1+2i)"}}`,
+		},
+	}
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			got := Append(nil, t.entry, t.options)
+			s.Assert().True(bytes.HasSuffix(got, []byte("}\n")))
+			s.Assert().JSONEq(t.want, string(got))
+		})
+	}
+}
+
+func (s *EcsSuite) TestOrder() {
+	got := string(Append(nil, entry.Entry{
+		Time:    at,
+		Level:   log.InfoLevel,
+		Message: "<hello>",
+		Fields:  log.Fields{"b": 1, "a": 2},
+	}, Options{}))
+
+	s.Assert().Equal(`{"@timestamp":"2021-01-02T06:04:05.123Z","log.level":"info","message":"<hello>",`+
+		`"ecs.version":"8.11.0","a":2,"b":1}`+"\n", got)
+}
+
+func (s *EcsSuite) TestPriorityKeys() {
+	got := string(Append(nil, entry.Entry{
+		Time:    at,
+		Level:   log.InfoLevel,
+		Message: "<hello>",
+		Fields:  log.Fields{"b": 1, "a": 2, "user.id": 3, "user.name": "alice"},
+	}, Options{PriorityKeys: []string{"user.name", "b"}}))
+
+	s.Assert().Equal(`{"@timestamp":"2021-01-02T06:04:05.123Z","log.level":"info","message":"<hello>",`+
+		`"ecs.version":"8.11.0","user":{"id":3,"name":"alice"},"b":1,"a":2}`+"\n", got)
+}
+
+func (s *EcsSuite) TestCollisions() {
+	e := entry.Entry{
+		Time:    at,
+		Level:   log.InfoLevel,
+		Message: "hello",
+		Fields: log.Fields{
+			"url":          "/",
+			"url.path":     "/a",
+			"http.request": map[string]interface{}{"method": "GET", "method.name": "get"},
+			"user.name":    "alice",
+			"user":         map[string]interface{}{"name": "bob", "id": 1},
+		},
+	}
+	want := `{"@timestamp":"2021-01-02T06:04:05.123Z","log.level":"info","message":"hello","ecs.version":"8.11.0",` +
+		`"http":{"request":{"method":"GET","method.name":"get"}},"url":"/","url.path":"/a","user":{"id":1,"name":"alice"}}` + "\n"
+
+	// the fields being maps, a collision depending on their order would vary
+	for i := 0; i < 50; i++ {
+		s.Require().Equal(want, string(Append(nil, e, Options{})))
+	}
+}
+
+func (s *EcsSuite) TestWriter() {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, Options{})
+
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Level: log.InfoLevel, Message: "a"}))
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Level: log.InfoLevel, Message: "b"}))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	s.Require().Len(lines, 2)
+	s.Assert().Contains(lines[0], `"message":"a"`)
+	s.Assert().Contains(lines[1], `"message":"b"`)
+}
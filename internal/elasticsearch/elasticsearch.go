@@ -0,0 +1,409 @@
+// Package elasticsearch indexes entries into Elasticsearch or OpenSearch with
+// the _bulk API, as described by
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-bulk.html.
+//
+// Entries are queued and sent by a background goroutine, in batches flushed
+// when they reach the batch size or the batch age. Each entry is a create
+// action, into the index named by the template and the time of the entry. The
+// items of a bulk request rejected with 429 or 5xx, and the whole requests
+// which fail or are rejected with those statuses, are retried with an
+// exponential backoff, the other rejected items are dropped.
+//
+// The documents hold the fields of the entry as they are, along with the time,
+// message, level and caller. These follow the Elastic Common Schema when
+// asked to: @timestamp, message, log.level, log.origin.file.name,
+// log.origin.file.line and ecs.version.
+package elasticsearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/americanas-go/log/internal/ecs"
+	"github.com/americanas-go/log/internal/entry"
+)
+
+const (
+	defaultURL        = "http://localhost:9200"
+	defaultIndex      = "logs-{2006.01.02}"
+	defaultBatchSize  = 100
+	defaultBatchAge   = time.Second
+	defaultBufferSize = 1024
+	defaultTimeout    = 10 * time.Second
+	defaultMaxRetries = 5
+	defaultMinBackoff = 500 * time.Millisecond
+	defaultMaxBackoff = 30 * time.Second
+
+	// ECSVersion is the version of the Elastic Common Schema of the documents.
+	ECSVersion = ecs.Version
+
+	closeTimeout = 5 * time.Second
+)
+
+var errBufferFull = errors.New("elasticsearch: buffer is full, entry dropped")
+
+// Options configures a Writer. Zero values take a default.
+type Options struct {
+	URL        string        // base URL of the cluster, http://localhost:9200 when empty
+	Index      string        // index name template, logs-{2006.01.02} when empty
+	Username   string        // user of the basic authentication
+	Password   string        // password of the basic authentication
+	APIKey     string        // encoded API key, instead of the basic authentication
+	BatchSize  int           // entries of a batch, 100 when zero
+	BatchAge   time.Duration // longest wait before a batch is sent, 1s when zero
+	BufferSize int           // entries waiting to be sent, 1024 when zero
+	Timeout    time.Duration // timeout of a bulk request, 10s when zero
+	MaxRetries int           // retries of a bulk request or item, 5 when zero
+	MinBackoff time.Duration // first delay between retries, 500ms when zero
+	MaxBackoff time.Duration // longest delay between retries, 30s when zero
+	Client     *http.Client  // client of the requests, one with Timeout when nil
+
+	// ECS names the time, message, level and caller after the Elastic Common
+	// Schema, instead of the keys below. The time, the level and the caller
+	// are omitted when their key is empty.
+	ECS        bool
+	TimeKey    string
+	MessageKey string
+	LevelKey   string
+	CallerKey  string
+}
+
+// item is an encoded entry, the action and the document of a bulk request.
+type item struct {
+	action []byte
+	doc    []byte
+}
+
+// Writer indexes entries into Elasticsearch.
+type Writer struct {
+	options Options
+	url     string
+
+	events  chan item
+	flushes chan chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+	closed  sync.Once
+}
+
+// New returns a Writer from options and starts sending.
+func New(options Options) *Writer {
+	if options.URL == "" {
+		options.URL = defaultURL
+	}
+	if options.Index == "" {
+		options.Index = defaultIndex
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = defaultBatchSize
+	}
+	if options.BatchAge <= 0 {
+		options.BatchAge = defaultBatchAge
+	}
+	if options.BufferSize <= 0 {
+		options.BufferSize = defaultBufferSize
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = defaultTimeout
+	}
+	if options.MaxRetries <= 0 {
+		options.MaxRetries = defaultMaxRetries
+	}
+	if options.MinBackoff <= 0 {
+		options.MinBackoff = defaultMinBackoff
+	}
+	if options.MaxBackoff < options.MinBackoff {
+		options.MaxBackoff = max(defaultMaxBackoff, options.MinBackoff)
+	}
+	if options.Client == nil {
+		options.Client = &http.Client{Timeout: options.Timeout}
+	}
+	if options.MessageKey == "" {
+		options.MessageKey = "message"
+	}
+
+	w := &Writer{
+		options: options,
+		url:     strings.TrimSuffix(options.URL, "/") + "/_bulk",
+		events:  make(chan item, options.BufferSize),
+		flushes: make(chan chan struct{}),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+
+	go w.run()
+	return w
+}
+
+// WriteEntry implements entry.Writer. It queues e and fails when the buffer is
+// full.
+func (w *Writer) WriteEntry(e entry.Entry) error {
+	select {
+	case w.events <- w.encode(e):
+		return nil
+	default:
+		return errBufferFull
+	}
+}
+
+// Flush sends the pending batch, the queued entries included, and waits for
+// it to be indexed, retries included, for up to 5 seconds.
+func (w *Writer) Flush() error {
+	flushed := make(chan struct{})
+	timeout := time.After(closeTimeout)
+
+	select {
+	case w.flushes <- flushed:
+	case <-w.stopped:
+		return nil
+	case <-timeout:
+		return errors.New("elasticsearch: timed out sending the pending entries")
+	}
+
+	select {
+	case <-flushed:
+		return nil
+	case <-w.stopped:
+		return nil
+	case <-timeout:
+		return errors.New("elasticsearch: timed out sending the pending entries")
+	}
+}
+
+// Close sends the pending batch, trying once, and stops sending.
+func (w *Writer) Close() error {
+	w.closed.Do(func() { close(w.done) })
+
+	select {
+	case <-w.stopped:
+		return nil
+	case <-time.After(closeTimeout):
+		return errors.New("elasticsearch: timed out sending the pending entries")
+	}
+}
+
+// IndexName returns the name of the index of an entry logged at t: the parts
+// of template between braces are time layouts, such as app-logs-{2006.01.02},
+// and a template without braces is a layout as a whole, such as
+// app-logs-2006.01.02. The time is in UTC.
+func IndexName(template string, t time.Time) string {
+	t = t.UTC()
+	if !strings.Contains(template, "{") {
+		return t.Format(template)
+	}
+
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			break
+		}
+		end += start
+		b.WriteString(template[:start])
+		b.WriteString(t.Format(template[start+1 : end]))
+		template = template[end+1:]
+	}
+	b.WriteString(template)
+	return b.String()
+}
+
+func (w *Writer) encode(e entry.Entry) item {
+	t := e.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+
+	doc := make(map[string]interface{}, len(e.Fields)+6)
+	for k, v := range e.Fields {
+		doc[k] = value(v)
+	}
+
+	if w.options.ECS {
+		doc["@timestamp"] = t.Format(time.RFC3339Nano)
+		doc["message"] = e.Message
+		doc["log.level"] = strings.ToLower(e.Level.String())
+		if e.Caller != "" {
+			file := e.Caller
+			if i := strings.LastIndexByte(e.Caller, ':'); i >= 0 {
+				if line, err := strconv.Atoi(e.Caller[i+1:]); err == nil {
+					file = e.Caller[:i]
+					doc["log.origin.file.line"] = line
+				}
+			}
+			doc["log.origin.file.name"] = file
+		}
+		doc["ecs.version"] = ECSVersion
+	} else {
+		if w.options.TimeKey != "" {
+			doc[w.options.TimeKey] = t.Format(time.RFC3339Nano)
+		}
+		if w.options.LevelKey != "" {
+			doc[w.options.LevelKey] = e.Level.String()
+		}
+		if w.options.CallerKey != "" && e.Caller != "" {
+			doc[w.options.CallerKey] = e.Caller
+		}
+		doc[w.options.MessageKey] = e.Message
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		data, _ = json.Marshal(map[string]string{"message": e.Message, "error.message": err.Error()})
+	}
+	action, _ := json.Marshal(map[string]map[string]string{"create": {"_index": IndexName(w.options.Index, t)}})
+	return item{action: action, doc: data}
+}
+
+func (w *Writer) run() {
+	defer close(w.stopped)
+
+	ticker := time.NewTicker(w.options.BatchAge)
+	defer ticker.Stop()
+
+	var batch []item
+	for {
+		var flushed chan struct{}
+		select {
+		case <-w.done:
+			// the pending items are sent once, the queued ones included
+			for len(w.events) > 0 {
+				batch = append(batch, <-w.events)
+			}
+			if len(batch) > 0 {
+				_, _ = w.bulk(batch)
+			}
+			return
+		case it := <-w.events:
+			batch = append(batch, it)
+			if len(batch) < w.options.BatchSize {
+				continue
+			}
+		case flushed = <-w.flushes:
+			for len(w.events) > 0 {
+				batch = append(batch, <-w.events)
+			}
+		case <-ticker.C:
+		}
+
+		if len(batch) > 0 {
+			if !w.deliver(batch) {
+				return
+			}
+			batch = batch[:0]
+		}
+		if flushed != nil {
+			close(flushed)
+		}
+	}
+}
+
+// deliver sends items, retrying the retryable ones. It returns false when the
+// writer was closed meanwhile.
+func (w *Writer) deliver(items []item) bool {
+	backoff := w.options.MinBackoff
+	for retry := 0; ; retry++ {
+		failed, err := w.bulk(items)
+		if err == nil && len(failed) == 0 || retry == w.options.MaxRetries {
+			return true
+		}
+		if err == nil {
+			items = failed
+		}
+
+		select {
+		case <-w.done:
+			// the items are tried once more on close
+			_, _ = w.bulk(items)
+			return false
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, w.options.MaxBackoff)
+	}
+}
+
+// bulk sends items once. It returns the items to retry, or an error when the
+// whole request should be retried. The rejected items and requests which
+// cannot be retried are dropped.
+func (w *Writer) bulk(items []item) ([]item, error) {
+	var body bytes.Buffer
+	for _, it := range items {
+		body.Write(it.action)
+		body.WriteByte('\n')
+		body.Write(it.doc)
+		body.WriteByte('\n')
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.url, &body)
+	if err != nil {
+		return nil, nil
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	switch {
+	case w.options.APIKey != "":
+		req.Header.Set("Authorization", "ApiKey "+w.options.APIKey)
+	case w.options.Username != "":
+		req.SetBasicAuth(w.options.Username, w.options.Password)
+	}
+
+	resp, err := w.options.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1024))
+		if retryable(resp.StatusCode) {
+			return nil, fmt.Errorf("elasticsearch: bulk request rejected with status %d", resp.StatusCode)
+		}
+		return nil, nil
+	}
+
+	var response struct {
+		Errors bool                              `json:"errors"`
+		Items  []map[string]struct{ Status int } `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil || !response.Errors {
+		return nil, nil
+	}
+
+	var failed []item
+	for i, result := range response.Items {
+		if i >= len(items) {
+			break
+		}
+		for _, action := range result {
+			if retryable(action.Status) {
+				failed = append(failed, items[i])
+			}
+		}
+	}
+	return failed, nil
+}
+
+// retryable tells whether a request or an item rejected with status can be
+// retried.
+func retryable(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// value returns v as it should be encoded in a document, errors being written
+// as their message.
+func value(v interface{}) interface{} {
+	if err, ok := v.(error); ok {
+		return err.Error()
+	}
+	return v
+}
@@ -0,0 +1,325 @@
+package elasticsearch
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/stretchr/testify/suite"
+)
+
+type ElasticsearchSuite struct {
+	suite.Suite
+}
+
+func TestElasticsearchSuite(t *testing.T) {
+	suite.Run(t, new(ElasticsearchSuite))
+}
+
+var at = time.Date(2021, 1, 2, 3, 4, 5, 123456789, time.UTC)
+
+// request is a bulk request received by the cluster.
+type request struct {
+	header  http.Header
+	actions []map[string]map[string]string
+	docs    []map[string]interface{}
+}
+
+// cluster is a _bulk endpoint. respond returns the status of a request and
+// the status of each of its items, nil when they all succeed.
+type cluster struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []request
+	received chan request
+}
+
+func (s *ElasticsearchSuite) cluster(respond func(n int, r request) (int, []int)) *cluster {
+	c := &cluster{received: make(chan request, 100)}
+	c.Server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_bulk" || r.Method != http.MethodPost {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		req := request{header: r.Header}
+		scanner := bufio.NewScanner(r.Body)
+		for i := 0; scanner.Scan(); i++ {
+			if i%2 == 0 {
+				var action map[string]map[string]string
+				_ = json.Unmarshal(scanner.Bytes(), &action)
+				req.actions = append(req.actions, action)
+			} else {
+				var doc map[string]interface{}
+				_ = json.Unmarshal(scanner.Bytes(), &doc)
+				req.docs = append(req.docs, doc)
+			}
+		}
+
+		c.mu.Lock()
+		c.requests = append(c.requests, req)
+		n := len(c.requests)
+		c.mu.Unlock()
+
+		status, items := respond(n, req)
+		rw.WriteHeader(status)
+		if status == http.StatusOK {
+			response := map[string]interface{}{"errors": items != nil}
+			results := make([]interface{}, 0, len(req.actions))
+			for i := range req.actions {
+				itemStatus := http.StatusCreated
+				if items != nil {
+					itemStatus = items[i]
+				}
+				results = append(results, map[string]interface{}{"create": map[string]interface{}{"status": itemStatus}})
+			}
+			response["items"] = results
+			_ = json.NewEncoder(rw).Encode(response)
+		}
+		c.received <- req
+	}))
+	return c
+}
+
+func ok(int, request) (int, []int) {
+	return http.StatusOK, nil
+}
+
+func (s *ElasticsearchSuite) receive(c *cluster) request {
+	select {
+	case r := <-c.received:
+		return r
+	case <-time.After(5 * time.Second):
+		s.FailNow("no bulk request received")
+		return request{}
+	}
+}
+
+func (s *ElasticsearchSuite) TestECS() {
+	c := s.cluster(ok)
+	defer c.Close()
+
+	w := New(Options{URL: c.URL, Index: "app-logs-{2006.01.02}", ECS: true, BatchSize: 2})
+	defer w.Close()
+
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Level: log.WarnLevel, Message: "a", Caller: "orders/pay.go:42", Fields: log.Fields{"ID": 1}}))
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at.Add(24 * time.Hour), Level: log.ErrorLevel, Message: "b", Fields: log.Fields{"err": errors.New("bad")}}))
+
+	r := s.receive(c)
+	s.Assert().Equal("application/x-ndjson", r.header.Get("Content-Type"))
+	s.Assert().Equal([]map[string]map[string]string{
+		{"create": {"_index": "app-logs-2021.01.02"}},
+		{"create": {"_index": "app-logs-2021.01.03"}},
+	}, r.actions)
+	s.Assert().Equal([]map[string]interface{}{
+		{
+			"@timestamp":           "2021-01-02T03:04:05.123456789Z",
+			"message":              "a",
+			"log.level":            "warn",
+			"log.origin.file.name": "orders/pay.go",
+			"log.origin.file.line": float64(42),
+			"ecs.version":          ECSVersion,
+			"ID":                   float64(1),
+		},
+		{
+			"@timestamp":  "2021-01-03T03:04:05.123456789Z",
+			"message":     "b",
+			"log.level":   "error",
+			"ecs.version": ECSVersion,
+			"err":         "bad",
+		},
+	}, r.docs)
+}
+
+func (s *ElasticsearchSuite) TestFieldNames() {
+	c := s.cluster(ok)
+	defer c.Close()
+
+	w := New(Options{URL: c.URL, BatchSize: 1, TimeKey: "ts", MessageKey: "msg", LevelKey: "level", CallerKey: "caller"})
+	defer w.Close()
+
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Level: log.InfoLevel, Message: "a", Caller: "pay.go:42"}))
+
+	r := s.receive(c)
+	s.Assert().Equal("logs-2021.01.02", r.actions[0]["create"]["_index"])
+	s.Assert().Equal(map[string]interface{}{"ts": "2021-01-02T03:04:05.123456789Z", "msg": "a", "level": "INFO", "caller": "pay.go:42"}, r.docs[0])
+}
+
+func (s *ElasticsearchSuite) TestAuth() {
+	tt := []struct {
+		name    string
+		options Options
+		want    string
+	}{
+		{name: "basic", options: Options{Username: "elastic", Password: "secret"}, want: "Basic ZWxhc3RpYzpzZWNyZXQ="},
+		{name: "api key", options: Options{Username: "elastic", APIKey: "a2V5"}, want: "ApiKey a2V5"},
+		{name: "none", want: ""},
+	}
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			c := s.cluster(ok)
+			defer c.Close()
+
+			t.options.URL = c.URL + "/"
+			t.options.BatchSize = 1
+			w := New(t.options)
+			defer w.Close()
+
+			s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Message: "a"}))
+			s.Assert().Equal(t.want, s.receive(c).header.Get("Authorization"))
+		})
+	}
+}
+
+func (s *ElasticsearchSuite) TestPartialFailure() {
+	c := s.cluster(func(n int, r request) (int, []int) {
+		if n == 1 {
+			// a is indexed, b is throttled and c cannot be indexed
+			return http.StatusOK, []int{http.StatusCreated, http.StatusTooManyRequests, http.StatusBadRequest}
+		}
+		return http.StatusOK, nil
+	})
+	defer c.Close()
+
+	w := New(Options{URL: c.URL, BatchSize: 3, MinBackoff: 10 * time.Millisecond})
+	defer w.Close()
+
+	for _, msg := range []string{"a", "b", "c"} {
+		s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Message: msg}))
+	}
+
+	s.Assert().Len(s.receive(c).docs, 3)
+	retried := s.receive(c)
+	s.Require().Len(retried.docs, 1)
+	s.Assert().Equal("b", retried.docs[0]["message"])
+}
+
+func (s *ElasticsearchSuite) TestRetry() {
+	tt := []struct {
+		name     string
+		status   int
+		requests int
+	}{
+		{name: "too many requests", status: http.StatusTooManyRequests, requests: 2},
+		{name: "server error", status: http.StatusServiceUnavailable, requests: 2},
+		{name: "bad request", status: http.StatusBadRequest, requests: 1},
+	}
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			c := s.cluster(func(n int, r request) (int, []int) {
+				if n == 1 {
+					return t.status, nil
+				}
+				return http.StatusOK, nil
+			})
+			defer c.Close()
+
+			w := New(Options{URL: c.URL, BatchSize: 1, MinBackoff: 10 * time.Millisecond})
+			s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Message: "a"}))
+
+			for i := 0; i < t.requests; i++ {
+				s.receive(c)
+			}
+			s.Require().NoError(w.Close())
+
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			s.Assert().Len(c.requests, t.requests)
+		})
+	}
+}
+
+func (s *ElasticsearchSuite) TestMaxRetries() {
+	c := s.cluster(func(int, request) (int, []int) { return http.StatusOK, []int{http.StatusServiceUnavailable} })
+	defer c.Close()
+
+	w := New(Options{URL: c.URL, BatchSize: 1, MaxRetries: 2, MinBackoff: time.Millisecond})
+	defer w.Close()
+
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Message: "a"}))
+	for i := 0; i < 3; i++ {
+		s.receive(c)
+	}
+	select {
+	case <-c.received:
+		s.Fail("the item was retried more than MaxRetries times")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func (s *ElasticsearchSuite) TestBatchAge() {
+	c := s.cluster(ok)
+	defer c.Close()
+
+	w := New(Options{URL: c.URL, BatchAge: 10 * time.Millisecond})
+	defer w.Close()
+
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Message: "a"}))
+	s.Assert().Equal("a", s.receive(c).docs[0]["message"])
+}
+
+func (s *ElasticsearchSuite) TestCloseFlushes() {
+	c := s.cluster(ok)
+	defer c.Close()
+
+	w := New(Options{URL: c.URL, BatchAge: time.Hour})
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Message: "a"}))
+	s.Require().NoError(w.Close())
+
+	s.Assert().Equal("a", s.receive(c).docs[0]["message"])
+}
+
+func (s *ElasticsearchSuite) TestFlush() {
+	c := s.cluster(ok)
+	defer c.Close()
+
+	w := New(Options{URL: c.URL, BatchAge: time.Hour})
+	defer w.Close()
+
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Message: "a"}))
+	s.Require().NoError(w.Flush())
+	s.Assert().Equal("a", s.receive(c).docs[0]["message"])
+
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Message: "b"}))
+	s.Require().NoError(w.Flush())
+	s.Assert().Equal("b", s.receive(c).docs[0]["message"])
+}
+
+func (s *ElasticsearchSuite) TestBufferFull() {
+	w := New(Options{URL: "http://127.0.0.1:1", BufferSize: 1, BatchSize: 1})
+	defer w.Close()
+
+	var err error
+	for i := 0; i < 10 && err == nil; i++ {
+		err = w.WriteEntry(entry.Entry{Message: "a"})
+	}
+	s.Assert().ErrorIs(err, errBufferFull)
+}
+
+func (s *ElasticsearchSuite) TestIndexName() {
+	local := time.Date(2021, 1, 2, 23, 0, 0, 0, time.FixedZone("BRT", -3*60*60))
+
+	tt := []struct {
+		template string
+		want     string
+	}{
+		{template: "app-logs-2006.01.02", want: "app-logs-2021.01.03"},
+		{template: "app-logs-{2006.01.02}", want: "app-logs-2021.01.03"},
+		{template: "orders-v2-{2006}-{01}", want: "orders-v2-2021-01"},
+		{template: "orders", want: "orders"},
+		{template: "orders-{2006", want: "orders-{2006"},
+	}
+	for _, t := range tt {
+		s.Run(t.template, func() {
+			s.Assert().Equal(t.want, IndexName(t.template, local))
+		})
+	}
+}
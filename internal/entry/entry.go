@@ -0,0 +1,25 @@
+// Package entry defines the log entries handed by the contribs to the outputs
+// that encode entries themselves instead of writing the bytes produced by the
+// backend, such as syslog.
+package entry
+
+import (
+	"time"
+
+	"github.com/americanas-go/log"
+)
+
+// Entry is a log entry, independent of the backend which produced it.
+type Entry struct {
+	Time     time.Time
+	Level    log.Level
+	Message  string
+	Caller   string // file:line, empty when the caller is not recorded
+	Function string // function of the caller, empty when not recorded
+	Fields   log.Fields
+}
+
+// Writer writes entries, encoding them itself.
+type Writer interface {
+	WriteEntry(e Entry) error
+}
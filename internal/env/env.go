@@ -0,0 +1,263 @@
+// Package env loads struct values from environment variables.
+//
+// Variable names are derived from the path of each field in the struct: the
+// Go field names are converted to upper snake case and joined with an
+// underscore after the prefix, so the field File.MaxSize loaded with the
+// prefix "LOG" is read from LOG_FILE_MAX_SIZE. The name of a field can be
+// replaced with an `env:"NAME"` tag and a field is skipped with `env:"-"`.
+// Fields of embedded structs are promoted to the parent path.
+package env
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	fileModeType = reflect.TypeOf(os.FileMode(0))
+)
+
+// Load sets the fields of the struct pointed by v from the environment
+// variables found under prefix. Fields without a variable are left untouched,
+// as are fields whose kind can not be represented by a string (interfaces,
+// functions, channels and pointers).
+//
+// Malformed values are reported together in the returned error and the
+// corresponding fields are left untouched.
+func Load(prefix string, v interface{}) error {
+	_, err := Capture(prefix, v)
+	return err
+}
+
+// Capture loads v as Load does and returns a function setting the fields of
+// the struct it is given, of the type of v, to the values loaded in v. The
+// function does not read the environment again, and leaves the fields without
+// a variable, or with a malformed one, untouched.
+func Capture(prefix string, v interface{}) (func(dst interface{}), error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("env: a pointer to struct is required, got %T", v)
+	}
+
+	prefix = strings.ToUpper(prefix)
+	values := map[string]reflect.Value{}
+	var errs []error
+	walk(prefix, rv.Elem(), func(name string, field reflect.Value) {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return
+		}
+
+		if err := set(field, strings.TrimSpace(value)); err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid value %q: %w", name, value, err))
+			return
+		}
+		values[name] = clone(field)
+	})
+
+	apply := func(dst interface{}) {
+		walk(prefix, reflect.ValueOf(dst).Elem(), func(name string, field reflect.Value) {
+			if value, ok := values[name]; ok {
+				field.Set(clone(value))
+			}
+		})
+	}
+	return apply, errors.Join(errs...)
+}
+
+// Names returns the environment variable names read by Load for the struct
+// pointed by v, in declaration order.
+func Names(prefix string, v interface{}) []string {
+	var names []string
+	walk(strings.ToUpper(prefix), reflect.ValueOf(v).Elem(), func(name string, _ reflect.Value) {
+		names = append(names, name)
+	})
+	return names
+}
+
+// Name joins prefix and the upper snake case form of each path element.
+func Name(prefix string, path ...string) string {
+	parts := make([]string, 0, len(path)+1)
+	if prefix != "" {
+		parts = append(parts, prefix)
+	}
+	for _, p := range path {
+		parts = append(parts, snake(p))
+	}
+	return strings.Join(parts, "_")
+}
+
+// clone copies v, with its own backing array or map when v is a slice or a
+// map.
+func clone(v reflect.Value) reflect.Value {
+	switch {
+	case v.Kind() == reflect.Slice && !v.IsNil():
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(c, v)
+		return c
+	case v.Kind() == reflect.Map && !v.IsNil():
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			c.SetMapIndex(iter.Key(), iter.Value())
+		}
+		return c
+	default:
+		return reflect.ValueOf(v.Interface())
+	}
+}
+
+func walk(prefix string, v reflect.Value, fn func(name string, field reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("env")
+		if tag == "-" {
+			continue
+		}
+
+		field := v.Field(i)
+		if sf.Anonymous && field.Kind() == reflect.Struct {
+			walk(prefix, field, fn)
+			continue
+		}
+
+		if !sf.IsExported() {
+			continue
+		}
+
+		name := Name(prefix, sf.Name)
+		if tag != "" {
+			name = prefix + sep(prefix) + tag
+		}
+
+		switch {
+		case field.Kind() == reflect.Struct && field.Type() != durationType:
+			walk(name, field, fn)
+		case supported(field.Type()):
+			fn(name, field)
+		}
+	}
+}
+
+func sep(prefix string) string {
+	if prefix == "" {
+		return ""
+	}
+	return "_"
+}
+
+func supported(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.String
+	case reflect.Map:
+		return t.Key().Kind() == reflect.String && t.Elem().Kind() == reflect.String
+	default:
+		return false
+	}
+}
+
+func set(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.Unwrap(err)
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if field.Type() == durationType {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return err
+			}
+			field.SetInt(int64(d))
+			return nil
+		}
+		i, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return errors.Unwrap(err)
+		}
+		field.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		base := 10
+		if field.Type() == fileModeType {
+			base = 8
+		}
+		u, err := strconv.ParseUint(value, base, field.Type().Bits())
+		if err != nil {
+			return errors.Unwrap(err)
+		}
+		field.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return errors.Unwrap(err)
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		items := split(value)
+		s := reflect.MakeSlice(field.Type(), len(items), len(items))
+		for i, item := range items {
+			s.Index(i).SetString(item)
+		}
+		field.Set(s)
+	case reflect.Map:
+		m := reflect.MakeMap(field.Type())
+		for _, item := range split(value) {
+			k, v, ok := strings.Cut(item, "=")
+			if !ok {
+				return fmt.Errorf("entry %q is not in key=value form", item)
+			}
+			m.SetMapIndex(reflect.ValueOf(strings.TrimSpace(k)).Convert(field.Type().Key()),
+				reflect.ValueOf(strings.TrimSpace(v)).Convert(field.Type().Elem()))
+		}
+		field.Set(m)
+	}
+
+	return nil
+}
+
+func split(value string) []string {
+	if value == "" {
+		return []string{}
+	}
+
+	items := strings.Split(value, ",")
+	for i := range items {
+		items[i] = strings.TrimSpace(items[i])
+	}
+	return items
+}
+
+// snake converts a Go identifier such as MaxSize or TLSConfig to MAX_SIZE or
+// TLS_CONFIG.
+func snake(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
@@ -0,0 +1,159 @@
+package env
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type EnvSuite struct {
+	suite.Suite
+}
+
+func TestEnvSuite(t *testing.T) {
+	suite.Run(t, new(EnvSuite))
+}
+
+type embedded struct {
+	Shared string
+}
+
+type options struct {
+	embedded
+	Level    string
+	Disabled bool
+	Timeout  time.Duration
+	Mode     os.FileMode
+	Ratio    float64
+	Keys     []string
+	Labels   map[string]string
+	Renamed  string `env:"OTHER_NAME"`
+	Skipped  string `env:"-"`
+	Writer   interface{}
+	File     struct {
+		MaxSize int
+		TLSCert string
+	}
+	private string
+}
+
+func (s *EnvSuite) TestLoad() {
+	s.T().Setenv("APP_SHARED", "shared")
+	s.T().Setenv("APP_LEVEL", "DEBUG")
+	s.T().Setenv("APP_DISABLED", "true")
+	s.T().Setenv("APP_TIMEOUT", "1m30s")
+	s.T().Setenv("APP_MODE", "0640")
+	s.T().Setenv("APP_RATIO", "0.5")
+	s.T().Setenv("APP_KEYS", "request_id, trace_id")
+	s.T().Setenv("APP_LABELS", "app=api, env=prod")
+	s.T().Setenv("APP_OTHER_NAME", "renamed")
+	s.T().Setenv("APP_SKIPPED", "skipped")
+	s.T().Setenv("APP_FILE_MAX_SIZE", " 10 ")
+	s.T().Setenv("APP_FILE_TLS_CERT", "/cert.pem")
+
+	got := options{}
+	s.Require().NoError(Load("app", &got))
+
+	s.Assert().Equal("shared", got.Shared)
+	s.Assert().Equal("DEBUG", got.Level)
+	s.Assert().True(got.Disabled)
+	s.Assert().Equal(90*time.Second, got.Timeout)
+	s.Assert().Equal(os.FileMode(0640), got.Mode)
+	s.Assert().Equal(0.5, got.Ratio)
+	s.Assert().Equal([]string{"request_id", "trace_id"}, got.Keys)
+	s.Assert().Equal(map[string]string{"app": "api", "env": "prod"}, got.Labels)
+	s.Assert().Equal("renamed", got.Renamed)
+	s.Assert().Empty(got.Skipped)
+	s.Assert().Equal(10, got.File.MaxSize)
+	s.Assert().Equal("/cert.pem", got.File.TLSCert)
+}
+
+func (s *EnvSuite) TestLoadKeepsUnsetFields() {
+	got := options{Level: "INFO"}
+	got.File.MaxSize = 100
+
+	s.Require().NoError(Load("UNSET_PREFIX", &got))
+	s.Assert().Equal("INFO", got.Level)
+	s.Assert().Equal(100, got.File.MaxSize)
+}
+
+func (s *EnvSuite) TestLoadMalformed() {
+	s.T().Setenv("BAD_DISABLED", "maybe")
+	s.T().Setenv("BAD_FILE_MAX_SIZE", "ten")
+	s.T().Setenv("BAD_TIMEOUT", "soon")
+	s.T().Setenv("BAD_LABELS", "app")
+	s.T().Setenv("BAD_LEVEL", "WARN")
+
+	got := options{}
+	err := Load("BAD", &got)
+	s.Require().Error(err)
+	s.Assert().Contains(err.Error(), `BAD_DISABLED: invalid value "maybe"`)
+	s.Assert().Contains(err.Error(), `BAD_FILE_MAX_SIZE: invalid value "ten"`)
+	s.Assert().Contains(err.Error(), `BAD_TIMEOUT: invalid value "soon"`)
+	s.Assert().Contains(err.Error(), `BAD_LABELS: invalid value "app"`)
+	s.Assert().Equal("WARN", got.Level)
+	s.Assert().Equal(0, got.File.MaxSize)
+}
+
+func (s *EnvSuite) TestCapture() {
+	s.T().Setenv("CAPTURE_LEVEL", "DEBUG")
+	s.T().Setenv("CAPTURE_KEYS", "request_id")
+
+	loaded := options{}
+	apply, err := Capture("CAPTURE", &loaded)
+	s.Require().NoError(err)
+	s.Assert().Equal("DEBUG", loaded.Level)
+
+	// the values are not read again, and the unset fields are left untouched
+	s.T().Setenv("CAPTURE_LEVEL", "WARN")
+	got := options{Renamed: "kept"}
+	apply(&got)
+	s.Assert().Equal("DEBUG", got.Level)
+	s.Assert().Equal([]string{"request_id"}, got.Keys)
+	s.Assert().Equal("kept", got.Renamed)
+
+	// the slices are not shared
+	got.Keys[0] = "changed"
+	other := options{}
+	apply(&other)
+	s.Assert().Equal([]string{"request_id"}, other.Keys)
+}
+
+func (s *EnvSuite) TestLoadRequiresStructPointer() {
+	s.Assert().Error(Load("APP", options{}))
+}
+
+func (s *EnvSuite) TestNames() {
+	got := Names("log", &options{})
+	s.Assert().Equal([]string{
+		"LOG_SHARED",
+		"LOG_LEVEL",
+		"LOG_DISABLED",
+		"LOG_TIMEOUT",
+		"LOG_MODE",
+		"LOG_RATIO",
+		"LOG_KEYS",
+		"LOG_LABELS",
+		"LOG_OTHER_NAME",
+		"LOG_FILE_MAX_SIZE",
+		"LOG_FILE_TLS_CERT",
+	}, got)
+}
+
+func (s *EnvSuite) TestName() {
+	tt := []struct {
+		prefix string
+		path   []string
+		want   string
+	}{
+		{prefix: "LOG", path: []string{"ErrorFieldName"}, want: "LOG_ERROR_FIELD_NAME"},
+		{prefix: "", path: []string{"Console", "Level"}, want: "CONSOLE_LEVEL"},
+		{prefix: "LOG", path: []string{"TLSConfig"}, want: "LOG_TLS_CONFIG"},
+		{prefix: "LOG", path: []string{"MaxSize2Go"}, want: "LOG_MAX_SIZE2_GO"},
+	}
+	for _, t := range tt {
+		s.Assert().Equal(t.want, Name(t.prefix, t.path...))
+	}
+}
@@ -0,0 +1,493 @@
+// Package forward sends entries to Fluentd or Fluent Bit with the Forward
+// protocol, as described by
+// https://github.com/fluent/fluentd/wiki/Forward-Protocol-Specification-v1.
+//
+// Entries are queued and sent by a background goroutine, in the Message,
+// Forward or PackedForward mode. The last two send batches, flushed when they
+// reach the batch size or every flush interval. When acknowledgements are
+// required, each message carries a chunk id and is sent again until the
+// aggregator acknowledges it. Failed connections are reopened with an
+// exponential backoff, a message being dropped after the maximum retries. The
+// fields named like the message, level or caller keys are prefixed with
+// fields. in the records. The handshake of secure forward inputs is not
+// supported.
+package forward
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/americanas-go/log/internal/msgpack"
+	"github.com/americanas-go/log/internal/order"
+)
+
+// Modes.
+const (
+	ModeMessage       = "MESSAGE"
+	ModeForward       = "FORWARD"
+	ModePackedForward = "PACKED_FORWARD"
+)
+
+const (
+	defaultTag           = "app"
+	defaultBatchSize     = 100
+	defaultFlushInterval = time.Second
+	defaultBufferSize    = 1024
+	defaultAckTimeout    = 5 * time.Second
+	defaultMaxRetries    = 10
+	defaultMinBackoff    = 100 * time.Millisecond
+	defaultMaxBackoff    = 30 * time.Second
+
+	// reservedPrefix prefixes the fields named like the keys written by the
+	// writer.
+	reservedPrefix = "fields."
+
+	// eventTimeType is the extension type of the EventTime, holding
+	// nanoseconds.
+	eventTimeType = 0
+
+	dialTimeout  = 5 * time.Second
+	writeTimeout = 5 * time.Second
+	closeTimeout = 5 * time.Second
+)
+
+var (
+	// Modes are the supported modes.
+	Modes = []string{ModeMessage, ModeForward, ModePackedForward}
+
+	errBufferFull = errors.New("forward: buffer is full, entry dropped")
+)
+
+// Options configures a Writer. Zero values take a default.
+type Options struct {
+	Address       string        // host:port of the forward input
+	Tag           string        // tag of the entries, app when empty
+	TagField      string        // field holding the tag of an entry, removed from its record
+	Mode          string        // MESSAGE/FORWARD/PACKED_FORWARD, FORWARD when empty
+	RequireAck    bool          // wait for the acknowledgement of each message
+	AckTimeout    time.Duration // longest wait for an acknowledgement, 5s when zero
+	BatchSize     int           // entries of a batch, 100 when zero
+	FlushInterval time.Duration // longest wait before a batch is sent, 1s when zero
+	BufferSize    int           // entries waiting to be sent, 1024 when zero
+	MaxRetries    int           // retries of a message, 10 when zero
+	MinBackoff    time.Duration // first delay between reconnections, 100ms when zero
+	MaxBackoff    time.Duration // longest delay between reconnections, 30s when zero
+
+	// keys of the message, level and caller in the records, the level and the
+	// caller are omitted when empty
+	MessageKey string
+	LevelKey   string
+	CallerKey  string
+
+	PriorityKeys []string // fields written first, in this order, the others being sorted by key
+}
+
+// event is an encoded entry.
+type event struct {
+	tag    string
+	time   []byte
+	record []byte
+}
+
+// Writer sends entries to a forward input.
+type Writer struct {
+	options Options
+
+	events  chan event
+	flushes chan chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+	closed  sync.Once
+
+	conn net.Conn
+}
+
+// New returns a Writer from options and starts sending.
+func New(options Options) *Writer {
+	if options.Tag == "" {
+		options.Tag = defaultTag
+	}
+	if options.Mode != ModeMessage && options.Mode != ModePackedForward {
+		options.Mode = ModeForward
+	}
+	if options.AckTimeout <= 0 {
+		options.AckTimeout = defaultAckTimeout
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = defaultBatchSize
+	}
+	if options.FlushInterval <= 0 {
+		options.FlushInterval = defaultFlushInterval
+	}
+	if options.BufferSize <= 0 {
+		options.BufferSize = defaultBufferSize
+	}
+	if options.MaxRetries <= 0 {
+		options.MaxRetries = defaultMaxRetries
+	}
+	if options.MinBackoff <= 0 {
+		options.MinBackoff = defaultMinBackoff
+	}
+	if options.MaxBackoff < options.MinBackoff {
+		options.MaxBackoff = max(defaultMaxBackoff, options.MinBackoff)
+	}
+	if options.MessageKey == "" {
+		options.MessageKey = "message"
+	}
+
+	w := &Writer{
+		options: options,
+		events:  make(chan event, options.BufferSize),
+		flushes: make(chan chan struct{}),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+
+	go w.run()
+	return w
+}
+
+// WriteEntry implements entry.Writer. It queues e and fails when the buffer is
+// full.
+func (w *Writer) WriteEntry(e entry.Entry) error {
+	select {
+	case w.events <- w.encode(e):
+		return nil
+	default:
+		return errBufferFull
+	}
+}
+
+// Flush sends the pending batches, the queued entries included, and waits for
+// them to be sent, acknowledgements included, for up to 5 seconds.
+func (w *Writer) Flush() error {
+	flushed := make(chan struct{})
+	timeout := time.After(closeTimeout)
+
+	select {
+	case w.flushes <- flushed:
+	case <-w.stopped:
+		return nil
+	case <-timeout:
+		return errors.New("forward: timed out sending the pending entries")
+	}
+
+	select {
+	case <-flushed:
+		return nil
+	case <-w.stopped:
+		return nil
+	case <-timeout:
+		return errors.New("forward: timed out sending the pending entries")
+	}
+}
+
+// Close sends the pending batches, trying once, and stops sending.
+func (w *Writer) Close() error {
+	w.closed.Do(func() { close(w.done) })
+
+	select {
+	case <-w.stopped:
+		return nil
+	case <-time.After(closeTimeout):
+		return errors.New("forward: timed out sending the pending entries")
+	}
+}
+
+func (w *Writer) encode(e entry.Entry) event {
+	tag := w.options.Tag
+	fields := e.Fields
+	if w.options.TagField != "" {
+		if t, ok := fields[w.options.TagField].(string); ok && t != "" {
+			tag = t
+		}
+	}
+
+	n := 1
+	for k := range fields {
+		if k != w.options.TagField {
+			n++
+		}
+	}
+	if w.options.LevelKey != "" {
+		n++
+	}
+	if w.options.CallerKey != "" && e.Caller != "" {
+		n++
+	}
+
+	record := msgpack.AppendMapHeader(nil, n)
+	for _, k := range order.Keys(fields, w.options.PriorityKeys) {
+		if k != w.options.TagField {
+			record = msgpack.AppendString(record, w.fieldName(k, e))
+			record = msgpack.AppendValue(record, fields[k])
+		}
+	}
+	if w.options.LevelKey != "" {
+		record = msgpack.AppendString(record, w.options.LevelKey)
+		record = msgpack.AppendString(record, e.Level.String())
+	}
+	if w.options.CallerKey != "" && e.Caller != "" {
+		record = msgpack.AppendString(record, w.options.CallerKey)
+		record = msgpack.AppendString(record, e.Caller)
+	}
+	record = msgpack.AppendString(record, w.options.MessageKey)
+	record = msgpack.AppendString(record, e.Message)
+
+	t := e.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	data := binary.BigEndian.AppendUint32(nil, uint32(t.Unix()))
+	data = binary.BigEndian.AppendUint32(data, uint32(t.Nanosecond()))
+
+	return event{tag: tag, time: msgpack.AppendExt(nil, eventTimeType, data), record: record}
+}
+
+// fieldName returns the key of the field k of e in its record, prefixed with
+// fields. while the writer or another field of e uses it, so that the record
+// has no duplicate keys.
+func (w *Writer) fieldName(k string, e entry.Entry) string {
+	taken := func(name string) bool {
+		_, ok := e.Fields[name]
+		return ok
+	}
+
+	name := k
+	for w.reserved(name, e) || name != k && taken(name) {
+		name = reservedPrefix + name
+	}
+	return name
+}
+
+// reserved tells whether the writer writes the key name in the record of e.
+func (w *Writer) reserved(name string, e entry.Entry) bool {
+	return name == w.options.MessageKey ||
+		w.options.LevelKey != "" && name == w.options.LevelKey ||
+		w.options.CallerKey != "" && e.Caller != "" && name == w.options.CallerKey
+}
+
+func (w *Writer) run() {
+	defer close(w.stopped)
+	defer w.disconnect()
+
+	ticker := time.NewTicker(w.options.FlushInterval)
+	defer ticker.Stop()
+
+	var tags []string
+	batches := map[string][]event{}
+	add := func(ev event) {
+		if _, ok := batches[ev.tag]; !ok {
+			tags = append(tags, ev.tag)
+		}
+		batches[ev.tag] = append(batches[ev.tag], ev)
+	}
+
+	for {
+		var flushed chan struct{}
+		select {
+		case <-w.done:
+			// the pending events are sent once, the queued ones included
+			for len(w.events) > 0 {
+				add(<-w.events)
+			}
+			for _, tag := range tags {
+				for _, msg := range w.messages(tag, batches[tag]) {
+					w.deliverOnce(msg)
+				}
+			}
+			return
+		case ev := <-w.events:
+			add(ev)
+			if w.options.Mode != ModeMessage && len(batches[ev.tag]) < w.options.BatchSize {
+				continue
+			}
+		case flushed = <-w.flushes:
+			for len(w.events) > 0 {
+				add(<-w.events)
+			}
+		case <-ticker.C:
+		}
+
+		for _, tag := range tags {
+			if !w.flush(tag, batches[tag]) {
+				return
+			}
+		}
+		tags = tags[:0]
+		clear(batches)
+		if flushed != nil {
+			close(flushed)
+		}
+	}
+}
+
+// flush sends the events of tag. It returns false when the writer was closed
+// meanwhile.
+func (w *Writer) flush(tag string, events []event) bool {
+	for _, msg := range w.messages(tag, events) {
+		if !w.deliver(msg) {
+			return false
+		}
+	}
+	return true
+}
+
+// message is an encoded forward message, with its chunk id when an
+// acknowledgement is required.
+type message struct {
+	data  []byte
+	chunk string
+}
+
+// messages encodes events in the messages of the mode: one per event in the
+// Message mode, one per batch otherwise.
+func (w *Writer) messages(tag string, events []event) []message {
+	if len(events) == 0 {
+		return nil
+	}
+
+	if w.options.Mode == ModeMessage {
+		msgs := make([]message, 0, len(events))
+		for _, ev := range events {
+			chunk := w.chunk()
+			size := 3
+			if chunk != "" {
+				size = 4
+			}
+			data := msgpack.AppendArrayHeader(nil, size)
+			data = msgpack.AppendString(data, tag)
+			data = append(data, ev.time...)
+			data = append(data, ev.record...)
+			if chunk != "" {
+				data = msgpack.AppendMapHeader(data, 1)
+				data = msgpack.AppendString(data, "chunk")
+				data = msgpack.AppendString(data, chunk)
+			}
+			msgs = append(msgs, message{data: data, chunk: chunk})
+		}
+		return msgs
+	}
+
+	var entries []byte
+	if w.options.Mode == ModeForward {
+		entries = msgpack.AppendArrayHeader(entries, len(events))
+	}
+	for _, ev := range events {
+		entries = msgpack.AppendArrayHeader(entries, 2)
+		entries = append(entries, ev.time...)
+		entries = append(entries, ev.record...)
+	}
+
+	chunk := w.chunk()
+	data := msgpack.AppendArrayHeader(nil, 3)
+	data = msgpack.AppendString(data, tag)
+	if w.options.Mode == ModePackedForward {
+		data = msgpack.AppendBytes(data, entries)
+	} else {
+		data = append(data, entries...)
+	}
+
+	if chunk != "" {
+		data = msgpack.AppendMapHeader(data, 2)
+		data = msgpack.AppendString(data, "chunk")
+		data = msgpack.AppendString(data, chunk)
+	} else {
+		data = msgpack.AppendMapHeader(data, 1)
+	}
+	data = msgpack.AppendString(data, "size")
+	data = msgpack.AppendInt(data, int64(len(events)))
+
+	return []message{{data: data, chunk: chunk}}
+}
+
+// chunk returns a new chunk id when acknowledgements are required.
+func (w *Writer) chunk() string {
+	if !w.options.RequireAck {
+		return ""
+	}
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return base64.StdEncoding.EncodeToString(id)
+}
+
+// deliver sends msg, reconnecting up to the maximum retries, after which msg
+// is dropped. It returns false when the writer was closed meanwhile.
+func (w *Writer) deliver(msg message) bool {
+	backoff := w.options.MinBackoff
+	for retry := 0; ; retry++ {
+		if err := w.send(msg); err == nil {
+			return true
+		}
+
+		w.disconnect()
+		if retry == w.options.MaxRetries {
+			return true
+		}
+		select {
+		case <-w.done:
+			// the message is tried once more on close
+			w.deliverOnce(msg)
+			return false
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, w.options.MaxBackoff)
+	}
+}
+
+func (w *Writer) deliverOnce(msg message) {
+	if err := w.send(msg); err != nil {
+		w.disconnect()
+	}
+}
+
+func (w *Writer) send(msg message) error {
+	if w.conn == nil {
+		conn, err := net.DialTimeout("tcp", w.options.Address, dialTimeout)
+		if err != nil {
+			return err
+		}
+		w.conn = conn
+	}
+
+	if err := w.conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+		return err
+	}
+	if _, err := w.conn.Write(msg.data); err != nil {
+		return err
+	}
+
+	if msg.chunk == "" {
+		return nil
+	}
+	return w.waitAck(msg.chunk)
+}
+
+func (w *Writer) waitAck(chunk string) error {
+	if err := w.conn.SetReadDeadline(time.Now().Add(w.options.AckTimeout)); err != nil {
+		return err
+	}
+
+	v, err := msgpack.NewDecoder(w.conn).Decode()
+	if err != nil {
+		return err
+	}
+	if response, ok := v.(map[string]interface{}); !ok || response["ack"] != chunk {
+		return fmt.Errorf("forward: unexpected acknowledgement %v", v)
+	}
+	return nil
+}
+
+func (w *Writer) disconnect() {
+	if w.conn != nil {
+		w.conn.Close()
+		w.conn = nil
+	}
+}
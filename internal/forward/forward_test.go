@@ -0,0 +1,309 @@
+package forward
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/americanas-go/log/internal/msgpack"
+	"github.com/stretchr/testify/suite"
+)
+
+type ForwardSuite struct {
+	suite.Suite
+}
+
+func TestForwardSuite(t *testing.T) {
+	suite.Run(t, new(ForwardSuite))
+}
+
+var at = time.Date(2021, 1, 2, 3, 4, 5, 123456789, time.UTC)
+
+// received is a message decoded by the aggregator.
+type received struct {
+	tag     string
+	records []map[string]interface{}
+	times   []time.Time
+	option  map[string]interface{}
+}
+
+// aggregator is an in-process forward input. It acknowledges the chunks,
+// after dropping the first connections when told to.
+type aggregator struct {
+	ln       net.Listener
+	messages chan received
+	drop     int
+}
+
+func (s *ForwardSuite) listen(drop int) *aggregator {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+
+	a := &aggregator{ln: ln, messages: make(chan received, 100), drop: drop}
+	go a.serve()
+	return a
+}
+
+func (a *aggregator) serve() {
+	for {
+		conn, err := a.ln.Accept()
+		if err != nil {
+			return
+		}
+		drop := a.drop > 0
+		a.drop--
+		go a.handle(conn, drop)
+	}
+}
+
+func (a *aggregator) handle(conn net.Conn, drop bool) {
+	defer conn.Close()
+
+	d := msgpack.NewDecoder(bufio.NewReader(conn))
+	for {
+		v, err := d.Decode()
+		if err != nil {
+			return
+		}
+		if drop {
+			// the message is lost before being acknowledged
+			return
+		}
+
+		msg := decodeMessage(v.([]interface{}))
+		a.messages <- msg
+
+		if chunk, ok := msg.option["chunk"]; ok {
+			if _, err := conn.Write(msgpack.AppendValue(nil, map[string]interface{}{"ack": chunk})); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func decodeMessage(v []interface{}) received {
+	msg := received{tag: v[0].(string)}
+
+	var entries []interface{}
+	switch e := v[1].(type) {
+	case msgpack.Ext:
+		// Message mode: tag, time, record, option
+		entries = []interface{}{[]interface{}{e, v[2]}}
+		v = append([]interface{}{v[0], nil}, v[3:]...)
+	case []interface{}:
+		entries = e
+	case []byte:
+		d := msgpack.NewDecoder(bytes.NewReader(e))
+		for {
+			entry, err := d.Decode()
+			if err != nil {
+				break
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	for _, e := range entries {
+		pair := e.([]interface{})
+		ext := pair[0].(msgpack.Ext)
+		sec, nsec := binary.BigEndian.Uint32(ext.Data), binary.BigEndian.Uint32(ext.Data[4:])
+		msg.times = append(msg.times, time.Unix(int64(sec), int64(nsec)).UTC())
+		msg.records = append(msg.records, pair[1].(map[string]interface{}))
+	}
+	if len(v) > 2 {
+		msg.option, _ = v[2].(map[string]interface{})
+	}
+	return msg
+}
+
+func (s *ForwardSuite) receive(a *aggregator) received {
+	select {
+	case msg := <-a.messages:
+		return msg
+	case <-time.After(5 * time.Second):
+		s.FailNow("no message received")
+		return received{}
+	}
+}
+
+func (s *ForwardSuite) TestModes() {
+	for _, mode := range Modes {
+		s.Run(mode, func() {
+			a := s.listen(0)
+			defer a.ln.Close()
+
+			w := New(Options{Address: a.ln.Addr().String(), Tag: "orders", Mode: mode, BatchSize: 2, LevelKey: "level"})
+			defer w.Close()
+
+			s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Level: log.InfoLevel, Message: "a", Fields: log.Fields{"ID": 1}}))
+			s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Level: log.WarnLevel, Message: "b"}))
+
+			var records []map[string]interface{}
+			for len(records) < 2 {
+				msg := s.receive(a)
+				s.Assert().Equal("orders", msg.tag)
+				s.Assert().Equal(at, msg.times[0])
+				if mode != ModeMessage {
+					s.Assert().Equal(int64(2), msg.option["size"])
+				}
+				records = append(records, msg.records...)
+			}
+
+			s.Assert().Equal([]map[string]interface{}{
+				{"ID": int64(1), "level": "INFO", "message": "a"},
+				{"level": "WARN", "message": "b"},
+			}, records)
+		})
+	}
+}
+
+func (s *ForwardSuite) TestTagField() {
+	a := s.listen(0)
+	defer a.ln.Close()
+
+	w := New(Options{Address: a.ln.Addr().String(), TagField: "tag", Mode: ModeMessage})
+	defer w.Close()
+
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Message: "a", Fields: log.Fields{"tag": "audit"}}))
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Message: "b"}))
+
+	msg := s.receive(a)
+	s.Assert().Equal("audit", msg.tag)
+	s.Assert().Equal(map[string]interface{}{"message": "a"}, msg.records[0])
+	s.Assert().Equal("app", s.receive(a).tag)
+}
+
+func (s *ForwardSuite) TestFlushInterval() {
+	a := s.listen(0)
+	defer a.ln.Close()
+
+	w := New(Options{Address: a.ln.Addr().String(), FlushInterval: 10 * time.Millisecond})
+	defer w.Close()
+
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Message: "a"}))
+	s.Assert().Equal(map[string]interface{}{"message": "a"}, s.receive(a).records[0])
+}
+
+func (s *ForwardSuite) TestAckResendsLostChunks() {
+	a := s.listen(1)
+	defer a.ln.Close()
+
+	w := New(Options{
+		Address:    a.ln.Addr().String(),
+		RequireAck: true,
+		AckTimeout: time.Second,
+		BatchSize:  1,
+		MinBackoff: 10 * time.Millisecond,
+	})
+	defer w.Close()
+
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Message: "a"}))
+
+	msg := s.receive(a)
+	s.Assert().NotEmpty(msg.option["chunk"])
+	s.Assert().Equal(map[string]interface{}{"message": "a"}, msg.records[0])
+}
+
+func (s *ForwardSuite) TestCloseFlushes() {
+	a := s.listen(0)
+	defer a.ln.Close()
+
+	w := New(Options{Address: a.ln.Addr().String(), FlushInterval: time.Hour})
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Message: "a"}))
+	s.Require().NoError(w.Close())
+
+	s.Assert().Equal(map[string]interface{}{"message": "a"}, s.receive(a).records[0])
+}
+
+func (s *ForwardSuite) TestFlush() {
+	a := s.listen(0)
+	defer a.ln.Close()
+
+	w := New(Options{Address: a.ln.Addr().String(), FlushInterval: time.Hour})
+	defer w.Close()
+
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Message: "a"}))
+	s.Require().NoError(w.Flush())
+	s.Assert().Equal(map[string]interface{}{"message": "a"}, s.receive(a).records[0])
+
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Message: "b"}))
+	s.Require().NoError(w.Flush())
+	s.Assert().Equal(map[string]interface{}{"message": "b"}, s.receive(a).records[0])
+}
+
+func (s *ForwardSuite) TestPriorityKeys() {
+	w := New(Options{Address: "127.0.0.1:1", LevelKey: "level", PriorityKeys: []string{"c", "b"}})
+	defer w.Close()
+
+	ev := w.encode(entry.Entry{Time: at, Level: log.InfoLevel, Message: "hello", Fields: log.Fields{"a": 1, "b": 2, "c": 3}})
+	got, err := msgpack.NewDecoder(bytes.NewReader(ev.record)).DecodeMap()
+	s.Require().NoError(err)
+	s.Assert().Equal([]msgpack.Pair{
+		{Key: "c", Value: int64(3)},
+		{Key: "b", Value: int64(2)},
+		{Key: "a", Value: int64(1)},
+		{Key: "level", Value: "INFO"},
+		{Key: "message", Value: "hello"},
+	}, got)
+}
+
+func (s *ForwardSuite) TestReservedFields() {
+	w := New(Options{Address: "127.0.0.1:1", LevelKey: "level", CallerKey: "caller"})
+	defer w.Close()
+
+	ev := w.encode(entry.Entry{
+		Time:    at,
+		Level:   log.InfoLevel,
+		Message: "hello",
+		Caller:  "main.go:1",
+		Fields:  log.Fields{"message": "a", "level": "b", "fields.level": "c", "caller": "d"},
+	})
+	got, err := msgpack.NewDecoder(bytes.NewReader(ev.record)).DecodeMap()
+	s.Require().NoError(err)
+	s.Assert().Equal([]msgpack.Pair{
+		{Key: "fields.caller", Value: "d"},
+		{Key: "fields.level", Value: "c"},
+		{Key: "fields.fields.level", Value: "b"},
+		{Key: "fields.message", Value: "a"},
+		{Key: "level", Value: "INFO"},
+		{Key: "caller", Value: "main.go:1"},
+		{Key: "message", Value: "hello"},
+	}, got)
+}
+
+func (s *ForwardSuite) TestDropsAfterMaxRetries() {
+	a := s.listen(2)
+	defer a.ln.Close()
+
+	w := New(Options{
+		Address:    a.ln.Addr().String(),
+		RequireAck: true,
+		AckTimeout: time.Second,
+		BatchSize:  1,
+		MaxRetries: 1,
+		MinBackoff: 10 * time.Millisecond,
+	})
+	defer w.Close()
+
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Message: "a"}))
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Message: "b"}))
+
+	// a is lost with the first connection and its retry
+	s.Assert().Equal(map[string]interface{}{"message": "b"}, s.receive(a).records[0])
+}
+
+func (s *ForwardSuite) TestBufferFull() {
+	w := New(Options{Address: "127.0.0.1:1", BufferSize: 1, BatchSize: 1})
+	defer w.Close()
+
+	var err error
+	for i := 0; i < 10 && err == nil; i++ {
+		err = w.WriteEntry(entry.Entry{Message: "a"})
+	}
+	s.Assert().ErrorIs(err, errBufferFull)
+}
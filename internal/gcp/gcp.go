@@ -0,0 +1,267 @@
+// Package gcp encodes entries as the structured JSON of Google Cloud Logging,
+// described by https://cloud.google.com/logging/docs/structured-logging, which
+// the logging agents of GKE, Cloud Run and App Engine read from the standard
+// outputs.
+//
+// Each entry is a line starting with timestamp, severity and message, followed
+// by the special fields of Cloud Logging: the caller as
+// logging.googleapis.com/sourceLocation, the trace id as
+// logging.googleapis.com/trace, in the projects/<project>/traces/<id> form
+// which links the entry to Cloud Trace, the span id as
+// logging.googleapis.com/spanId and the HTTP request field as httpRequest. The
+// other fields follow, sorted by key, and end up in the jsonPayload.
+package gcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/americanas-go/log/internal/order"
+)
+
+// Default keys of the fields moved to the special fields.
+const (
+	DefaultTraceIDKey     = "trace_id"
+	DefaultSpanIDKey      = "span_id"
+	DefaultHTTPRequestKey = "httpRequest"
+)
+
+// Special fields of Cloud Logging.
+const (
+	SourceLocationKey = "logging.googleapis.com/sourceLocation"
+	TraceKey          = "logging.googleapis.com/trace"
+	SpanIDKey         = "logging.googleapis.com/spanId"
+	HTTPRequestKey    = "httpRequest"
+)
+
+// keys of the fields written first, the fields with the same keys are dropped.
+var reserved = map[string]bool{
+	"timestamp":       true,
+	"severity":        true,
+	"message":         true,
+	SourceLocationKey: true,
+	TraceKey:          true,
+	SpanIDKey:         true,
+}
+
+// Options configures the encoding. Empty keys take a default.
+type Options struct {
+	ProjectID      string // project of the traces, the trace id is written as is when empty
+	TraceIDKey     string // key of the trace id, written as logging.googleapis.com/trace
+	SpanIDKey      string // key of the span id, written as logging.googleapis.com/spanId
+	HTTPRequestKey string // key of the HTTP request, written as httpRequest
+
+	PriorityKeys []string // fields written first, in this order, the others being sorted by key
+}
+
+func (o Options) withDefaults() Options {
+	if o.TraceIDKey == "" {
+		o.TraceIDKey = DefaultTraceIDKey
+	}
+	if o.SpanIDKey == "" {
+		o.SpanIDKey = DefaultSpanIDKey
+	}
+	if o.HTTPRequestKey == "" {
+		o.HTTPRequestKey = DefaultHTTPRequestKey
+	}
+	return o
+}
+
+// Severity returns the LogSeverity of level. Cloud Logging has no trace
+// level, trace entries are DEBUG ones, and panics and fatal errors are both
+// CRITICAL.
+func Severity(level log.Level) string {
+	switch level {
+	case log.TraceLevel, log.DebugLevel:
+		return "DEBUG"
+	case log.InfoLevel:
+		return "INFO"
+	case log.WarnLevel:
+		return "WARNING"
+	case log.ErrorLevel:
+		return "ERROR"
+	case log.PanicLevel, log.FatalLevel:
+		return "CRITICAL"
+	default:
+		return "DEFAULT"
+	}
+}
+
+// Writer writes entries as Cloud Logging JSON lines to an io.Writer.
+type Writer struct {
+	mu      sync.Mutex
+	w       io.Writer
+	options Options
+}
+
+// NewWriter returns a Writer writing to w.
+func NewWriter(w io.Writer, options Options) *Writer {
+	return &Writer{w: w, options: options}
+}
+
+// WriteEntry implements entry.Writer.
+func (w *Writer) WriteEntry(e entry.Entry) error {
+	line := Append(nil, e, w.options)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	_, err := w.w.Write(line)
+	return err
+}
+
+// Append appends the Cloud Logging JSON line of e to dst.
+func Append(dst []byte, e entry.Entry, options Options) []byte {
+	options = options.withDefaults()
+
+	t := e.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+
+	buf := bytes.NewBuffer(dst)
+	buf.WriteString(`{"timestamp":`)
+	appendJSON(buf, t.UTC().Format(time.RFC3339Nano))
+	buf.WriteString(`,"severity":`)
+	appendJSON(buf, Severity(e.Level))
+	buf.WriteString(`,"message":`)
+	appendJSON(buf, e.Message)
+
+	if e.Caller != "" {
+		buf.WriteString(`,"` + SourceLocationKey + `":`)
+		appendJSON(buf, sourceLocation(e.Caller, e.Function))
+	}
+	if v, ok := e.Fields[options.TraceIDKey]; ok {
+		buf.WriteString(`,"` + TraceKey + `":`)
+		appendJSON(buf, trace(fmt.Sprint(v), options.ProjectID))
+	}
+	if v, ok := e.Fields[options.SpanIDKey]; ok {
+		buf.WriteString(`,"` + SpanIDKey + `":`)
+		appendJSON(buf, fmt.Sprint(v))
+	}
+
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		if !reserved[k] && k != options.TraceIDKey && k != options.SpanIDKey {
+			keys = append(keys, k)
+		}
+	}
+	order.Sort(keys, options.PriorityKeys)
+
+	for _, k := range keys {
+		v := e.Fields[k]
+		if k == options.HTTPRequestKey {
+			k, v = HTTPRequestKey, httpRequest(v)
+		}
+		buf.WriteByte(',')
+		appendJSON(buf, k)
+		buf.WriteByte(':')
+		appendJSON(buf, value(v))
+	}
+
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+// sourceLocation returns the LogEntrySourceLocation of a file:line caller,
+// whose line is an int64 and therefore a JSON string.
+func sourceLocation(caller, function string) map[string]string {
+	location := map[string]string{"file": caller}
+	if i := strings.LastIndexByte(caller, ':'); i > 0 {
+		if _, err := strconv.Atoi(caller[i+1:]); err == nil {
+			location["file"], location["line"] = caller[:i], caller[i+1:]
+		}
+	}
+	if function != "" {
+		location["function"] = function
+	}
+	return location
+}
+
+// trace returns the resource name of the trace id, which links the entry to
+// Cloud Trace.
+func trace(id, projectID string) string {
+	if projectID == "" || strings.HasPrefix(id, "projects/") {
+		return id
+	}
+	return "projects/" + projectID + "/traces/" + id
+}
+
+// httpRequest returns the HttpRequest object of v: an *http.Request is
+// described by its method, URL, user agent, referer, remote IP and protocol,
+// and the durations of a map are written as the "3.5s" strings of the latency.
+// Other values are written as they are.
+func httpRequest(v interface{}) interface{} {
+	switch r := v.(type) {
+	case *http.Request:
+		request := map[string]interface{}{
+			"requestMethod": r.Method,
+			"requestUrl":    r.URL.String(),
+			"protocol":      r.Proto,
+		}
+		if r.RequestURI != "" && !r.URL.IsAbs() && r.Host != "" {
+			scheme := "http"
+			if r.TLS != nil {
+				scheme = "https"
+			}
+			request["requestUrl"] = scheme + "://" + r.Host + r.RequestURI
+		}
+		if ua := r.UserAgent(); ua != "" {
+			request["userAgent"] = ua
+		}
+		if referer := r.Referer(); referer != "" {
+			request["referer"] = referer
+		}
+		if r.RemoteAddr != "" {
+			ip := r.RemoteAddr
+			if host, _, err := net.SplitHostPort(ip); err == nil {
+				ip = host
+			}
+			request["remoteIp"] = ip
+		}
+		return request
+	case map[string]interface{}:
+		request := make(map[string]interface{}, len(r))
+		for k, e := range r {
+			if d, ok := e.(time.Duration); ok {
+				e = strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+			}
+			request[k] = e
+		}
+		return request
+	}
+	return v
+}
+
+// value returns v as it is written: errors as their message, and other values
+// as their JSON encoding, or as formatted by fmt when they have none.
+func value(v interface{}) interface{} {
+	switch v := v.(type) {
+	case error:
+		return v.Error()
+	case nil, string, bool, int, int64, float64, json.Number:
+		return v
+	}
+	if _, err := json.Marshal(v); err != nil {
+		return fmt.Sprint(v)
+	}
+	return v
+}
+
+// appendJSON appends the JSON encoding of v, without escaping HTML.
+func appendJSON(buf *bytes.Buffer, v interface{}) {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(v)
+	buf.Truncate(buf.Len() - 1) // the newline of Encode
+}
@@ -0,0 +1,163 @@
+package gcp
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/stretchr/testify/suite"
+)
+
+type GcpSuite struct {
+	suite.Suite
+}
+
+func TestGcpSuite(t *testing.T) {
+	suite.Run(t, new(GcpSuite))
+}
+
+var at = time.Date(2021, 1, 2, 3, 4, 5, 123456789, time.FixedZone("", -3*60*60))
+
+func (s *GcpSuite) TestSeverity() {
+	tt := []struct {
+		level log.Level
+		want  string
+	}{
+		{level: log.TraceLevel, want: "DEBUG"},
+		{level: log.DebugLevel, want: "DEBUG"},
+		{level: log.InfoLevel, want: "INFO"},
+		{level: log.WarnLevel, want: "WARNING"},
+		{level: log.ErrorLevel, want: "ERROR"},
+		{level: log.PanicLevel, want: "CRITICAL"},
+		{level: log.FatalLevel, want: "CRITICAL"},
+	}
+	for _, t := range tt {
+		s.Run(t.level.String(), func() {
+			s.Assert().Equal(t.want, Severity(t.level))
+		})
+	}
+}
+
+func (s *GcpSuite) TestAppend() {
+	request := httptest.NewRequest("GET", "/orders?id=1", nil)
+	request.Header.Set("User-Agent", "curl/8.0")
+	request.Header.Set("Referer", "https://example.com/")
+
+	tt := []struct {
+		name    string
+		entry   entry.Entry
+		options Options
+		want    string
+	}{
+		{
+			name:  "without fields",
+			entry: entry.Entry{Time: at, Level: log.InfoLevel, Message: "hello"},
+			want:  `{"timestamp":"2021-01-02T06:04:05.123456789Z","severity":"INFO","message":"hello"}`,
+		},
+		{
+			name: "with caller, trace and fields",
+			entry: entry.Entry{
+				Time:     at,
+				Level:    log.ErrorLevel,
+				Message:  "failed",
+				Caller:   "app/main.go:10",
+				Function: "main.main",
+				Fields: log.Fields{
+					"trace_id": "4bf92f3577b34da6a3ce929d0e0e4736",
+					"span_id":  "00f067aa0ba902b7",
+					"err":      errors.New("bad"),
+					"severity": "dropped",
+				},
+			},
+			options: Options{ProjectID: "orders-prod"},
+			want: `{"timestamp":"2021-01-02T06:04:05.123456789Z","severity":"ERROR","message":"failed",
+				"logging.googleapis.com/sourceLocation":{"file":"app/main.go","line":"10","function":"main.main"},
+				"logging.googleapis.com/trace":"projects/orders-prod/traces/4bf92f3577b34da6a3ce929d0e0e4736",
+				"logging.googleapis.com/spanId":"00f067aa0ba902b7","err":"bad"}`,
+		},
+		{
+			name:    "with trace without project",
+			entry:   entry.Entry{Time: at, Level: log.DebugLevel, Message: "hello", Fields: log.Fields{"traceID": "abc"}},
+			options: Options{TraceIDKey: "traceID"},
+			want: `{"timestamp":"2021-01-02T06:04:05.123456789Z","severity":"DEBUG","message":"hello",
+				"logging.googleapis.com/trace":"abc"}`,
+		},
+		{
+			name: "with http request",
+			entry: entry.Entry{
+				Time:    at,
+				Level:   log.InfoLevel,
+				Message: "served",
+				Fields:  log.Fields{"httpRequest": request},
+			},
+			want: `{"timestamp":"2021-01-02T06:04:05.123456789Z","severity":"INFO","message":"served",
+				"httpRequest":{"requestMethod":"GET","requestUrl":"http://example.com/orders?id=1","protocol":"HTTP/1.1",
+				"userAgent":"curl/8.0","referer":"https://example.com/","remoteIp":"192.0.2.1"}}`,
+		},
+		{
+			name: "with http request map",
+			entry: entry.Entry{
+				Time:    at,
+				Level:   log.FatalLevel,
+				Message: "served",
+				Fields: log.Fields{"request": map[string]interface{}{
+					"requestMethod": "POST",
+					"status":        500,
+					"latency":       1500 * time.Millisecond,
+				}},
+			},
+			options: Options{HTTPRequestKey: "request"},
+			want: `{"timestamp":"2021-01-02T06:04:05.123456789Z","severity":"CRITICAL","message":"served",
+				"httpRequest":{"requestMethod":"POST","status":500,"latency":"1.5s"}}`,
+		},
+	}
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			got := Append(nil, t.entry, t.options)
+			s.Assert().True(bytes.HasSuffix(got, []byte("}\n")))
+			s.Assert().JSONEq(t.want, string(got))
+		})
+	}
+}
+
+func (s *GcpSuite) TestOrder() {
+	got := string(Append(nil, entry.Entry{
+		Time:    at,
+		Level:   log.WarnLevel,
+		Message: "<hello>",
+		Fields:  log.Fields{"b": 1, "a": 2},
+	}, Options{}))
+
+	s.Assert().Equal(`{"timestamp":"2021-01-02T06:04:05.123456789Z","severity":"WARNING","message":"<hello>",`+
+		`"a":2,"b":1}`+"\n", got)
+}
+
+func (s *GcpSuite) TestPriorityKeys() {
+	got := string(Append(nil, entry.Entry{
+		Time:    at,
+		Level:   log.WarnLevel,
+		Message: "<hello>",
+		Fields:  log.Fields{"b": 1, "a": 2, "c": 3},
+	}, Options{PriorityKeys: []string{"c", "b"}}))
+
+	s.Assert().Equal(`{"timestamp":"2021-01-02T06:04:05.123456789Z","severity":"WARNING","message":"<hello>",`+
+		`"c":3,"b":1,"a":2}`+"\n", got)
+}
+
+func (s *GcpSuite) TestWriter() {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, Options{})
+
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Level: log.InfoLevel, Message: "a"}))
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Level: log.InfoLevel, Message: "b"}))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	s.Require().Len(lines, 2)
+	s.Assert().Contains(lines[0], `"message":"a"`)
+	s.Assert().Contains(lines[1], `"message":"b"`)
+}
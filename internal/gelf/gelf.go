@@ -0,0 +1,307 @@
+// Package gelf writes entries to Graylog as GELF 1.1 messages, over UDP or
+// TCP.
+//
+// The fields of the entries are written as additional fields, prefixed with an
+// underscore, and the levels are mapped to syslog severities. UDP messages are
+// compressed and split in chunks when larger than the chunk size; TCP messages
+// are not compressed and are terminated by a null byte.
+package gelf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/americanas-go/log/internal/syslog"
+)
+
+// Protocols.
+const (
+	ProtocolUDP = "udp"
+	ProtocolTCP = "tcp"
+)
+
+// Compressions of the UDP messages.
+const (
+	CompressionGzip = "GZIP"
+	CompressionZlib = "ZLIB"
+	CompressionNone = "NONE"
+)
+
+const (
+	version = "1.1"
+
+	// ShortMessageLength is the longest short_message, in characters. Longer
+	// or multiline messages are also written whole as full_message.
+	ShortMessageLength = 250
+
+	defaultChunkSize = 1420
+	minChunkSize     = 512
+	chunkHeaderSize  = 12
+	maxChunks        = 128
+
+	dialTimeout  = 5 * time.Second
+	writeTimeout = 5 * time.Second
+)
+
+var (
+	// Protocols are the supported protocols.
+	Protocols = []string{ProtocolUDP, ProtocolTCP}
+
+	// Compressions are the supported compressions.
+	Compressions = []string{CompressionGzip, CompressionZlib, CompressionNone}
+
+	chunkMagic = []byte{0x1e, 0x0f}
+
+	errTooManyChunks = errors.New("gelf: message needs more than 128 chunks")
+)
+
+// Options configures a Writer. Empty values take a default: UDP, GZIP, the
+// host name and chunks of 1420 bytes.
+type Options struct {
+	Protocol    string // udp/tcp
+	Address     string // host:port
+	Compression string // compression of UDP messages GZIP/ZLIB/NONE
+	Host        string // host field of the messages
+	ChunkSize   int    // largest UDP datagram, at least 512 bytes
+}
+
+// Writer sends entries to a GELF input. It connects on the first write and
+// reconnects once when a write fails, e.g. after Graylog was restarted.
+type Writer struct {
+	mu   sync.Mutex
+	conn net.Conn
+
+	protocol    string
+	address     string
+	compression string
+	host        string
+	chunkSize   int
+}
+
+// New returns a Writer from options. Unknown protocols and compressions fall
+// back to the defaults.
+func New(options Options) *Writer {
+	w := &Writer{
+		protocol:    options.Protocol,
+		address:     options.Address,
+		compression: strings.ToUpper(options.Compression),
+		host:        options.Host,
+		chunkSize:   options.ChunkSize,
+	}
+
+	if w.protocol != ProtocolTCP {
+		w.protocol = ProtocolUDP
+	}
+	if w.compression != CompressionZlib && w.compression != CompressionNone {
+		w.compression = CompressionGzip
+	}
+	if w.host == "" {
+		w.host, _ = os.Hostname()
+	}
+	if w.chunkSize == 0 {
+		w.chunkSize = defaultChunkSize
+	}
+	w.chunkSize = max(w.chunkSize, minChunkSize)
+
+	return w
+}
+
+// WriteEntry implements entry.Writer.
+func (w *Writer) WriteEntry(e entry.Entry) error {
+	msg, err := w.encode(e)
+	if err != nil {
+		return err
+	}
+
+	var packets [][]byte
+	if w.protocol == ProtocolTCP {
+		packets = [][]byte{append(msg, 0)}
+	} else if packets, err = w.packets(msg); err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.write(packets); err != nil {
+		w.close()
+		return w.write(packets)
+	}
+	return nil
+}
+
+// Close closes the connection to Graylog, if any. Later writes reconnect.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.close()
+}
+
+func (w *Writer) write(packets [][]byte) error {
+	if w.conn == nil {
+		conn, err := net.DialTimeout(w.protocol, w.address, dialTimeout)
+		if err != nil {
+			return err
+		}
+		w.conn = conn
+	}
+
+	if err := w.conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+		return err
+	}
+	for _, p := range packets {
+		if _, err := w.conn.Write(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *Writer) close() error {
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// packets compresses msg and splits it in chunks when it does not fit in a
+// datagram.
+func (w *Writer) packets(msg []byte) ([][]byte, error) {
+	msg, err := compress(msg, w.compression)
+	if err != nil {
+		return nil, err
+	}
+	if len(msg) <= w.chunkSize {
+		return [][]byte{msg}, nil
+	}
+
+	size := w.chunkSize - chunkHeaderSize
+	count := (len(msg) + size - 1) / size
+	if count > maxChunks {
+		return nil, errTooManyChunks
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	chunks := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		chunk := make([]byte, 0, w.chunkSize)
+		chunk = append(chunk, chunkMagic...)
+		chunk = append(chunk, id...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, msg[i*size:min((i+1)*size, len(msg))]...)
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
+
+func compress(msg []byte, compression string) ([]byte, error) {
+	var buf bytes.Buffer
+	var zw io.WriteCloser
+	switch compression {
+	case CompressionNone:
+		return msg, nil
+	case CompressionZlib:
+		zw = zlib.NewWriter(&buf)
+	default:
+		zw = gzip.NewWriter(&buf)
+	}
+
+	if _, err := zw.Write(msg); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// encode encodes e as a GELF JSON object.
+func (w *Writer) encode(e entry.Entry) ([]byte, error) {
+	short, full := messages(e.Message)
+
+	t := e.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+
+	msg := map[string]interface{}{
+		"version":       version,
+		"host":          w.host,
+		"short_message": short,
+		"timestamp":     json.Number(fmt.Sprintf("%d.%03d", t.Unix(), t.Nanosecond()/int(time.Millisecond))),
+		"level":         syslog.Severity(e.Level),
+	}
+	if full != "" {
+		msg["full_message"] = full
+	}
+
+	for k, v := range e.Fields {
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		msg[additionalField(k)] = v
+	}
+	if e.Caller != "" {
+		msg["_caller"] = e.Caller
+	}
+
+	return json.Marshal(msg)
+}
+
+// messages returns the short message, the first line of message cut at
+// ShortMessageLength characters, and the full message, message itself when
+// it does not fit in the short one.
+func messages(message string) (short string, full string) {
+	short, _, _ = strings.Cut(strings.TrimLeft(message, "\r\n"), "\n")
+	short = strings.TrimRight(short, "\r")
+	if utf8.RuneCountInString(short) > ShortMessageLength {
+		short = string([]rune(short)[:ShortMessageLength])
+	}
+
+	if short != message {
+		full = message
+	}
+	if short == "" {
+		// short_message is mandatory and can not be empty
+		short = "-"
+	}
+	return short, full
+}
+
+// additionalField returns key as the name of an additional field: an
+// underscore followed by letters, digits, '_', '.' and '-'. The field _id is
+// reserved by GELF and renamed to __id.
+func additionalField(key string) string {
+	b := []byte(key)
+	for i, c := range b {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '.' || c == '-') {
+			b[i] = '_'
+		}
+	}
+
+	name := "_" + string(b)
+	if name == "_id" {
+		name = "__id"
+	}
+	return name
+}
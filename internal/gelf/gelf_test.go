@@ -0,0 +1,210 @@
+package gelf
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/stretchr/testify/suite"
+)
+
+type GelfSuite struct {
+	suite.Suite
+}
+
+func TestGelfSuite(t *testing.T) {
+	suite.Run(t, new(GelfSuite))
+}
+
+var at = time.Date(2021, 1, 2, 3, 4, 5, 123456789, time.UTC)
+
+func (s *GelfSuite) TestEncode() {
+	tt := []struct {
+		name  string
+		entry entry.Entry
+		want  string
+	}{
+		{
+			name:  "without fields",
+			entry: entry.Entry{Time: at, Level: log.InfoLevel, Message: "hello"},
+			want:  `{"version":"1.1","host":"host","short_message":"hello","timestamp":1609556645.123,"level":6}`,
+		},
+		{
+			name: "with fields and caller",
+			entry: entry.Entry{
+				Time:    at,
+				Level:   log.ErrorLevel,
+				Message: "failed",
+				Caller:  "main.go:10",
+				Fields:  log.Fields{"ID": 1, "id": "a", "err": errors.New("bad"), "with space": true},
+			},
+			want: `{"version":"1.1","host":"host","short_message":"failed","timestamp":1609556645.123,"level":3,
+				"_ID":1,"__id":"a","_err":"bad","_with_space":true,"_caller":"main.go:10"}`,
+		},
+		{
+			name:  "multiline message",
+			entry: entry.Entry{Time: at, Level: log.WarnLevel, Message: "panic: boom\ngoroutine 1"},
+			want: `{"version":"1.1","host":"host","short_message":"panic: boom","timestamp":1609556645.123,"level":4,
+				"full_message":"panic: boom\ngoroutine 1"}`,
+		},
+		{
+			name:  "empty message",
+			entry: entry.Entry{Time: at, Level: log.FatalLevel},
+			want:  `{"version":"1.1","host":"host","short_message":"-","timestamp":1609556645.123,"level":1}`,
+		},
+	}
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			got, err := New(Options{Host: "host"}).encode(t.entry)
+			s.Require().NoError(err)
+			s.Assert().JSONEq(t.want, string(got))
+		})
+	}
+}
+
+func (s *GelfSuite) TestMessages() {
+	long := strings.Repeat("é", ShortMessageLength+1)
+
+	short, full := messages(long)
+	s.Assert().Equal(strings.Repeat("é", ShortMessageLength), short)
+	s.Assert().Equal(long, full)
+
+	short, full = messages("hello")
+	s.Assert().Equal("hello", short)
+	s.Assert().Empty(full)
+}
+
+func (s *GelfSuite) TestNewDefaults() {
+	w := New(Options{ChunkSize: 100})
+	s.Assert().Equal(ProtocolUDP, w.protocol)
+	s.Assert().Equal(CompressionGzip, w.compression)
+	s.Assert().Equal(minChunkSize, w.chunkSize)
+	s.Assert().NotEmpty(w.host)
+}
+
+func (s *GelfSuite) TestWriteEntryUDP() {
+	tt := []struct {
+		name        string
+		compression string
+		message     string
+		decompress  func(io.Reader) (io.Reader, error)
+	}{
+		{
+			name:        "gzip",
+			compression: CompressionGzip,
+			message:     "hello",
+			decompress:  func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+		},
+		{
+			name:        "zlib",
+			compression: CompressionZlib,
+			message:     "hello",
+			decompress:  func(r io.Reader) (io.Reader, error) { return zlib.NewReader(r) },
+		},
+		{
+			name:        "chunked",
+			compression: CompressionNone,
+			message:     strings.Repeat("x", 2000),
+			decompress:  func(r io.Reader) (io.Reader, error) { return r, nil },
+		},
+	}
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+			s.Require().NoError(err)
+			defer conn.Close()
+
+			w := New(Options{Address: conn.LocalAddr().String(), Compression: t.compression, Host: "host", ChunkSize: minChunkSize})
+			defer w.Close()
+
+			s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Level: log.InfoLevel, Message: t.message}))
+
+			r, err := t.decompress(bytes.NewReader(s.readDatagrams(conn)))
+			s.Require().NoError(err)
+			var msg map[string]interface{}
+			s.Require().NoError(json.NewDecoder(r).Decode(&msg))
+			s.Assert().Equal("host", msg["host"])
+			if len(t.message) > ShortMessageLength {
+				s.Assert().Equal(t.message, msg["full_message"])
+			} else {
+				s.Assert().Equal(t.message, msg["short_message"])
+			}
+		})
+	}
+}
+
+func (s *GelfSuite) TestWriteEntryTCP() {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+	defer ln.Close()
+
+	w := New(Options{Protocol: ProtocolTCP, Address: ln.Addr().String(), Host: "host"})
+	defer w.Close()
+
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Level: log.InfoLevel, Message: "a"}))
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Level: log.InfoLevel, Message: "b"}))
+
+	conn, err := ln.Accept()
+	s.Require().NoError(err)
+	defer conn.Close()
+	s.Require().NoError(conn.SetReadDeadline(time.Now().Add(5 * time.Second)))
+
+	r := bufio.NewReader(conn)
+	for _, want := range []string{"a", "b"} {
+		frame, err := r.ReadBytes(0)
+		s.Require().NoError(err)
+		var msg map[string]interface{}
+		s.Require().NoError(json.Unmarshal(frame[:len(frame)-1], &msg))
+		s.Assert().Equal(want, msg["short_message"])
+	}
+}
+
+func (s *GelfSuite) TestTooManyChunks() {
+	w := New(Options{Compression: CompressionNone, ChunkSize: minChunkSize})
+	_, err := w.packets(make([]byte, maxChunks*minChunkSize))
+	s.Assert().ErrorIs(err, errTooManyChunks)
+}
+
+// readDatagrams reads a message, reassembling its chunks.
+func (s *GelfSuite) readDatagrams(conn net.PacketConn) []byte {
+	var chunks [][]byte
+	buf := make([]byte, 65536)
+	for {
+		s.Require().NoError(conn.SetReadDeadline(time.Now().Add(5 * time.Second)))
+		n, _, err := conn.ReadFrom(buf)
+		s.Require().NoError(err)
+		packet := append([]byte(nil), buf[:n]...)
+
+		if !bytes.HasPrefix(packet, chunkMagic) {
+			return packet
+		}
+		s.Assert().LessOrEqual(n, minChunkSize)
+		count := int(packet[11])
+		if chunks == nil {
+			chunks = make([][]byte, count)
+		}
+		chunks[packet[10]] = packet[chunkHeaderSize:]
+		if len(chunks) == count && !containsNil(chunks) {
+			return bytes.Join(chunks, nil)
+		}
+	}
+}
+
+func containsNil(chunks [][]byte) bool {
+	for _, c := range chunks {
+		if c == nil {
+			return true
+		}
+	}
+	return false
+}
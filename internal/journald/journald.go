@@ -0,0 +1,233 @@
+// Package journald writes entries to the systemd journal with its native
+// protocol, described by https://systemd.io/JOURNAL_NATIVE_PROTOCOL/.
+//
+// Each entry is a datagram of journal fields: MESSAGE, PRIORITY,
+// SYSLOG_IDENTIFIER, CODE_FILE, CODE_LINE and CODE_FUNC, then the fields of the
+// entry, their keys uppercased and sanitized into journal field names, the ones
+// named like the fields above being prefixed with FIELDS_. Entries
+// too large for a datagram are written to a sealed memfd whose descriptor is
+// sent instead, on Linux.
+package journald
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/americanas-go/log/internal/order"
+	"github.com/americanas-go/log/internal/syslog"
+)
+
+// DefaultSocket is the socket of the native protocol.
+const DefaultSocket = "/run/systemd/journal/socket"
+
+// reserved are the fields written by the writer.
+var reserved = map[string]bool{
+	"MESSAGE":           true,
+	"PRIORITY":          true,
+	"SYSLOG_IDENTIFIER": true,
+	"CODE_FILE":         true,
+	"CODE_LINE":         true,
+	"CODE_FUNC":         true,
+}
+
+const (
+	// maxNameLength is the longest journal field name.
+	maxNameLength = 64
+
+	// reservedPrefix prefixes the fields named like the ones of the writer.
+	reservedPrefix = "FIELDS_"
+
+	writeTimeout = 5 * time.Second
+)
+
+// Options configures a Writer. Empty values take a default: DefaultSocket and
+// the base name of the executable.
+type Options struct {
+	Socket     string // path of the journald socket
+	Identifier string // SYSLOG_IDENTIFIER of the entries
+
+	PriorityKeys []string // fields written first, in this order, the others being sorted by key
+}
+
+// Writer sends entries to journald. It connects on the first write and
+// reconnects once when a write fails, e.g. after journald was restarted.
+type Writer struct {
+	mu   sync.Mutex
+	conn *net.UnixConn
+
+	socket     string
+	identifier string
+	priority   []string
+}
+
+// New returns a Writer from options.
+func New(options Options) *Writer {
+	w := &Writer{socket: options.Socket, identifier: options.Identifier, priority: options.PriorityKeys}
+	if w.socket == "" {
+		w.socket = DefaultSocket
+	}
+	if w.identifier == "" {
+		w.identifier = filepath.Base(os.Args[0])
+	}
+	return w
+}
+
+// WriteEntry implements entry.Writer.
+func (w *Writer) WriteEntry(e entry.Entry) error {
+	msg := w.encode(e)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.write(msg); err != nil {
+		w.close()
+		return w.write(msg)
+	}
+	return nil
+}
+
+// Close closes the connection to journald, if any. Later writes reconnect.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.close()
+}
+
+func (w *Writer) write(msg []byte) error {
+	if w.conn == nil {
+		conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: w.socket, Net: "unixgram"})
+		if err != nil {
+			return err
+		}
+		w.conn = conn
+	}
+
+	if err := w.conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+		return err
+	}
+	_, err := w.conn.Write(msg)
+	if errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS) {
+		return writeMemfd(w.conn, msg)
+	}
+	return err
+}
+
+func (w *Writer) close() error {
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// encode returns the journal fields of e.
+func (w *Writer) encode(e entry.Entry) []byte {
+	buf := &bytes.Buffer{}
+	appendField(buf, "MESSAGE", e.Message)
+	appendField(buf, "PRIORITY", strconv.Itoa(syslog.Severity(e.Level)))
+	appendField(buf, "SYSLOG_IDENTIFIER", w.identifier)
+
+	if e.Caller != "" {
+		file, line := e.Caller, ""
+		if i := strings.LastIndexByte(e.Caller, ':'); i > 0 {
+			file, line = e.Caller[:i], e.Caller[i+1:]
+		}
+		appendField(buf, "CODE_FILE", file)
+		if line != "" {
+			appendField(buf, "CODE_LINE", line)
+		}
+	}
+	if e.Function != "" {
+		appendField(buf, "CODE_FUNC", e.Function)
+	}
+
+	for _, k := range order.Keys(e.Fields, w.priority) {
+		if name := FieldName(k); name != "" {
+			appendField(buf, name, value(e.Fields[k]))
+		}
+	}
+
+	return buf.Bytes()
+}
+
+// appendField appends name=value, or name, the length of value as a little
+// endian uint64 and value when value spans several lines.
+func appendField(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name)
+	if strings.IndexByte(value, '\n') < 0 {
+		buf.WriteByte('=')
+		buf.WriteString(value)
+	} else {
+		buf.WriteByte('\n')
+		_ = binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+		buf.WriteString(value)
+	}
+	buf.WriteByte('\n')
+}
+
+// FieldName returns key as a journal field name: uppercase letters, digits and
+// underscores, starting with a letter and at most 64 characters long. Other
+// characters are replaced by underscores and leading underscores, reserved to
+// the fields set by journald, are removed. The names of the fields written by
+// the writer, such as MESSAGE and PRIORITY, are prefixed with FIELDS_, so that
+// they are not written twice. It returns an empty name when nothing is left.
+func FieldName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, strings.ToUpper(key))
+
+	name = strings.TrimLeft(name, "_")
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "F" + name
+	}
+	if reserved[name] {
+		name = reservedPrefix + name
+	}
+	if len(name) > maxNameLength {
+		name = name[:maxNameLength]
+	}
+	return name
+}
+
+// value returns v as a journal field value. Maps, slices and structs are
+// written as JSON.
+func value(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	case nil:
+		return ""
+	}
+
+	switch reflect.Indirect(reflect.ValueOf(v)).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		if data, err := json.Marshal(v); err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,225 @@
+//go:build linux
+
+package journald
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/stretchr/testify/suite"
+)
+
+type JournaldSuite struct {
+	suite.Suite
+}
+
+func TestJournaldSuite(t *testing.T) {
+	suite.Run(t, new(JournaldSuite))
+}
+
+// journal is a unixgram socket standing in for journald.
+type journal struct {
+	conn *net.UnixConn
+	path string
+}
+
+func (s *JournaldSuite) journal() *journal {
+	// socket paths are limited to about 100 bytes, shorter than most TempDir
+	dir, err := os.MkdirTemp("", "journald")
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "socket")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { conn.Close() })
+
+	return &journal{conn: conn, path: path}
+}
+
+// receive returns the fields of the next entry, read from the memfd sent
+// instead of the datagram for large entries.
+func (s *JournaldSuite) receive(j *journal) map[string]string {
+	s.Require().NoError(j.conn.SetReadDeadline(time.Now().Add(5 * time.Second)))
+
+	buf := make([]byte, 1<<16)
+	oob := make([]byte, syscall.CmsgSpace(4))
+	n, oobn, _, _, err := j.conn.ReadMsgUnix(buf, oob)
+	s.Require().NoError(err)
+
+	data := buf[:n]
+	if oobn > 0 {
+		s.Require().Zero(n, "a memfd is sent with an empty datagram")
+		msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+		s.Require().NoError(err)
+		fds, err := syscall.ParseUnixRights(&msgs[0])
+		s.Require().NoError(err)
+
+		f := os.NewFile(uintptr(fds[0]), "memfd")
+		defer f.Close()
+		// the offset is shared with the writer, journald maps the memfd instead
+		data, err = io.ReadAll(io.NewSectionReader(f, 0, 1<<30))
+		s.Require().NoError(err)
+	}
+
+	return s.decode(data)
+}
+
+// decode reads the fields of the native protocol.
+func (s *JournaldSuite) decode(data []byte) map[string]string {
+	fields := map[string]string{}
+	for len(data) > 0 {
+		line := bytes.IndexByte(data, '\n')
+		s.Require().GreaterOrEqual(line, 0)
+
+		if eq := bytes.IndexByte(data[:line], '='); eq >= 0 {
+			fields[string(data[:eq])] = string(data[eq+1 : line])
+			data = data[line+1:]
+			continue
+		}
+
+		name := string(data[:line])
+		data = data[line+1:]
+		size := int(binary.LittleEndian.Uint64(data))
+		fields[name] = string(data[8 : 8+size])
+		s.Require().Equal(byte('\n'), data[8+size])
+		data = data[8+size+1:]
+	}
+	return fields
+}
+
+func (s *JournaldSuite) TestWriteEntry() {
+	j := s.journal()
+	w := New(Options{Socket: j.path, Identifier: "orders"})
+	defer w.Close()
+
+	s.Require().NoError(w.WriteEntry(entry.Entry{
+		Time:     time.Now(),
+		Level:    log.WarnLevel,
+		Message:  "payment\nrefused",
+		Caller:   "orders/pay.go:42",
+		Function: "github.com/acme/orders.Pay",
+		Fields: log.Fields{
+			"request_id":  "abc",
+			"http.status": 402,
+			"err":         errors.New("bad"),
+			"_source":     "x",
+			"order":       map[string]int{"total": 10},
+			"message":     "duplicate",
+		},
+	}))
+
+	s.Assert().Equal(map[string]string{
+		"MESSAGE":           "payment\nrefused",
+		"PRIORITY":          "4",
+		"SYSLOG_IDENTIFIER": "orders",
+		"CODE_FILE":         "orders/pay.go",
+		"CODE_LINE":         "42",
+		"CODE_FUNC":         "github.com/acme/orders.Pay",
+		"REQUEST_ID":        "abc",
+		"HTTP_STATUS":       "402",
+		"ERR":               "bad",
+		"SOURCE":            "x",
+		"ORDER":             `{"total":10}`,
+		"FIELDS_MESSAGE":    "duplicate",
+	}, s.receive(j))
+}
+
+func (s *JournaldSuite) TestPriorityKeys() {
+	w := New(Options{Identifier: "orders", PriorityKeys: []string{"c", "b"}})
+
+	got := w.encode(entry.Entry{Level: log.InfoLevel, Message: "hello", Fields: log.Fields{"a": 1, "b": 2, "c": 3}})
+	s.Assert().True(bytes.HasSuffix(got, []byte("C=3\nB=2\nA=1\n")), string(got))
+}
+
+func (s *JournaldSuite) TestPriority() {
+	tt := []struct {
+		level log.Level
+		want  string
+	}{
+		{level: log.TraceLevel, want: "7"},
+		{level: log.DebugLevel, want: "7"},
+		{level: log.InfoLevel, want: "6"},
+		{level: log.WarnLevel, want: "4"},
+		{level: log.ErrorLevel, want: "3"},
+		{level: log.PanicLevel, want: "2"},
+		{level: log.FatalLevel, want: "1"},
+	}
+	j := s.journal()
+	w := New(Options{Socket: j.path})
+	defer w.Close()
+
+	for _, t := range tt {
+		s.Run(t.level.String(), func() {
+			s.Require().NoError(w.WriteEntry(entry.Entry{Level: t.level, Message: "a"}))
+			fields := s.receive(j)
+			s.Assert().Equal(t.want, fields["PRIORITY"])
+			s.Assert().Equal(filepath.Base(os.Args[0]), fields["SYSLOG_IDENTIFIER"])
+		})
+	}
+}
+
+func (s *JournaldSuite) TestLargeEntry() {
+	j := s.journal()
+	w := New(Options{Socket: j.path})
+	defer w.Close()
+
+	message := strings.Repeat("a", 4<<20)
+	s.Require().NoError(w.WriteEntry(entry.Entry{Level: log.InfoLevel, Message: message}))
+	s.Assert().Equal(message, s.receive(j)["MESSAGE"])
+}
+
+func (s *JournaldSuite) TestReconnect() {
+	j := s.journal()
+	w := New(Options{Socket: j.path})
+	defer w.Close()
+
+	s.Require().NoError(w.WriteEntry(entry.Entry{Message: "a"}))
+	s.Assert().Equal("a", s.receive(j)["MESSAGE"])
+
+	// journald is restarted, binding a new socket at the same path
+	j.conn.Close()
+	s.Require().NoError(os.Remove(j.path))
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: j.path, Net: "unixgram"})
+	s.Require().NoError(err)
+	defer conn.Close()
+	j.conn = conn
+
+	s.Require().NoError(w.WriteEntry(entry.Entry{Message: "b"}))
+	s.Assert().Equal("b", s.receive(j)["MESSAGE"])
+}
+
+func (s *JournaldSuite) TestFieldName() {
+	tt := []struct {
+		key  string
+		want string
+	}{
+		{key: "request_id", want: "REQUEST_ID"},
+		{key: "http.status-code", want: "HTTP_STATUS_CODE"},
+		{key: "__cursor", want: "CURSOR"},
+		{key: "1st", want: "F1ST"},
+		{key: "ação", want: "A__O"},
+		{key: "___", want: ""},
+		{key: "message", want: "FIELDS_MESSAGE"},
+		{key: "priority", want: "FIELDS_PRIORITY"},
+		{key: "code_file", want: "FIELDS_CODE_FILE"},
+		{key: "syslog.identifier", want: "FIELDS_SYSLOG_IDENTIFIER"},
+		{key: strings.Repeat("k", 70), want: strings.Repeat("K", 64)},
+	}
+	for _, t := range tt {
+		s.Run(t.key, func() {
+			s.Assert().Equal(t.want, FieldName(t.key))
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package journald
+
+import (
+	"net"
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// writeMemfd writes msg to a sealed memfd and sends its descriptor, which
+// journald reads the entry from.
+func writeMemfd(conn *net.UnixConn, msg []byte) error {
+	fd, err := unix.MemfdCreate("journal-entry", unix.MFD_CLOEXEC|unix.MFD_ALLOW_SEALING)
+	if err != nil {
+		return err
+	}
+	f := os.NewFile(uintptr(fd), "journal-entry")
+	defer f.Close()
+
+	if _, err := f.Write(msg); err != nil {
+		return err
+	}
+	// journald only accepts sealed memfds
+	seals := unix.F_SEAL_SHRINK | unix.F_SEAL_GROW | unix.F_SEAL_WRITE | unix.F_SEAL_SEAL
+	if _, err := unix.FcntlInt(f.Fd(), unix.F_ADD_SEALS, seals); err != nil {
+		return err
+	}
+
+	// WriteMsgUnix refuses connected datagram sockets
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+	rights := syscall.UnixRights(int(f.Fd()))
+	if ctrlErr := raw.Control(func(s uintptr) {
+		err = unix.Sendmsg(int(s), nil, rights, nil, 0)
+	}); ctrlErr != nil {
+		return ctrlErr
+	}
+	return err
+}
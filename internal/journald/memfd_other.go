@@ -0,0 +1,13 @@
+//go:build !linux
+
+package journald
+
+import (
+	"errors"
+	"net"
+)
+
+// writeMemfd fails, memfds being specific to Linux like journald.
+func writeMemfd(*net.UnixConn, []byte) error {
+	return errors.New("journald: entry too large for a datagram")
+}
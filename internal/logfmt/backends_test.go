@@ -0,0 +1,91 @@
+package logfmt_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/contrib/go.uber.org/zap.v1"
+	"github.com/americanas-go/log/contrib/rs/zerolog.v1"
+	"github.com/americanas-go/log/contrib/sirupsen/logrus.v1"
+	logrusfmt "github.com/americanas-go/log/contrib/sirupsen/logrus.v1/formatter/logfmt"
+	"github.com/stretchr/testify/suite"
+)
+
+type BackendsSuite struct {
+	suite.Suite
+}
+
+func TestBackendsSuite(t *testing.T) {
+	suite.Run(t, new(BackendsSuite))
+}
+
+var at = time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// logFile logs with the logger returned by newLogger for a LOGFMT file output
+// in dir, returning the content of the file.
+func (s *BackendsSuite) logFile(newLogger func(dir string) log.Logger) string {
+	dir := s.T().TempDir()
+	logger := newLogger(dir)
+	logger.Debugf("hello %s", "world")
+	logger.
+		WithFields(log.Fields{"user.name": "John Doe", "order": 1, "path": `C:\tmp`}).
+		WithError(errors.New("something bad")).
+		Warn("order created")
+
+	data, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	s.Require().NoError(err)
+	return string(data)
+}
+
+func (s *BackendsSuite) TestDefaultKeys() {
+	backends := map[string]func(dir string) log.Logger{
+		"zap": func(dir string) log.Logger {
+			return zap.NewLogger(
+				zap.WithConsoleEnabled(false),
+				zap.WithFileEnabled(true),
+				zap.WithFilePath(dir),
+				zap.WithFileName("app.log"),
+				zap.WithFileFormatter("LOGFMT"),
+				zap.WithFileLevel("DEBUG"),
+				zap.WithTimeClock(func() time.Time { return at }),
+				// the default field names, the caller excepted, which the
+				// other backends omit by default
+				zap.WithFieldNames("ts", "level", "msg", "", "stacktrace"),
+			)
+		},
+		"zerolog": func(dir string) log.Logger {
+			return zerolog.NewLogger(
+				zerolog.WithConsoleEnabled(false),
+				zerolog.WithFileEnabled(true),
+				zerolog.WithFilePath(dir),
+				zerolog.WithFileName("app.log"),
+				zerolog.WithFileFormatter("LOGFMT"),
+				zerolog.WithFileLevel("DEBUG"),
+				zerolog.WithTimeClock(func() time.Time { return at }),
+			)
+		},
+		"logrus": func(dir string) log.Logger {
+			return logrus.NewLogger(
+				logrus.WithConsoleEnabled(false),
+				logrus.WithFileEnabled(true),
+				logrus.WithFilePath(dir),
+				logrus.WithFileName("app.log"),
+				logrus.WithFileFormatter(logrusfmt.New()),
+				logrus.WithFileLevel("DEBUG"),
+				logrus.WithTimeClock(func() time.Time { return at }),
+			)
+		},
+	}
+
+	want := `time=2021-01-02T03:04:05Z level=debug msg="hello world"` + "\n" +
+		`time=2021-01-02T03:04:05Z level=warn msg="order created" err="something bad" order=1 path="C:\\tmp" user.name="John Doe"` + "\n"
+	for name, newLogger := range backends {
+		s.Run(name, func() {
+			s.Assert().Equal(want, s.logFile(newLogger))
+		})
+	}
+}
@@ -0,0 +1,249 @@
+// Package logfmt encodes entries as strict logfmt lines, the same whichever
+// backend writes them:
+//
+//	time=2021-01-02T03:04:05Z level=info msg="order created" caller=app/main.go:10 order=1 user.name="John Doe"
+//
+// The core keys come first, in the order time, level, msg and caller, followed
+// by the fields sorted by key. A field whose key is one of the core keys is
+// written with a "fields." prefix, as by the text formatter of logrus.
+//
+// Keys are made of the printable characters but space, = and ", the others
+// being replaced by _. Values are quoted, with the escapes of strconv.Quote,
+// when they are empty or hold a space, =, " or a character that is not
+// printable. Errors are written as their message, times in the time format,
+// and maps, slices and structs as their JSON encoding.
+package logfmt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/americanas-go/log/internal/order"
+)
+
+// Default core keys.
+const (
+	DefaultTimeKey    = "time"
+	DefaultLevelKey   = "level"
+	DefaultMessageKey = "msg"
+	DefaultCallerKey  = "caller"
+)
+
+// Predefined time formats, any other value is used as a time.Format layout.
+const (
+	TimeFormatISO8601     = "ISO8601"
+	TimeFormatRFC3339     = "RFC3339"
+	TimeFormatRFC3339Nano = "RFC3339NANO"
+	TimeFormatEpoch       = "EPOCH"
+	TimeFormatEpochMillis = "EPOCH_MILLIS"
+	TimeFormatEpochNanos  = "EPOCH_NANOS"
+)
+
+const iso8601Layout = "2006-01-02T15:04:05.000Z0700"
+
+// Options configures the encoding. An empty time, level or caller key omits
+// the key, and an empty message key takes the default.
+type Options struct {
+	TimeKey    string
+	LevelKey   string
+	MessageKey string
+	CallerKey  string
+	TimeFormat string // format of the times, RFC3339 when empty
+
+	PriorityKeys []string // fields written first, in this order, the others being sorted by key
+}
+
+// Level returns the value of level, in lower case.
+func Level(level log.Level) string {
+	switch level {
+	case log.TraceLevel:
+		return "trace"
+	case log.DebugLevel:
+		return "debug"
+	case log.InfoLevel:
+		return "info"
+	case log.WarnLevel:
+		return "warn"
+	case log.ErrorLevel:
+		return "error"
+	case log.PanicLevel:
+		return "panic"
+	default:
+		return "fatal"
+	}
+}
+
+// Writer writes entries as logfmt lines to an io.Writer.
+type Writer struct {
+	mu      sync.Mutex
+	w       io.Writer
+	options Options
+}
+
+// NewWriter returns a Writer writing to w.
+func NewWriter(w io.Writer, options Options) *Writer {
+	return &Writer{w: w, options: options}
+}
+
+// WriteEntry implements entry.Writer.
+func (w *Writer) WriteEntry(e entry.Entry) error {
+	line := Append(nil, e, w.options)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	_, err := w.w.Write(line)
+	return err
+}
+
+// Append appends the logfmt line of e to dst.
+func Append(dst []byte, e entry.Entry, options Options) []byte {
+	if options.MessageKey == "" {
+		options.MessageKey = DefaultMessageKey
+	}
+
+	buf := bytes.NewBuffer(dst)
+	core := make(map[string]bool, 4)
+	pair := func(k string, v interface{}) {
+		if buf.Len() > len(dst) {
+			buf.WriteByte(' ')
+		}
+		appendKey(buf, k)
+		buf.WriteByte('=')
+		appendValue(buf, v, options.TimeFormat)
+	}
+
+	if options.TimeKey != "" {
+		t := e.Time
+		if t.IsZero() {
+			t = time.Now()
+		}
+		pair(options.TimeKey, t)
+		core[options.TimeKey] = true
+	}
+	if options.LevelKey != "" {
+		pair(options.LevelKey, Level(e.Level))
+		core[options.LevelKey] = true
+	}
+	pair(options.MessageKey, e.Message)
+	core[options.MessageKey] = true
+	if options.CallerKey != "" && e.Caller != "" {
+		pair(options.CallerKey, e.Caller)
+		core[options.CallerKey] = true
+	}
+
+	for _, k := range order.Keys(e.Fields, options.PriorityKeys) {
+		v := e.Fields[k]
+		if core[k] {
+			k = "fields." + k
+		}
+		pair(k, v)
+	}
+
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+// AppendPair appends the key=value pair of key and v to dst, with no
+// separator, for the lines that are not entries.
+func AppendPair(dst []byte, key string, v interface{}, timeFormat string) []byte {
+	buf := bytes.NewBuffer(dst)
+	appendKey(buf, key)
+	buf.WriteByte('=')
+	appendValue(buf, v, timeFormat)
+	return buf.Bytes()
+}
+
+func appendKey(buf *bytes.Buffer, key string) {
+	if key == "" {
+		buf.WriteByte('_')
+		return
+	}
+	for _, r := range key {
+		if r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError || !unicode.IsPrint(r) {
+			r = '_'
+		}
+		buf.WriteRune(r)
+	}
+}
+
+func appendValue(buf *bytes.Buffer, v interface{}, timeFormat string) {
+	s := format(v, timeFormat)
+	if needsQuoting(s) {
+		buf.WriteString(strconv.Quote(s))
+		return
+	}
+	buf.WriteString(s)
+}
+
+// format returns the text of v, before quoting.
+func format(v interface{}, timeFormat string) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case error:
+		return v.Error()
+	case time.Time:
+		return formatTime(v, timeFormat)
+	case time.Duration:
+		return v.String()
+	case fmt.Stringer:
+		return v.String()
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr,
+		float32, float64, complex64, complex128, json.Number:
+		return fmt.Sprint(v)
+	}
+
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return fmt.Sprint(v)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func formatTime(t time.Time, format string) string {
+	switch format {
+	case "", TimeFormatRFC3339:
+		return t.Format(time.RFC3339)
+	case TimeFormatRFC3339Nano:
+		return t.Format(time.RFC3339Nano)
+	case TimeFormatISO8601:
+		return t.Format(iso8601Layout)
+	case TimeFormatEpoch:
+		return strconv.FormatInt(t.Unix(), 10)
+	case TimeFormatEpochMillis:
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	case TimeFormatEpochNanos:
+		return strconv.FormatInt(t.UnixNano(), 10)
+	default:
+		return t.Format(format)
+	}
+}
+
+func needsQuoting(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, r := range s {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || r == utf8.RuneError || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+package logfmt
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/stretchr/testify/suite"
+)
+
+type LogfmtSuite struct {
+	suite.Suite
+}
+
+func TestLogfmtSuite(t *testing.T) {
+	suite.Run(t, new(LogfmtSuite))
+}
+
+var at = time.Date(2021, 1, 2, 3, 4, 5, 123456789, time.UTC)
+
+var defaults = Options{
+	TimeKey:    DefaultTimeKey,
+	LevelKey:   DefaultLevelKey,
+	MessageKey: DefaultMessageKey,
+	CallerKey:  DefaultCallerKey,
+}
+
+func (s *LogfmtSuite) TestLevel() {
+	tt := []struct {
+		level log.Level
+		want  string
+	}{
+		{level: log.TraceLevel, want: "trace"},
+		{level: log.DebugLevel, want: "debug"},
+		{level: log.InfoLevel, want: "info"},
+		{level: log.WarnLevel, want: "warn"},
+		{level: log.ErrorLevel, want: "error"},
+		{level: log.PanicLevel, want: "panic"},
+		{level: log.FatalLevel, want: "fatal"},
+	}
+	for _, t := range tt {
+		s.Run(t.level.String(), func() {
+			s.Assert().Equal(t.want, Level(t.level))
+		})
+	}
+}
+
+func (s *LogfmtSuite) TestAppend() {
+	tt := []struct {
+		name    string
+		entry   entry.Entry
+		options Options
+		want    string
+	}{
+		{
+			name:    "without fields",
+			entry:   entry.Entry{Time: at, Level: log.InfoLevel, Message: "hello"},
+			options: defaults,
+			want:    `time=2021-01-02T03:04:05Z level=info msg=hello`,
+		},
+		{
+			name: "with caller and sorted fields",
+			entry: entry.Entry{Time: at, Level: log.WarnLevel, Message: "order created", Caller: "app/main.go:10", Fields: log.Fields{
+				"user.name": "John Doe",
+				"order":     1,
+				"err":       errors.New("bad"),
+				"ok":        true,
+			}},
+			options: defaults,
+			want:    `time=2021-01-02T03:04:05Z level=warn msg="order created" caller=app/main.go:10 err=bad ok=true order=1 user.name="John Doe"`,
+		},
+		{
+			name: "with quoting and escaping",
+			entry: entry.Entry{Time: at, Level: log.ErrorLevel, Message: "a \"quoted\"\nline", Fields: log.Fields{
+				"empty":     "",
+				"equals":    "a=b",
+				"backslash": `C:\tmp`,
+				"bad key=":  "v",
+				"unicode":   "não",
+				"nil":       nil,
+			}},
+			options: defaults,
+			want: `time=2021-01-02T03:04:05Z level=error msg="a \"quoted\"\nline" backslash="C:\\tmp" bad_key_=v ` +
+				`empty="" equals="a=b" nil=null unicode=não`,
+		},
+		{
+			name: "with json values and durations",
+			entry: entry.Entry{Time: at, Level: log.DebugLevel, Message: "hello", Fields: log.Fields{
+				"map":     map[string]interface{}{"b": 1, "a": "<x>"},
+				"slice":   []int{1, 2},
+				"elapsed": 1500 * time.Millisecond,
+			}},
+			options: defaults,
+			want:    `time=2021-01-02T03:04:05Z level=debug msg=hello elapsed=1.5s map="{\"a\":\"<x>\",\"b\":1}" slice=[1,2]`,
+		},
+		{
+			name:    "with priority keys",
+			entry:   entry.Entry{Time: at, Level: log.InfoLevel, Message: "hello", Fields: log.Fields{"b": 1, "user_id": 2, "a": 3, "request_id": 4}},
+			options: Options{MessageKey: "msg", PriorityKeys: []string{"request_id", "trace_id", "user_id"}},
+			want:    `msg=hello request_id=4 user_id=2 a=3 b=1`,
+		},
+		{
+			name:    "with core keys as fields",
+			entry:   entry.Entry{Time: at, Level: log.InfoLevel, Message: "hello", Fields: log.Fields{"level": "custom", "msg": "other"}},
+			options: defaults,
+			want:    `time=2021-01-02T03:04:05Z level=info msg=hello fields.level=custom fields.msg=other`,
+		},
+		{
+			name:    "with custom keys and time format",
+			entry:   entry.Entry{Time: at, Level: log.InfoLevel, Message: "hello", Caller: "main.go:1"},
+			options: Options{TimeKey: "ts", LevelKey: "lvl", MessageKey: "message", TimeFormat: TimeFormatEpochMillis},
+			want:    `ts=1609556645123 lvl=info message=hello`,
+		},
+		{
+			name:    "without time and level",
+			entry:   entry.Entry{Time: at, Level: log.InfoLevel, Message: "hello"},
+			options: Options{},
+			want:    `msg=hello`,
+		},
+		{
+			name:    "with iso8601 time",
+			entry:   entry.Entry{Time: at, Level: log.InfoLevel, Message: "hello"},
+			options: Options{TimeKey: "time", TimeFormat: TimeFormatISO8601},
+			want:    `time=2021-01-02T03:04:05.123Z msg=hello`,
+		},
+	}
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			s.Assert().Equal(t.want+"\n", string(Append(nil, t.entry, t.options)))
+		})
+	}
+}
+
+func (s *LogfmtSuite) TestWriter() {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, Options{})
+
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Level: log.InfoLevel, Message: "a"}))
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Level: log.InfoLevel, Message: "b"}))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	s.Require().Equal([]string{"msg=a", "msg=b"}, lines)
+}
+
+func (s *LogfmtSuite) TestAppendPair() {
+	b := AppendPair([]byte("a=1 "), "user name", "John Doe", "")
+	s.Assert().Equal(`a=1 user_name="John Doe"`, string(b))
+
+	b = AppendPair(nil, "time", at, TimeFormatRFC3339Nano)
+	s.Assert().Equal("time=2021-01-02T03:04:05.123456789Z", string(b))
+}
@@ -0,0 +1,98 @@
+package logtest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/americanas-go/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// closeURLEnv holds the Loki URL of the process run again by Close to test
+// Fatal.
+const closeURLEnv = "LOGTEST_CLOSE_URL"
+
+// Close tests that the logger returned by newLogger, writing only to the Loki
+// push URL and buffering its entries for longer than the test, sends them on
+// Sync, Close and Fatal. The test binary is run again to test Fatal, with the
+// name of the test calling Close.
+func Close(t *testing.T, newLogger func(url string) log.Logger) {
+	if url := os.Getenv(closeURLEnv); url != "" {
+		logger := newLogger(url)
+		logger.Info("pending")
+		logger.Fatal("exiting")
+		return
+	}
+
+	test := t.Name()
+	tests := []struct {
+		name string
+		run  func(t *testing.T, url string, bodies chan string)
+	}{
+		{
+			name: "Sync and Close send the pending entries",
+			run: func(t *testing.T, url string, bodies chan string) {
+				logger := newLogger(url)
+				closer, ok := logger.(log.Closer)
+				require.True(t, ok)
+
+				logger.WithField("ID", "1").Info("synced")
+				require.NoError(t, closer.Sync())
+				assert.Contains(t, receive(t, bodies), "synced")
+
+				logger.Info("closed")
+				require.NoError(t, closer.Close())
+				assert.Contains(t, receive(t, bodies), "closed")
+				require.NoError(t, closer.Close())
+			},
+		},
+		{
+			name: "Fatal sends the pending entries",
+			run: func(t *testing.T, url string, bodies chan string) {
+				cmd := exec.Command(os.Args[0], "-test.run=^"+test+"$")
+				cmd.Env = append(os.Environ(), closeURLEnv+"="+url)
+				assert.Error(t, cmd.Run())
+
+				body := receive(t, bodies)
+				assert.Contains(t, body, "pending")
+				assert.Contains(t, body, "exiting")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url, bodies := loki(t)
+			tt.run(t, url, bodies)
+		})
+	}
+}
+
+// loki returns the push URL of a Loki endpoint sending the bodies it receives
+// to the returned channel.
+func loki(t *testing.T) (string, chan string) {
+	bodies := make(chan string, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- string(body)
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL + "/loki/api/v1/push", bodies
+}
+
+func receive(t *testing.T, bodies chan string) string {
+	select {
+	case body := <-bodies:
+		return body
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no push received")
+		return ""
+	}
+}
@@ -0,0 +1,103 @@
+package logtest
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/americanas-go/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Console tests that the console writers write the entries to stdout and
+// stderr. newLogger returns a logger writing DEBUG JSON entries to the console
+// writer STDOUT, STDERR or SPLIT, splitting them at splitLevel; message is the
+// key of their message, and decode, when not nil, returns the JSON lines of
+// what the logger wrote.
+func Console(t *testing.T, newLogger func(writer, splitLevel string) log.Logger, message string, decode func([]byte) []byte) {
+	tests := []struct {
+		name       string
+		writer     string
+		splitLevel string
+		wantStdout []string
+		wantStderr []string
+	}{
+		{
+			name:       "when STDOUT",
+			writer:     "STDOUT",
+			splitLevel: "WARN",
+			wantStdout: []string{"debug", "info", "warn", "error"},
+		},
+		{
+			name:       "when STDERR",
+			writer:     "STDERR",
+			splitLevel: "WARN",
+			wantStderr: []string{"debug", "info", "warn", "error"},
+		},
+		{
+			name:       "when SPLIT",
+			writer:     "SPLIT",
+			splitLevel: "WARN",
+			wantStdout: []string{"debug", "info"},
+			wantStderr: []string{"warn", "error"},
+		},
+		{
+			name:       "when SPLIT below the console level",
+			writer:     "SPLIT",
+			splitLevel: "TRACE",
+			wantStderr: []string{"debug", "info", "warn", "error"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr := redirect(t)
+
+			logger := newLogger(tt.writer, tt.splitLevel)
+			logger.Debug("debug")
+			logger.Info("info")
+			logger.Warn("warn")
+			logger.Error("error")
+
+			assertMessages(t, tt.wantStdout, stdout.Name(), message, decode)
+			assertMessages(t, tt.wantStderr, stderr.Name(), message, decode)
+		})
+	}
+}
+
+// redirect replaces os.Stdout and os.Stderr by temporary files until the end
+// of the test and returns them.
+func redirect(t *testing.T) (stdout *os.File, stderr *os.File) {
+	dir := t.TempDir()
+
+	stdout, err := os.Create(dir + "/stdout")
+	require.NoError(t, err)
+	stderr, err = os.Create(dir + "/stderr")
+	require.NoError(t, err)
+
+	previousStdout, previousStderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = stdout, stderr
+	t.Cleanup(func() {
+		os.Stdout, os.Stderr = previousStdout, previousStderr
+	})
+
+	return stdout, stderr
+}
+
+func assertMessages(t *testing.T, want []string, name string, message string, decode func([]byte) []byte) {
+	b, err := os.ReadFile(name)
+	require.NoError(t, err)
+
+	got := []string{}
+	for _, line := range strings.Split(strings.TrimSpace(string(decodeJSON(decode, b))), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		got = append(got, entry[message].(string))
+	}
+	assert.ElementsMatch(t, want, got)
+}
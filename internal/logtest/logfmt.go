@@ -0,0 +1,63 @@
+package logtest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/americanas-go/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Logfmt tests that the LOGFMT formatter writes the same lines with every
+// backend, newLogger returning the logger configured by file.
+func Logfmt(t *testing.T, newLogger func(file File) log.Logger) {
+	at := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	clock := func() time.Time { return at }
+
+	t.Run("fields", func(t *testing.T) {
+		got := logFile(t, newLogger, File{
+			Formatter: "LOGFMT",
+			Names:     FieldNames{Time: "time", Level: "level", Message: "msg"},
+			Clock:     clock,
+		}, func(logger log.Logger) {
+			logger.Debugf("hello %s", "world")
+			logger.
+				WithFields(log.Fields{"user.name": "John Doe", "order": 1, "path": `C:\tmp`}).
+				WithError(errors.New("something bad")).
+				Warn("order created")
+		})
+
+		assert.Equal(t, []string{
+			`time=2021-01-02T03:04:05Z level=debug msg="hello world"`,
+			`time=2021-01-02T03:04:05Z level=warn msg="order created" err="something bad" order=1 path="C:\\tmp" user.name="John Doe"`,
+		}, lines(got))
+	})
+
+	t.Run("keys", func(t *testing.T) {
+		got := logFile(t, newLogger, File{
+			Formatter:  "LOGFMT",
+			Names:      FieldNames{Time: "timestamp", Level: "lvl", Message: "message"},
+			TimeFormat: "EPOCH",
+			Clock:      clock,
+		}, func(logger log.Logger) {
+			logger.WithField("level", "custom").Info("hello")
+		})
+
+		assert.Equal(t, []string{`timestamp=1609556645 lvl=info message=hello level=custom`}, lines(got))
+	})
+
+	t.Run("caller", func(t *testing.T) {
+		got := logFile(t, newLogger, File{
+			Formatter: "LOGFMT",
+			Names:     FieldNames{Time: "time", Level: "level", Message: "msg", Caller: "caller"},
+			Clock:     clock,
+		}, func(logger log.Logger) {
+			logger.Info("hello")
+		})
+
+		require.Len(t, lines(got), 1)
+		assert.Regexp(t, `^time=2021-01-02T03:04:05Z level=info msg=hello caller=\S+:\d+$`, lines(got)[0])
+	})
+}
@@ -0,0 +1,61 @@
+// Package logtest holds the tests shared by the contribs: each contrib calls
+// them from its own tests with a function building its logger, so that the
+// behaviors promised by every backend are tested the same way.
+package logtest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/americanas-go/log"
+	"github.com/stretchr/testify/require"
+)
+
+// File configures the loggers of the tests of the file formatters, which each
+// contrib maps to its own options: the entries are written at DEBUG level to
+// the app.log file of Dir, and not to the console.
+type File struct {
+	Dir        string           // directory of app.log
+	Formatter  string           // formatter of the file, LOGFMT or PRETTY
+	Names      FieldNames       // fields written on every entry
+	TimeFormat string           // format of the time, the default one when empty
+	Clock      func() time.Time // time of the entries
+}
+
+// FieldNames are the names of the fields written on every entry. An empty
+// name omits the field, except for the message.
+type FieldNames struct {
+	Time    string
+	Level   string
+	Message string
+	Caller  string
+}
+
+// logFile logs with logWith and the logger returned by newLogger for file,
+// its Dir being a temporary directory, and returns what was written to the
+// file.
+func logFile(t *testing.T, newLogger func(file File) log.Logger, file File, logWith func(logger log.Logger)) string {
+	file.Dir = t.TempDir()
+	logWith(newLogger(file))
+
+	data, err := os.ReadFile(filepath.Join(file.Dir, "app.log"))
+	require.NoError(t, err)
+	return string(data)
+}
+
+// lines returns the lines of s, without its last new line.
+func lines(s string) []string {
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// decodeJSON returns the JSON lines of b with decode, b itself when decode is
+// nil.
+func decodeJSON(decode func([]byte) []byte, b []byte) []byte {
+	if decode == nil {
+		return b
+	}
+	return decode(b)
+}
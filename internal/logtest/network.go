@@ -0,0 +1,43 @@
+package logtest
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/network"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Network tests that the network output sends the entries as lines. newLogger
+// returns a logger writing only the WARN entries, with the msg key for their
+// message, to the TCP address and counting them in metrics; decode, when not
+// nil, returns the JSON lines of what the logger wrote.
+func Network(t *testing.T, newLogger func(address string, metrics *network.Metrics) log.Logger, decode func([]byte) []byte) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	metrics := &network.Metrics{}
+	logger := newLogger(ln.Addr().String(), metrics)
+	logger.Info("skipped")
+	logger.WithField("ID", "1").Warn("blah")
+
+	conn, err := ln.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	require.NoError(t, err)
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(decodeJSON(decode, line), &entry))
+	assert.Equal(t, "blah", entry["msg"])
+	assert.Equal(t, "1", entry["ID"])
+	assert.Eventually(t, func() bool { return metrics.EntriesSent.Load() == 1 }, 5*time.Second, time.Millisecond)
+}
@@ -0,0 +1,57 @@
+package logtest
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/americanas-go/log"
+	"github.com/stretchr/testify/assert"
+)
+
+// Pretty tests that the PRETTY formatter writes the same text with every
+// backend, newLogger returning the logger configured by file. The colors are
+// left to the terminal detection, which finds none in the tests.
+func Pretty(t *testing.T, newLogger func(file File) log.Logger) {
+	for _, key := range []string{"NO_COLOR", "FORCE_COLOR", "CLICOLOR", "CLICOLOR_FORCE"} {
+		t.Setenv(key, "")
+	}
+	at := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	got := logFile(t, newLogger, File{
+		Formatter: "PRETTY",
+		Names:     FieldNames{Time: "time", Level: "level", Message: "msg"},
+		Clock:     func() time.Time { return at },
+	}, func(logger log.Logger) {
+		logger.WithFields(map[string]interface{}{
+			"order": 1,
+			"user":  map[string]interface{}{"name": "John Doe"},
+		}).WithError(errors.New("something bad")).Warn("order created")
+	})
+
+	assert.Equal(t, "WARN  +0.000s   order created\n"+
+		"    order: 1\n"+
+		"    user:\n"+
+		"      name: John Doe\n"+
+		"    err: something bad\n", got)
+
+	// the time of the entries is read with its fraction of a second
+	start := time.Date(2021, 1, 2, 3, 4, 5, 700*int(time.Millisecond), time.UTC)
+	var calls atomic.Int32
+	got = logFile(t, newLogger, File{
+		Formatter: "PRETTY",
+		Names:     FieldNames{Time: "time", Level: "level", Message: "msg"},
+		Clock: func() time.Time {
+			// the first call is the start of the relative times
+			if calls.Add(1) == 1 {
+				return start
+			}
+			return start.Add(500 * time.Millisecond)
+		},
+	}, func(logger log.Logger) {
+		logger.Warn("order created")
+	})
+
+	assert.Equal(t, "WARN  +0.500s   order created\n", got)
+}
@@ -0,0 +1,494 @@
+// Package loki pushes entries to Grafana Loki, through the
+// /loki/api/v1/push endpoint described by
+// https://grafana.com/docs/loki/latest/reference/loki-http-api/#ingest-logs.
+//
+// Entries are queued and sent by a background goroutine, in batches flushed
+// when they reach the batch size or every batch wait. A batch is encoded as
+// JSON, optionally gzipped, or as snappy compressed protobuf. The pushes
+// rejected with 429 or 5xx, and the failed requests, are retried with an
+// exponential backoff, the other rejected pushes are dropped.
+//
+// The stream labels of an entry are the static labels and the selected fields
+// of the entry, every other field goes to the line. The streams are limited:
+// once the limit is reached, the entries which would open a new stream keep
+// their fields in the line and only get the static labels.
+package loki
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/americanas-go/log/internal/entry"
+)
+
+// Encodings.
+const (
+	EncodingJSON     = "JSON"
+	EncodingProtobuf = "PROTOBUF"
+)
+
+const (
+	defaultURL        = "http://localhost:3100/loki/api/v1/push"
+	defaultMaxStreams = 100
+	defaultBatchSize  = 100
+	defaultBatchWait  = time.Second
+	defaultBufferSize = 1024
+	defaultTimeout    = 10 * time.Second
+	defaultMaxRetries = 10
+	defaultMinBackoff = 500 * time.Millisecond
+	defaultMaxBackoff = 30 * time.Second
+
+	// defaultJob labels the streams when no static label is set, as Loki
+	// rejects the streams without labels.
+	defaultJob = "app"
+
+	tenantHeader = "X-Scope-OrgID"
+	closeTimeout = 5 * time.Second
+)
+
+var (
+	// Encodings are the supported encodings.
+	Encodings = []string{EncodingJSON, EncodingProtobuf}
+
+	errBufferFull = errors.New("loki: buffer is full, entry dropped")
+)
+
+// Options configures a Writer. Zero values take a default.
+type Options struct {
+	URL         string            // push endpoint, http://localhost:3100/loki/api/v1/push when empty
+	TenantID    string            // tenant of the entries, sent in the X-Scope-OrgID header when set
+	Labels      map[string]string // static labels of every stream, job=app when empty
+	LabelFields []string          // fields promoted to stream labels, removed from the line
+	MaxStreams  int               // most streams opened by the promoted fields, 100 when zero
+	Encoding    string            // JSON/PROTOBUF, JSON when empty
+	Gzip        bool              // gzip the JSON pushes
+	BatchSize   int               // entries of a batch, 100 when zero
+	BatchWait   time.Duration     // longest wait before a batch is sent, 1s when zero
+	BufferSize  int               // entries waiting to be sent, 1024 when zero
+	Timeout     time.Duration     // timeout of a push, 10s when zero
+	MaxRetries  int               // retries of a push, 10 when zero
+	MinBackoff  time.Duration     // first delay between retries, 500ms when zero
+	MaxBackoff  time.Duration     // longest delay between retries, 30s when zero
+	Client      *http.Client      // client of the pushes, one with Timeout when nil
+
+	// keys of the message, level and caller in the lines, the level and the
+	// caller are omitted when empty
+	MessageKey string
+	LevelKey   string
+	CallerKey  string
+}
+
+// event is an encoded entry.
+type event struct {
+	stream string // labels in the Prometheus format, the key of the stream
+	time   time.Time
+	line   string
+}
+
+// Writer pushes entries to Loki.
+type Writer struct {
+	options Options
+
+	static    map[string]string
+	staticKey string
+	mu        sync.Mutex
+	streams   map[string]struct{}
+
+	events  chan event
+	flushes chan chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+	closed  sync.Once
+}
+
+// New returns a Writer from options and starts sending.
+func New(options Options) *Writer {
+	if options.URL == "" {
+		options.URL = defaultURL
+	}
+	if options.MaxStreams <= 0 {
+		options.MaxStreams = defaultMaxStreams
+	}
+	if options.Encoding != EncodingProtobuf {
+		options.Encoding = EncodingJSON
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = defaultBatchSize
+	}
+	if options.BatchWait <= 0 {
+		options.BatchWait = defaultBatchWait
+	}
+	if options.BufferSize <= 0 {
+		options.BufferSize = defaultBufferSize
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = defaultTimeout
+	}
+	if options.MaxRetries <= 0 {
+		options.MaxRetries = defaultMaxRetries
+	}
+	if options.MinBackoff <= 0 {
+		options.MinBackoff = defaultMinBackoff
+	}
+	if options.MaxBackoff < options.MinBackoff {
+		options.MaxBackoff = max(defaultMaxBackoff, options.MinBackoff)
+	}
+	if options.Client == nil {
+		options.Client = &http.Client{Timeout: options.Timeout}
+	}
+	if options.MessageKey == "" {
+		options.MessageKey = "message"
+	}
+
+	static := make(map[string]string, len(options.Labels))
+	for k, v := range options.Labels {
+		static[labelName(k)] = v
+	}
+	if len(static) == 0 {
+		static["job"] = defaultJob
+	}
+
+	w := &Writer{
+		options:   options,
+		static:    static,
+		staticKey: streamKey(static),
+		streams:   map[string]struct{}{},
+		events:    make(chan event, options.BufferSize),
+		flushes:   make(chan chan struct{}),
+		done:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+
+	go w.run()
+	return w
+}
+
+// WriteEntry implements entry.Writer. It queues e and fails when the buffer is
+// full.
+func (w *Writer) WriteEntry(e entry.Entry) error {
+	select {
+	case w.events <- w.encode(e):
+		return nil
+	default:
+		return errBufferFull
+	}
+}
+
+// Flush sends the pending batch, the queued entries included, and waits for
+// it to be pushed, retries included, for up to 5 seconds.
+func (w *Writer) Flush() error {
+	flushed := make(chan struct{})
+	timeout := time.After(closeTimeout)
+
+	select {
+	case w.flushes <- flushed:
+	case <-w.stopped:
+		return nil
+	case <-timeout:
+		return errors.New("loki: timed out sending the pending entries")
+	}
+
+	select {
+	case <-flushed:
+		return nil
+	case <-w.stopped:
+		return nil
+	case <-timeout:
+		return errors.New("loki: timed out sending the pending entries")
+	}
+}
+
+// Close sends the pending batch, trying once, and stops sending.
+func (w *Writer) Close() error {
+	w.closed.Do(func() { close(w.done) })
+
+	select {
+	case <-w.stopped:
+		return nil
+	case <-time.After(closeTimeout):
+		return errors.New("loki: timed out sending the pending entries")
+	}
+}
+
+func (w *Writer) encode(e entry.Entry) event {
+	labels := make(map[string]string, len(w.static)+len(w.options.LabelFields))
+	for k, v := range w.static {
+		labels[k] = v
+	}
+	promoted := map[string]bool{}
+	for _, field := range w.options.LabelFields {
+		if v, ok := e.Fields[field]; ok {
+			labels[labelName(field)] = fmt.Sprint(v)
+			promoted[field] = true
+		}
+	}
+
+	stream := streamKey(labels)
+	if len(promoted) > 0 && !w.open(stream) {
+		// the stream would exceed the limit, the fields stay in the line
+		promoted = nil
+		stream = w.staticKey
+	}
+
+	record := make(map[string]interface{}, len(e.Fields)+3)
+	for k, v := range e.Fields {
+		if !promoted[k] {
+			record[k] = value(v)
+		}
+	}
+	if w.options.LevelKey != "" {
+		record[w.options.LevelKey] = e.Level.String()
+	}
+	if w.options.CallerKey != "" && e.Caller != "" {
+		record[w.options.CallerKey] = e.Caller
+	}
+	record[w.options.MessageKey] = e.Message
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		line, _ = json.Marshal(map[string]string{w.options.MessageKey: e.Message, "error": err.Error()})
+	}
+
+	t := e.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	return event{stream: stream, time: t, line: string(line)}
+}
+
+// open tells whether the stream is known or can be opened, opening it.
+func (w *Writer) open(stream string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.streams[stream]; ok {
+		return true
+	}
+	if len(w.streams) >= w.options.MaxStreams {
+		return false
+	}
+	w.streams[stream] = struct{}{}
+	return true
+}
+
+func (w *Writer) run() {
+	defer close(w.stopped)
+
+	ticker := time.NewTicker(w.options.BatchWait)
+	defer ticker.Stop()
+
+	var batch []event
+	for {
+		var flushed chan struct{}
+		select {
+		case <-w.done:
+			// the pending events are sent once, the queued ones included
+			for len(w.events) > 0 {
+				batch = append(batch, <-w.events)
+			}
+			if len(batch) > 0 {
+				_, _ = w.push(w.body(batch))
+			}
+			return
+		case ev := <-w.events:
+			batch = append(batch, ev)
+			if len(batch) < w.options.BatchSize {
+				continue
+			}
+		case flushed = <-w.flushes:
+			for len(w.events) > 0 {
+				batch = append(batch, <-w.events)
+			}
+		case <-ticker.C:
+		}
+
+		if len(batch) > 0 {
+			if !w.deliver(w.body(batch)) {
+				return
+			}
+			batch = batch[:0]
+		}
+		if flushed != nil {
+			close(flushed)
+		}
+	}
+}
+
+// deliver pushes body, retrying the retryable failures. It returns false when
+// the writer was closed meanwhile.
+func (w *Writer) deliver(body []byte) bool {
+	backoff := w.options.MinBackoff
+	for retry := 0; ; retry++ {
+		retryable, err := w.push(body)
+		if err == nil || !retryable || retry == w.options.MaxRetries {
+			return true
+		}
+
+		select {
+		case <-w.done:
+			// the push is tried once more on close
+			_, _ = w.push(body)
+			return false
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, w.options.MaxBackoff)
+	}
+}
+
+// push sends body once. It tells whether a failed push can be retried.
+func (w *Writer) push(body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, w.options.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	if w.options.Encoding == EncodingProtobuf {
+		req.Header.Set("Content-Type", "application/x-protobuf")
+	} else {
+		req.Header.Set("Content-Type", "application/json")
+		if w.options.Gzip {
+			req.Header.Set("Content-Encoding", "gzip")
+		}
+	}
+	if w.options.TenantID != "" {
+		req.Header.Set(tenantHeader, w.options.TenantID)
+	}
+
+	resp, err := w.options.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1024))
+
+	if resp.StatusCode/100 == 2 {
+		return false, nil
+	}
+	err = fmt.Errorf("loki: push rejected with status %d", resp.StatusCode)
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, err
+}
+
+// stream is the events of a batch sharing their labels.
+type stream struct {
+	labels string
+	events []event
+}
+
+// body encodes the batch in the encoding of the writer.
+func (w *Writer) body(batch []event) []byte {
+	var streams []*stream
+	byLabels := map[string]*stream{}
+	for _, ev := range batch {
+		s, ok := byLabels[ev.stream]
+		if !ok {
+			s = &stream{labels: ev.stream}
+			byLabels[ev.stream] = s
+			streams = append(streams, s)
+		}
+		s.events = append(s.events, ev)
+	}
+
+	if w.options.Encoding == EncodingProtobuf {
+		return encodeSnappy(encodeProtobuf(streams))
+	}
+
+	body := encodeJSON(streams)
+	if !w.options.Gzip {
+		return body
+	}
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, _ = gz.Write(body)
+	_ = gz.Close()
+	return buf.Bytes()
+}
+
+// encodeJSON returns the JSON push request of streams.
+func encodeJSON(streams []*stream) []byte {
+	type jsonStream struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	}
+
+	request := struct {
+		Streams []jsonStream `json:"streams"`
+	}{Streams: make([]jsonStream, 0, len(streams))}
+	for _, s := range streams {
+		js := jsonStream{Stream: parseStreamKey(s.labels), Values: make([][2]string, 0, len(s.events))}
+		for _, ev := range s.events {
+			js.Values = append(js.Values, [2]string{strconv.FormatInt(ev.time.UnixNano(), 10), ev.line})
+		}
+		request.Streams = append(request.Streams, js)
+	}
+
+	body, _ := json.Marshal(request)
+	return body
+}
+
+// streamKey returns labels in the Prometheus format, sorted by name, such as
+// {app="orders", env="prod"}. It is the labels field of the protobuf streams.
+func streamKey(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(strconv.Quote(labels[name]))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// parseStreamKey returns the labels of a key made by streamKey.
+func parseStreamKey(key string) map[string]string {
+	labels := map[string]string{}
+	rest := strings.TrimSuffix(strings.TrimPrefix(key, "{"), "}")
+	for rest != "" {
+		name, after, _ := strings.Cut(rest, "=")
+		quoted, err := strconv.QuotedPrefix(after)
+		if err != nil {
+			break
+		}
+		labels[name], _ = strconv.Unquote(quoted)
+		rest = strings.TrimPrefix(after[len(quoted):], ", ")
+	}
+	return labels
+}
+
+// labelName replaces the characters not allowed in a label name by _.
+func labelName(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 0 && c >= '0' && c <= '9') {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+// value returns v as it should be encoded in a line, errors being written as
+// their message.
+func value(v interface{}) interface{} {
+	if err, ok := v.(error); ok {
+		return err.Error()
+	}
+	return v
+}
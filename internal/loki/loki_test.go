@@ -0,0 +1,334 @@
+package loki
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/americanas-go/log/internal/protobuf"
+	"github.com/stretchr/testify/suite"
+)
+
+type LokiSuite struct {
+	suite.Suite
+}
+
+func TestLokiSuite(t *testing.T) {
+	suite.Run(t, new(LokiSuite))
+}
+
+var at = time.Date(2021, 1, 2, 3, 4, 5, 123456789, time.UTC)
+
+// push is a request received by the server.
+type push struct {
+	header http.Header
+	body   []byte
+}
+
+// server is a Loki push endpoint answering the statuses in turn, then 204.
+func (s *LokiSuite) server(statuses ...int) (*httptest.Server, chan push, *atomic.Int32) {
+	pushes := make(chan push, 100)
+	calls := &atomic.Int32{}
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1))
+		if r.URL.Path != "/loki/api/v1/push" {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if n <= len(statuses) {
+			rw.WriteHeader(statuses[n-1])
+			return
+		}
+		pushes <- push{header: r.Header, body: body}
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	return srv, pushes, calls
+}
+
+func (s *LokiSuite) receive(pushes chan push) push {
+	select {
+	case p := <-pushes:
+		return p
+	case <-time.After(5 * time.Second):
+		s.FailNow("no push received")
+		return push{}
+	}
+}
+
+func (s *LokiSuite) TestJSON() {
+	srv, pushes, _ := s.server()
+	defer srv.Close()
+
+	w := New(Options{
+		URL:         srv.URL + "/loki/api/v1/push",
+		TenantID:    "team-a",
+		Labels:      map[string]string{"app": "orders"},
+		LabelFields: []string{"service"},
+		BatchSize:   2,
+		LevelKey:    "level",
+	})
+	defer w.Close()
+
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Level: log.InfoLevel, Message: "a", Fields: log.Fields{"service": "api", "ID": 1}}))
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Level: log.ErrorLevel, Message: "b", Fields: log.Fields{"err": errors.New("bad")}}))
+
+	p := s.receive(pushes)
+	s.Assert().Equal("team-a", p.header.Get("X-Scope-OrgID"))
+	s.Assert().Equal("application/json", p.header.Get("Content-Type"))
+	s.Assert().JSONEq(`{"streams":[
+		{"stream":{"app":"orders","service":"api"},"values":[["1609556645123456789","{\"ID\":1,\"level\":\"INFO\",\"message\":\"a\"}"]]},
+		{"stream":{"app":"orders"},"values":[["1609556645123456789","{\"err\":\"bad\",\"level\":\"ERROR\",\"message\":\"b\"}"]]}
+	]}`, string(p.body))
+}
+
+func (s *LokiSuite) TestGzip() {
+	srv, pushes, _ := s.server()
+	defer srv.Close()
+
+	w := New(Options{URL: srv.URL + "/loki/api/v1/push", Gzip: true, BatchSize: 1})
+	defer w.Close()
+
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Message: "a"}))
+
+	p := s.receive(pushes)
+	s.Assert().Equal("gzip", p.header.Get("Content-Encoding"))
+	r, err := gzip.NewReader(bytes.NewReader(p.body))
+	s.Require().NoError(err)
+	body, err := io.ReadAll(r)
+	s.Require().NoError(err)
+	s.Assert().JSONEq(`{"streams":[{"stream":{"job":"app"},"values":[["1609556645123456789","{\"message\":\"a\"}"]]}]}`, string(body))
+}
+
+func (s *LokiSuite) TestProtobuf() {
+	srv, pushes, _ := s.server()
+	defer srv.Close()
+
+	w := New(Options{URL: srv.URL + "/loki/api/v1/push", Encoding: EncodingProtobuf, BatchSize: 2})
+	defer w.Close()
+
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Message: "a"}))
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at.Add(time.Second), Message: strings.Repeat("b", 100)}))
+
+	p := s.receive(pushes)
+	s.Assert().Equal("application/x-protobuf", p.header.Get("Content-Type"))
+
+	request := decodeProtobuf(s.T(), decodeSnappy(s.T(), p.body))
+	s.Require().Len(request[1], 1)
+	adapter := decodeProtobuf(s.T(), request[1][0].([]byte))
+	s.Assert().Equal(`{job="app"}`, string(adapter[1][0].([]byte)))
+	s.Require().Len(adapter[2], 2)
+
+	var lines []string
+	for _, e := range adapter[2] {
+		fields := decodeProtobuf(s.T(), e.([]byte))
+		timestamp := decodeProtobuf(s.T(), fields[1][0].([]byte))
+		s.Assert().Equal(uint64(123456789), timestamp[2][0])
+		lines = append(lines, string(fields[2][0].([]byte)))
+	}
+	s.Assert().Equal([]string{`{"message":"a"}`, `{"message":"` + strings.Repeat("b", 100) + `"}`}, lines)
+}
+
+func (s *LokiSuite) TestMaxStreams() {
+	srv, pushes, _ := s.server()
+	defer srv.Close()
+
+	w := New(Options{URL: srv.URL + "/loki/api/v1/push", LabelFields: []string{"user.id"}, MaxStreams: 1, BatchSize: 3})
+	defer w.Close()
+
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Message: "a", Fields: log.Fields{"user.id": 1}}))
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Message: "b", Fields: log.Fields{"user.id": 2}}))
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Message: "c", Fields: log.Fields{"user.id": 1}}))
+
+	s.Assert().JSONEq(`{"streams":[
+		{"stream":{"job":"app","user_id":"1"},"values":[["1609556645123456789","{\"message\":\"a\"}"],["1609556645123456789","{\"message\":\"c\"}"]]},
+		{"stream":{"job":"app"},"values":[["1609556645123456789","{\"message\":\"b\",\"user.id\":2}"]]}
+	]}`, string(s.receive(pushes).body))
+}
+
+func (s *LokiSuite) TestRetry() {
+	tt := []struct {
+		name     string
+		statuses []int
+		calls    int32
+		received bool
+	}{
+		{name: "too many requests", statuses: []int{http.StatusTooManyRequests}, calls: 2, received: true},
+		{name: "server error", statuses: []int{http.StatusBadGateway, http.StatusServiceUnavailable}, calls: 3, received: true},
+		{name: "bad request", statuses: []int{http.StatusBadRequest}, calls: 1},
+	}
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			srv, pushes, calls := s.server(t.statuses...)
+			defer srv.Close()
+
+			w := New(Options{URL: srv.URL + "/loki/api/v1/push", BatchSize: 1, MinBackoff: 10 * time.Millisecond})
+			s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Message: "a"}))
+
+			if t.received {
+				s.receive(pushes)
+			}
+			s.Assert().Eventually(func() bool { return calls.Load() == t.calls }, 5*time.Second, 10*time.Millisecond)
+			s.Require().NoError(w.Close())
+			s.Assert().Equal(t.calls, calls.Load())
+		})
+	}
+}
+
+func (s *LokiSuite) TestCloseFlushes() {
+	srv, pushes, _ := s.server()
+	defer srv.Close()
+
+	w := New(Options{URL: srv.URL + "/loki/api/v1/push", BatchWait: time.Hour})
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Message: "a"}))
+	s.Require().NoError(w.Close())
+
+	s.Assert().Contains(string(s.receive(pushes).body), `{\"message\":\"a\"}`)
+}
+
+func (s *LokiSuite) TestFlush() {
+	srv, pushes, _ := s.server()
+	defer srv.Close()
+
+	w := New(Options{URL: srv.URL + "/loki/api/v1/push", BatchWait: time.Hour})
+	defer w.Close()
+
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Message: "a"}))
+	s.Require().NoError(w.Flush())
+	s.Assert().Contains(string(s.receive(pushes).body), `{\"message\":\"a\"}`)
+
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Message: "b"}))
+	s.Require().NoError(w.Flush())
+	s.Assert().Contains(string(s.receive(pushes).body), `{\"message\":\"b\"}`)
+}
+
+func (s *LokiSuite) TestBufferFull() {
+	w := New(Options{URL: "http://127.0.0.1:1/loki/api/v1/push", BufferSize: 1, BatchSize: 1})
+	defer w.Close()
+
+	var err error
+	for i := 0; i < 10 && err == nil; i++ {
+		err = w.WriteEntry(entry.Entry{Message: "a"})
+	}
+	s.Assert().ErrorIs(err, errBufferFull)
+}
+
+func (s *LokiSuite) TestSnappy() {
+	random := make([]byte, 1000)
+	_, _ = rand.New(rand.NewSource(1)).Read(random)
+
+	tt := []struct {
+		name string
+		src  []byte
+	}{
+		{name: "empty", src: []byte{}},
+		{name: "short", src: []byte("abc")},
+		{name: "repeated", src: bytes.Repeat([]byte("loki"), 1000)},
+		{name: "literals", src: random},
+		{name: "several blocks", src: bytes.Repeat([]byte(`{"message":"order paid","ID":12345}`), 5000)},
+	}
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			got := encodeSnappy(t.src)
+			s.Assert().Equal(t.src, decodeSnappy(s.T(), got))
+			if len(t.src) > 1000 {
+				s.Assert().Less(len(got), len(t.src)/10)
+			}
+		})
+	}
+}
+
+// decodeSnappy decompresses a snappy block.
+func decodeSnappy(t *testing.T, src []byte) []byte {
+	n, read := binary.Uvarint(src)
+	if read <= 0 {
+		t.Fatal("snappy: invalid length")
+	}
+	src = src[read:]
+
+	dst := make([]byte, 0, n)
+	for len(src) > 0 {
+		tag := src[0]
+		switch tag & 0x03 {
+		case 0x00:
+			length := int(tag >> 2)
+			src = src[1:]
+			if length >= 60 {
+				size := length - 59
+				length = 0
+				for i := size - 1; i >= 0; i-- {
+					length = length<<8 | int(src[i])
+				}
+				src = src[size:]
+			}
+			length++
+			dst = append(dst, src[:length]...)
+			src = src[length:]
+		case 0x01:
+			length := int(tag>>2&0x07) + 4
+			offset := int(tag&0xe0)<<3 | int(src[1])
+			dst = appendCopy(dst, offset, length)
+			src = src[2:]
+		case 0x02:
+			length := int(tag>>2) + 1
+			offset := int(binary.LittleEndian.Uint16(src[1:]))
+			dst = appendCopy(dst, offset, length)
+			src = src[3:]
+		default:
+			length := int(tag>>2) + 1
+			offset := int(binary.LittleEndian.Uint32(src[1:]))
+			dst = appendCopy(dst, offset, length)
+			src = src[5:]
+		}
+	}
+	if uint64(len(dst)) != n {
+		t.Fatalf("snappy: decoded %d bytes, want %d", len(dst), n)
+	}
+	return dst
+}
+
+func appendCopy(dst []byte, offset, length int) []byte {
+	start := len(dst) - offset
+	for i := 0; i < length; i++ {
+		dst = append(dst, dst[start+i])
+	}
+	return dst
+}
+
+// decodeProtobuf returns the values of a message by field number, uint64 for
+// the varints and []byte for the length delimited ones.
+func decodeProtobuf(t *testing.T, b []byte) map[int][]interface{} {
+	parsed, err := protobuf.Parse(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := map[int][]interface{}{}
+	for _, f := range parsed {
+		if f.Type == protobuf.WireBytes {
+			fields[f.Number] = append(fields[f.Number], f.Bytes)
+		} else {
+			fields[f.Number] = append(fields[f.Number], f.Varint)
+		}
+	}
+	return fields
+}
+
+func (s *LokiSuite) TestStreamKey() {
+	labels := map[string]string{"b": `say "hi"`, "a": "1"}
+	key := streamKey(labels)
+	s.Assert().Equal(`{a="1", b="say \"hi\""}`, key)
+	s.Assert().Equal(labels, parseStreamKey(key))
+	s.Assert().Equal("user_id", labelName("user.id"))
+	s.Assert().Equal("_st", labelName("1st"))
+}
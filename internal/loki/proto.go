@@ -0,0 +1,110 @@
+package loki
+
+import (
+	"encoding/binary"
+
+	"github.com/americanas-go/log/internal/protobuf"
+)
+
+// encodeProtobuf returns the logproto.PushRequest of streams:
+//
+//	message PushRequest { repeated StreamAdapter streams = 1; }
+//	message StreamAdapter { string labels = 1; repeated EntryAdapter entries = 2; }
+//	message EntryAdapter { google.protobuf.Timestamp timestamp = 1; string line = 2; }
+//	message Timestamp { int64 seconds = 1; int32 nanos = 2; }
+func encodeProtobuf(streams []*stream) []byte {
+	var request []byte
+	for _, s := range streams {
+		var adapter []byte
+		adapter = protobuf.AppendString(adapter, 1, s.labels)
+		for _, ev := range s.events {
+			var timestamp []byte
+			timestamp = protobuf.AppendInt(timestamp, 1, ev.time.Unix())
+			timestamp = protobuf.AppendInt(timestamp, 2, int64(ev.time.Nanosecond()))
+
+			var e []byte
+			e = protobuf.AppendMessage(e, 1, timestamp)
+			e = protobuf.AppendString(e, 2, ev.line)
+			adapter = protobuf.AppendMessage(adapter, 2, e)
+		}
+		request = protobuf.AppendMessage(request, 1, adapter)
+	}
+	return request
+}
+
+const (
+	// snappyBlockSize is the largest input compressed as a whole, keeping the
+	// copy offsets within 2 bytes.
+	snappyBlockSize = 1 << 16
+	snappyTableBits = 14
+
+	snappyTagLiteral = 0x00
+	snappyTagCopy2   = 0x02
+)
+
+// encodeSnappy compresses src in the snappy block format, as described by
+// https://github.com/google/snappy/blob/main/format_description.txt, the one
+// Loki expects for the protobuf pushes. It looks for matches of 4 bytes with a
+// hash table and writes them as copies with a 2 bytes offset.
+func encodeSnappy(src []byte) []byte {
+	dst := binary.AppendUvarint(nil, uint64(len(src)))
+	for len(src) > 0 {
+		n := min(len(src), snappyBlockSize)
+		dst = appendSnappyBlock(dst, src[:n])
+		src = src[n:]
+	}
+	return dst
+}
+
+func appendSnappyBlock(dst, src []byte) []byte {
+	// table holds the position of the last occurrence of a hash, plus one
+	var table [1 << snappyTableBits]int32
+
+	literal := 0
+	for i := 0; i+4 <= len(src); {
+		current := binary.LittleEndian.Uint32(src[i:])
+		h := (current * 0x1e35a7bd) >> (32 - snappyTableBits)
+		candidate := int(table[h]) - 1
+		table[h] = int32(i + 1)
+
+		if candidate < 0 || binary.LittleEndian.Uint32(src[candidate:]) != current {
+			i++
+			continue
+		}
+
+		n := 4
+		for i+n < len(src) && src[candidate+n] == src[i+n] {
+			n++
+		}
+		dst = appendSnappyLiteral(dst, src[literal:i])
+		dst = appendSnappyCopy(dst, i-candidate, n)
+		i += n
+		literal = i
+	}
+	return appendSnappyLiteral(dst, src[literal:])
+}
+
+func appendSnappyLiteral(dst, lit []byte) []byte {
+	n := len(lit) - 1
+	switch {
+	case n < 0:
+		return dst
+	case n < 60:
+		dst = append(dst, byte(n)<<2|snappyTagLiteral)
+	case n < 1<<8:
+		dst = append(dst, 60<<2|snappyTagLiteral, byte(n))
+	default:
+		// the blocks are never longer than 1<<16
+		dst = append(dst, 61<<2|snappyTagLiteral, byte(n), byte(n>>8))
+	}
+	return append(dst, lit...)
+}
+
+func appendSnappyCopy(dst []byte, offset, length int) []byte {
+	for length > 0 {
+		n := min(length, 64)
+		dst = append(dst, byte(n-1)<<2|snappyTagCopy2, byte(offset), byte(offset>>8))
+		length -= n
+	}
+	return dst
+}
@@ -0,0 +1,313 @@
+package msgpack
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// Pair is a key and value of a map decoded by DecodeMap.
+type Pair struct {
+	Key   string
+	Value interface{}
+}
+
+// Ext is a decoded extension value, other than a timestamp.
+type Ext struct {
+	Type int8
+	Data []byte
+}
+
+// Decoder reads values from a stream. Integers are decoded as int64, or uint64
+// when they do not fit, floats as float64, binaries as []byte, arrays as
+// []interface{}, maps as map[string]interface{}, timestamps as time.Time and
+// the other extensions as Ext.
+type Decoder struct {
+	r *bufio.Reader
+}
+
+// NewDecoder returns a Decoder reading from r.
+func NewDecoder(r io.Reader) *Decoder {
+	if br, ok := r.(*bufio.Reader); ok {
+		return &Decoder{r: br}
+	}
+	return &Decoder{r: bufio.NewReader(r)}
+}
+
+// Unmarshal decodes the single value of data.
+func Unmarshal(data []byte) (interface{}, error) {
+	d := NewDecoder(bytes.NewReader(data))
+	v, err := d.Decode()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := d.r.ReadByte(); err != io.EOF {
+		return nil, errors.New("msgpack: trailing data")
+	}
+	return v, nil
+}
+
+// Decode reads the next value. It returns io.EOF when the stream ends between
+// two values and io.ErrUnexpectedEOF when it ends within one.
+func (d *Decoder) Decode() (interface{}, error) {
+	c, err := d.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := d.decode(c)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return v, err
+}
+
+// DecodeMap reads the next value, which must be a map, and returns its pairs
+// in the order of the stream.
+func (d *Decoder) DecodeMap() ([]Pair, error) {
+	c, err := d.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	pairs, err := d.decodePairs(c)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return pairs, err
+}
+
+func (d *Decoder) decodePairs(c byte) ([]Pair, error) {
+	var n int
+	switch {
+	case c&0xf0 == 0x80:
+		n = int(c & 0x0f)
+	case c == 0xde, c == 0xdf:
+		var err error
+		if n, err = d.length(c - 0xde + 1); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("msgpack: 0x%x is not a map", c)
+	}
+
+	pairs := make([]Pair, 0, min(n, 1024))
+	for i := 0; i < n; i++ {
+		k, err := d.next()
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.next()
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, Pair{Key: key(k), Value: v})
+	}
+	return pairs, nil
+}
+
+func (d *Decoder) decode(c byte) (interface{}, error) {
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xf0 == 0x80:
+		return d.decodeMap(int(c & 0x0f))
+	case c&0xf0 == 0x90:
+		return d.decodeArray(int(c & 0x0f))
+	case c&0xe0 == 0xa0:
+		return d.decodeString(int(c & 0x1f))
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.length(c - 0xc4)
+		if err != nil {
+			return nil, err
+		}
+		return d.read(n)
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.length(c - 0xc7)
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeExt(n)
+	case 0xca:
+		b, err := d.read(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), nil
+	case 0xcb:
+		b, err := d.read(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		b, err := d.read(1 << (c - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		u := bigEndian(b)
+		if u > math.MaxInt64 {
+			return u, nil
+		}
+		return int64(u), nil
+	case 0xd0:
+		b, err := d.read(1)
+		if err != nil {
+			return nil, err
+		}
+		return int64(int8(b[0])), nil
+	case 0xd1:
+		b, err := d.read(2)
+		if err != nil {
+			return nil, err
+		}
+		return int64(int16(binary.BigEndian.Uint16(b))), nil
+	case 0xd2:
+		b, err := d.read(4)
+		if err != nil {
+			return nil, err
+		}
+		return int64(int32(binary.BigEndian.Uint32(b))), nil
+	case 0xd3:
+		b, err := d.read(8)
+		if err != nil {
+			return nil, err
+		}
+		return int64(binary.BigEndian.Uint64(b)), nil
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.decodeExt(1 << (c - 0xd4))
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.length(c - 0xd9)
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeString(n)
+	case 0xdc, 0xdd:
+		n, err := d.length(c - 0xdc + 1)
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeArray(n)
+	case 0xde, 0xdf:
+		n, err := d.length(c - 0xde + 1)
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeMap(n)
+	}
+
+	return nil, fmt.Errorf("msgpack: invalid type 0x%x", c)
+}
+
+// length reads a length of 1, 2 or 4 bytes, for size 0, 1 and 2.
+func (d *Decoder) length(size byte) (int, error) {
+	b, err := d.read(1 << size)
+	if err != nil {
+		return 0, err
+	}
+	return int(bigEndian(b)), nil
+}
+
+func (d *Decoder) read(n int) ([]byte, error) {
+	b := make([]byte, n)
+	_, err := io.ReadFull(d.r, b)
+	return b, err
+}
+
+func (d *Decoder) decodeString(n int) (interface{}, error) {
+	b, err := d.read(n)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (d *Decoder) decodeArray(n int) (interface{}, error) {
+	a := make([]interface{}, 0, min(n, 1024))
+	for i := 0; i < n; i++ {
+		v, err := d.next()
+		if err != nil {
+			return nil, err
+		}
+		a = append(a, v)
+	}
+	return a, nil
+}
+
+func (d *Decoder) decodeMap(n int) (interface{}, error) {
+	m := make(map[string]interface{}, min(n, 1024))
+	for i := 0; i < n; i++ {
+		k, err := d.next()
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.next()
+		if err != nil {
+			return nil, err
+		}
+		m[key(k)] = v
+	}
+	return m, nil
+}
+
+func (d *Decoder) decodeExt(n int) (interface{}, error) {
+	b, err := d.read(n + 1)
+	if err != nil {
+		return nil, err
+	}
+	typ, data := int8(b[0]), b[1:]
+
+	if typ == TimestampType {
+		switch n {
+		case 4:
+			return time.Unix(int64(binary.BigEndian.Uint32(data)), 0), nil
+		case 8:
+			v := binary.BigEndian.Uint64(data)
+			return time.Unix(int64(v&0x3ffffffff), int64(v>>34)), nil
+		case 12:
+			return time.Unix(int64(binary.BigEndian.Uint64(data[4:])), int64(binary.BigEndian.Uint32(data))), nil
+		}
+	}
+	return Ext{Type: typ, Data: data}, nil
+}
+
+func (d *Decoder) next() (interface{}, error) {
+	c, err := d.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	return d.decode(c)
+}
+
+// key returns the string of the map key k.
+func key(k interface{}) string {
+	if s, ok := k.(string); ok {
+		return s
+	}
+	return fmt.Sprint(k)
+}
+
+// bigEndian returns the big endian unsigned integer of b.
+func bigEndian(b []byte) uint64 {
+	var u uint64
+	for _, c := range b {
+		u = u<<8 | uint64(c)
+	}
+	return u
+}
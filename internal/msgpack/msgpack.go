@@ -0,0 +1,266 @@
+// Package msgpack encodes and decodes the MessagePack values written by the
+// outputs, as described by https://github.com/msgpack/msgpack/blob/master/spec.md.
+//
+// It only covers what the outputs need: the Append functions add a value to a
+// buffer and the Decoder reads the values back as plain Go values, mostly for
+// the acknowledgements of the receivers and for tests.
+package msgpack
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+// TimestampType is the extension type of the timestamps.
+const TimestampType = -1
+
+// AppendNil appends nil to b.
+func AppendNil(b []byte) []byte {
+	return append(b, 0xc0)
+}
+
+// AppendBool appends v to b.
+func AppendBool(b []byte, v bool) []byte {
+	if v {
+		return append(b, 0xc3)
+	}
+	return append(b, 0xc2)
+}
+
+// AppendInt appends v to b, in its shortest form.
+func AppendInt(b []byte, v int64) []byte {
+	switch {
+	case v >= 0:
+		return AppendUint(b, uint64(v))
+	case v >= -32:
+		return append(b, byte(v))
+	case v >= math.MinInt8:
+		return append(b, 0xd0, byte(v))
+	case v >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(b, 0xd1), uint16(v))
+	case v >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(b, 0xd2), uint32(v))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, 0xd3), uint64(v))
+	}
+}
+
+// AppendUint appends v to b, in its shortest form.
+func AppendUint(b []byte, v uint64) []byte {
+	switch {
+	case v <= math.MaxInt8:
+		return append(b, byte(v))
+	case v <= math.MaxUint8:
+		return append(b, 0xcc, byte(v))
+	case v <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xcd), uint16(v))
+	case v <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, 0xce), uint32(v))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, 0xcf), v)
+	}
+}
+
+// AppendFloat appends v to b as a float 64.
+func AppendFloat(b []byte, v float64) []byte {
+	return binary.BigEndian.AppendUint64(append(b, 0xcb), math.Float64bits(v))
+}
+
+// AppendString appends v to b.
+func AppendString(b []byte, v string) []byte {
+	n := len(v)
+	switch {
+	case n < 32:
+		b = append(b, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		b = append(b, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xda), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xdb), uint32(n))
+	}
+	return append(b, v...)
+}
+
+// AppendBytes appends v to b as binary.
+func AppendBytes(b []byte, v []byte) []byte {
+	n := len(v)
+	switch {
+	case n <= math.MaxUint8:
+		b = append(b, 0xc4, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xc5), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xc6), uint32(n))
+	}
+	return append(b, v...)
+}
+
+// AppendArrayHeader appends the header of an array of n values to b.
+func AppendArrayHeader(b []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(b, 0x90|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xdc), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(b, 0xdd), uint32(n))
+	}
+}
+
+// AppendMapHeader appends the header of a map of n pairs to b.
+func AppendMapHeader(b []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(b, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xde), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(b, 0xdf), uint32(n))
+	}
+}
+
+// AppendExt appends the extension value data of type typ to b.
+func AppendExt(b []byte, typ int8, data []byte) []byte {
+	n := len(data)
+	switch n {
+	case 1:
+		b = append(b, 0xd4)
+	case 2:
+		b = append(b, 0xd5)
+	case 4:
+		b = append(b, 0xd6)
+	case 8:
+		b = append(b, 0xd7)
+	case 16:
+		b = append(b, 0xd8)
+	default:
+		switch {
+		case n <= math.MaxUint8:
+			b = append(b, 0xc7, byte(n))
+		case n <= math.MaxUint16:
+			b = binary.BigEndian.AppendUint16(append(b, 0xc8), uint16(n))
+		default:
+			b = binary.BigEndian.AppendUint32(append(b, 0xc9), uint32(n))
+		}
+	}
+	b = append(b, byte(typ))
+	return append(b, data...)
+}
+
+// AppendTime appends t to b as a timestamp 96, which holds every time.
+func AppendTime(b []byte, t time.Time) []byte {
+	data := binary.BigEndian.AppendUint32(nil, uint32(t.Nanosecond()))
+	data = binary.BigEndian.AppendUint64(data, uint64(t.Unix()))
+	return AppendExt(b, TimestampType, data)
+}
+
+// AppendValue appends v to b. Errors and fmt.Stringers are written as
+// strings, json.Numbers as numbers and the values of other types as they
+// would be encoded to JSON.
+func AppendValue(b []byte, v interface{}) []byte {
+	switch v := v.(type) {
+	case nil:
+		return AppendNil(b)
+	case bool:
+		return AppendBool(b, v)
+	case int:
+		return AppendInt(b, int64(v))
+	case int8:
+		return AppendInt(b, int64(v))
+	case int16:
+		return AppendInt(b, int64(v))
+	case int32:
+		return AppendInt(b, int64(v))
+	case int64:
+		return AppendInt(b, v)
+	case uint:
+		return AppendUint(b, uint64(v))
+	case uint8:
+		return AppendUint(b, uint64(v))
+	case uint16:
+		return AppendUint(b, uint64(v))
+	case uint32:
+		return AppendUint(b, uint64(v))
+	case uint64:
+		return AppendUint(b, v)
+	case float32:
+		return AppendFloat(b, float64(v))
+	case float64:
+		return AppendFloat(b, v)
+	case string:
+		return AppendString(b, v)
+	case []byte:
+		return AppendBytes(b, v)
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return AppendInt(b, i)
+		}
+		if f, err := v.Float64(); err == nil {
+			return AppendFloat(b, f)
+		}
+		return AppendString(b, v.String())
+	case time.Time:
+		return AppendTime(b, v)
+	case time.Duration:
+		return AppendInt(b, int64(v))
+	case error:
+		return AppendString(b, v.Error())
+	case fmt.Stringer:
+		return AppendString(b, v.String())
+	case map[string]interface{}:
+		b = AppendMapHeader(b, len(v))
+		for k, e := range v {
+			b = AppendString(b, k)
+			b = AppendValue(b, e)
+		}
+		return b
+	case []interface{}:
+		b = AppendArrayHeader(b, len(v))
+		for _, e := range v {
+			b = AppendValue(b, e)
+		}
+		return b
+	}
+
+	return appendReflected(b, reflect.ValueOf(v))
+}
+
+func appendReflected(b []byte, v reflect.Value) []byte {
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() == reflect.String {
+			b = AppendMapHeader(b, v.Len())
+			iter := v.MapRange()
+			for iter.Next() {
+				b = AppendString(b, iter.Key().String())
+				b = AppendValue(b, iter.Value().Interface())
+			}
+			return b
+		}
+	case reflect.Slice, reflect.Array:
+		b = AppendArrayHeader(b, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			b = AppendValue(b, v.Index(i).Interface())
+		}
+		return b
+	}
+
+	// other values are written as their JSON representation
+	data, err := json.Marshal(v.Interface())
+	if err != nil {
+		return AppendString(b, fmt.Sprint(v.Interface()))
+	}
+	var decoded interface{}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	if err := d.Decode(&decoded); err != nil {
+		return AppendString(b, string(data))
+	}
+	return AppendValue(b, decoded)
+}
@@ -0,0 +1,119 @@
+package msgpack
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MsgpackSuite struct {
+	suite.Suite
+}
+
+func TestMsgpackSuite(t *testing.T) {
+	suite.Run(t, new(MsgpackSuite))
+}
+
+func (s *MsgpackSuite) TestAppendValue() {
+	at := time.Date(2021, 1, 2, 3, 4, 5, 123456789, time.UTC)
+
+	tt := []struct {
+		name  string
+		value interface{}
+		want  interface{}
+		bytes []byte
+	}{
+		{name: "nil", value: nil, want: nil, bytes: []byte{0xc0}},
+		{name: "true", value: true, want: true, bytes: []byte{0xc3}},
+		{name: "positive fixint", value: 7, want: int64(7), bytes: []byte{0x07}},
+		{name: "negative fixint", value: -3, want: int64(-3), bytes: []byte{0xfd}},
+		{name: "uint 8", value: uint8(200), want: int64(200), bytes: []byte{0xcc, 0xc8}},
+		{name: "int 16", value: int16(-300), want: int64(-300), bytes: []byte{0xd1, 0xfe, 0xd4}},
+		{name: "int 64", value: int64(math.MinInt64), want: int64(math.MinInt64)},
+		{name: "uint 64", value: uint64(math.MaxUint64), want: uint64(math.MaxUint64)},
+		{name: "float", value: 1.5, want: 1.5},
+		{name: "fixstr", value: "abc", want: "abc", bytes: []byte{0xa3, 'a', 'b', 'c'}},
+		{name: "str 8", value: strings.Repeat("a", 40), want: strings.Repeat("a", 40)},
+		{name: "str 16", value: strings.Repeat("a", 300), want: strings.Repeat("a", 300)},
+		{name: "bin", value: []byte{1, 2}, want: []byte{1, 2}, bytes: []byte{0xc4, 0x02, 0x01, 0x02}},
+		{name: "json number", value: json.Number("12"), want: int64(12)},
+		{name: "json float", value: json.Number("1.25"), want: 1.25},
+		{name: "error", value: errors.New("bad"), want: "bad"},
+		{name: "duration", value: time.Second, want: int64(time.Second)},
+		{name: "time", value: at, want: at.Local()},
+		{name: "map", value: map[string]interface{}{"a": 1}, want: map[string]interface{}{"a": int64(1)}},
+		{name: "array", value: []interface{}{"a", 1}, want: []interface{}{"a", int64(1)}},
+		{name: "typed slice", value: []string{"a", "b"}, want: []interface{}{"a", "b"}},
+		{name: "typed map", value: map[string]int{"a": 1}, want: map[string]interface{}{"a": int64(1)}},
+		{name: "struct", value: struct{ A int }{A: 1}, want: map[string]interface{}{"A": int64(1)}},
+		{name: "array 16", value: make([]interface{}, 20), want: make([]interface{}, 20)},
+	}
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			b := AppendValue(nil, t.value)
+			if t.bytes != nil {
+				s.Assert().Equal(t.bytes, b)
+			}
+
+			got, err := Unmarshal(b)
+			s.Require().NoError(err)
+			s.Assert().Equal(t.want, got)
+		})
+	}
+}
+
+func (s *MsgpackSuite) TestAppendExt() {
+	b := AppendExt(nil, 0, []byte{1, 2, 3, 4, 5, 6, 7, 8})
+	s.Assert().Equal([]byte{0xd7, 0x00, 1, 2, 3, 4, 5, 6, 7, 8}, b)
+
+	got, err := Unmarshal(b)
+	s.Require().NoError(err)
+	s.Assert().Equal(Ext{Type: 0, Data: []byte{1, 2, 3, 4, 5, 6, 7, 8}}, got)
+}
+
+func (s *MsgpackSuite) TestDecoder() {
+	b := AppendString(nil, "a")
+	b = AppendInt(b, 1)
+
+	d := NewDecoder(strings.NewReader(string(b)))
+	v, err := d.Decode()
+	s.Require().NoError(err)
+	s.Assert().Equal("a", v)
+	v, err = d.Decode()
+	s.Require().NoError(err)
+	s.Assert().Equal(int64(1), v)
+	_, err = d.Decode()
+	s.Assert().Equal(io.EOF, err)
+}
+
+func (s *MsgpackSuite) TestDecoderErrors() {
+	_, err := Unmarshal([]byte{0x92, 0x01})
+	s.Assert().Equal(io.ErrUnexpectedEOF, err)
+
+	_, err = Unmarshal([]byte{0xc1})
+	s.Assert().Error(err)
+
+	_, err = Unmarshal([]byte{0x01, 0x02})
+	s.Assert().Error(err)
+}
+
+func (s *MsgpackSuite) TestDecodeMap() {
+	b := AppendMapHeader(nil, 2)
+	b = AppendString(b, "b")
+	b = AppendInt(b, 1)
+	b = AppendString(b, "a")
+	b = AppendString(b, "x")
+
+	got, err := NewDecoder(strings.NewReader(string(b))).DecodeMap()
+	s.Require().NoError(err)
+	s.Assert().Equal([]Pair{{Key: "b", Value: int64(1)}, {Key: "a", Value: "x"}}, got)
+
+	_, err = NewDecoder(strings.NewReader(string(AppendInt(nil, 1)))).DecodeMap()
+	s.Assert().Error(err)
+}
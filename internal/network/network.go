@@ -0,0 +1,485 @@
+// Package network streams newline delimited entries to a TCP, TLS or UDP
+// endpoint, such as a local Vector or Fluent Bit agent.
+//
+// Writes never block the logger: the entries are queued in a bounded buffer
+// and sent by a background goroutine, which reconnects with an exponential
+// backoff whenever the connection fails. Entries overflowing the buffer are
+// appended to a spill file, when configured, up to its largest size, and
+// dropped otherwise. Once an entry was spilled, the following ones are spilled
+// too until the file is sent, streamed once the buffer is drained, so that the
+// entries are sent in order. On Close, the entries which could not be sent are
+// spilled before the spilled ones, and a spill file left by a previous process
+// is sent first.
+package network
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Protocols.
+const (
+	ProtocolTCP = "tcp"
+	ProtocolTLS = "tls"
+	ProtocolUDP = "udp"
+)
+
+const (
+	defaultBufferSize   = 1024
+	defaultSpillMaxSize = 100
+	defaultMinBackoff   = 100 * time.Millisecond
+	defaultMaxBackoff   = 30 * time.Second
+
+	dialTimeout  = 5 * time.Second
+	writeTimeout = 5 * time.Second
+	syncTimeout  = time.Second
+	closeTimeout = 5 * time.Second
+)
+
+// Protocols are the supported protocols.
+var Protocols = []string{ProtocolTCP, ProtocolTLS, ProtocolUDP}
+
+// Options configures a Writer. Zero values take a default.
+type Options struct {
+	Protocol     string        // tcp/tls/udp
+	Address      string        // host:port
+	TLSConfig    *tls.Config   // configuration of tls connections, the system roots when nil
+	BufferSize   int           // entries kept in memory while disconnected, 1024 when zero
+	SpillPath    string        // file receiving the entries overflowing the buffer, dropped when empty
+	SpillMaxSize int           // largest size of the spill file in megabytes, 100 when zero
+	MinBackoff   time.Duration // first delay between reconnections, 100ms when zero
+	MaxBackoff   time.Duration // longest delay between reconnections, 30s when zero
+	Metrics      *Metrics      // counters of the writer, updated when not nil
+}
+
+// Metrics counts the activity of a Writer. It is updated by the writer and can
+// be read at any time.
+type Metrics struct {
+	BytesSent      atomic.Uint64 // bytes written to the connection
+	EntriesSent    atomic.Uint64 // entries written to the connection
+	EntriesSpilled atomic.Uint64 // entries appended to the spill file when the buffer was full
+	EntriesDropped atomic.Uint64 // entries lost because the buffer was full and could not be spilled
+}
+
+// Writer is an io.Writer sending each write as a line to the endpoint.
+type Writer struct {
+	options Options
+	metrics *Metrics
+
+	entries chan []byte
+	spilled atomic.Bool
+	wake    chan struct{}
+	flushes chan chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+	closed  sync.Once
+
+	spillMu    sync.Mutex
+	spillSize  int64 // size of the spill file
+	spillLimit int64
+	sentOffset int64 // end of the spilled entries already sent, only used by run
+	conn       net.Conn
+}
+
+// New returns a Writer from options and starts sending.
+func New(options Options) *Writer {
+	if options.BufferSize <= 0 {
+		options.BufferSize = defaultBufferSize
+	}
+	if options.SpillMaxSize <= 0 {
+		options.SpillMaxSize = defaultSpillMaxSize
+	}
+	if options.MinBackoff <= 0 {
+		options.MinBackoff = defaultMinBackoff
+	}
+	if options.MaxBackoff < options.MinBackoff {
+		options.MaxBackoff = max(defaultMaxBackoff, options.MinBackoff)
+	}
+
+	w := &Writer{
+		options:    options,
+		metrics:    options.Metrics,
+		entries:    make(chan []byte, options.BufferSize),
+		wake:       make(chan struct{}, 1),
+		flushes:    make(chan chan struct{}),
+		done:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+		spillLimit: int64(options.SpillMaxSize) << 20,
+	}
+	if w.metrics == nil {
+		w.metrics = &Metrics{}
+	}
+	if options.SpillPath != "" {
+		if info, err := os.Stat(options.SpillPath); err == nil && info.Size() > 0 {
+			w.spillSize = info.Size()
+			w.spilled.Store(true)
+			w.wake <- struct{}{}
+		}
+	}
+
+	go w.run()
+	return w
+}
+
+// Write queues a copy of p, terminated by a new line, or spills it while
+// spilled entries are waiting. It never fails: the entries which fit neither in
+// the buffer nor in the spill file are counted as dropped.
+func (w *Writer) Write(p []byte) (int, error) {
+	b := make([]byte, len(p), len(p)+1)
+	copy(b, p)
+	if len(b) == 0 || b[len(b)-1] != '\n' {
+		b = append(b, '\n')
+	}
+
+	if !w.spilled.Load() {
+		select {
+		case w.entries <- b:
+			return len(p), nil
+		default:
+		}
+	}
+	w.overflow(b)
+	return len(p), nil
+}
+
+// Sync implements zapcore.WriteSyncer, it flushes the writer.
+func (w *Writer) Sync() error {
+	return w.Flush()
+}
+
+// Flush waits, for up to a second, for the buffered and spilled entries to be
+// sent.
+func (w *Writer) Flush() error {
+	flushed := make(chan struct{})
+	timeout := time.After(syncTimeout)
+
+	select {
+	case w.flushes <- flushed:
+	case <-w.stopped:
+		return nil
+	case <-timeout:
+		return errors.New("network: timed out sending the buffered entries")
+	}
+
+	select {
+	case <-flushed:
+		return nil
+	case <-w.stopped:
+		return nil
+	case <-timeout:
+		return errors.New("network: timed out sending the buffered entries")
+	}
+}
+
+// Close stops sending and closes the connection. The buffered entries are sent
+// on the connection, trying once, and the ones which could not be sent are
+// spilled, or dropped without a spill file.
+func (w *Writer) Close() error {
+	w.closed.Do(func() { close(w.done) })
+
+	select {
+	case <-w.stopped:
+		return nil
+	case <-time.After(closeTimeout):
+		return errors.New("network: timed out spilling the buffered entries")
+	}
+}
+
+func (w *Writer) overflow(b []byte) {
+	if w.options.SpillPath != "" && w.spill(b) == nil {
+		w.metrics.EntriesSpilled.Add(1)
+		select {
+		case w.wake <- struct{}{}:
+		default:
+		}
+		return
+	}
+	w.metrics.EntriesDropped.Add(1)
+}
+
+// spill appends b to the spill file, unless the file would exceed its largest
+// size.
+func (w *Writer) spill(b []byte) error {
+	w.spillMu.Lock()
+	defer w.spillMu.Unlock()
+
+	if w.spillSize+int64(len(b)) > w.spillLimit {
+		return errors.New("network: spill file is full")
+	}
+
+	f, err := os.OpenFile(w.options.SpillPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		// a partial entry would be sent with the next one
+		_ = f.Truncate(w.spillSize)
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	w.spillSize += int64(len(b))
+	w.spilled.Store(true)
+	return nil
+}
+
+// spillFirst rewrites the spill file with entries followed by the spilled
+// entries not sent yet, which are newer, so that they are sent in order by the
+// next process. The entries not fitting in the file are dropped.
+func (w *Writer) spillFirst(entries [][]byte) {
+	w.spillMu.Lock()
+	defer w.spillMu.Unlock()
+
+	tmp := w.options.SpillPath + ".tmp"
+	size, spilled, err := w.writeSpill(tmp, entries)
+	if err == nil {
+		err = os.Rename(tmp, w.options.SpillPath)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		w.metrics.EntriesDropped.Add(uint64(len(entries)))
+		return
+	}
+
+	w.metrics.EntriesSpilled.Add(uint64(spilled))
+	w.metrics.EntriesDropped.Add(uint64(len(entries) - spilled))
+	w.spillSize, w.sentOffset = size, 0
+	w.spilled.Store(size > 0)
+}
+
+// writeSpill writes to path the entries fitting in the spill file followed by
+// the spilled entries not sent yet, returning the size of the file and how many
+// entries were written.
+func (w *Writer) writeSpill(path string, entries [][]byte) (int64, int, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	unsent := w.spillSize - w.sentOffset
+	size, spilled := int64(0), 0
+	bw := bufio.NewWriter(f)
+	for _, b := range entries {
+		if size+int64(len(b))+unsent > w.spillLimit {
+			continue
+		}
+		if _, err := bw.Write(b); err != nil {
+			return 0, 0, err
+		}
+		size += int64(len(b))
+		spilled++
+	}
+
+	if unsent > 0 {
+		old, err := os.Open(w.options.SpillPath)
+		if err != nil {
+			return 0, 0, err
+		}
+		defer old.Close()
+		if _, err := io.Copy(bw, io.NewSectionReader(old, w.sentOffset, unsent)); err != nil {
+			return 0, 0, err
+		}
+		size += unsent
+	}
+
+	if err := bw.Flush(); err != nil {
+		return 0, 0, err
+	}
+	return size, spilled, f.Close()
+}
+
+// unspill streams the spill file from the end of the entries already sent,
+// the entries spilled meanwhile included, and empties it once every entry was
+// sent. It returns false when the writer was closed meanwhile, the entries left
+// being sent by the next call.
+func (w *Writer) unspill() bool {
+	f, err := os.Open(w.options.SpillPath)
+	if err != nil {
+		w.spillMu.Lock()
+		w.spillSize, w.sentOffset = 0, 0
+		w.spilled.Store(false)
+		w.spillMu.Unlock()
+		return true
+	}
+	defer f.Close()
+
+	for {
+		w.spillMu.Lock()
+		size := w.spillSize
+		if w.sentOffset >= size {
+			if err := os.Truncate(w.options.SpillPath, 0); err == nil {
+				w.spillSize, w.sentOffset = 0, 0
+			}
+			w.spilled.Store(false)
+			w.spillMu.Unlock()
+			return true
+		}
+		w.spillMu.Unlock()
+
+		// the entries are whole lines, the ones appended from now on are read
+		// by the next round
+		r := bufio.NewReader(io.NewSectionReader(f, w.sentOffset, size-w.sentOffset))
+		for {
+			b, err := r.ReadBytes('\n')
+			if len(b) > 0 {
+				if !w.deliver(b) {
+					return false
+				}
+				w.sentOffset += int64(len(b))
+			}
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				// the unreadable entries are skipped
+				w.metrics.EntriesDropped.Add(1)
+				w.sentOffset = size
+				break
+			}
+		}
+	}
+}
+
+func (w *Writer) run() {
+	defer close(w.stopped)
+	defer w.disconnect()
+
+	for {
+		var flushed chan struct{}
+		select {
+		case <-w.done:
+			w.drain(nil)
+			return
+		case b := <-w.entries:
+			if !w.deliver(b) {
+				w.drain(b)
+				return
+			}
+		case flushed = <-w.flushes:
+			for len(w.entries) > 0 {
+				if b := <-w.entries; !w.deliver(b) {
+					w.drain(b)
+					return
+				}
+			}
+			if w.spilled.Load() && !w.unspill() {
+				w.drain(nil)
+				return
+			}
+		case <-w.wake:
+		}
+
+		// the spilled entries, newer than the buffered ones, are sent once the
+		// buffer is drained
+		if len(w.entries) == 0 && w.spilled.Load() && !w.unspill() {
+			w.drain(nil)
+			return
+		}
+		if flushed != nil {
+			close(flushed)
+		}
+	}
+}
+
+// drain sends pending, when not nil, and the buffered entries on the
+// connection, trying once, and spills the ones which could not be sent before
+// the spilled entries. Without a spill file, they are dropped.
+func (w *Writer) drain(pending []byte) {
+	var entries [][]byte
+	if pending != nil {
+		entries = append(entries, pending)
+	}
+	for len(w.entries) > 0 {
+		entries = append(entries, <-w.entries)
+	}
+
+	for len(entries) > 0 && w.conn != nil {
+		n, err := w.send(entries[0])
+		w.metrics.BytesSent.Add(uint64(n))
+		if err != nil {
+			entries[0] = entries[0][n:]
+			w.disconnect()
+			break
+		}
+		w.metrics.EntriesSent.Add(1)
+		entries = entries[1:]
+	}
+
+	if w.options.SpillPath == "" {
+		w.metrics.EntriesDropped.Add(uint64(len(entries)))
+		return
+	}
+	// the spill file is rewritten without the entries already sent
+	if len(entries) > 0 || w.spilled.Load() {
+		w.spillFirst(entries)
+	}
+}
+
+// deliver sends b, reconnecting until it succeeds. A partially written entry
+// is resumed from the first byte which was not written. It returns false when
+// the writer was closed meanwhile.
+func (w *Writer) deliver(b []byte) bool {
+	backoff := w.options.MinBackoff
+	for {
+		n, err := w.send(b)
+		w.metrics.BytesSent.Add(uint64(n))
+		if err == nil {
+			w.metrics.EntriesSent.Add(1)
+			return true
+		}
+		b = b[n:]
+
+		w.disconnect()
+		select {
+		case <-w.done:
+			return false
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, w.options.MaxBackoff)
+	}
+}
+
+// send writes b to the connection and returns how many bytes were written.
+func (w *Writer) send(b []byte) (int, error) {
+	if w.conn == nil {
+		conn, err := w.dial()
+		if err != nil {
+			return 0, err
+		}
+		w.conn = conn
+	}
+
+	if err := w.conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+		return 0, err
+	}
+	return w.conn.Write(b)
+}
+
+func (w *Writer) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	switch w.options.Protocol {
+	case ProtocolTLS:
+		return tls.DialWithDialer(dialer, "tcp", w.options.Address, w.options.TLSConfig)
+	case ProtocolUDP:
+		return dialer.Dial("udp", w.options.Address)
+	default:
+		return dialer.Dial("tcp", w.options.Address)
+	}
+}
+
+func (w *Writer) disconnect() {
+	if w.conn != nil {
+		w.conn.Close()
+		w.conn = nil
+	}
+}
@@ -0,0 +1,311 @@
+package network
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type NetworkSuite struct {
+	suite.Suite
+}
+
+func TestNetworkSuite(t *testing.T) {
+	suite.Run(t, new(NetworkSuite))
+}
+
+// readLines accepts a connection on ln and reads n lines from it.
+func (s *NetworkSuite) readLines(ln net.Listener, n int) []string {
+	conn, err := ln.Accept()
+	s.Require().NoError(err)
+	defer conn.Close()
+	s.Require().NoError(conn.SetReadDeadline(time.Now().Add(5 * time.Second)))
+
+	var lines []string
+	r := bufio.NewReader(conn)
+	for len(lines) < n {
+		line, err := r.ReadString('\n')
+		s.Require().NoError(err)
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func (s *NetworkSuite) TestTCP() {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+	defer ln.Close()
+
+	metrics := &Metrics{}
+	w := New(Options{Protocol: ProtocolTCP, Address: ln.Addr().String(), Metrics: metrics})
+	defer w.Close()
+
+	_, err = w.Write([]byte(`{"msg":"a"}` + "\n"))
+	s.Require().NoError(err)
+	_, err = w.Write([]byte(`{"msg":"b"}`))
+	s.Require().NoError(err)
+
+	s.Assert().Equal([]string{`{"msg":"a"}` + "\n", `{"msg":"b"}` + "\n"}, s.readLines(ln, 2))
+
+	s.Require().NoError(w.Sync())
+	s.Assert().Equal(uint64(2), metrics.EntriesSent.Load())
+	s.Assert().Equal(uint64(24), metrics.BytesSent.Load())
+	s.Assert().Equal(uint64(0), metrics.EntriesDropped.Load())
+}
+
+func (s *NetworkSuite) TestTLS() {
+	cert, pool := s.certificate()
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	s.Require().NoError(err)
+	defer ln.Close()
+
+	w := New(Options{Protocol: ProtocolTLS, Address: ln.Addr().String(), TLSConfig: &tls.Config{RootCAs: pool, ServerName: "localhost"}})
+	defer w.Close()
+
+	_, err = w.Write([]byte(`{"msg":"a"}`))
+	s.Require().NoError(err)
+
+	s.Assert().Equal([]string{`{"msg":"a"}` + "\n"}, s.readLines(ln, 1))
+}
+
+func (s *NetworkSuite) TestUDP() {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	w := New(Options{Protocol: ProtocolUDP, Address: conn.LocalAddr().String()})
+	defer w.Close()
+
+	_, err = w.Write([]byte(`{"msg":"a"}`))
+	s.Require().NoError(err)
+
+	buf := make([]byte, 1024)
+	s.Require().NoError(conn.SetReadDeadline(time.Now().Add(5 * time.Second)))
+	n, _, err := conn.ReadFrom(buf)
+	s.Require().NoError(err)
+	s.Assert().Equal(`{"msg":"a"}`+"\n", string(buf[:n]))
+}
+
+func (s *NetworkSuite) TestDropsWhenBufferIsFull() {
+	address := s.unusedAddress()
+
+	metrics := &Metrics{}
+	w := New(Options{Address: address, BufferSize: 1, MinBackoff: time.Hour, Metrics: metrics})
+	defer w.Close()
+
+	for i := 0; i < 5; i++ {
+		_, err := w.Write([]byte("{}"))
+		s.Require().NoError(err)
+	}
+
+	// one entry is being delivered and another one is buffered
+	s.Assert().GreaterOrEqual(metrics.EntriesDropped.Load(), uint64(3))
+	s.Assert().Equal(uint64(0), metrics.EntriesSent.Load())
+}
+
+func (s *NetworkSuite) TestSpillsAndReconnects() {
+	address := s.unusedAddress()
+
+	metrics := &Metrics{}
+	w := New(Options{
+		Address:    address,
+		BufferSize: 1,
+		SpillPath:  filepath.Join(s.T().TempDir(), "spill.log"),
+		MinBackoff: 10 * time.Millisecond,
+		MaxBackoff: 50 * time.Millisecond,
+		Metrics:    metrics,
+	})
+	defer w.Close()
+
+	want := []string{"1\n", "2\n", "3\n", "4\n", "5\n"}
+	for _, line := range want {
+		_, err := w.Write([]byte(line))
+		s.Require().NoError(err)
+	}
+	s.Assert().GreaterOrEqual(metrics.EntriesSpilled.Load(), uint64(3))
+
+	// the agent comes up
+	ln, err := net.Listen("tcp", address)
+	s.Require().NoError(err)
+	defer ln.Close()
+
+	s.Assert().Equal(want, s.readLines(ln, len(want)))
+	s.Assert().Equal(uint64(0), metrics.EntriesDropped.Load())
+
+	// the spill file is emptied once sent
+	s.Require().NoError(w.Flush())
+	info, err := os.Stat(w.options.SpillPath)
+	s.Require().NoError(err)
+	s.Assert().Zero(info.Size())
+}
+
+func (s *NetworkSuite) TestDropsWhenSpillIsFull() {
+	address := s.unusedAddress()
+
+	metrics := &Metrics{}
+	w := New(Options{
+		Address:      address,
+		BufferSize:   1,
+		SpillPath:    filepath.Join(s.T().TempDir(), "spill.log"),
+		SpillMaxSize: 1,
+		MinBackoff:   time.Hour,
+		Metrics:      metrics,
+	})
+	defer w.Close()
+
+	line := bytes.Repeat([]byte("a"), 300<<10)
+	for i := 0; i < 6; i++ {
+		_, err := w.Write(line)
+		s.Require().NoError(err)
+	}
+
+	// the spill file holds 3 entries of 300KiB within its megabyte
+	s.Assert().Equal(uint64(3), metrics.EntriesSpilled.Load())
+	s.Assert().GreaterOrEqual(metrics.EntriesDropped.Load(), uint64(1))
+	info, err := os.Stat(w.options.SpillPath)
+	s.Require().NoError(err)
+	s.Assert().Equal(int64(3*(len(line)+1)), info.Size())
+}
+
+func (s *NetworkSuite) TestSendsSpillOfPreviousProcess() {
+	path := filepath.Join(s.T().TempDir(), "spill.log")
+	s.Require().NoError(os.WriteFile(path, []byte("1\n2\n"), 0o600))
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+	defer ln.Close()
+
+	w := New(Options{Address: ln.Addr().String(), SpillPath: path})
+	defer w.Close()
+
+	// the new entries are spilled after the ones left
+	_, err = w.Write([]byte("3"))
+	s.Require().NoError(err)
+
+	s.Assert().Equal([]string{"1\n", "2\n", "3\n"}, s.readLines(ln, 3))
+}
+
+func (s *NetworkSuite) TestFlushTimesOut() {
+	w := New(Options{Address: s.unusedAddress(), MinBackoff: time.Hour})
+	defer w.Close()
+
+	_, err := w.Write([]byte("1"))
+	s.Require().NoError(err)
+
+	s.Assert().Error(w.Flush())
+}
+
+func (s *NetworkSuite) TestCloseSpillsBufferedEntries() {
+	path := filepath.Join(s.T().TempDir(), "spill.log")
+
+	metrics := &Metrics{}
+	w := New(Options{
+		Address:    s.unusedAddress(),
+		BufferSize: 2,
+		SpillPath:  path,
+		MinBackoff: time.Hour,
+		Metrics:    metrics,
+	})
+
+	for _, line := range []string{"1", "2", "3", "4", "5"} {
+		_, err := w.Write([]byte(line))
+		s.Require().NoError(err)
+	}
+	s.Require().NoError(w.Close())
+
+	// the buffered entries are spilled before the newer spilled ones
+	data, err := os.ReadFile(path)
+	s.Require().NoError(err)
+	s.Assert().Equal("1\n2\n3\n4\n5\n", string(data))
+	s.Assert().Equal(uint64(0), metrics.EntriesDropped.Load())
+}
+
+func (s *NetworkSuite) TestResumesPartialWrite() {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+	defer ln.Close()
+
+	metrics := &Metrics{}
+	w := &Writer{
+		options: Options{Address: ln.Addr().String(), MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+		metrics: metrics,
+		done:    make(chan struct{}),
+		conn:    &partialConn{n: 3},
+	}
+	defer w.disconnect()
+
+	s.Require().True(w.deliver([]byte("abcdef\n")))
+
+	s.Assert().Equal([]string{"def\n"}, s.readLines(ln, 1))
+	s.Assert().Equal(uint64(7), metrics.BytesSent.Load())
+	s.Assert().Equal(uint64(1), metrics.EntriesSent.Load())
+}
+
+// partialConn is a connection writing n bytes and failing.
+type partialConn struct {
+	net.Conn
+	n int
+}
+
+func (c *partialConn) Write(b []byte) (int, error) {
+	return min(c.n, len(b)), errors.New("connection reset")
+}
+
+func (c *partialConn) SetWriteDeadline(time.Time) error {
+	return nil
+}
+
+func (c *partialConn) Close() error {
+	return nil
+}
+
+// unusedAddress returns the address of a closed TCP port.
+func (s *NetworkSuite) unusedAddress() string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+	address := ln.Addr().String()
+	s.Require().NoError(ln.Close())
+	return address
+}
+
+// certificate returns a self signed certificate for localhost and a pool
+// trusting it.
+func (s *NetworkSuite) certificate() (tls.Certificate, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s.Require().NoError(err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	s.Require().NoError(err)
+
+	leaf, err := x509.ParseCertificate(der)
+	s.Require().NoError(err)
+	pool := x509.NewCertPool()
+	pool.AddCert(leaf)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, pool
+}
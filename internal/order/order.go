@@ -0,0 +1,43 @@
+// Package order gives the fields of the entries a stable order, the same
+// whichever backend writes them: the priority keys first, in their order,
+// followed by the other keys sorted.
+package order
+
+import "sort"
+
+// Keys returns the keys of fields, the priority ones first.
+func Keys(fields map[string]interface{}, priority []string) []string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	Sort(keys, priority)
+	return keys
+}
+
+// Sort sorts keys in place, the priority ones first.
+func Sort(keys []string, priority []string) {
+	if len(priority) == 0 {
+		sort.Strings(keys)
+		return
+	}
+
+	rank := make(map[string]int, len(priority))
+	for i, k := range priority {
+		if _, ok := rank[k]; !ok {
+			rank[k] = i
+		}
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		ri, iok := rank[keys[i]]
+		rj, jok := rank[keys[j]]
+		switch {
+		case iok && jok:
+			return ri < rj
+		case iok != jok:
+			return iok
+		default:
+			return keys[i] < keys[j]
+		}
+	})
+}
@@ -0,0 +1,42 @@
+package order
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type OrderSuite struct {
+	suite.Suite
+}
+
+func TestOrderSuite(t *testing.T) {
+	suite.Run(t, new(OrderSuite))
+}
+
+func (s *OrderSuite) TestKeys() {
+	fields := map[string]interface{}{"user_id": 1, "b": 2, "request_id": 3, "a": 4}
+
+	tt := []struct {
+		name     string
+		priority []string
+		want     []string
+	}{
+		{name: "sorted", want: []string{"a", "b", "request_id", "user_id"}},
+		{name: "with priority keys", priority: []string{"request_id", "trace_id", "user_id"}, want: []string{"request_id", "user_id", "a", "b"}},
+		{name: "with repeated priority keys", priority: []string{"user_id", "request_id", "user_id"}, want: []string{"user_id", "request_id", "a", "b"}},
+	}
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			for i := 0; i < 10; i++ {
+				s.Require().Equal(t.want, Keys(fields, t.priority))
+			}
+		})
+	}
+}
+
+func (s *OrderSuite) TestSort() {
+	keys := []string{"time", "level", "msg", "b", "request_id"}
+	Sort(keys[3:], []string{"request_id"})
+	s.Assert().Equal([]string{"time", "level", "msg", "request_id", "b"}, keys)
+}
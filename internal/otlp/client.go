@@ -0,0 +1,30 @@
+//go:build go1.24
+
+package otlp
+
+import (
+	"net/http"
+	"strings"
+)
+
+// cleartextGRPC reports whether gRPC reaches the http endpoints, with cleartext
+// HTTP/2.
+const cleartextGRPC = true
+
+// newClient returns a client speaking HTTP/2 to gRPC endpoints, in cleartext
+// for the http ones, and the usual HTTP client otherwise.
+func newClient(options Options) *http.Client {
+	if options.Protocol != ProtocolGRPC {
+		return &http.Client{Timeout: options.Timeout}
+	}
+
+	var protocols http.Protocols
+	if strings.HasPrefix(options.Endpoint, "https://") {
+		protocols.SetHTTP2(true)
+	} else {
+		protocols.SetUnencryptedHTTP2(true)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Protocols = &protocols
+	return &http.Client{Timeout: options.Timeout, Transport: transport}
+}
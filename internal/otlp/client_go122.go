@@ -0,0 +1,24 @@
+//go:build !go1.24
+
+package otlp
+
+import (
+	"net/http"
+)
+
+// cleartextGRPC reports whether gRPC reaches the http endpoints, with cleartext
+// HTTP/2, which requires Go 1.24.
+const cleartextGRPC = false
+
+// newClient returns the usual HTTP client, which speaks HTTP/2 to the https
+// gRPC endpoints. Cleartext HTTP/2 requires Go 1.24, the http gRPC endpoints
+// are unreachable before.
+func newClient(options Options) *http.Client {
+	if options.Protocol != ProtocolGRPC {
+		return &http.Client{Timeout: options.Timeout}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ForceAttemptHTTP2 = true
+	return &http.Client{Timeout: options.Timeout, Transport: transport}
+}
@@ -0,0 +1,100 @@
+//go:build go1.24
+
+package otlp
+
+import (
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"time"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/entry"
+)
+
+// grpcCollector is a stand-in of an OTLP/gRPC collector, over cleartext
+// HTTP/2. respond returns the grpc-status of the nth call.
+func (s *OTLPSuite) grpcCollector(respond func(n int) int) *collector {
+	c := &collector{received: make(chan request, 100)}
+	c.Server = httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != grpcPath || r.ProtoMajor != 2 {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		s.Require().GreaterOrEqual(len(body), 5)
+		s.Require().Equal(byte(0), body[0])
+		s.Require().Equal(len(body)-5, int(binary.BigEndian.Uint32(body[1:5])))
+
+		req := s.decodeRequest(body[5:])
+		req.header = r.Header
+		n := c.add(req)
+
+		rw.Header().Set("Content-Type", "application/grpc")
+		rw.WriteHeader(http.StatusOK)
+		// an empty ExportLogsServiceResponse
+		_, _ = rw.Write([]byte{0, 0, 0, 0, 0})
+		rw.Header().Set(http.TrailerPrefix+"grpc-status", strconv.Itoa(respond(n)))
+		c.received <- req
+	}))
+	c.Config.Protocols = new(http.Protocols)
+	c.Config.Protocols.SetUnencryptedHTTP2(true)
+	c.Start()
+	return c
+}
+
+func (s *OTLPSuite) TestGRPC() {
+	c := s.grpcCollector(func(int) int { return 0 })
+	defer c.Close()
+
+	w := New(Options{Protocol: ProtocolGRPC, Endpoint: c.URL, Headers: map[string]string{"Authorization": "Bearer token"}, ServiceName: "orders", BatchSize: 2})
+	defer w.Close()
+
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Level: log.InfoLevel, Message: "a"}))
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Level: log.ErrorLevel, Message: "b"}))
+
+	r := s.receive(c)
+	s.Assert().Equal("application/grpc", r.header.Get("Content-Type"))
+	s.Assert().Equal("Bearer token", r.header.Get("Authorization"))
+	s.Assert().Equal("orders", r.resource["service.name"])
+	s.Require().Len(r.records, 2)
+	s.Assert().Equal("a", r.records[0].body)
+	s.Assert().Equal(uint64(17), r.records[1].severity)
+}
+
+func (s *OTLPSuite) TestGRPCRetry() {
+	tt := []struct {
+		name     string
+		status   int
+		requests int
+	}{
+		{name: "unavailable", status: 14, requests: 2},
+		{name: "resource exhausted", status: 8, requests: 2},
+		{name: "invalid argument", status: 3, requests: 1},
+	}
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			c := s.grpcCollector(func(n int) int {
+				if n == 1 {
+					return t.status
+				}
+				return 0
+			})
+			defer c.Close()
+
+			w := New(Options{Protocol: ProtocolGRPC, Endpoint: c.URL, BatchSize: 1, MinBackoff: 10 * time.Millisecond})
+			s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Message: "a"}))
+
+			for i := 0; i < t.requests; i++ {
+				s.receive(c)
+			}
+			s.Require().NoError(w.Close())
+
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			s.Assert().Len(c.requests, t.requests)
+		})
+	}
+}
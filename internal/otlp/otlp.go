@@ -0,0 +1,364 @@
+// Package otlp exports entries as OpenTelemetry log records, with the OTLP
+// protocol described by https://opentelemetry.io/docs/specs/otlp/.
+//
+// Entries are queued and exported by a background goroutine, in batches
+// flushed when they reach the batch size or every batch timeout. A batch is
+// an ExportLogsServiceRequest, sent as protobuf over HTTP or over gRPC. gRPC
+// uses HTTP/2, in cleartext for http endpoints from Go 1.24, see CheckEndpoint.
+// The exports which fail with a retryable status are retried with an
+// exponential backoff, the others are dropped.
+//
+// The records carry the entry time, a severity number and text mapped from
+// the level, the message as body and the fields as attributes. The fields
+// holding the trace and span ids, usually added to the context by a tracing
+// middleware or read from the span context by SpanContext, correlate the
+// records with their span instead.
+package otlp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/americanas-go/log/internal/entry"
+)
+
+// Protocols.
+const (
+	ProtocolHTTP = "HTTP"
+	ProtocolGRPC = "GRPC"
+)
+
+const (
+	defaultHTTPEndpoint = "http://localhost:4318/v1/logs"
+	defaultGRPCEndpoint = "http://localhost:4317"
+	defaultTraceIDField = "trace_id"
+	defaultSpanIDField  = "span_id"
+	defaultBatchSize    = 512
+	defaultBatchTimeout = time.Second
+	defaultBufferSize   = 2048
+	defaultTimeout      = 10 * time.Second
+	defaultMaxRetries   = 5
+	defaultMinBackoff   = 500 * time.Millisecond
+	defaultMaxBackoff   = 30 * time.Second
+
+	// grpcPath is the path of the Export method of the logs service.
+	grpcPath = "/opentelemetry.proto.collector.logs.v1.LogsService/Export"
+
+	closeTimeout = 5 * time.Second
+)
+
+var (
+	// Protocols are the supported protocols.
+	Protocols = []string{ProtocolHTTP, ProtocolGRPC}
+
+	errBufferFull = errors.New("otlp: buffer is full, entry dropped")
+)
+
+// Options configures a Writer. Zero values take a default.
+type Options struct {
+	Protocol           string            // HTTP/GRPC, HTTP when empty
+	Endpoint           string            // URL of the collector, the default of the protocol when empty
+	Headers            map[string]string // headers of the exports, such as an authorization
+	ServiceName        string            // service.name resource attribute, unknown_service:<executable> when empty
+	ServiceVersion     string            // service.version resource attribute, omitted when empty
+	ResourceAttributes map[string]string // other resource attributes
+	TraceIDField       string            // field holding the hex trace id, trace_id when empty
+	SpanIDField        string            // field holding the hex span id, span_id when empty
+	BatchSize          int               // records of a batch, 512 when zero
+	BatchTimeout       time.Duration     // longest wait before a batch is exported, 1s when zero
+	BufferSize         int               // records waiting to be exported, 2048 when zero
+	Timeout            time.Duration     // timeout of an export, 10s when zero
+	MaxRetries         int               // retries of an export, 5 when zero
+	MinBackoff         time.Duration     // first delay between retries, 500ms when zero
+	MaxBackoff         time.Duration     // longest delay between retries, 30s when zero
+	Client             *http.Client      // client of the exports, one suited to the protocol when nil
+
+	PriorityKeys []string // fields written first as attributes, in this order, the others being sorted by key
+}
+
+// CheckEndpoint reports an error when endpoint, the default one of protocol
+// when empty, can not be reached: gRPC to an http endpoint uses cleartext
+// HTTP/2, which requires Go 1.24.
+func CheckEndpoint(protocol, endpoint string) error {
+	if protocol != ProtocolGRPC || cleartextGRPC {
+		return nil
+	}
+	if endpoint == "" {
+		endpoint = defaultGRPCEndpoint
+	}
+	if strings.HasPrefix(endpoint, "https://") {
+		return nil
+	}
+	return fmt.Errorf("gRPC to %q requires Go 1.24 for cleartext HTTP/2, use an https endpoint or the HTTP protocol", endpoint)
+}
+
+// Writer exports entries to an OTLP collector.
+type Writer struct {
+	options  Options
+	url      string
+	resource []byte
+
+	records chan []byte
+	flushes chan chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+	closed  sync.Once
+}
+
+// New returns a Writer from options and starts exporting.
+func New(options Options) *Writer {
+	if options.Protocol != ProtocolGRPC {
+		options.Protocol = ProtocolHTTP
+	}
+	if options.Endpoint == "" {
+		options.Endpoint = defaultHTTPEndpoint
+		if options.Protocol == ProtocolGRPC {
+			options.Endpoint = defaultGRPCEndpoint
+		}
+	}
+	if options.ServiceName == "" {
+		options.ServiceName = "unknown_service:" + filepath.Base(os.Args[0])
+	}
+	if options.TraceIDField == "" {
+		options.TraceIDField = defaultTraceIDField
+	}
+	if options.SpanIDField == "" {
+		options.SpanIDField = defaultSpanIDField
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = defaultBatchSize
+	}
+	if options.BatchTimeout <= 0 {
+		options.BatchTimeout = defaultBatchTimeout
+	}
+	if options.BufferSize <= 0 {
+		options.BufferSize = defaultBufferSize
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = defaultTimeout
+	}
+	if options.MaxRetries <= 0 {
+		options.MaxRetries = defaultMaxRetries
+	}
+	if options.MinBackoff <= 0 {
+		options.MinBackoff = defaultMinBackoff
+	}
+	if options.MaxBackoff < options.MinBackoff {
+		options.MaxBackoff = max(defaultMaxBackoff, options.MinBackoff)
+	}
+	if options.Client == nil {
+		options.Client = newClient(options)
+	}
+
+	url := options.Endpoint
+	if options.Protocol == ProtocolGRPC {
+		url = strings.TrimSuffix(url, "/") + grpcPath
+	}
+
+	w := &Writer{
+		options:  options,
+		url:      url,
+		resource: encodeResource(options),
+		records:  make(chan []byte, options.BufferSize),
+		flushes:  make(chan chan struct{}),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+
+	go w.run()
+	return w
+}
+
+// WriteEntry implements entry.Writer. It queues e and fails when the buffer is
+// full.
+func (w *Writer) WriteEntry(e entry.Entry) error {
+	select {
+	case w.records <- encodeRecord(e, w.options):
+		return nil
+	default:
+		return errBufferFull
+	}
+}
+
+// Flush exports the pending batch, the queued records included, and waits for
+// it to be exported, retries included, for up to 5 seconds.
+func (w *Writer) Flush() error {
+	flushed := make(chan struct{})
+	timeout := time.After(closeTimeout)
+
+	select {
+	case w.flushes <- flushed:
+	case <-w.stopped:
+		return nil
+	case <-timeout:
+		return errors.New("otlp: timed out exporting the pending entries")
+	}
+
+	select {
+	case <-flushed:
+		return nil
+	case <-w.stopped:
+		return nil
+	case <-timeout:
+		return errors.New("otlp: timed out exporting the pending entries")
+	}
+}
+
+// Close exports the pending batch, trying once, and stops exporting.
+func (w *Writer) Close() error {
+	w.closed.Do(func() { close(w.done) })
+
+	select {
+	case <-w.stopped:
+		return nil
+	case <-time.After(closeTimeout):
+		return errors.New("otlp: timed out exporting the pending entries")
+	}
+}
+
+func (w *Writer) run() {
+	defer close(w.stopped)
+
+	ticker := time.NewTicker(w.options.BatchTimeout)
+	defer ticker.Stop()
+
+	var batch [][]byte
+	for {
+		var flushed chan struct{}
+		select {
+		case <-w.done:
+			// the pending records are exported once, the queued ones included
+			for len(w.records) > 0 {
+				batch = append(batch, <-w.records)
+			}
+			if len(batch) > 0 {
+				_, _ = w.export(encodeRequest(w.resource, batch))
+			}
+			return
+		case record := <-w.records:
+			batch = append(batch, record)
+			if len(batch) < w.options.BatchSize {
+				continue
+			}
+		case flushed = <-w.flushes:
+			for len(w.records) > 0 {
+				batch = append(batch, <-w.records)
+			}
+		case <-ticker.C:
+		}
+
+		if len(batch) > 0 {
+			if !w.deliver(encodeRequest(w.resource, batch)) {
+				return
+			}
+			batch = batch[:0]
+		}
+		if flushed != nil {
+			close(flushed)
+		}
+	}
+}
+
+// deliver exports request, retrying the retryable failures. It returns false
+// when the writer was closed meanwhile.
+func (w *Writer) deliver(request []byte) bool {
+	backoff := w.options.MinBackoff
+	for retry := 0; ; retry++ {
+		retryable, err := w.export(request)
+		if err == nil || !retryable || retry == w.options.MaxRetries {
+			return true
+		}
+
+		select {
+		case <-w.done:
+			// the export is tried once more on close
+			_, _ = w.export(request)
+			return false
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, w.options.MaxBackoff)
+	}
+}
+
+// export sends request once. It tells whether a failed export can be retried.
+func (w *Writer) export(request []byte) (bool, error) {
+	body := request
+	if w.options.Protocol == ProtocolGRPC {
+		// uncompressed length prefixed message
+		body = binary.BigEndian.AppendUint32([]byte{0}, uint32(len(request)))
+		body = append(body, request...)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	for k, v := range w.options.Headers {
+		req.Header.Set(k, v)
+	}
+	if w.options.Protocol == ProtocolGRPC {
+		req.Header.Set("Content-Type", "application/grpc")
+		req.Header.Set("TE", "trailers")
+	} else {
+		req.Header.Set("Content-Type", "application/x-protobuf")
+	}
+
+	resp, err := w.options.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if w.options.Protocol == ProtocolGRPC {
+		return grpcStatus(resp)
+	}
+	if resp.StatusCode/100 == 2 {
+		return false, nil
+	}
+	err = fmt.Errorf("otlp: export rejected with status %d", resp.StatusCode)
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true, err
+	}
+	return false, err
+}
+
+// grpcStatus returns the outcome of a gRPC call, read from the grpc-status
+// trailer, or header for the responses without body.
+func grpcStatus(resp *http.Response) (bool, error) {
+	if resp.StatusCode != http.StatusOK {
+		return true, fmt.Errorf("otlp: export rejected with status %d", resp.StatusCode)
+	}
+
+	status := resp.Trailer.Get("grpc-status")
+	if status == "" {
+		status = resp.Header.Get("grpc-status")
+	}
+	code, err := strconv.Atoi(status)
+	if err != nil {
+		return true, fmt.Errorf("otlp: invalid grpc-status %q", status)
+	}
+	if code == 0 {
+		return false, nil
+	}
+
+	err = fmt.Errorf("otlp: export failed with grpc status %d: %s", code, resp.Trailer.Get("grpc-message"))
+	switch code {
+	case 1, 4, 8, 10, 11, 14, 15:
+		// CANCELLED, DEADLINE_EXCEEDED, RESOURCE_EXHAUSTED, ABORTED,
+		// OUT_OF_RANGE, UNAVAILABLE and DATA_LOSS are retryable
+		return true, err
+	}
+	return false, err
+}
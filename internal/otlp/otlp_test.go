@@ -0,0 +1,447 @@
+package otlp
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/americanas-go/log/internal/protobuf"
+	"github.com/stretchr/testify/suite"
+)
+
+type OTLPSuite struct {
+	suite.Suite
+}
+
+func TestOTLPSuite(t *testing.T) {
+	suite.Run(t, new(OTLPSuite))
+}
+
+var at = time.Date(2021, 1, 2, 3, 4, 5, 123456789, time.UTC)
+
+// record is a decoded LogRecord.
+type record struct {
+	time       uint64
+	severity   uint64
+	text       string
+	body       interface{}
+	attributes map[string]interface{}
+	traceID    string
+	spanID     string
+	observed   bool
+}
+
+// request is a decoded ExportLogsServiceRequest.
+type request struct {
+	header   http.Header
+	resource map[string]interface{}
+	scope    string
+	records  []record
+}
+
+// collector is a stand-in of an OTLP collector. respond returns the HTTP
+// status of the nth request.
+type collector struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []request
+	received chan request
+}
+
+func (s *OTLPSuite) collector(respond func(n int) int) *collector {
+	c := &collector{received: make(chan request, 100)}
+	c.Server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/logs" || r.Method != http.MethodPost {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		req := s.decodeRequest(body)
+		req.header = r.Header
+
+		n := c.add(req)
+		rw.WriteHeader(respond(n))
+		c.received <- req
+	}))
+	return c
+}
+
+func (c *collector) add(req request) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests = append(c.requests, req)
+	return len(c.requests)
+}
+
+func ok(int) int {
+	return http.StatusOK
+}
+
+func (s *OTLPSuite) receive(c *collector) request {
+	select {
+	case r := <-c.received:
+		return r
+	case <-time.After(5 * time.Second):
+		s.FailNow("no export received")
+		return request{}
+	}
+}
+
+func (s *OTLPSuite) parse(b []byte) []protobuf.Field {
+	fields, err := protobuf.Parse(b)
+	s.Require().NoError(err)
+	return fields
+}
+
+func (s *OTLPSuite) decodeRequest(b []byte) request {
+	var req request
+	for _, resourceLogs := range s.parse(b) {
+		for _, f := range s.parse(resourceLogs.Bytes) {
+			switch f.Number {
+			case 1:
+				req.resource = s.decodeKeyValues(f.Bytes)
+			case 2:
+				for _, scopeLogs := range s.parse(f.Bytes) {
+					switch scopeLogs.Number {
+					case 1:
+						req.scope = string(s.parse(scopeLogs.Bytes)[0].Bytes)
+					case 2:
+						req.records = append(req.records, s.decodeRecord(scopeLogs.Bytes))
+					}
+				}
+			}
+		}
+	}
+	return req
+}
+
+func (s *OTLPSuite) decodeRecord(b []byte) record {
+	r := record{attributes: map[string]interface{}{}}
+	for _, f := range s.parse(b) {
+		switch f.Number {
+		case 1:
+			r.time = f.Fixed
+		case 2:
+			r.severity = f.Varint
+		case 3:
+			r.text = string(f.Bytes)
+		case 5:
+			r.body = s.decodeAnyValue(f.Bytes)
+		case 6:
+			k, v := s.decodeKeyValue(f.Bytes)
+			r.attributes[k] = v
+		case 9:
+			r.traceID = hex.EncodeToString(f.Bytes)
+		case 10:
+			r.spanID = hex.EncodeToString(f.Bytes)
+		case 11:
+			r.observed = f.Fixed > 0
+		}
+	}
+	return r
+}
+
+func (s *OTLPSuite) decodeKeyValues(b []byte) map[string]interface{} {
+	kvs := map[string]interface{}{}
+	for _, f := range s.parse(b) {
+		k, v := s.decodeKeyValue(f.Bytes)
+		kvs[k] = v
+	}
+	return kvs
+}
+
+func (s *OTLPSuite) decodeKeyValue(b []byte) (string, interface{}) {
+	var k string
+	var v interface{}
+	for _, f := range s.parse(b) {
+		switch f.Number {
+		case 1:
+			k = string(f.Bytes)
+		case 2:
+			v = s.decodeAnyValue(f.Bytes)
+		}
+	}
+	return k, v
+}
+
+func (s *OTLPSuite) decodeAnyValue(b []byte) interface{} {
+	fields := s.parse(b)
+	if len(fields) == 0 {
+		return nil
+	}
+	f := fields[0]
+	switch f.Number {
+	case 1:
+		return string(f.Bytes)
+	case 2:
+		return f.Varint == 1
+	case 3:
+		return int64(f.Varint)
+	case 4:
+		return math.Float64frombits(f.Fixed)
+	case 5:
+		var values []interface{}
+		for _, e := range s.parse(f.Bytes) {
+			values = append(values, s.decodeAnyValue(e.Bytes))
+		}
+		return values
+	case 6:
+		return s.decodeKeyValues(f.Bytes)
+	case 7:
+		return f.Bytes
+	}
+	return nil
+}
+
+func (s *OTLPSuite) TestRecord() {
+	c := s.collector(ok)
+	defer c.Close()
+
+	w := New(Options{
+		Endpoint:           c.URL + "/v1/logs",
+		Headers:            map[string]string{"Authorization": "Bearer token"},
+		ServiceName:        "orders",
+		ServiceVersion:     "1.2.3",
+		ResourceAttributes: map[string]string{"deployment.environment": "production"},
+		BatchSize:          2,
+	})
+	defer w.Close()
+
+	s.Require().NoError(w.WriteEntry(entry.Entry{
+		Time:    at,
+		Level:   log.WarnLevel,
+		Message: "a",
+		Caller:  "orders/pay.go:42",
+		Fields: log.Fields{
+			"trace_id": "4bf92f3577b34da6a3ce929d0e0e4736",
+			"span_id":  "00f067aa0ba902b7",
+			"ID":       1,
+			"ok":       false,
+			"ratio":    0.5,
+			"err":      errors.New("bad"),
+			"tags":     []string{"x", "y"},
+			"order":    map[string]interface{}{"total": 10},
+			"elapsed":  time.Second,
+		},
+	}))
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Level: log.FatalLevel, Message: "b", Fields: log.Fields{"trace_id": "invalid"}}))
+
+	r := s.receive(c)
+	s.Assert().Equal("application/x-protobuf", r.header.Get("Content-Type"))
+	s.Assert().Equal("Bearer token", r.header.Get("Authorization"))
+	s.Assert().Equal(map[string]interface{}{"service.name": "orders", "service.version": "1.2.3", "deployment.environment": "production"}, r.resource)
+	s.Assert().Equal(ScopeName, r.scope)
+	s.Require().Len(r.records, 2)
+
+	s.Assert().Equal(record{
+		time:     uint64(at.UnixNano()),
+		severity: 13,
+		text:     "WARN",
+		body:     "a",
+		attributes: map[string]interface{}{
+			"ID":            int64(1),
+			"ok":            false,
+			"ratio":         0.5,
+			"err":           "bad",
+			"tags":          []interface{}{"x", "y"},
+			"order":         map[string]interface{}{"total": int64(10)},
+			"elapsed":       int64(time.Second),
+			"code.filepath": "orders/pay.go:42",
+		},
+		traceID:  "4bf92f3577b34da6a3ce929d0e0e4736",
+		spanID:   "00f067aa0ba902b7",
+		observed: true,
+	}, r.records[0])
+
+	s.Assert().Equal(uint64(22), r.records[1].severity)
+	s.Assert().Equal("FATAL", r.records[1].text)
+	s.Assert().Equal(map[string]interface{}{"trace_id": "invalid"}, r.records[1].attributes)
+	s.Assert().Empty(r.records[1].traceID)
+}
+
+func (s *OTLPSuite) TestSeverity() {
+	tt := []struct {
+		level log.Level
+		want  uint64
+	}{
+		{level: log.TraceLevel, want: 1},
+		{level: log.DebugLevel, want: 5},
+		{level: log.InfoLevel, want: 9},
+		{level: log.WarnLevel, want: 13},
+		{level: log.ErrorLevel, want: 17},
+		{level: log.PanicLevel, want: 21},
+		{level: log.FatalLevel, want: 22},
+	}
+	for _, t := range tt {
+		s.Run(t.level.String(), func() {
+			r := s.decodeRecord(encodeRecord(entry.Entry{Time: at, Level: t.level}, Options{TraceIDField: defaultTraceIDField, SpanIDField: defaultSpanIDField}))
+			s.Assert().Equal(t.want, r.severity)
+			s.Assert().Equal(t.level.String(), r.text)
+		})
+	}
+}
+
+func (s *OTLPSuite) TestTraceFields() {
+	r := s.decodeRecord(encodeRecord(entry.Entry{
+		Time:   at,
+		Fields: log.Fields{"traceId": "4bf92f3577b34da6a3ce929d0e0e4736", "spanId": "00f067aa0ba902b7", "trace_id": "x"},
+	}, Options{TraceIDField: "traceId", SpanIDField: "spanId"}))
+
+	s.Assert().Equal("4bf92f3577b34da6a3ce929d0e0e4736", r.traceID)
+	s.Assert().Equal("00f067aa0ba902b7", r.spanID)
+	s.Assert().Equal(map[string]interface{}{"trace_id": "x"}, r.attributes)
+}
+
+func (s *OTLPSuite) TestPriorityKeys() {
+	b := encodeRecord(entry.Entry{
+		Time:   at,
+		Fields: log.Fields{"a": 1, "b": 2, "c": 3},
+	}, Options{PriorityKeys: []string{"c", "b"}})
+
+	var keys []string
+	for _, f := range s.parse(b) {
+		if f.Number == 6 {
+			k, _ := s.decodeKeyValue(f.Bytes)
+			keys = append(keys, k)
+		}
+	}
+	s.Assert().Equal([]string{"c", "b", "a"}, keys)
+}
+
+func (s *OTLPSuite) TestSpanContext() {
+	ctx := context.WithValue(context.Background(), spanKey{}, [2]string{"4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"})
+	spans := func(ctx context.Context) (string, string) {
+		ids, _ := ctx.Value(spanKey{}).([2]string)
+		return ids[0], ids[1]
+	}
+
+	s.Assert().Equal(log.Fields{
+		"trace_id": "4bf92f3577b34da6a3ce929d0e0e4736",
+		"span_id":  "00f067aa0ba902b7",
+	}, (&SpanContext{Func: spans}).Fields(ctx))
+	s.Assert().Equal(log.Fields{
+		"traceId": "4bf92f3577b34da6a3ce929d0e0e4736",
+		"spanId":  "00f067aa0ba902b7",
+	}, (&SpanContext{Func: spans, TraceIDField: "traceId", SpanIDField: "spanId"}).Fields(ctx))
+	s.Assert().Nil((&SpanContext{Func: spans}).Fields(context.Background()))
+	s.Assert().Nil((*SpanContext)(nil).Fields(ctx))
+}
+
+// spanKey is the context key of the span ids of TestSpanContext.
+type spanKey struct{}
+
+func (s *OTLPSuite) TestCheckEndpoint() {
+	s.Assert().NoError(CheckEndpoint(ProtocolHTTP, "http://localhost:4318/v1/logs"))
+	s.Assert().NoError(CheckEndpoint(ProtocolGRPC, "https://collector:4317"))
+	if cleartextGRPC {
+		s.Assert().NoError(CheckEndpoint(ProtocolGRPC, ""))
+	} else {
+		s.Assert().Error(CheckEndpoint(ProtocolGRPC, ""))
+		s.Assert().Error(CheckEndpoint(ProtocolGRPC, "http://localhost:4317"))
+	}
+}
+
+func (s *OTLPSuite) TestDefaultServiceName() {
+	c := s.collector(ok)
+	defer c.Close()
+
+	w := New(Options{Endpoint: c.URL + "/v1/logs", BatchSize: 1})
+	defer w.Close()
+
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Message: "a"}))
+	s.Assert().Regexp("^unknown_service:.+", s.receive(c).resource["service.name"])
+}
+
+func (s *OTLPSuite) TestRetry() {
+	tt := []struct {
+		name     string
+		status   int
+		requests int
+	}{
+		{name: "too many requests", status: http.StatusTooManyRequests, requests: 2},
+		{name: "unavailable", status: http.StatusServiceUnavailable, requests: 2},
+		{name: "bad request", status: http.StatusBadRequest, requests: 1},
+	}
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			c := s.collector(func(n int) int {
+				if n == 1 {
+					return t.status
+				}
+				return http.StatusOK
+			})
+			defer c.Close()
+
+			w := New(Options{Endpoint: c.URL + "/v1/logs", BatchSize: 1, MinBackoff: 10 * time.Millisecond})
+			s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Message: "a"}))
+
+			for i := 0; i < t.requests; i++ {
+				s.receive(c)
+			}
+			s.Require().NoError(w.Close())
+
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			s.Assert().Len(c.requests, t.requests)
+		})
+	}
+}
+
+func (s *OTLPSuite) TestBatchTimeout() {
+	c := s.collector(ok)
+	defer c.Close()
+
+	w := New(Options{Endpoint: c.URL + "/v1/logs", BatchTimeout: 10 * time.Millisecond})
+	defer w.Close()
+
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Message: "a"}))
+	s.Assert().Equal("a", s.receive(c).records[0].body)
+}
+
+func (s *OTLPSuite) TestCloseFlushes() {
+	c := s.collector(ok)
+	defer c.Close()
+
+	w := New(Options{Endpoint: c.URL + "/v1/logs", BatchTimeout: time.Hour})
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Message: "a"}))
+	s.Require().NoError(w.Close())
+
+	s.Assert().Equal("a", s.receive(c).records[0].body)
+}
+
+func (s *OTLPSuite) TestFlush() {
+	c := s.collector(ok)
+	defer c.Close()
+
+	w := New(Options{Endpoint: c.URL + "/v1/logs", BatchTimeout: time.Hour})
+	defer w.Close()
+
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Message: "a"}))
+	s.Require().NoError(w.Flush())
+	s.Assert().Equal("a", s.receive(c).records[0].body)
+
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Message: "b"}))
+	s.Require().NoError(w.Flush())
+	s.Assert().Equal("b", s.receive(c).records[0].body)
+}
+
+func (s *OTLPSuite) TestBufferFull() {
+	w := New(Options{Endpoint: "http://127.0.0.1:1/v1/logs", BufferSize: 1, BatchSize: 1})
+	defer w.Close()
+
+	var err error
+	for i := 0; i < 10 && err == nil; i++ {
+		err = w.WriteEntry(entry.Entry{Message: "a"})
+	}
+	s.Assert().ErrorIs(err, errBufferFull)
+}
@@ -0,0 +1,282 @@
+package otlp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/americanas-go/log/internal/order"
+	"github.com/americanas-go/log/internal/protobuf"
+)
+
+// ScopeName is the name of the instrumentation scope of the records.
+const ScopeName = "github.com/americanas-go/log"
+
+// Severity numbers of the levels, the first of the matching range of
+// https://opentelemetry.io/docs/specs/otel/logs/data-model/#field-severitynumber,
+// the second of the FATAL range for FATAL as it is above PANIC.
+var severities = map[log.Level]uint64{
+	log.TraceLevel: 1,
+	log.DebugLevel: 5,
+	log.InfoLevel:  9,
+	log.WarnLevel:  13,
+	log.ErrorLevel: 17,
+	log.PanicLevel: 21,
+	log.FatalLevel: 22,
+}
+
+// The messages below are those of the opentelemetry-proto repository, only
+// the fields the writer sets are listed.
+//
+//	message ExportLogsServiceRequest { repeated ResourceLogs resource_logs = 1; }
+//	message ResourceLogs { Resource resource = 1; repeated ScopeLogs scope_logs = 2; }
+//	message Resource { repeated KeyValue attributes = 1; }
+//	message ScopeLogs { InstrumentationScope scope = 1; repeated LogRecord log_records = 2; }
+//	message InstrumentationScope { string name = 1; }
+//	message LogRecord {
+//	  fixed64 time_unix_nano = 1; SeverityNumber severity_number = 2; string severity_text = 3;
+//	  AnyValue body = 5; repeated KeyValue attributes = 6; bytes trace_id = 9; bytes span_id = 10;
+//	  fixed64 observed_time_unix_nano = 11;
+//	}
+//	message KeyValue { string key = 1; AnyValue value = 2; }
+//	message AnyValue {
+//	  oneof value { string string_value = 1; bool bool_value = 2; int64 int_value = 3; double double_value = 4;
+//	    ArrayValue array_value = 5; KeyValueList kvlist_value = 6; bytes bytes_value = 7; }
+//	}
+//	message ArrayValue { repeated AnyValue values = 1; }
+//	message KeyValueList { repeated KeyValue values = 1; }
+
+// encodeRequest returns the ExportLogsServiceRequest of the encoded records.
+func encodeRequest(resource []byte, records [][]byte) []byte {
+	scope := protobuf.AppendMessage(nil, 1, protobuf.AppendString(nil, 1, ScopeName))
+	for _, record := range records {
+		scope = protobuf.AppendMessage(scope, 2, record)
+	}
+
+	resourceLogs := protobuf.AppendMessage(nil, 1, resource)
+	resourceLogs = protobuf.AppendMessage(resourceLogs, 2, scope)
+	return protobuf.AppendMessage(nil, 1, resourceLogs)
+}
+
+// encodeResource returns the Resource of the options.
+func encodeResource(options Options) []byte {
+	attributes := make(map[string]string, len(options.ResourceAttributes)+2)
+	for k, v := range options.ResourceAttributes {
+		attributes[k] = v
+	}
+	attributes["service.name"] = options.ServiceName
+	if options.ServiceVersion != "" {
+		attributes["service.version"] = options.ServiceVersion
+	}
+
+	keys := make([]string, 0, len(attributes))
+	for k := range attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var resource []byte
+	for _, k := range keys {
+		resource = protobuf.AppendMessage(resource, 1, encodeKeyValue(k, attributes[k]))
+	}
+	return resource
+}
+
+// encodeRecord returns the LogRecord of e. The fields named by the trace and
+// span id fields of options set the trace and span ids when they hold valid
+// hex ids, they are attributes otherwise. The attributes of the priority fields
+// come first.
+func encodeRecord(e entry.Entry, options Options) []byte {
+	traceIDField, spanIDField := options.TraceIDField, options.SpanIDField
+
+	observed := time.Now()
+	t := e.Time
+	if t.IsZero() {
+		t = observed
+	}
+
+	var record []byte
+	record = protobuf.AppendFixed64(record, 1, uint64(t.UnixNano()))
+	record = protobuf.AppendVarint(record, 2, severities[e.Level])
+	record = protobuf.AppendString(record, 3, e.Level.String())
+	record = protobuf.AppendMessage(record, 5, encodeAnyValue(e.Message))
+
+	traceID, traceOK := hexID(e.Fields[traceIDField], 16)
+	spanID, spanOK := hexID(e.Fields[spanIDField], 8)
+
+	keys := make([]string, 0, len(e.Fields)+1)
+	for k := range e.Fields {
+		if !(k == traceIDField && traceOK || k == spanIDField && spanOK) {
+			keys = append(keys, k)
+		}
+	}
+	order.Sort(keys, options.PriorityKeys)
+	for _, k := range keys {
+		record = protobuf.AppendMessage(record, 6, encodeKeyValue(k, e.Fields[k]))
+	}
+	if e.Caller != "" {
+		record = protobuf.AppendMessage(record, 6, encodeKeyValue("code.filepath", e.Caller))
+	}
+
+	record = protobuf.AppendBytes(record, 9, traceID)
+	record = protobuf.AppendBytes(record, 10, spanID)
+	return protobuf.AppendFixed64(record, 11, uint64(observed.UnixNano()))
+}
+
+// hexID returns the id of size bytes held by v, as hex or as bytes.
+func hexID(v interface{}, size int) ([]byte, bool) {
+	var id []byte
+	switch v := v.(type) {
+	case nil:
+		return nil, false
+	case []byte:
+		id = v
+	case string:
+		id, _ = hex.DecodeString(v)
+	default:
+		// such as the TraceID and SpanID types of the OpenTelemetry API
+		id, _ = hex.DecodeString(fmt.Sprint(v))
+	}
+	if len(id) != size || bytes.Count(id, []byte{0}) == size {
+		return nil, false
+	}
+	return id, true
+}
+
+func encodeKeyValue(k string, v interface{}) []byte {
+	kv := protobuf.AppendString(nil, 1, k)
+	return protobuf.AppendMessage(kv, 2, encodeAnyValue(v))
+}
+
+// encodeAnyValue returns the AnyValue of v. Errors and fmt.Stringers are
+// strings, times are RFC 3339 strings, durations are nanoseconds and the values
+// of other types are converted as they would be encoded to JSON.
+func encodeAnyValue(v interface{}) []byte {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return protobuf.AppendMessage(nil, 1, []byte(v))
+	case bool:
+		if v {
+			return oneofVarint(2, 1)
+		}
+		return oneofVarint(2, 0)
+	case int:
+		return oneofVarint(3, uint64(v))
+	case int8:
+		return oneofVarint(3, uint64(v))
+	case int16:
+		return oneofVarint(3, uint64(v))
+	case int32:
+		return oneofVarint(3, uint64(v))
+	case int64:
+		return oneofVarint(3, uint64(v))
+	case uint:
+		return encodeUint(uint64(v))
+	case uint8:
+		return encodeUint(uint64(v))
+	case uint16:
+		return encodeUint(uint64(v))
+	case uint32:
+		return encodeUint(uint64(v))
+	case uint64:
+		return encodeUint(v)
+	case float32:
+		return oneofDouble(float64(v))
+	case float64:
+		return oneofDouble(v)
+	case []byte:
+		return protobuf.AppendMessage(nil, 7, v)
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return oneofVarint(3, uint64(i))
+		}
+		if f, err := v.Float64(); err == nil {
+			return oneofDouble(f)
+		}
+		return protobuf.AppendMessage(nil, 1, []byte(v))
+	case time.Time:
+		return protobuf.AppendMessage(nil, 1, []byte(v.Format(time.RFC3339Nano)))
+	case time.Duration:
+		return oneofVarint(3, uint64(v))
+	case error:
+		return protobuf.AppendMessage(nil, 1, []byte(v.Error()))
+	case fmt.Stringer:
+		return protobuf.AppendMessage(nil, 1, []byte(v.String()))
+	case map[string]interface{}:
+		var list []byte
+		for k, e := range v {
+			list = protobuf.AppendMessage(list, 1, encodeKeyValue(k, e))
+		}
+		return protobuf.AppendMessage(nil, 6, list)
+	case []interface{}:
+		var array []byte
+		for _, e := range v {
+			array = protobuf.AppendMessage(array, 1, encodeAnyValue(e))
+		}
+		return protobuf.AppendMessage(nil, 5, array)
+	}
+
+	return encodeReflected(reflect.ValueOf(v))
+}
+
+func encodeReflected(v reflect.Value) []byte {
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() == reflect.String {
+			var list []byte
+			iter := v.MapRange()
+			for iter.Next() {
+				list = protobuf.AppendMessage(list, 1, encodeKeyValue(iter.Key().String(), iter.Value().Interface()))
+			}
+			return protobuf.AppendMessage(nil, 6, list)
+		}
+	case reflect.Slice, reflect.Array:
+		var array []byte
+		for i := 0; i < v.Len(); i++ {
+			array = protobuf.AppendMessage(array, 1, encodeAnyValue(v.Index(i).Interface()))
+		}
+		return protobuf.AppendMessage(nil, 5, array)
+	}
+
+	// other values are written as their JSON representation
+	data, err := json.Marshal(v.Interface())
+	if err != nil {
+		return protobuf.AppendMessage(nil, 1, []byte(fmt.Sprint(v.Interface())))
+	}
+	var decoded interface{}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	if err := d.Decode(&decoded); err != nil {
+		return protobuf.AppendMessage(nil, 1, data)
+	}
+	return encodeAnyValue(decoded)
+}
+
+// encodeUint returns the AnyValue of v, a string when it does not fit an
+// int64.
+func encodeUint(v uint64) []byte {
+	if v > math.MaxInt64 {
+		return protobuf.AppendMessage(nil, 1, []byte(fmt.Sprint(v)))
+	}
+	return oneofVarint(3, v)
+}
+
+// oneofVarint returns a varint member of a oneof, written even when zero.
+func oneofVarint(number int, v uint64) []byte {
+	return binary.AppendUvarint(protobuf.AppendTag(nil, number, protobuf.WireVarint), v)
+}
+
+// oneofDouble returns a double member of a oneof, written even when zero.
+func oneofDouble(v float64) []byte {
+	return binary.LittleEndian.AppendUint64(protobuf.AppendTag(nil, 4, protobuf.WireFixed64), math.Float64bits(v))
+}
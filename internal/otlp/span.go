@@ -0,0 +1,46 @@
+package otlp
+
+import (
+	"context"
+
+	"github.com/americanas-go/log"
+)
+
+// SpanContext reads the trace and span ids of the span of a context, so that
+// the loggers returned by FromContext correlate their records with the span
+// even when no middleware added the ids to the fields.
+type SpanContext struct {
+	Func         func(ctx context.Context) (traceID, spanID string) // hex ids of the span of ctx, empty without a span
+	TraceIDField string                                             // field holding the trace id, trace_id when empty
+	SpanIDField  string                                             // field holding the span id, span_id when empty
+}
+
+// Fields returns the ids of the span of ctx as fields, nil when s or its Func
+// is nil or ctx holds no span.
+func (s *SpanContext) Fields(ctx context.Context) log.Fields {
+	if s == nil || s.Func == nil || ctx == nil {
+		return nil
+	}
+
+	traceID, spanID := s.Func(ctx)
+	if traceID == "" && spanID == "" {
+		return nil
+	}
+
+	traceIDField, spanIDField := s.TraceIDField, s.SpanIDField
+	if traceIDField == "" {
+		traceIDField = defaultTraceIDField
+	}
+	if spanIDField == "" {
+		spanIDField = defaultSpanIDField
+	}
+
+	fields := log.Fields{}
+	if traceID != "" {
+		fields[traceIDField] = traceID
+	}
+	if spanID != "" {
+		fields[spanIDField] = spanID
+	}
+	return fields
+}
@@ -0,0 +1,358 @@
+// Package pretty writes entries for the humans reading a console, the same
+// whichever backend writes them:
+//
+//	INFO  +1.204s   order created app/main.go:10
+//	    order: 1
+//	    user:
+//	      name: John Doe
+//	      tags:
+//	        - new
+//	    error: something bad
+//
+// The first line holds the level badge, padded so that the messages are
+// aligned, the time elapsed since the start of the logger, the message and
+// the caller. The fields follow, one per line, the priority ones first and the
+// others sorted by key, maps and structs being indented below their key and
+// lists written as items. The error and the stack trace come last, their lines
+// below their key, and the values longer than the width are wrapped.
+package pretty
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/color"
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/americanas-go/log/internal/order"
+)
+
+// DefaultWidth is the width at which the values are wrapped.
+const DefaultWidth = 100
+
+// Indent is the indentation of the fields, below the first line.
+const Indent = 4
+
+// Options configures the writing.
+type Options struct {
+	Start    time.Time // start of the relative times, written as clock times when zero
+	Color    bool      // whether the levels, times, keys and errors are colored
+	Width    int       // width at which the values are wrapped, DefaultWidth when zero
+	ErrorKey string    // field of the error, written last
+	StackKey string    // field of the stack trace, written after the error
+
+	PriorityKeys []string // fields written first, in this order, the others being sorted by key
+}
+
+// Writer writes entries as pretty text to an io.Writer.
+type Writer struct {
+	mu      sync.Mutex
+	w       io.Writer
+	options Options
+}
+
+// NewWriter returns a Writer writing to w.
+func NewWriter(w io.Writer, options Options) *Writer {
+	return &Writer{w: w, options: options}
+}
+
+// WriteEntry implements entry.Writer.
+func (w *Writer) WriteEntry(e entry.Entry) error {
+	text := Append(nil, e, w.options)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	_, err := w.w.Write(text)
+	return err
+}
+
+// Append appends the pretty text of e to dst.
+func Append(dst []byte, e entry.Entry, options Options) []byte {
+	dst = append(dst, Badge(e.Level, options.Color)...)
+	dst = append(dst, ' ')
+	dst = append(dst, Since(e.Time, options)...)
+	dst = append(dst, ' ')
+	dst = append(dst, e.Message...)
+	if e.Caller != "" {
+		dst = append(dst, ' ')
+		dst = append(dst, paint(options, color.Faint, e.Caller)...)
+	}
+	dst = append(dst, '\n')
+	return AppendFields(dst, e.Fields, options)
+}
+
+// Badge returns the name of level, padded to the length of the longest one.
+func Badge(level log.Level, colored bool) string {
+	var name string
+	switch level {
+	case log.TraceLevel:
+		name = "TRACE"
+	case log.DebugLevel:
+		name = "DEBUG"
+	case log.InfoLevel:
+		name = "INFO "
+	case log.WarnLevel:
+		name = "WARN "
+	case log.ErrorLevel:
+		name = "ERROR"
+	case log.PanicLevel:
+		name = "PANIC"
+	default:
+		name = "FATAL"
+	}
+	if colored {
+		return color.Level(level, name)
+	}
+	return name
+}
+
+// Since returns the time elapsed between the start and t, such as +1.204s,
+// padded so that the messages are aligned, or the clock time of t when there
+// is no start.
+func Since(t time.Time, options Options) string {
+	var s string
+	if options.Start.IsZero() {
+		s = t.Format("15:04:05.000")
+	} else {
+		s = fmt.Sprintf("%-9s", "+"+formatDuration(t.Sub(options.Start)))
+	}
+	return paint(options, color.Faint, s)
+}
+
+// formatDuration returns d in seconds with milliseconds, and with minutes and
+// hours when it is longer.
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Millisecond)
+	if d < time.Minute && d > -time.Minute {
+		return strconv.FormatFloat(d.Seconds(), 'f', 3, 64) + "s"
+	}
+	return d.String()
+}
+
+// AppendFields appends the lines of fields to dst.
+func AppendFields(dst []byte, fields log.Fields, options Options) []byte {
+	if options.Width <= 0 {
+		options.Width = DefaultWidth
+	}
+
+	p := printer{options: options}
+	for _, k := range order.Keys(fields, options.PriorityKeys) {
+		if k == options.ErrorKey || k == options.StackKey {
+			continue
+		}
+		dst = p.appendField(dst, Indent, k, fields[k])
+	}
+	if v, ok := fields[options.ErrorKey]; ok && options.ErrorKey != "" {
+		dst = p.appendError(dst, options.ErrorKey, v)
+	}
+	if v, ok := fields[options.StackKey]; ok && options.StackKey != "" {
+		dst = p.appendLines(dst, Indent, options.StackKey, stackTrace(v))
+	}
+	return dst
+}
+
+type printer struct {
+	options Options
+}
+
+func (p printer) appendKey(dst []byte, indent int, key string) []byte {
+	dst = append(dst, strings.Repeat(" ", indent)...)
+	return append(dst, paint(p.options, color.Cyan, key+":")...)
+}
+
+func (p printer) appendField(dst []byte, indent int, key string, v interface{}) []byte {
+	dst = p.appendKey(dst, indent, key)
+	return p.appendValue(dst, indent, len(key)+1, normalize(v))
+}
+
+// appendValue appends v after a key or an item marker of width used, on the
+// same line when it fits and below it otherwise.
+func (p printer) appendValue(dst []byte, indent int, used int, v interface{}) []byte {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return append(dst, " {}\n"...)
+		}
+		dst = append(dst, '\n')
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			dst = p.appendField(dst, indent+2, k, v[k])
+		}
+		return dst
+	case []interface{}:
+		if len(v) == 0 {
+			return append(dst, " []\n"...)
+		}
+		dst = append(dst, '\n')
+		for _, e := range v {
+			dst = append(dst, strings.Repeat(" ", indent+2)...)
+			dst = append(dst, '-')
+			dst = p.appendValue(dst, indent+2, 1, normalize(e))
+		}
+		return dst
+	default:
+		return p.appendText(dst, indent, used, scalar(v), 0)
+	}
+}
+
+// appendText appends s on the same line when it fits, and wrapped on the
+// lines below otherwise.
+func (p printer) appendText(dst []byte, indent int, used int, s string, code int) []byte {
+	if !strings.Contains(s, "\n") && indent+used+1+len(s) <= p.options.Width {
+		dst = append(dst, ' ')
+		dst = append(dst, p.paintCode(code, s)...)
+		return append(dst, '\n')
+	}
+
+	dst = append(dst, '\n')
+	width := max(p.options.Width-indent-2, 20)
+	for _, line := range strings.Split(strings.TrimRight(s, "\n"), "\n") {
+		for _, part := range wrap(line, width) {
+			dst = append(dst, strings.Repeat(" ", indent+2)...)
+			dst = append(dst, p.paintCode(code, part)...)
+			dst = append(dst, '\n')
+		}
+	}
+	return dst
+}
+
+// appendLines appends the lines of s below the key.
+func (p printer) appendLines(dst []byte, indent int, key string, s string) []byte {
+	dst = p.appendKey(dst, indent, key)
+	dst = append(dst, '\n')
+	for _, line := range strings.Split(strings.TrimRight(s, "\n"), "\n") {
+		dst = append(dst, strings.Repeat(" ", indent+2)...)
+		dst = append(dst, line...)
+		dst = append(dst, '\n')
+	}
+	return dst
+}
+
+// appendError appends the message of the error v, followed by the lines of
+// its stack trace when it formats one with %+v, as the errors of
+// github.com/pkg/errors do.
+func (p printer) appendError(dst []byte, key string, v interface{}) []byte {
+	dst = p.appendKey(dst, Indent, key)
+	err, ok := v.(error)
+	if !ok {
+		return p.appendText(dst, Indent, len(key)+1, scalar(normalize(v)), color.Red)
+	}
+
+	dst = p.appendText(dst, Indent, len(key)+1, err.Error(), color.Red)
+	if f, ok := err.(fmt.Formatter); ok {
+		if verbose := fmt.Sprintf("%+v", f); verbose != err.Error() {
+			for _, line := range strings.Split(strings.TrimRight(verbose, "\n"), "\n") {
+				dst = append(dst, strings.Repeat(" ", Indent+2)...)
+				dst = append(dst, line...)
+				dst = append(dst, '\n')
+			}
+		}
+	}
+	return dst
+}
+
+func (p printer) paintCode(code int, s string) string {
+	if code == 0 {
+		return s
+	}
+	return paint(p.options, code, s)
+}
+
+func paint(options Options, code int, s string) string {
+	if !options.Color {
+		return s
+	}
+	return color.Paint(code, s)
+}
+
+// wrap splits s into lines of at most width bytes, at the spaces when there
+// are some.
+func wrap(s string, width int) []string {
+	var lines []string
+	for len(s) > width {
+		i := strings.LastIndexByte(s[:width+1], ' ')
+		if i <= 0 {
+			lines = append(lines, s[:width])
+			s = s[width:]
+			continue
+		}
+		lines = append(lines, s[:i])
+		s = s[i+1:]
+	}
+	return append(lines, s)
+}
+
+// normalize returns v as a scalar, a map[string]interface{} or a
+// []interface{}, structs and typed maps and slices being converted through
+// their JSON encoding.
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case nil, bool, string, json.Number, error, time.Time, time.Duration, fmt.Stringer, []byte,
+		int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return v
+	case map[string]interface{}, []interface{}:
+		return v
+	case log.Fields:
+		return map[string]interface{}(v)
+	}
+
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct, reflect.Pointer:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		var decoded interface{}
+		d := json.NewDecoder(bytes.NewReader(data))
+		d.UseNumber()
+		if err := d.Decode(&decoded); err != nil {
+			return string(data)
+		}
+		return decoded
+	}
+	return v
+}
+
+// scalar returns the text of a value which is neither a map nor a list.
+func scalar(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case error:
+		return v.Error()
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		return v.String()
+	}
+	return fmt.Sprint(v)
+}
+
+// stackTrace returns v as a string, stack traces being arrays of frames for
+// some backends.
+func stackTrace(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	if data, err := json.Marshal(v); err == nil {
+		return string(data)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,158 @@
+package pretty
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/stretchr/testify/suite"
+)
+
+type PrettySuite struct {
+	suite.Suite
+}
+
+func TestPrettySuite(t *testing.T) {
+	suite.Run(t, new(PrettySuite))
+}
+
+var start = time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+var defaults = Options{Start: start, ErrorKey: "err", StackKey: "stacktrace"}
+
+type user struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+func (s *PrettySuite) TestBadge() {
+	tt := []struct {
+		level log.Level
+		want  string
+	}{
+		{level: log.TraceLevel, want: "TRACE"},
+		{level: log.DebugLevel, want: "DEBUG"},
+		{level: log.InfoLevel, want: "INFO "},
+		{level: log.WarnLevel, want: "WARN "},
+		{level: log.ErrorLevel, want: "ERROR"},
+		{level: log.PanicLevel, want: "PANIC"},
+		{level: log.FatalLevel, want: "FATAL"},
+	}
+	for _, t := range tt {
+		s.Run(t.level.String(), func() {
+			s.Assert().Equal(t.want, Badge(t.level, false))
+		})
+	}
+
+	s.Assert().Equal("\x1b[33mWARN \x1b[0m", Badge(log.WarnLevel, true))
+}
+
+func (s *PrettySuite) TestSince() {
+	s.Assert().Equal("+1.204s  ", Since(start.Add(1204*time.Millisecond), defaults))
+	s.Assert().Equal("+2m5s    ", Since(start.Add(125*time.Second), defaults))
+	s.Assert().Equal("03:04:06.500", Since(start.Add(1500*time.Millisecond), Options{}))
+}
+
+func (s *PrettySuite) TestAppend() {
+	tt := []struct {
+		name    string
+		entry   entry.Entry
+		options Options
+		want    string
+	}{
+		{
+			name:    "without fields",
+			entry:   entry.Entry{Time: start.Add(time.Second), Level: log.InfoLevel, Message: "hello"},
+			options: defaults,
+			want:    "INFO  +1.000s   hello\n",
+		},
+		{
+			name: "with caller and nested fields",
+			entry: entry.Entry{Time: start, Level: log.WarnLevel, Message: "order created", Caller: "app/main.go:10", Fields: log.Fields{
+				"order": 1,
+				"user":  user{Name: "John Doe", Tags: []string{"new", "vip"}},
+				"meta":  map[string]interface{}{"b": 2, "a": map[string]interface{}{}},
+			}},
+			options: defaults,
+			want: "WARN  +0.000s   order created app/main.go:10\n" +
+				"    meta:\n" +
+				"      a: {}\n" +
+				"      b: 2\n" +
+				"    order: 1\n" +
+				"    user:\n" +
+				"      name: John Doe\n" +
+				"      tags:\n" +
+				"        - new\n" +
+				"        - vip\n",
+		},
+		{
+			name: "with error and stack trace last",
+			entry: entry.Entry{Time: start, Level: log.ErrorLevel, Message: "failed", Fields: log.Fields{
+				"stacktrace": "main.main\n\tapp/main.go:10",
+				"err":        errors.New("something bad"),
+				"attempt":    3,
+			}},
+			options: defaults,
+			want: "ERROR +0.000s   failed\n" +
+				"    attempt: 3\n" +
+				"    err: something bad\n" +
+				"    stacktrace:\n" +
+				"      main.main\n" +
+				"      \tapp/main.go:10\n",
+		},
+		{
+			name: "with priority keys",
+			entry: entry.Entry{Time: start, Level: log.InfoLevel, Message: "hello", Fields: log.Fields{
+				"b": 1, "user_id": 2, "a": 3, "request_id": 4,
+			}},
+			options: Options{Start: start, PriorityKeys: []string{"request_id", "trace_id", "user_id"}},
+			want: "INFO  +0.000s   hello\n" +
+				"    request_id: 4\n" +
+				"    user_id: 2\n" +
+				"    a: 3\n" +
+				"    b: 1\n",
+		},
+		{
+			name: "with long and multiline values",
+			entry: entry.Entry{Time: start, Level: log.DebugLevel, Message: "hello", Fields: log.Fields{
+				"query": "select * from orders where id = 1",
+				"body":  "line one\nline two",
+			}},
+			options: Options{Start: start, Width: 30},
+			want: "DEBUG +0.000s   hello\n" +
+				"    body:\n" +
+				"      line one\n" +
+				"      line two\n" +
+				"    query:\n" +
+				"      select * from orders\n" +
+				"      where id = 1\n",
+		},
+		{
+			name:    "with colors",
+			entry:   entry.Entry{Time: start, Level: log.ErrorLevel, Message: "failed", Fields: log.Fields{"err": "bad"}},
+			options: Options{Start: start, Color: true, ErrorKey: "err"},
+			want: "\x1b[31mERROR\x1b[0m \x1b[90m+0.000s  \x1b[0m failed\n" +
+				"    \x1b[36merr:\x1b[0m \x1b[31mbad\x1b[0m\n",
+		},
+	}
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			s.Assert().Equal(t.want, string(Append(nil, t.entry, t.options)))
+		})
+	}
+}
+
+func (s *PrettySuite) TestWriter() {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, Options{Start: start})
+
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: start, Level: log.InfoLevel, Message: "a"}))
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: start, Level: log.InfoLevel, Message: "b"}))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	s.Require().Equal([]string{"INFO  +0.000s   a", "INFO  +0.000s   b"}, lines)
+}
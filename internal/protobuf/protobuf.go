@@ -0,0 +1,151 @@
+// Package protobuf encodes and decodes the Protocol Buffers wire format of the
+// messages sent by the outputs, as described by
+// https://protobuf.dev/programming-guides/encoding/.
+//
+// It only covers what the outputs need: the Append functions add a field to a
+// buffer, embedded messages being encoded in their own buffer first, and Parse
+// reads the fields of a message back, mostly for tests.
+package protobuf
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Wire types.
+const (
+	WireVarint  = 0
+	WireFixed64 = 1
+	WireBytes   = 2
+	WireFixed32 = 5
+)
+
+// AppendTag appends the key of field number with wire type typ to b.
+func AppendTag(b []byte, number int, typ int) []byte {
+	return binary.AppendUvarint(b, uint64(number)<<3|uint64(typ))
+}
+
+// AppendVarint appends field number holding v, omitting it when v is zero,
+// the default value.
+func AppendVarint(b []byte, number int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	return binary.AppendUvarint(AppendTag(b, number, WireVarint), v)
+}
+
+// AppendInt appends field number holding the int64 v, omitting it when v is
+// zero.
+func AppendInt(b []byte, number int, v int64) []byte {
+	return AppendVarint(b, number, uint64(v))
+}
+
+// AppendBool appends field number holding v, omitting it when v is false.
+func AppendBool(b []byte, number int, v bool) []byte {
+	if !v {
+		return b
+	}
+	return AppendVarint(b, number, 1)
+}
+
+// AppendFixed64 appends field number holding v, omitting it when v is zero.
+func AppendFixed64(b []byte, number int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	return binary.LittleEndian.AppendUint64(AppendTag(b, number, WireFixed64), v)
+}
+
+// AppendFixed32 appends field number holding v, omitting it when v is zero.
+func AppendFixed32(b []byte, number int, v uint32) []byte {
+	if v == 0 {
+		return b
+	}
+	return binary.LittleEndian.AppendUint32(AppendTag(b, number, WireFixed32), v)
+}
+
+// AppendDouble appends field number holding v, omitting it when v is zero.
+func AppendDouble(b []byte, number int, v float64) []byte {
+	return AppendFixed64(b, number, math.Float64bits(v))
+}
+
+// AppendBytes appends field number holding v, omitting it when v is empty.
+// Embedded messages are appended as their encoding, with AppendMessage.
+func AppendBytes(b []byte, number int, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	return AppendMessage(b, number, v)
+}
+
+// AppendString appends field number holding v, omitting it when v is empty.
+func AppendString(b []byte, number int, v string) []byte {
+	if v == "" {
+		return b
+	}
+	b = binary.AppendUvarint(AppendTag(b, number, WireBytes), uint64(len(v)))
+	return append(b, v...)
+}
+
+// AppendMessage appends field number holding the encoded message m, even when
+// m is empty, as the presence of a message or of a oneof member is meaningful.
+func AppendMessage(b []byte, number int, m []byte) []byte {
+	b = binary.AppendUvarint(AppendTag(b, number, WireBytes), uint64(len(m)))
+	return append(b, m...)
+}
+
+// Field is a field read by Parse. Varint and Fixed hold the value of the
+// numeric wire types, Bytes the one of the length delimited type.
+type Field struct {
+	Number int
+	Type   int
+	Varint uint64
+	Fixed  uint64
+	Bytes  []byte
+}
+
+var errTruncated = errors.New("protobuf: truncated message")
+
+// Parse returns the fields of the message b, in order.
+func Parse(b []byte) ([]Field, error) {
+	var fields []Field
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, errTruncated
+		}
+		b = b[n:]
+
+		f := Field{Number: int(key >> 3), Type: int(key & 0x07)}
+		switch f.Type {
+		case WireVarint:
+			f.Varint, n = binary.Uvarint(b)
+			if n <= 0 {
+				return nil, errTruncated
+			}
+			b = b[n:]
+		case WireFixed64:
+			if len(b) < 8 {
+				return nil, errTruncated
+			}
+			f.Fixed, b = binary.LittleEndian.Uint64(b), b[8:]
+		case WireFixed32:
+			if len(b) < 4 {
+				return nil, errTruncated
+			}
+			f.Fixed, b = uint64(binary.LittleEndian.Uint32(b)), b[4:]
+		case WireBytes:
+			l, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < l {
+				return nil, errTruncated
+			}
+			f.Bytes, b = b[n:n+int(l)], b[n+int(l):]
+		default:
+			return nil, fmt.Errorf("protobuf: unsupported wire type %d", f.Type)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
@@ -0,0 +1,68 @@
+package protobuf
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ProtobufSuite struct {
+	suite.Suite
+}
+
+func TestProtobufSuite(t *testing.T) {
+	suite.Run(t, new(ProtobufSuite))
+}
+
+func (s *ProtobufSuite) TestAppend() {
+	tt := []struct {
+		name  string
+		bytes []byte
+		want  []Field
+	}{
+		{name: "varint", bytes: AppendVarint(nil, 1, 150), want: []Field{{Number: 1, Type: WireVarint, Varint: 150}}},
+		{name: "negative int", bytes: AppendInt(nil, 2, -1), want: []Field{{Number: 2, Type: WireVarint, Varint: math.MaxUint64}}},
+		{name: "bool", bytes: AppendBool(nil, 3, true), want: []Field{{Number: 3, Type: WireVarint, Varint: 1}}},
+		{name: "fixed64", bytes: AppendFixed64(nil, 4, 1<<40), want: []Field{{Number: 4, Type: WireFixed64, Fixed: 1 << 40}}},
+		{name: "fixed32", bytes: AppendFixed32(nil, 5, 7), want: []Field{{Number: 5, Type: WireFixed32, Fixed: 7}}},
+		{name: "double", bytes: AppendDouble(nil, 6, 1.5), want: []Field{{Number: 6, Type: WireFixed64, Fixed: math.Float64bits(1.5)}}},
+		{name: "string", bytes: AppendString(nil, 7, "abc"), want: []Field{{Number: 7, Type: WireBytes, Bytes: []byte("abc")}}},
+		{name: "bytes", bytes: AppendBytes(nil, 8, []byte{1}), want: []Field{{Number: 8, Type: WireBytes, Bytes: []byte{1}}}},
+		{name: "empty message", bytes: AppendMessage(nil, 9, nil), want: []Field{{Number: 9, Type: WireBytes, Bytes: []byte{}}}},
+		{name: "large number", bytes: AppendString(nil, 300, "a"), want: []Field{{Number: 300, Type: WireBytes, Bytes: []byte("a")}}},
+	}
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			got, err := Parse(t.bytes)
+			s.Require().NoError(err)
+			s.Assert().Equal(t.want, got)
+		})
+	}
+}
+
+func (s *ProtobufSuite) TestOmitsDefaults() {
+	var b []byte
+	b = AppendVarint(b, 1, 0)
+	b = AppendInt(b, 2, 0)
+	b = AppendBool(b, 3, false)
+	b = AppendFixed64(b, 4, 0)
+	b = AppendFixed32(b, 5, 0)
+	b = AppendDouble(b, 6, 0)
+	b = AppendString(b, 7, "")
+	b = AppendBytes(b, 8, nil)
+	s.Assert().Empty(b)
+}
+
+func (s *ProtobufSuite) TestEncoding() {
+	// the example of the encoding guide
+	s.Assert().Equal([]byte{0x08, 0x96, 0x01}, AppendVarint(nil, 1, 150))
+	s.Assert().Equal([]byte{0x12, 0x07, 't', 'e', 's', 't', 'i', 'n', 'g'}, AppendString(nil, 2, "testing"))
+}
+
+func (s *ProtobufSuite) TestParseErrors() {
+	for _, b := range [][]byte{{0x08}, {0x12, 0x05, 'a'}, {0x09, 0x01}, {0x0b}, {0x80}} {
+		_, err := Parse(b)
+		s.Assert().Error(err, "%x", b)
+	}
+}
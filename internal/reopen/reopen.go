@@ -0,0 +1,207 @@
+// Package reopen writes to a file rotated by an external tool, such as
+// logrotate with its create directive, instead of rotating it itself.
+//
+// The file is reopened when Reopen is called, when the process receives SIGHUP
+// once NotifySIGHUP was called, and when its path no longer leads to the open
+// file, which is checked at most once a second by the writes. Its missing
+// parent directories are created.
+package reopen
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultFileMode = 0o600
+	defaultDirMode  = 0o755
+)
+
+// checkInterval is the minimum interval between two checks of the path of the
+// file, replaced by tests.
+var checkInterval = time.Second
+
+var (
+	writersMu sync.Mutex
+	writers   = map[string]*Writer{}
+
+	notify sync.Once
+)
+
+// Options configures a Writer. Zero modes take a default, before the umask:
+// 0600 for the file and 0755 for the directories.
+type Options struct {
+	Filename string      // path of the file
+	FileMode os.FileMode // permissions of the file when it is created
+	DirMode  os.FileMode // permissions of the parent directories when they are created
+}
+
+// Writer is an io.WriteCloser writing to a file which is reopened after an
+// external rotation. The file is opened by the first write.
+type Writer struct {
+	options Options
+	key     string // path of the writer in writers
+	refs    int    // calls to New not matched by Close, guarded by writersMu
+
+	mu      sync.Mutex
+	file    *os.File
+	info    os.FileInfo
+	checked time.Time
+}
+
+// New returns the Writer of the file of options, the one already returned for
+// the same path if any, so that the loggers writing to a file share it and
+// the writers are not kept once per logger. It fails when the Writer of the
+// path was returned for other modes and not closed since.
+func New(options Options) (*Writer, error) {
+	if options.FileMode == 0 {
+		options.FileMode = defaultFileMode
+	}
+	if options.DirMode == 0 {
+		options.DirMode = defaultDirMode
+	}
+
+	key := filepath.Clean(options.Filename)
+	if abs, err := filepath.Abs(key); err == nil {
+		key = abs
+	}
+
+	writersMu.Lock()
+	defer writersMu.Unlock()
+
+	if w, ok := writers[key]; ok {
+		if w.options.FileMode != options.FileMode || w.options.DirMode != options.DirMode {
+			return nil, fmt.Errorf("reopen: %s is already written with the modes %v and %v", key, w.options.FileMode, w.options.DirMode)
+		}
+		w.refs++
+		return w, nil
+	}
+	w := &Writer{options: options, key: key, refs: 1}
+	writers[key] = w
+	return w, nil
+}
+
+// NotifySIGHUP starts reopening the files of all the writers when the process
+// receives SIGHUP, which no longer terminates it. Later calls do nothing.
+func NotifySIGHUP() {
+	notify.Do(func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGHUP)
+		go func() {
+			for range signals {
+				_ = Reopen()
+			}
+		}()
+	})
+}
+
+// Reopen reopens the files of all the writers, as on SIGHUP.
+func Reopen() error {
+	writersMu.Lock()
+	all := make([]*Writer, 0, len(writers))
+	for _, w := range writers {
+		all = append(all, w)
+	}
+	writersMu.Unlock()
+
+	var errs []error
+	for _, w := range all {
+		if err := w.Reopen(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Write implements io.Writer, reopening the file first when its path leads to
+// another file or to none.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file != nil && time.Since(w.checked) >= checkInterval {
+		w.checked = time.Now()
+		if w.moved() {
+			_ = w.close()
+		}
+	}
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+	return w.file.Write(p)
+}
+
+// Reopen closes the file and opens the one at its path, creating it when
+// missing.
+func (w *Writer) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	_ = w.close()
+	return w.open()
+}
+
+// Close releases the Writer returned by New. Once each call to New for its
+// path is matched by a Close, the file is closed and the next call to New
+// returns another Writer, for any modes. Later writes reopen the file.
+func (w *Writer) Close() error {
+	writersMu.Lock()
+	if w.refs > 0 {
+		w.refs--
+	}
+	last := w.refs == 0
+	if last && writers[w.key] == w {
+		delete(writers, w.key)
+	}
+	writersMu.Unlock()
+
+	if !last {
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.close()
+}
+
+func (w *Writer) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.options.Filename), w.options.DirMode); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(w.options.Filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, w.options.FileMode)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.file, w.info, w.checked = f, info, time.Now()
+	return nil
+}
+
+func (w *Writer) close() error {
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file, w.info = nil, nil
+	return err
+}
+
+// moved reports whether the path of the file leads to another file, or to
+// none, e.g. after it was renamed by logrotate.
+func (w *Writer) moved() bool {
+	info, err := os.Stat(w.options.Filename)
+	return err != nil || !os.SameFile(info, w.info)
+}
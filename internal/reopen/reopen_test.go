@@ -0,0 +1,172 @@
+package reopen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ReopenSuite struct {
+	suite.Suite
+	dir string
+}
+
+func TestReopenSuite(t *testing.T) {
+	suite.Run(t, new(ReopenSuite))
+}
+
+func (s *ReopenSuite) SetupTest() {
+	s.dir = s.T().TempDir()
+}
+
+// open returns the Writer of options.
+func (s *ReopenSuite) open(options Options) *Writer {
+	w, err := New(options)
+	s.Require().NoError(err)
+	return w
+}
+
+func (s *ReopenSuite) write(w *Writer, data string) {
+	_, err := w.Write([]byte(data))
+	s.Require().NoError(err)
+}
+
+func (s *ReopenSuite) read(name string) string {
+	data, err := os.ReadFile(filepath.Join(s.dir, name))
+	s.Require().NoError(err)
+	return string(data)
+}
+
+// rotate renames the file as logrotate does, creating a new one when create
+// is set.
+func (s *ReopenSuite) rotate(create bool) {
+	s.Require().NoError(os.Rename(filepath.Join(s.dir, "app.log"), filepath.Join(s.dir, "app.log.1")))
+	if create {
+		s.Require().NoError(os.WriteFile(filepath.Join(s.dir, "app.log"), nil, 0o600))
+	}
+}
+
+func (s *ReopenSuite) TestReopen() {
+	w := s.open(Options{Filename: filepath.Join(s.dir, "app.log")})
+	defer w.Close()
+
+	s.write(w, "a")
+	s.rotate(true)
+	s.write(w, "b")
+	s.Require().NoError(w.Reopen())
+	s.write(w, "c")
+
+	s.Assert().Equal("ab", s.read("app.log.1"))
+	s.Assert().Equal("c", s.read("app.log"))
+}
+
+func (s *ReopenSuite) TestReopenAll() {
+	w := s.open(Options{Filename: filepath.Join(s.dir, "app.log")})
+	defer w.Close()
+
+	s.write(w, "a")
+	s.rotate(false)
+	s.Require().NoError(Reopen())
+	s.write(w, "b")
+
+	s.Assert().Equal("a", s.read("app.log.1"))
+	s.Assert().Equal("b", s.read("app.log"))
+}
+
+func (s *ReopenSuite) TestMoved() {
+	interval := checkInterval
+	checkInterval = 0
+	defer func() { checkInterval = interval }()
+
+	tt := []struct {
+		name   string
+		create bool
+	}{
+		{name: "created", create: true},
+		{name: "missing", create: false},
+	}
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			s.SetupTest()
+			w := s.open(Options{Filename: filepath.Join(s.dir, "app.log")})
+			defer w.Close()
+
+			s.write(w, "a")
+			s.rotate(t.create)
+			s.write(w, "b")
+
+			s.Assert().Equal("a", s.read("app.log.1"))
+			s.Assert().Equal("b", s.read("app.log"))
+		})
+	}
+}
+
+func (s *ReopenSuite) TestShared() {
+	name := filepath.Join(s.dir, "app.log")
+	w := s.open(Options{Filename: name})
+	defer w.Close()
+
+	s.Assert().Same(w, s.open(Options{Filename: filepath.Join(s.dir, ".", "app.log")}))
+	s.Assert().NotSame(w, s.open(Options{Filename: filepath.Join(s.dir, "other.log")}))
+}
+
+func (s *ReopenSuite) TestSharedWithOtherModes() {
+	name := filepath.Join(s.dir, "app.log")
+	w := s.open(Options{Filename: name, FileMode: 0o640})
+	defer w.Close()
+
+	_, err := New(Options{Filename: name, FileMode: 0o644})
+	s.Assert().ErrorContains(err, "already written with the modes")
+	_, err = New(Options{Filename: name, FileMode: 0o640, DirMode: 0o700})
+	s.Assert().Error(err)
+
+	// the default modes are those of the zero values
+	s.Assert().Same(s.open(Options{Filename: filepath.Join(s.dir, "other.log")}), s.open(Options{Filename: filepath.Join(s.dir, "other.log"), FileMode: 0o600, DirMode: 0o755}))
+}
+
+func (s *ReopenSuite) TestCloseShared() {
+	name := filepath.Join(s.dir, "app.log")
+	w := s.open(Options{Filename: name})
+	s.Require().Same(w, s.open(Options{Filename: name}))
+	s.write(w, "a")
+
+	// the file stays open until the last Close
+	s.Require().NoError(w.Close())
+	s.Assert().NotNil(w.file)
+	s.Assert().Same(w, s.open(Options{Filename: name}))
+	s.Require().NoError(w.Close())
+	s.Require().NoError(w.Close())
+	s.Assert().Nil(w.file)
+
+	// the path is written again with other modes once closed
+	other := s.open(Options{Filename: name, FileMode: 0o640})
+	defer other.Close()
+	s.Assert().NotSame(w, other)
+	s.write(other, "b")
+	s.Assert().Equal("ab", s.read("app.log"))
+}
+
+func (s *ReopenSuite) TestModes() {
+	w := s.open(Options{Filename: filepath.Join(s.dir, "a", "b", "app.log"), FileMode: 0o640, DirMode: 0o750})
+	defer w.Close()
+	s.write(w, "a")
+
+	// the modes are those of a file and a directory created with the umask
+	reference := s.T().TempDir()
+	s.Require().NoError(os.Mkdir(filepath.Join(reference, "dir"), 0o750))
+	s.Require().NoError(os.WriteFile(filepath.Join(reference, "file"), nil, 0o640))
+
+	for _, t := range []struct{ got, want string }{
+		{got: filepath.Join(s.dir, "a"), want: filepath.Join(reference, "dir")},
+		{got: filepath.Join(s.dir, "a", "b"), want: filepath.Join(reference, "dir")},
+		{got: filepath.Join(s.dir, "a", "b", "app.log"), want: filepath.Join(reference, "file")},
+	} {
+		got, err := os.Stat(t.got)
+		s.Require().NoError(err)
+		want, err := os.Stat(t.want)
+		s.Require().NoError(err)
+		s.Assert().Equal(want.Mode(), got.Mode(), t.got)
+	}
+}
@@ -0,0 +1,27 @@
+//go:build unix
+
+package reopen
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+func (s *ReopenSuite) TestSIGHUP() {
+	w := s.open(Options{Filename: filepath.Join(s.dir, "app.log")})
+	defer w.Close()
+	NotifySIGHUP()
+
+	s.write(w, "a")
+	s.rotate(false)
+	s.Require().NoError(syscall.Kill(os.Getpid(), syscall.SIGHUP))
+
+	s.Assert().Eventually(func() bool {
+		_, err := os.Stat(filepath.Join(s.dir, "app.log"))
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	s.write(w, "b")
+	s.Assert().Equal("b", s.read("app.log"))
+}
@@ -0,0 +1,558 @@
+// Package rotate writes to a file which is rotated by size, daily or hourly,
+// keeping its backups within a count, an age and a total size.
+//
+// The file keeps its name, the backups are renamed after a pattern holding
+// time layouts between braces, such as app-{2006-01-02}.log, formatted with
+// the start of the period of a time rotation or with the time of a size
+// rotation. A counter is appended to the backups which would overwrite
+// another, e.g. app-2021-01-02.log.1 after a size rotation during that day.
+// Backups are compressed and removed by a background goroutine after each
+// rotation.
+package rotate
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rotations. With RotationNone the file is rotated by an external tool
+// instead, see package reopen, New handles it as RotationSize.
+const (
+	RotationSize   = "SIZE"
+	RotationDaily  = "DAILY"
+	RotationHourly = "HOURLY"
+	RotationNone   = "NONE"
+)
+
+const (
+	megabyte = 1024 * 1024
+	day      = 24 * time.Hour
+
+	defaultMaxSize  = 100
+	defaultFileMode = 0o600
+	defaultDirMode  = 0o755
+
+	compressSuffix = ".gz"
+)
+
+// Rotations are the supported rotations.
+var Rotations = []string{RotationSize, RotationDaily, RotationHourly, RotationNone}
+
+// Options configures a Writer. Zero values take a default, the modes before
+// the umask.
+type Options struct {
+	Filename        string      // path of the file
+	Rotation        string      // SIZE/DAILY/HOURLY, SIZE when empty
+	Pattern         string      // backup file name, with time layouts between braces, the default of the rotation when empty
+	MaxSize         int         // size in megabytes which rotates the file, whatever the rotation, 100 when zero
+	MaxAge          int         // days a backup is kept, unlimited when zero
+	MaxBackups      int         // backups kept, unlimited when zero
+	MaxTotalSize    int         // megabytes used by the file and its backups, unlimited when zero
+	Compress        bool        // gzip the backups
+	LocalTime       bool        // name the backups and start the periods in local time, instead of UTC
+	RotateOnStartup bool        // rotate the existing file when opening it
+	FileMode        os.FileMode // permissions of the created files, 0600 when zero
+	DirMode         os.FileMode // permissions of the created parent directories, 0755 when zero
+}
+
+// Writer is an io.WriteCloser writing to a rotated file. The file is opened
+// by the first write, and reopened by the writes following Close.
+type Writer struct {
+	options  Options
+	dir      string
+	pattern  string
+	location *time.Location
+
+	mu      sync.Mutex
+	file    *os.File
+	size    int64
+	period  time.Time // start of the period of the file, for time rotations
+	started bool      // whether the file was opened once, so that it is rotated on startup once
+
+	milling sync.WaitGroup
+	millMu  sync.Mutex
+}
+
+// now is the clock of the writers, replaced by tests.
+var now = time.Now
+
+var (
+	writersMu sync.Mutex
+	writers   = map[string]*Writer{}
+)
+
+// New returns the Writer of the file of options, the one already returned for
+// the same path if any, so that the loggers writing to a file share it and
+// the file is not rotated twice. The shared Writer takes the other options,
+// its file being reopened with them by the next write when they changed.
+func New(options Options) *Writer {
+	if options.Rotation != RotationDaily && options.Rotation != RotationHourly {
+		options.Rotation = RotationSize
+	}
+	if options.MaxSize <= 0 {
+		options.MaxSize = defaultMaxSize
+	}
+	if options.FileMode == 0 {
+		options.FileMode = defaultFileMode
+	}
+	if options.DirMode == 0 {
+		options.DirMode = defaultDirMode
+	}
+
+	key := filepath.Clean(options.Filename)
+	if abs, err := filepath.Abs(key); err == nil {
+		key = abs
+	}
+
+	writersMu.Lock()
+	defer writersMu.Unlock()
+
+	w, ok := writers[key]
+	if !ok {
+		w = &Writer{}
+		writers[key] = w
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if ok && options == w.options {
+		return w
+	}
+	// the running compressions and removals read the options
+	_ = w.close()
+	w.milling.Wait()
+
+	w.options = options
+	w.dir = filepath.Dir(options.Filename)
+	w.pattern = options.Pattern
+	w.location = time.UTC
+	if options.LocalTime {
+		w.location = time.Local
+	}
+	if w.pattern == "" {
+		w.pattern = DefaultPattern(filepath.Base(options.Filename), options.Rotation)
+	}
+	return w
+}
+
+// DefaultPattern returns the backup pattern of the rotation for the file
+// name: the name followed by the time of the rotation, to the millisecond, for
+// SIZE, by the day for DAILY and by the hour for HOURLY, before the
+// extension. The SIZE one matches the backups of lumberjack, which are
+// rotated and removed along with the new ones.
+func DefaultPattern(name, rotation string) string {
+	ext := filepath.Ext(name)
+	layout := "2006-01-02T15-04-05.000"
+	switch rotation {
+	case RotationDaily:
+		layout = "2006-01-02"
+	case RotationHourly:
+		layout = "2006-01-02T15"
+	}
+	return strings.TrimSuffix(name, ext) + "-{" + layout + "}" + ext
+}
+
+// Write implements io.Writer, rotating the file first when its period is over
+// or when p would exceed the maximum size.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	t := now()
+	if w.options.Rotation != RotationSize && w.periodStart(t).After(w.period) {
+		if err := w.rotate(w.period); err != nil {
+			return 0, err
+		}
+	}
+	if w.size > 0 && w.size+int64(len(p)) > int64(w.options.MaxSize)*megabyte {
+		if err := w.rotate(t); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Rotate closes the file, renames it after the backup pattern and opens a new
+// one.
+func (w *Writer) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return err
+		}
+	}
+	t := now()
+	if w.options.Rotation != RotationSize {
+		t = w.period
+	}
+	return w.rotate(t)
+}
+
+// Close closes the file and waits for the pending compressions and removals.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	err := w.close()
+	w.mu.Unlock()
+
+	w.milling.Wait()
+	return err
+}
+
+func (w *Writer) close() error {
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// open opens the existing file, rotating it first when asked to or when it
+// belongs to a past period, or creates it.
+func (w *Writer) open() error {
+	startup := w.options.RotateOnStartup && !w.started
+	w.started = true
+	w.period = w.periodStart(now())
+
+	info, err := os.Stat(w.options.Filename)
+	if os.IsNotExist(err) {
+		return w.create()
+	}
+	if err != nil {
+		return err
+	}
+
+	// the period of the file is the one of its last write
+	period := w.periodStart(info.ModTime())
+	if info.Size() > 0 && (startup || w.options.Rotation != RotationSize && period.Before(w.period)) {
+		t := now()
+		if w.options.Rotation != RotationSize {
+			t = period
+		}
+		if err := w.backup(t); err != nil {
+			return err
+		}
+		w.mill()
+		return nil
+	}
+
+	f, err := os.OpenFile(w.options.Filename, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return err
+	}
+	w.file, w.size = f, info.Size()
+	return nil
+}
+
+func (w *Writer) create() error {
+	if err := os.MkdirAll(w.dir, w.options.DirMode); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(w.options.Filename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, w.options.FileMode)
+	if err != nil {
+		return err
+	}
+	w.file, w.size = f, 0
+	return nil
+}
+
+// rotate renames the file to the backup named after t and opens a new one.
+func (w *Writer) rotate(t time.Time) error {
+	if err := w.close(); err != nil {
+		return err
+	}
+
+	w.period = w.periodStart(now())
+	if err := w.backup(t); err != nil {
+		return err
+	}
+	w.mill()
+	return nil
+}
+
+// backup renames the closed file to the backup named after t, and creates the
+// next one.
+func (w *Writer) backup(t time.Time) error {
+	name := filepath.Join(w.dir, w.backupName(t))
+	if err := os.Rename(w.options.Filename, name); err != nil {
+		return err
+	}
+	return w.create()
+}
+
+// backupName returns the pattern formatted with t, followed by the first free
+// counter when a backup, compressed or not, has the same name.
+func (w *Writer) backupName(t time.Time) string {
+	name := format(w.pattern, t.In(w.location))
+	candidate := name
+	for i := 1; exists(filepath.Join(w.dir, candidate)) || exists(filepath.Join(w.dir, candidate+compressSuffix)); i++ {
+		candidate = name + "." + strconv.Itoa(i)
+	}
+	return candidate
+}
+
+func exists(name string) bool {
+	_, err := os.Lstat(name)
+	return err == nil
+}
+
+// periodStart returns the start of the period of t, in the naming location.
+func (w *Writer) periodStart(t time.Time) time.Time {
+	t = t.In(w.location)
+	switch w.options.Rotation {
+	case RotationDaily:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, w.location)
+	case RotationHourly:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, w.location)
+	}
+	return time.Time{}
+}
+
+// format returns pattern with its parts between braces formatted as time
+// layouts with t.
+func format(pattern string, t time.Time) string {
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(pattern, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(pattern[start:], '}')
+		if end < 0 {
+			break
+		}
+		b.WriteString(pattern[:start])
+		b.WriteString(t.Format(pattern[start+1 : start+end]))
+		pattern = pattern[start+end+1:]
+	}
+	b.WriteString(pattern)
+	return b.String()
+}
+
+// glob returns the pattern of the backup names, the layouts replaced by *.
+func glob(pattern string) string {
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(pattern, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(pattern[start:], '}')
+		if end < 0 {
+			break
+		}
+		b.WriteString(globEscape(pattern[:start]))
+		b.WriteString("*")
+		pattern = pattern[start+end+1:]
+	}
+	b.WriteString(globEscape(pattern))
+	return b.String()
+}
+
+func globEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`).Replace(s)
+}
+
+// backupFile is a backup found by mill.
+type backupFile struct {
+	path    string
+	time    time.Time // time of the name, which orders the backups and gives their age
+	modTime time.Time
+	size    int64
+}
+
+// mill compresses and removes the backups in the background.
+func (w *Writer) mill() {
+	if !w.options.Compress && w.options.MaxAge <= 0 && w.options.MaxBackups <= 0 && w.options.MaxTotalSize <= 0 {
+		return
+	}
+
+	w.milling.Add(1)
+	go func() {
+		defer w.milling.Done()
+
+		w.millMu.Lock()
+		defer w.millMu.Unlock()
+		_ = w.millRun()
+	}()
+}
+
+func (w *Writer) millRun() error {
+	backups, err := w.backups()
+	if err != nil {
+		return err
+	}
+
+	// newest first, the counters of a name following their modification
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].time.Equal(backups[j].time) {
+			return backups[i].time.After(backups[j].time)
+		}
+		return backups[i].modTime.After(backups[j].modTime)
+	})
+
+	var total int64
+	if w.options.MaxTotalSize > 0 {
+		if info, err := os.Stat(w.options.Filename); err == nil {
+			total = info.Size()
+		}
+	}
+
+	cutoff := now().Add(-time.Duration(w.options.MaxAge) * day)
+	var kept []backupFile
+	for i, b := range backups {
+		total += b.size
+		switch {
+		case w.options.MaxBackups > 0 && i >= w.options.MaxBackups,
+			w.options.MaxAge > 0 && b.time.Before(cutoff),
+			w.options.MaxTotalSize > 0 && total > int64(w.options.MaxTotalSize)*megabyte:
+			_ = os.Remove(b.path)
+		default:
+			kept = append(kept, b)
+		}
+	}
+
+	if !w.options.Compress {
+		return nil
+	}
+	for _, b := range kept {
+		if !strings.HasSuffix(b.path, compressSuffix) {
+			if err := compress(b.path, b.modTime); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// backups returns the backups of the file, the names matching the pattern
+// after removing the compression suffix and the counter.
+func (w *Writer) backups() ([]backupFile, error) {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	g := glob(w.pattern)
+	active := filepath.Base(w.options.Filename)
+
+	var backups []backupFile
+	for _, e := range entries {
+		if !e.Type().IsRegular() || e.Name() == active {
+			continue
+		}
+		name := strings.TrimSuffix(e.Name(), compressSuffix)
+		if matched, _ := filepath.Match(g, name); !matched {
+			if i := strings.LastIndexByte(name, '.'); i < 0 || !isCounter(name[i+1:]) {
+				continue
+			} else if matched, _ := filepath.Match(g, name[:i]); !matched {
+				continue
+			} else {
+				name = name[:i]
+			}
+		}
+
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backupFile{
+			path:    filepath.Join(w.dir, e.Name()),
+			time:    w.backupTime(name, info.ModTime()),
+			modTime: info.ModTime(),
+			size:    info.Size(),
+		})
+	}
+	return backups, nil
+}
+
+// backupTime returns the time of the backup named name, without its
+// compression suffix and counter, parsed from the name when the pattern holds
+// a single layout, as lumberjack does, which does not keep the modification
+// time of the backups it compresses. It is modTime otherwise.
+func (w *Writer) backupTime(name string, modTime time.Time) time.Time {
+	start := strings.IndexByte(w.pattern, '{')
+	end := strings.IndexByte(w.pattern, '}')
+	if start < 0 || end < start || strings.Count(w.pattern, "{") != 1 {
+		return modTime
+	}
+
+	prefix, layout, suffix := w.pattern[:start], w.pattern[start+1:end], w.pattern[end+1:]
+	if len(name) < len(prefix)+len(suffix) || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, suffix) {
+		return modTime
+	}
+	t, err := time.ParseInLocation(layout, name[len(prefix):len(name)-len(suffix)], w.location)
+	if err != nil {
+		return modTime
+	}
+	return t
+}
+
+func isCounter(s string) bool {
+	if s == "" {
+		return false
+	}
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
+
+// compress gzips the backup at path, keeping its mode and modification time
+// which orders the backups, and removes it.
+func compress(path string, modTime time.Time) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	dst, err := os.OpenFile(path+compressSuffix, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			dst.Close()
+			os.Remove(path + compressSuffix)
+		}
+	}()
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err != nil {
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	if err = os.Chtimes(path+compressSuffix, modTime, modTime); err != nil {
+		return fmt.Errorf("rotate: keeping the time of %s: %w", path, err)
+	}
+	return os.Remove(path)
+}
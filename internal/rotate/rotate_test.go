@@ -0,0 +1,335 @@
+package rotate
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+type RotateSuite struct {
+	suite.Suite
+	dir string
+	at  time.Time
+}
+
+func TestRotateSuite(t *testing.T) {
+	suite.Run(t, new(RotateSuite))
+}
+
+func (s *RotateSuite) SetupTest() {
+	s.dir = s.T().TempDir()
+	s.at = time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	now = func() time.Time { return s.at }
+}
+
+func (s *RotateSuite) TearDownTest() {
+	now = time.Now
+}
+
+func (s *RotateSuite) filename() string {
+	return filepath.Join(s.dir, "app.log")
+}
+
+// files returns the names of the files of the directory.
+func (s *RotateSuite) files() []string {
+	entries, err := os.ReadDir(s.dir)
+	s.Require().NoError(err)
+
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *RotateSuite) read(name string) string {
+	data, err := os.ReadFile(filepath.Join(s.dir, name))
+	s.Require().NoError(err)
+	return string(data)
+}
+
+func (s *RotateSuite) write(w *Writer, data string) {
+	_, err := w.Write([]byte(data))
+	s.Require().NoError(err)
+}
+
+// backup creates a backup written at t.
+func (s *RotateSuite) backup(name, data string, t time.Time) {
+	path := filepath.Join(s.dir, name)
+	s.Require().NoError(os.WriteFile(path, []byte(data), 0o600))
+	s.Require().NoError(os.Chtimes(path, t, t))
+}
+
+func (s *RotateSuite) TestSize() {
+	w := New(Options{Filename: s.filename(), MaxSize: 1})
+
+	half := strings.Repeat("a", megabyte/2+1)
+	s.write(w, half)
+	s.write(w, "b")
+	s.write(w, half)
+	s.Require().NoError(w.Close())
+
+	s.Assert().Equal([]string{"app-2021-01-02T03-04-05.000.log", "app.log"}, s.files())
+	s.Assert().Equal(half+"b", s.read("app-2021-01-02T03-04-05.000.log"))
+	s.Assert().Equal(half, s.read("app.log"))
+}
+
+func (s *RotateSuite) TestDaily() {
+	w := New(Options{Filename: s.filename(), Rotation: RotationDaily})
+
+	s.write(w, "a")
+	s.at = s.at.Add(21 * time.Hour)
+	s.write(w, "b")
+	s.at = s.at.Add(time.Hour)
+	s.write(w, "c")
+	s.at = s.at.Add(48 * time.Hour)
+	s.write(w, "d")
+	s.Require().NoError(w.Close())
+
+	s.Assert().Equal([]string{"app-2021-01-02.log", "app-2021-01-03.log", "app.log"}, s.files())
+	s.Assert().Equal("a", s.read("app-2021-01-02.log"))
+	s.Assert().Equal("bc", s.read("app-2021-01-03.log"))
+	s.Assert().Equal("d", s.read("app.log"))
+}
+
+func (s *RotateSuite) TestHourly() {
+	w := New(Options{Filename: s.filename(), Rotation: RotationHourly, Pattern: "audit.{20060102.15}.log"})
+
+	s.write(w, "a")
+	s.at = s.at.Add(time.Hour)
+	s.write(w, "b")
+	s.Require().NoError(w.Close())
+
+	s.Assert().Equal([]string{"app.log", "audit.20210102.03.log"}, s.files())
+	s.Assert().Equal("a", s.read("audit.20210102.03.log"))
+}
+
+func (s *RotateSuite) TestLocalTime() {
+	local := time.Local
+	time.Local = time.FixedZone("BRT", -3*60*60)
+	defer func() { time.Local = local }()
+
+	tt := []struct {
+		name      string
+		localTime bool
+		want      string
+	}{
+		{name: "utc", localTime: false, want: "app-2021-01-02.log"},
+		{name: "local", localTime: true, want: "app-2021-01-01.log"},
+	}
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			s.SetupTest()
+			// the day before in local time
+			s.at = time.Date(2021, 1, 2, 1, 0, 0, 0, time.UTC)
+			w := New(Options{Filename: s.filename(), Rotation: RotationDaily, LocalTime: t.localTime})
+
+			s.write(w, "a")
+			s.at = s.at.Add(24 * time.Hour)
+			s.write(w, "b")
+			s.Require().NoError(w.Close())
+
+			s.Assert().Equal([]string{t.want, "app.log"}, s.files())
+		})
+	}
+}
+
+func (s *RotateSuite) TestCounter() {
+	w := New(Options{Filename: s.filename(), Rotation: RotationDaily})
+
+	s.write(w, "a")
+	s.Require().NoError(w.Rotate())
+	s.write(w, "b")
+	s.Require().NoError(w.Rotate())
+	s.write(w, "c")
+	s.Require().NoError(w.Close())
+
+	s.Assert().Equal([]string{"app-2021-01-02.log", "app-2021-01-02.log.1", "app.log"}, s.files())
+	s.Assert().Equal("b", s.read("app-2021-01-02.log.1"))
+}
+
+func (s *RotateSuite) TestStartup() {
+	tt := []struct {
+		name    string
+		options Options
+		written time.Time
+		want    []string
+	}{
+		{
+			name:    "same period",
+			options: Options{Rotation: RotationDaily},
+			written: s.at.Add(-time.Hour),
+			want:    []string{"app.log"},
+		},
+		{
+			name:    "past period",
+			options: Options{Rotation: RotationDaily},
+			written: s.at.Add(-24 * time.Hour),
+			want:    []string{"app-2021-01-01.log", "app.log"},
+		},
+		{
+			name:    "rotate on startup",
+			options: Options{RotateOnStartup: true},
+			written: s.at.Add(-time.Hour),
+			want:    []string{"app-2021-01-02T03-04-05.000.log", "app.log"},
+		},
+	}
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			s.SetupTest()
+			s.backup("app.log", "old\n", t.written)
+
+			t.options.Filename = s.filename()
+			w := New(t.options)
+			s.write(w, "new\n")
+			s.Require().NoError(w.Close())
+
+			s.Assert().Equal(t.want, s.files())
+			s.Assert().True(strings.HasSuffix(s.read("app.log"), "new\n"))
+		})
+	}
+}
+
+func (s *RotateSuite) TestRetention() {
+	tt := []struct {
+		name    string
+		options Options
+		want    []string
+	}{
+		{
+			name:    "max backups",
+			options: Options{MaxBackups: 2},
+			want:    []string{"app-2021-01-01T00-00-00.000.log", "app-2021-01-02T03-04-05.000.log", "app.log", "other.log"},
+		},
+		{
+			name:    "max age",
+			options: Options{MaxAge: 7},
+			want:    []string{"app-2020-12-30T00-00-00.000.log", "app-2021-01-01T00-00-00.000.log", "app-2021-01-02T03-04-05.000.log", "app.log", "other.log"},
+		},
+		{
+			name:    "max total size",
+			options: Options{MaxTotalSize: 1},
+			want:    []string{"app-2021-01-01T00-00-00.000.log", "app-2021-01-02T03-04-05.000.log", "app.log", "other.log"},
+		},
+	}
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			s.SetupTest()
+			big := strings.Repeat("a", megabyte/3)
+			s.backup("app-2020-12-01T00-00-00.000.log", big, time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC))
+			s.backup("app-2020-12-30T00-00-00.000.log", big, time.Date(2020, 12, 30, 0, 0, 0, 0, time.UTC))
+			s.backup("app-2021-01-01T00-00-00.000.log", big, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+			s.backup("other.log", big, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+
+			t.options.Filename = s.filename()
+			w := New(t.options)
+			s.write(w, big)
+			s.Require().NoError(w.Rotate())
+			s.write(w, "bb")
+			s.Require().NoError(w.Close())
+
+			s.Assert().Equal(t.want, s.files())
+		})
+	}
+}
+
+func (s *RotateSuite) TestCompress() {
+	w := New(Options{Filename: s.filename(), Rotation: RotationDaily, Compress: true})
+
+	s.write(w, "a")
+	s.at = s.at.Add(24 * time.Hour)
+	s.write(w, "b")
+	s.Require().NoError(w.Close())
+
+	s.Assert().Equal([]string{"app-2021-01-02.log.gz", "app.log"}, s.files())
+
+	f, err := os.Open(filepath.Join(s.dir, "app-2021-01-02.log.gz"))
+	s.Require().NoError(err)
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	s.Require().NoError(err)
+	data, err := io.ReadAll(gz)
+	s.Require().NoError(err)
+	s.Assert().Equal("a", string(data))
+
+	// the compressed backup keeps its place among the backups
+	w = New(Options{Filename: s.filename(), Rotation: RotationDaily, Compress: true})
+	s.Require().NoError(w.Rotate())
+	s.Require().NoError(w.Close())
+	s.Assert().Equal([]string{"app-2021-01-02.log.gz", "app-2021-01-03.log.gz", "app.log"}, s.files())
+}
+
+func (s *RotateSuite) TestLumberjackBackups() {
+	// lumberjack names its backups with the current time
+	now = time.Now
+	lj := &lumberjack.Logger{Filename: s.filename()}
+	for _, data := range []string{"a", "b", "c"} {
+		_, err := lj.Write([]byte(data))
+		s.Require().NoError(err)
+		s.Require().NoError(lj.Rotate())
+		time.Sleep(2 * time.Millisecond)
+	}
+	_, err := lj.Write([]byte("d"))
+	s.Require().NoError(err)
+	s.Require().NoError(lj.Close())
+
+	// the modification times of the backups compressed by lumberjack are not
+	// the ones of their rotation, the names order them
+	lumberjackBackups := s.files()[:3]
+	for i, name := range lumberjackBackups {
+		t := time.Now().Add(-time.Duration(i) * time.Hour)
+		s.Require().NoError(os.Chtimes(filepath.Join(s.dir, name), t, t))
+	}
+
+	w := New(Options{Filename: s.filename(), MaxBackups: 2})
+	s.write(w, "e")
+	s.Require().NoError(w.Rotate())
+	s.Require().NoError(w.Close())
+
+	files := s.files()
+	s.Require().Len(files, 3)
+	s.Assert().Equal(lumberjackBackups[2], files[0])
+	s.Assert().Equal("c", s.read(files[0]))
+	s.Assert().Regexp(`^app-\d{4}-\d{2}-\d{2}T\d{2}-\d{2}-\d{2}\.\d{3}\.log$`, files[1])
+	s.Assert().Equal("de", s.read(files[1]))
+	s.Assert().Equal("app.log", files[2])
+}
+
+func (s *RotateSuite) TestCreatesDirectory() {
+	w := New(Options{Filename: filepath.Join(s.dir, "logs", "app.log")})
+	s.write(w, "a")
+	s.Require().NoError(w.Close())
+
+	s.Assert().Equal("a", s.read(filepath.Join("logs", "app.log")))
+}
+
+func (s *RotateSuite) TestShared() {
+	w := New(Options{Filename: s.filename(), RotateOnStartup: true})
+	s.write(w, "a")
+	s.Require().NoError(w.Close())
+
+	// a logger replacing the closed one reopens the file without rotating it
+	s.Require().Same(w, New(Options{Filename: filepath.Join(s.dir, ".", "app.log"), RotateOnStartup: true}))
+	s.write(w, "b")
+	s.Assert().Equal([]string{"app.log"}, s.files())
+
+	// the shared writer takes the new options
+	s.Require().Same(w, New(Options{Filename: s.filename(), Rotation: RotationDaily}))
+	s.write(w, "c")
+	s.at = s.at.Add(24 * time.Hour)
+	s.write(w, "d")
+	s.Require().NoError(w.Close())
+
+	s.Assert().Equal([]string{"app-2021-01-02.log", "app.log"}, s.files())
+	s.Assert().Equal("abc", s.read("app-2021-01-02.log"))
+	s.Assert().Equal("d", s.read("app.log"))
+}
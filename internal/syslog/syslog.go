@@ -0,0 +1,473 @@
+// Package syslog writes entries to a syslog daemon as RFC 5424 or RFC 3164
+// messages, over unixgram, unix, UDP or TCP.
+//
+// The fields of the entries are written as the structured data of RFC 5424
+// messages and appended as key=value pairs to the text of RFC 3164 ones.
+// Messages sent over tcp are framed with octet counting, as described by RFC
+// 6587, and the ones sent over unix stream sockets are terminated by a NUL
+// byte, as by the syslog function of the C library.
+//
+// Writes never block the logger: the messages are queued in a bounded buffer
+// and sent by a background goroutine, which connects on the first message and
+// reconnects once when a write fails. The messages which can not be sent are
+// dropped, as are the ones queued until the next reconnection, delayed by a
+// backoff doubling up to 30 seconds while the daemon can not be reached. The
+// drops are reported by the next WriteEntry, Flush or Close.
+package syslog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/americanas-go/log/internal/order"
+)
+
+// Message formats.
+const (
+	FormatRFC5424 = "RFC5424"
+	FormatRFC3164 = "RFC3164"
+)
+
+const (
+	// structuredDataID is the SD-ID of the fields, using the enterprise number
+	// reserved for documentation by RFC 5612.
+	structuredDataID = "fields@32473"
+
+	nilValue = "-"
+
+	rfc5424Time = "2006-01-02T15:04:05.000000Z07:00"
+	rfc3164Time = time.Stamp
+
+	dialTimeout  = 5 * time.Second
+	writeTimeout = 5 * time.Second
+	closeTimeout = 5 * time.Second
+
+	minBackoff = 100 * time.Millisecond
+	maxBackoff = 30 * time.Second
+
+	// bufferSize is the number of messages waiting to be sent.
+	bufferSize = 1024
+)
+
+var (
+	// Formats are the supported message formats.
+	Formats = []string{FormatRFC5424, FormatRFC3164}
+
+	// Networks are the supported networks.
+	Networks = []string{"udp", "tcp", "unixgram", "unix"}
+
+	// Facilities are the names of the syslog facilities, ordered by code.
+	Facilities = []string{
+		"KERN", "USER", "MAIL", "DAEMON", "AUTH", "SYSLOG", "LPR", "NEWS",
+		"UUCP", "CRON", "AUTHPRIV", "FTP", "NTP", "SECURITY", "CONSOLE", "SOLARISCRON",
+		"LOCAL0", "LOCAL1", "LOCAL2", "LOCAL3", "LOCAL4", "LOCAL5", "LOCAL6", "LOCAL7",
+	}
+
+	errBufferFull = errors.New("syslog: buffer is full, entry dropped")
+	errClosed     = errors.New("syslog: writer is closed")
+)
+
+// Options configures a Writer. Empty values take a default: RFC5424, the USER
+// facility, the base name of the executable, the host name and the process id.
+type Options struct {
+	Network  string // udp/tcp/unixgram/unix
+	Address  string // host:port or socket path
+	Format   string // RFC5424/RFC3164
+	Facility string // one of Facilities
+	AppName  string // application name, or tag of RFC 3164 messages
+	Hostname string // host name
+	ProcID   string // process id
+
+	PriorityKeys []string // fields written first, in this order, the others being sorted by key
+}
+
+// Writer sends entries to a syslog daemon from a background goroutine. It
+// connects on the first message and reconnects once when a write fails, e.g.
+// after the daemon was restarted, then backs off while the daemon can not be
+// reached.
+type Writer struct {
+	dial func(network, address string, timeout time.Duration) (net.Conn, error)
+
+	// only used by run
+	conn    net.Conn
+	backoff time.Duration // delay before the next reconnection
+	retryAt time.Time     // time of the next reconnection
+
+	mu      sync.Mutex
+	dropped int   // number of messages dropped since the last report
+	dropErr error // error of the last dropped message
+
+	messages chan []byte
+	flushes  chan chan struct{}
+	done     chan struct{}
+	stopped  chan struct{}
+	closed   sync.Once
+
+	network  string
+	address  string
+	format   string
+	facility int
+	hostname string
+	appName  string
+	procID   string
+	priority []string
+}
+
+// New returns a Writer from options and starts sending. Unknown formats and
+// facilities fall back to the defaults.
+func New(options Options) *Writer {
+	w := &Writer{
+		dial:     net.DialTimeout,
+		messages: make(chan []byte, bufferSize),
+		flushes:  make(chan chan struct{}),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+
+		network:  options.Network,
+		address:  options.Address,
+		format:   options.Format,
+		facility: facility(options.Facility),
+		hostname: options.Hostname,
+		appName:  options.AppName,
+		procID:   options.ProcID,
+		priority: options.PriorityKeys,
+	}
+
+	if w.format != FormatRFC3164 {
+		w.format = FormatRFC5424
+	}
+	if w.hostname == "" {
+		w.hostname, _ = os.Hostname()
+	}
+	if w.appName == "" {
+		w.appName = filepath.Base(os.Args[0])
+	}
+	if w.procID == "" {
+		w.procID = strconv.Itoa(os.Getpid())
+	}
+
+	go w.run()
+	return w
+}
+
+func facility(name string) int {
+	for code, n := range Facilities {
+		if strings.EqualFold(n, name) {
+			return code
+		}
+	}
+	return 1 // USER
+}
+
+// Severity maps the level to a syslog severity.
+func Severity(level log.Level) int {
+	switch level {
+	case log.TraceLevel, log.DebugLevel:
+		return 7 // debug
+	case log.InfoLevel:
+		return 6 // informational
+	case log.WarnLevel:
+		return 4 // warning
+	case log.ErrorLevel:
+		return 3 // error
+	case log.PanicLevel:
+		return 2 // critical
+	default:
+		return 1 // alert
+	}
+}
+
+// WriteEntry implements entry.Writer. It queues e and fails when the buffer is
+// full or when queued messages were dropped since the last report.
+func (w *Writer) WriteEntry(e entry.Entry) error {
+	select {
+	case <-w.done:
+		return errClosed
+	default:
+	}
+
+	select {
+	case w.messages <- w.encode(e):
+		return w.drops()
+	default:
+		return errBufferFull
+	}
+}
+
+// Flush waits, for up to 5 seconds, for the queued entries to be sent.
+func (w *Writer) Flush() error {
+	flushed := make(chan struct{})
+	timeout := time.After(closeTimeout)
+
+	select {
+	case w.flushes <- flushed:
+	case <-w.stopped:
+		return nil
+	case <-timeout:
+		return errors.New("syslog: timed out sending the queued entries")
+	}
+
+	select {
+	case <-flushed:
+		return w.drops()
+	case <-w.stopped:
+		return w.drops()
+	case <-timeout:
+		return errors.New("syslog: timed out sending the queued entries")
+	}
+}
+
+// Close sends the queued entries and closes the connection to the daemon, if
+// any. Later writes fail.
+func (w *Writer) Close() error {
+	w.closed.Do(func() { close(w.done) })
+
+	select {
+	case <-w.stopped:
+		return w.drops()
+	case <-time.After(closeTimeout):
+		return errors.New("syslog: timed out sending the queued entries")
+	}
+}
+
+func (w *Writer) run() {
+	defer close(w.stopped)
+	defer w.close()
+
+	for {
+		select {
+		case <-w.done:
+			for len(w.messages) > 0 {
+				w.send(<-w.messages)
+			}
+			return
+		case msg := <-w.messages:
+			w.send(msg)
+		case flushed := <-w.flushes:
+			for len(w.messages) > 0 {
+				w.send(<-w.messages)
+			}
+			close(flushed)
+		}
+	}
+}
+
+// send writes msg, reconnecting once when the write on the connection fails.
+// When the daemon can not be reached, msg is dropped and so are the next
+// messages, without dialing, until the backoff elapses.
+func (w *Writer) send(msg []byte) {
+	if w.conn == nil && time.Now().Before(w.retryAt) {
+		w.drop(errors.New("syslog: daemon is unreachable, waiting to reconnect"))
+		return
+	}
+
+	connected := w.conn != nil
+	err := w.write(msg)
+	if err != nil && connected {
+		w.close()
+		err = w.write(msg)
+	}
+	if err != nil {
+		w.close()
+		w.backoff = min(max(2*w.backoff, minBackoff), maxBackoff)
+		w.retryAt = time.Now().Add(w.backoff)
+		w.drop(err)
+		return
+	}
+	w.backoff = 0
+}
+
+// drop counts a dropped message, to be reported by drops.
+func (w *Writer) drop(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.dropped++
+	w.dropErr = err
+}
+
+// drops returns an error reporting the messages dropped since the last call,
+// if any.
+func (w *Writer) drops() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.dropped == 0 {
+		return nil
+	}
+	err := fmt.Errorf("syslog: %d entries dropped: %w", w.dropped, w.dropErr)
+	w.dropped, w.dropErr = 0, nil
+	return err
+}
+
+func (w *Writer) write(msg []byte) error {
+	if w.conn == nil {
+		conn, err := w.dial(w.network, w.address, dialTimeout)
+		if err != nil {
+			return err
+		}
+		w.conn = conn
+	}
+
+	switch w.network {
+	case "tcp":
+		msg = append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+	case "unix":
+		msg = append(msg, 0)
+	}
+
+	if err := w.conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+		return err
+	}
+	_, err := w.conn.Write(msg)
+	return err
+}
+
+func (w *Writer) close() {
+	if w.conn != nil {
+		w.conn.Close()
+		w.conn = nil
+	}
+}
+
+func (w *Writer) encode(e entry.Entry) []byte {
+	if w.format == FormatRFC3164 {
+		return w.encodeRFC3164(e)
+	}
+	return w.encodeRFC5424(e)
+}
+
+// encodeRFC5424 encodes e as
+// <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG.
+func (w *Writer) encodeRFC5424(e entry.Entry) []byte {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "<%d>1 ", w.facility*8+Severity(e.Level))
+
+	if e.Time.IsZero() {
+		buf.WriteString(nilValue)
+	} else {
+		buf.WriteString(e.Time.Format(rfc5424Time))
+	}
+
+	buf.WriteByte(' ')
+	buf.WriteString(header(w.hostname, 255))
+	buf.WriteByte(' ')
+	buf.WriteString(header(w.appName, 48))
+	buf.WriteByte(' ')
+	buf.WriteString(header(w.procID, 128))
+	buf.WriteString(" - ")
+
+	keys, values := pairs(e, w.priority)
+	if len(keys) == 0 {
+		buf.WriteString(nilValue)
+	} else {
+		buf.WriteString("[" + structuredDataID)
+		for i, k := range keys {
+			buf.WriteString(" " + paramName(k) + `="` + paramValue(values[i]) + `"`)
+		}
+		buf.WriteString("]")
+	}
+
+	if e.Message != "" {
+		buf.WriteString(" " + e.Message)
+	}
+
+	return buf.Bytes()
+}
+
+// encodeRFC3164 encodes e as <PRI>TIMESTAMP HOSTNAME TAG[PID]: MSG, followed by
+// the fields as key=value pairs.
+func (w *Writer) encodeRFC3164(e entry.Entry) []byte {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "<%d>", w.facility*8+Severity(e.Level))
+
+	t := e.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	buf.WriteString(t.Format(rfc3164Time))
+
+	buf.WriteByte(' ')
+	buf.WriteString(header(w.hostname, 255))
+	buf.WriteByte(' ')
+	buf.WriteString(header(w.appName, 32))
+	buf.WriteString("[" + header(w.procID, 128) + "]: ")
+	buf.WriteString(e.Message)
+
+	keys, values := pairs(e, w.priority)
+	for i, k := range keys {
+		v := values[i]
+		if strings.ContainsAny(v, " =\"") || v == "" {
+			v = strconv.Quote(v)
+		}
+		buf.WriteString(" " + k + "=" + v)
+	}
+
+	return buf.Bytes()
+}
+
+// pairs returns the fields of e, with its caller, the priority ones first and
+// the others sorted by key.
+func pairs(e entry.Entry, priority []string) (keys []string, values []string) {
+	fields := make(map[string]string, len(e.Fields)+1)
+	for k, v := range e.Fields {
+		fields[k] = fmt.Sprint(v)
+	}
+	if e.Caller != "" {
+		fields["caller"] = e.Caller
+	}
+
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	order.Sort(keys, priority)
+
+	for _, k := range keys {
+		values = append(values, fields[k])
+	}
+	return keys, values
+}
+
+// header returns value as at most limit printable US-ASCII characters, the nil
+// value when empty.
+func header(value string, limit int) string {
+	if value == "" {
+		return nilValue
+	}
+
+	b := []byte(value)
+	for i, c := range b {
+		if c < 33 || c > 126 {
+			b[i] = '_'
+		}
+	}
+	if len(b) > limit {
+		b = b[:limit]
+	}
+	return string(b)
+}
+
+// paramName returns key as an SD-NAME: at most 32 printable US-ASCII
+// characters, other than '=', ' ', ']' and '"'.
+func paramName(key string) string {
+	b := []byte(header(key, 32))
+	for i, c := range b {
+		if c == '=' || c == ']' || c == '"' {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+// paramValue escapes '"', '\' and ']' in value, as required by RFC 5424.
+func paramValue(value string) string {
+	return strings.NewReplacer(`"`, `\"`, `\`, `\\`, `]`, `\]`).Replace(value)
+}
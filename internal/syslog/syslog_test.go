@@ -0,0 +1,274 @@
+package syslog
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/americanas-go/log"
+	"github.com/americanas-go/log/internal/entry"
+	"github.com/stretchr/testify/suite"
+)
+
+type SyslogSuite struct {
+	suite.Suite
+}
+
+func TestSyslogSuite(t *testing.T) {
+	suite.Run(t, new(SyslogSuite))
+}
+
+var at = time.Date(2021, 1, 2, 3, 4, 5, 123456789, time.UTC)
+
+func (s *SyslogSuite) TestEncode() {
+	tt := []struct {
+		name    string
+		options Options
+		entry   entry.Entry
+		want    string
+	}{
+		{
+			name:    "RFC5424 without fields",
+			options: Options{Facility: "LOCAL0"},
+			entry:   entry.Entry{Time: at, Level: log.InfoLevel, Message: "hello"},
+			want:    "<134>1 2021-01-02T03:04:05.123456Z host app 42 - - hello",
+		},
+		{
+			name:    "RFC5424 with fields and caller",
+			options: Options{},
+			entry: entry.Entry{
+				Time:    at,
+				Level:   log.ErrorLevel,
+				Message: "failed",
+				Caller:  "main.go:10",
+				Fields:  log.Fields{"ID": 1, "err": `bad "quote" ] \ `, "with space": true},
+			},
+			want: `<11>1 2021-01-02T03:04:05.123456Z host app 42 - [fields@32473 ID="1" caller="main.go:10" err="bad \"quote\" \] \\ " with_space="true"] failed`,
+		},
+		{
+			name:    "RFC5424 without time",
+			options: Options{Facility: "daemon"},
+			entry:   entry.Entry{Level: log.WarnLevel},
+			want:    "<28>1 - host app 42 - -",
+		},
+		{
+			name:    "RFC3164",
+			options: Options{Format: FormatRFC3164, Facility: "LOCAL7"},
+			entry:   entry.Entry{Time: at, Level: log.DebugLevel, Message: "hello", Fields: log.Fields{"ID": 1, "name": "a b"}},
+			want:    `<191>Jan  2 03:04:05 host app[42]: hello ID=1 name="a b"`,
+		},
+		{
+			name:    "RFC5424 with priority keys",
+			options: Options{PriorityKeys: []string{"c", "b"}},
+			entry:   entry.Entry{Time: at, Level: log.InfoLevel, Message: "hello", Fields: log.Fields{"a": 1, "b": 2, "c": 3}},
+			want:    `<14>1 2021-01-02T03:04:05.123456Z host app 42 - [fields@32473 c="3" b="2" a="1"] hello`,
+		},
+		{
+			name:    "RFC3164 with priority keys",
+			options: Options{Format: FormatRFC3164, PriorityKeys: []string{"c", "b"}},
+			entry:   entry.Entry{Time: at, Level: log.InfoLevel, Message: "hello", Fields: log.Fields{"a": 1, "b": 2, "c": 3}},
+			want:    `<14>Jan  2 03:04:05 host app[42]: hello c=3 b=2 a=1`,
+		},
+		{
+			name:    "unknown facility",
+			options: Options{Facility: "PRINTER"},
+			entry:   entry.Entry{Time: at, Level: log.FatalLevel, Message: "bye"},
+			want:    "<9>1 2021-01-02T03:04:05.123456Z host app 42 - - bye",
+		},
+	}
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			t.options.Hostname = "host"
+			t.options.AppName = "app"
+			t.options.ProcID = "42"
+
+			got := New(t.options).encode(t.entry)
+			s.Assert().Equal(t.want, string(got))
+		})
+	}
+}
+
+func (s *SyslogSuite) TestNewDefaults() {
+	w := New(Options{})
+	s.Assert().Equal(FormatRFC5424, w.format)
+	s.Assert().Equal(1, w.facility)
+	s.Assert().NotEmpty(w.hostname)
+	s.Assert().NotEmpty(w.appName)
+	s.Assert().NotEmpty(w.procID)
+}
+
+func (s *SyslogSuite) TestWriteEntryDatagram() {
+	tt := []struct {
+		name    string
+		network string
+		address func() string
+	}{
+		{name: "udp", network: "udp", address: func() string { return "127.0.0.1:0" }},
+		{name: "unixgram", network: "unixgram", address: func() string { return filepath.Join(s.T().TempDir(), "log.sock") }},
+	}
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			conn, err := net.ListenPacket(t.network, t.address())
+			s.Require().NoError(err)
+			defer conn.Close()
+
+			w := New(Options{Network: t.network, Address: conn.LocalAddr().String(), Hostname: "host", AppName: "app", ProcID: "42"})
+			defer w.Close()
+
+			s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Level: log.InfoLevel, Message: "hello"}))
+
+			buf := make([]byte, 1024)
+			s.Require().NoError(conn.SetReadDeadline(time.Now().Add(5 * time.Second)))
+			n, _, err := conn.ReadFrom(buf)
+			s.Require().NoError(err)
+			s.Assert().Equal("<14>1 2021-01-02T03:04:05.123456Z host app 42 - - hello", string(buf[:n]))
+		})
+	}
+}
+
+func (s *SyslogSuite) TestWriteEntryStreamReconnects() {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+	defer ln.Close()
+
+	conns := make(chan net.Conn, 2)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conns <- conn
+		}
+	}()
+
+	w := New(Options{Network: "tcp", Address: ln.Addr().String(), Hostname: "host", AppName: "app", ProcID: "42"})
+	defer w.Close()
+
+	e := entry.Entry{Time: at, Level: log.InfoLevel, Message: "hello"}
+	want := "<14>1 2021-01-02T03:04:05.123456Z host app 42 - - hello"
+
+	s.Require().NoError(w.WriteEntry(e))
+	first := <-conns
+	s.Assert().Equal(want, s.readFrame(bufio.NewReader(first)))
+
+	// the daemon restarts, the writes fail until the writer reconnects
+	s.Require().NoError(first.Close())
+	var second net.Conn
+	for second == nil {
+		_ = w.WriteEntry(e)
+		select {
+		case second = <-conns:
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	defer second.Close()
+
+	s.Assert().Equal(want, s.readFrame(bufio.NewReader(second)))
+}
+
+func (s *SyslogSuite) TestWriteEntryUnixStream() {
+	ln, err := net.Listen("unix", filepath.Join(s.T().TempDir(), "log.sock"))
+	s.Require().NoError(err)
+	defer ln.Close()
+
+	w := New(Options{Network: "unix", Address: ln.Addr().String(), Hostname: "host", AppName: "app", ProcID: "42"})
+	defer w.Close()
+
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Level: log.InfoLevel, Message: "hello\nworld"}))
+
+	conn, err := ln.Accept()
+	s.Require().NoError(err)
+	defer conn.Close()
+	s.Require().NoError(conn.SetReadDeadline(time.Now().Add(5 * time.Second)))
+
+	// the messages are terminated by a NUL byte, so that they can span lines
+	msg, err := bufio.NewReader(conn).ReadString(0)
+	s.Require().NoError(err)
+	s.Assert().Equal("<14>1 2021-01-02T03:04:05.123456Z host app 42 - - hello\nworld\x00", msg)
+}
+
+func (s *SyslogSuite) TestCloseSendsQueuedEntries() {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	w := New(Options{Network: "udp", Address: conn.LocalAddr().String(), Hostname: "host", AppName: "app", ProcID: "42"})
+	for _, msg := range []string{"a", "b", "c"} {
+		s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Level: log.InfoLevel, Message: msg}))
+	}
+	s.Require().NoError(w.Close())
+
+	buf := make([]byte, 1024)
+	for _, msg := range []string{"a", "b", "c"} {
+		s.Require().NoError(conn.SetReadDeadline(time.Now().Add(5 * time.Second)))
+		n, _, err := conn.ReadFrom(buf)
+		s.Require().NoError(err)
+		s.Assert().Equal("<14>1 2021-01-02T03:04:05.123456Z host app 42 - - "+msg, string(buf[:n]))
+	}
+
+	s.Assert().Error(w.WriteEntry(entry.Entry{Time: at, Level: log.InfoLevel, Message: "d"}))
+}
+
+func (s *SyslogSuite) TestSendBacksOffAndReportsDrops() {
+	dials := 0
+	w := &Writer{
+		network: "tcp",
+		address: "127.0.0.1:1",
+		dial: func(network, address string, timeout time.Duration) (net.Conn, error) {
+			dials++
+			return nil, errors.New("connection refused")
+		},
+	}
+
+	// the queued messages are dropped without dialing until the backoff elapses
+	for i := 0; i < 3; i++ {
+		w.send([]byte("a"))
+	}
+	s.Assert().Equal(1, dials)
+	s.Assert().Equal(minBackoff, w.backoff)
+
+	err := w.drops()
+	s.Require().Error(err)
+	s.Assert().Contains(err.Error(), "syslog: 3 entries dropped")
+	s.Assert().NoError(w.drops())
+
+	// each failed reconnection doubles the backoff
+	w.retryAt = time.Time{}
+	w.send([]byte("a"))
+	s.Assert().Equal(2, dials)
+	s.Assert().Equal(2*minBackoff, w.backoff)
+	s.Assert().ErrorContains(w.drops(), "connection refused")
+}
+
+func (s *SyslogSuite) TestFlushReportsDrops() {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+	address := ln.Addr().String()
+	s.Require().NoError(ln.Close())
+
+	w := New(Options{Network: "tcp", Address: address})
+	defer w.Close()
+
+	s.Require().NoError(w.WriteEntry(entry.Entry{Time: at, Level: log.InfoLevel, Message: "a"}))
+	s.Assert().ErrorContains(w.Flush(), "syslog: 1 entries dropped")
+}
+
+// readFrame reads an octet counted message.
+func (s *SyslogSuite) readFrame(r *bufio.Reader) string {
+	length, err := r.ReadString(' ')
+	s.Require().NoError(err)
+	n, err := strconv.Atoi(strings.TrimSpace(length))
+	s.Require().NoError(err)
+
+	buf := make([]byte, n)
+	_, err = io.ReadFull(r, buf)
+	s.Require().NoError(err)
+	return string(buf)
+}
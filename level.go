@@ -0,0 +1,66 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Level represents a logging level, ordered from the most to the least verbose.
+type Level int8
+
+const (
+	TraceLevel Level = iota
+	DebugLevel
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+	PanicLevel
+	FatalLevel
+)
+
+var levelNames = map[Level]string{
+	TraceLevel: "TRACE",
+	DebugLevel: "DEBUG",
+	InfoLevel:  "INFO",
+	WarnLevel:  "WARN",
+	ErrorLevel: "ERROR",
+	PanicLevel: "PANIC",
+	FatalLevel: "FATAL",
+}
+
+// ParseLevel returns the Level named by value. The name is case insensitive.
+func ParseLevel(value string) (Level, error) {
+	name := strings.ToUpper(value)
+	for level, n := range levelNames {
+		if n == name {
+			return level, nil
+		}
+	}
+
+	return InfoLevel, fmt.Errorf("unknown log level %q", value)
+}
+
+// String returns the upper case name of the level.
+func (l Level) String() string {
+	if name, ok := levelNames[l]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("LEVEL(%d)", l)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (l *Level) UnmarshalText(text []byte) error {
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+
+	*l = level
+	return nil
+}
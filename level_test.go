@@ -0,0 +1,61 @@
+package log
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type LevelSuite struct {
+	suite.Suite
+}
+
+func TestLevelSuite(t *testing.T) {
+	suite.Run(t, new(LevelSuite))
+}
+
+func (s *LevelSuite) TestParseLevel() {
+
+	tt := []struct {
+		name    string
+		in      string
+		want    Level
+		wantErr bool
+	}{
+		{name: "trace", in: "TRACE", want: TraceLevel},
+		{name: "debug", in: "DEBUG", want: DebugLevel},
+		{name: "info", in: "INFO", want: InfoLevel},
+		{name: "warn", in: "WARN", want: WarnLevel},
+		{name: "error", in: "ERROR", want: ErrorLevel},
+		{name: "panic", in: "PANIC", want: PanicLevel},
+		{name: "fatal", in: "FATAL", want: FatalLevel},
+		{name: "lower case", in: "debug", want: DebugLevel},
+		{name: "unknown", in: "VERBOSE", want: InfoLevel, wantErr: true},
+		{name: "empty", in: "", want: InfoLevel, wantErr: true},
+	}
+
+	for _, t := range tt {
+		s.Run(t.name, func() {
+			got, err := ParseLevel(t.in)
+			s.Assert().Equal(t.wantErr, err != nil, "unexpected error = %v", err)
+			s.Assert().Equal(t.want, got)
+		})
+	}
+}
+
+func (s *LevelSuite) TestLevelText() {
+	for level, name := range levelNames {
+		text, err := level.MarshalText()
+		s.Require().NoError(err)
+		s.Assert().Equal(name, string(text))
+
+		var got Level
+		s.Require().NoError(got.UnmarshalText(text))
+		s.Assert().Equal(level, got)
+	}
+
+	s.Assert().Equal("LEVEL(42)", Level(42).String())
+
+	var l Level
+	s.Assert().Error(l.UnmarshalText([]byte("LOUD")))
+}
@@ -56,3 +56,16 @@ type Logger interface {
 
 	Fields() Fields
 }
+
+// Closer is implemented by the loggers whose outputs buffer entries or hold
+// resources, such as files and connections, which the contrib loggers do.
+// The loggers derived from a logger, such as those returned by WithField,
+// share its outputs.
+type Closer interface {
+	// Sync sends the entries buffered by the outputs and waits for them to be
+	// sent.
+	Sync() error
+
+	// Close syncs the logger then closes its outputs.
+	Close() error
+}
@@ -0,0 +1,77 @@
+package log
+
+import "context"
+
+// Redacted replaces the values of the redacted fields.
+const Redacted = "[REDACTED]"
+
+// redactLogger is a Logger writing Redacted in place of the values of the
+// fields of keys added by WithField and WithFields.
+type redactLogger struct {
+	Logger
+	keys map[string]bool
+}
+
+// newRedactLogger returns logger redacting the fields of keys, logger itself
+// when there is none.
+func newRedactLogger(logger Logger, keys []string) Logger {
+	if len(keys) == 0 {
+		return logger
+	}
+
+	l := &redactLogger{Logger: logger, keys: make(map[string]bool, len(keys))}
+	for _, k := range keys {
+		l.keys[k] = true
+	}
+	return l
+}
+
+func (l *redactLogger) wrap(logger Logger) Logger {
+	return &redactLogger{Logger: logger, keys: l.keys}
+}
+
+func (l *redactLogger) WithFields(keyValues map[string]interface{}) Logger {
+	fields := make(map[string]interface{}, len(keyValues))
+	for k, v := range keyValues {
+		if l.keys[k] {
+			v = Redacted
+		}
+		fields[k] = v
+	}
+	return l.wrap(l.Logger.WithFields(fields))
+}
+
+func (l *redactLogger) WithField(key string, value interface{}) Logger {
+	if l.keys[key] {
+		value = Redacted
+	}
+	return l.wrap(l.Logger.WithField(key, value))
+}
+
+func (l *redactLogger) WithError(err error) Logger {
+	return l.wrap(l.Logger.WithError(err))
+}
+
+func (l *redactLogger) WithTypeOf(obj interface{}) Logger {
+	return l.wrap(l.Logger.WithTypeOf(obj))
+}
+
+func (l *redactLogger) FromContext(ctx context.Context) Logger {
+	return l.wrap(l.Logger.FromContext(ctx))
+}
+
+// Sync syncs the logger, when it is a Closer.
+func (l *redactLogger) Sync() error {
+	if c, ok := l.Logger.(Closer); ok {
+		return c.Sync()
+	}
+	return nil
+}
+
+// Close closes the logger, when it is a Closer.
+func (l *redactLogger) Close() error {
+	if c, ok := l.Logger.(Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package log
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RedactSuite struct {
+	suite.Suite
+}
+
+func TestRedactSuite(t *testing.T) {
+	suite.Run(t, new(RedactSuite))
+}
+
+func (s *RedactSuite) SetupTest() {
+	factoriesMu.Lock()
+	factories = map[string]Factory{
+		"recorder": func(cfg *Config) (Logger, error) {
+			r := &recorder{cfg: cfg, errors: make(chan string, 10)}
+			SetGlobalLogger(r)
+			return r, nil
+		},
+	}
+	factoriesMu.Unlock()
+}
+
+func (s *RedactSuite) TearDownTest() {
+	SetGlobalLogger(Noop{})
+}
+
+func (s *RedactSuite) TestNew() {
+	cfg := DefaultConfig()
+	cfg.Backend = "recorder"
+	cfg.RedactedFields = []string{"password", "token"}
+
+	logger, err := New(cfg)
+	s.Require().NoError(err)
+	s.Assert().Same(logger, GetLogger())
+
+	s.Assert().Equal(Fields{"password": Redacted, "user": "john"}, logger.
+		WithField("password", "secret").
+		WithField("user", "john").
+		Fields())
+	s.Assert().Equal(Fields{"token": Redacted, "user": "john"}, logger.
+		WithFields(map[string]interface{}{"token": "abc", "user": "john"}).
+		Fields())
+}
+
+func (s *RedactSuite) TestNewWithoutRedactedFields() {
+	cfg := DefaultConfig()
+	cfg.Backend = "recorder"
+
+	logger, err := New(cfg)
+	s.Require().NoError(err)
+	s.Assert().IsType(&recorder{}, logger)
+	s.Assert().Equal(Fields{"password": "secret"}, logger.WithField("password", "secret").Fields())
+}
@@ -0,0 +1,73 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Factory constructs a Logger from a backend independent Config.
+type Factory func(cfg *Config) (Logger, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = make(map[string]Factory)
+)
+
+// Register makes a logging backend available by the provided name.
+// Contribs register themselves on init, so importing a contrib, even only for
+// its side effects, is enough to use it with New.
+// If Register is called twice with the same name or if factory is nil, it panics.
+func Register(name string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+
+	if factory == nil {
+		panic("log: Register factory is nil")
+	}
+	if _, dup := factories[name]; dup {
+		panic("log: Register called twice for backend " + name)
+	}
+	factories[name] = factory
+}
+
+// Backends returns a sorted list of the names of the registered backends.
+func Backends() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New constructs a Logger using the backend named by cfg.Backend, redacting
+// the values of the fields of cfg.RedactedFields added by WithField and
+// WithFields. As with the contrib constructors, the new logger becomes the
+// global logger. A nil cfg is an error, there being no default backend.
+func New(cfg *Config) (Logger, error) {
+	if cfg == nil {
+		return nil, errors.New("log: nil config")
+	}
+
+	factoriesMu.RLock()
+	factory, ok := factories[cfg.Backend]
+	factoriesMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("log: unknown backend %q (forgotten import?), registered backends: %v", cfg.Backend, Backends())
+	}
+
+	logger, err := factory(cfg)
+	if err != nil || len(cfg.RedactedFields) == 0 {
+		return logger, err
+	}
+
+	logger = newRedactLogger(logger, cfg.RedactedFields)
+	SetGlobalLogger(logger)
+	return logger, nil
+}
@@ -0,0 +1,61 @@
+package log
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RegistrySuite struct {
+	suite.Suite
+}
+
+func TestRegistrySuite(t *testing.T) {
+	suite.Run(t, new(RegistrySuite))
+}
+
+func (s *RegistrySuite) SetupTest() {
+	factoriesMu.Lock()
+	factories = make(map[string]Factory)
+	factoriesMu.Unlock()
+}
+
+func (s *RegistrySuite) TestNew() {
+	var got *Config
+	Register("noop", func(cfg *Config) (Logger, error) {
+		got = cfg
+		return Noop{}, nil
+	})
+	Register("broken", func(cfg *Config) (Logger, error) {
+		return nil, errors.New("broken")
+	})
+
+	cfg := DefaultConfig()
+	cfg.Backend = "noop"
+
+	logger, err := New(cfg)
+	s.Require().NoError(err)
+	s.Assert().Equal(Noop{}, logger)
+	s.Assert().Same(cfg, got)
+
+	cfg.Backend = "broken"
+	_, err = New(cfg)
+	s.Assert().EqualError(err, "broken")
+
+	cfg.Backend = "unknown"
+	_, err = New(cfg)
+	s.Assert().EqualError(err, `log: unknown backend "unknown" (forgotten import?), registered backends: [broken noop]`)
+
+	_, err = New(nil)
+	s.Assert().EqualError(err, "log: nil config")
+}
+
+func (s *RegistrySuite) TestRegisterPanics() {
+	factory := func(cfg *Config) (Logger, error) { return Noop{}, nil }
+	Register("noop", factory)
+
+	s.Assert().Panics(func() { Register("noop", factory) })
+	s.Assert().Panics(func() { Register("nil", nil) })
+	s.Assert().Equal([]string{"noop"}, Backends())
+}
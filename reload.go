@@ -0,0 +1,200 @@
+package log
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// reloadHandle holds the logger of the current configuration of a
+// ConfigWatcher. The reloadLoggers read it for each entry, under a read lock,
+// so that a reload, taking the lock, replaces and closes a logger no entry is
+// being written with.
+type reloadHandle struct {
+	mu     sync.RWMutex
+	logger Logger
+	gen    uint64 // incremented by each reload
+}
+
+// swap replaces the logger of h and returns the previous one.
+func (h *reloadHandle) swap(logger Logger) Logger {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	previous := h.logger
+	h.logger = logger
+	h.gen++
+	return previous
+}
+
+// reloadLogger is a Logger following the reloads of a ConfigWatcher: it writes
+// with the logger of the current configuration, derived as it was itself by
+// the With methods and FromContext.
+type reloadLogger struct {
+	handle *reloadHandle
+	derive []func(Logger) Logger
+	cache  atomic.Pointer[reloadCache]
+}
+
+// reloadCache is the derived logger of a generation of a reloadHandle.
+type reloadCache struct {
+	gen    uint64
+	logger Logger
+}
+
+// current returns the logger of the current configuration, derived as l was.
+// The handle must be read locked.
+func (l *reloadLogger) current() Logger {
+	if c := l.cache.Load(); c != nil && c.gen == l.handle.gen {
+		return c.logger
+	}
+
+	logger := l.handle.logger
+	for _, derive := range l.derive {
+		logger = derive(logger)
+	}
+	l.cache.Store(&reloadCache{gen: l.handle.gen, logger: logger})
+	return logger
+}
+
+// do calls f with the current logger, which is not closed by a reload
+// meanwhile.
+func (l *reloadLogger) do(f func(logger Logger)) {
+	l.handle.mu.RLock()
+	defer l.handle.mu.RUnlock()
+	f(l.current())
+}
+
+// with returns a reloadLogger deriving the loggers of l with derive.
+func (l *reloadLogger) with(derive func(Logger) Logger) Logger {
+	return &reloadLogger{
+		handle: l.handle,
+		derive: append(l.derive[:len(l.derive):len(l.derive)], derive),
+	}
+}
+
+func (l *reloadLogger) Printf(format string, args ...interface{}) {
+	l.do(func(logger Logger) { logger.Printf(format, args...) })
+}
+
+func (l *reloadLogger) Tracef(format string, args ...interface{}) {
+	l.do(func(logger Logger) { logger.Tracef(format, args...) })
+}
+
+func (l *reloadLogger) Trace(args ...interface{}) {
+	l.do(func(logger Logger) { logger.Trace(args...) })
+}
+
+func (l *reloadLogger) Debugf(format string, args ...interface{}) {
+	l.do(func(logger Logger) { logger.Debugf(format, args...) })
+}
+
+func (l *reloadLogger) Debug(args ...interface{}) {
+	l.do(func(logger Logger) { logger.Debug(args...) })
+}
+
+func (l *reloadLogger) Infof(format string, args ...interface{}) {
+	l.do(func(logger Logger) { logger.Infof(format, args...) })
+}
+
+func (l *reloadLogger) Info(args ...interface{}) {
+	l.do(func(logger Logger) { logger.Info(args...) })
+}
+
+func (l *reloadLogger) Warnf(format string, args ...interface{}) {
+	l.do(func(logger Logger) { logger.Warnf(format, args...) })
+}
+
+func (l *reloadLogger) Warn(args ...interface{}) {
+	l.do(func(logger Logger) { logger.Warn(args...) })
+}
+
+func (l *reloadLogger) Errorf(format string, args ...interface{}) {
+	l.do(func(logger Logger) { logger.Errorf(format, args...) })
+}
+
+func (l *reloadLogger) Error(args ...interface{}) {
+	l.do(func(logger Logger) { logger.Error(args...) })
+}
+
+func (l *reloadLogger) Fatalf(format string, args ...interface{}) {
+	l.do(func(logger Logger) { logger.Fatalf(format, args...) })
+}
+
+func (l *reloadLogger) Fatal(args ...interface{}) {
+	l.do(func(logger Logger) { logger.Fatal(args...) })
+}
+
+func (l *reloadLogger) Panicf(format string, args ...interface{}) {
+	l.do(func(logger Logger) { logger.Panicf(format, args...) })
+}
+
+func (l *reloadLogger) Panic(args ...interface{}) {
+	l.do(func(logger Logger) { logger.Panic(args...) })
+}
+
+func (l *reloadLogger) WithFields(keyValues map[string]interface{}) Logger {
+	fields := make(map[string]interface{}, len(keyValues))
+	for k, v := range keyValues {
+		fields[k] = v
+	}
+	return l.with(func(logger Logger) Logger { return logger.WithFields(fields) })
+}
+
+func (l *reloadLogger) WithField(key string, value interface{}) Logger {
+	return l.with(func(logger Logger) Logger { return logger.WithField(key, value) })
+}
+
+func (l *reloadLogger) WithError(err error) Logger {
+	return l.with(func(logger Logger) Logger { return logger.WithError(err) })
+}
+
+func (l *reloadLogger) WithTypeOf(obj interface{}) Logger {
+	return l.with(func(logger Logger) Logger { return logger.WithTypeOf(obj) })
+}
+
+func (l *reloadLogger) ToContext(ctx context.Context) context.Context {
+	l.do(func(logger Logger) { ctx = logger.ToContext(ctx) })
+	return ctx
+}
+
+func (l *reloadLogger) FromContext(ctx context.Context) Logger {
+	return l.with(func(logger Logger) Logger { return logger.FromContext(ctx) })
+}
+
+// Output returns the writer of the current logger, which is not followed by
+// the writer after a reload.
+func (l *reloadLogger) Output() io.Writer {
+	var w io.Writer
+	l.do(func(logger Logger) { w = logger.Output() })
+	return w
+}
+
+func (l *reloadLogger) Fields() Fields {
+	var fields Fields
+	l.do(func(logger Logger) { fields = logger.Fields() })
+	return fields
+}
+
+// Sync syncs the current logger, when it is a Closer.
+func (l *reloadLogger) Sync() error {
+	var err error
+	l.do(func(logger Logger) {
+		if c, ok := logger.(Closer); ok {
+			err = c.Sync()
+		}
+	})
+	return err
+}
+
+// Close closes the current logger, when it is a Closer.
+func (l *reloadLogger) Close() error {
+	var err error
+	l.do(func(logger Logger) {
+		if c, ok := logger.(Closer); ok {
+			err = c.Close()
+		}
+	})
+	return err
+}
@@ -0,0 +1,248 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const defaultWatchInterval = 2 * time.Second
+
+// LoadConfig reads a Config from a YAML or JSON file. Files with the .json
+// extension are decoded as JSON, any other file as YAML. The values are
+// decoded on top of DefaultConfig and unknown keys are reported as errors.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeConfig(path, data)
+}
+
+func decodeConfig(path string, data []byte) (*Config, error) {
+	cfg := DefaultConfig()
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(cfg); err != nil {
+			return nil, fmt.Errorf("log: decoding %s: %w", path, err)
+		}
+		return cfg, nil
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("log: decoding %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// ConfigWatcher keeps the global logger in sync with a configuration file.
+//
+// The file is polled and, whenever its content changes, the new configuration
+// is validated by building a logger with New, which replaces the logger of the
+// previous configuration. An invalid file keeps the previous configuration and
+// the reason is logged through it.
+//
+// The global logger follows the reloads: it writes with the logger of the
+// current configuration, and so do the loggers derived from it, such as those
+// returned by log.WithField or log.FromContext, even when they were retained
+// before a reload. The logger replaced by a reload is closed, when it
+// implements Closer, once no entry is being written with it, so that its
+// connections and goroutines are released. The files are shared by the
+// loggers writing to them, so the new logger keeps writing to an unchanged
+// file.
+type ConfigWatcher struct {
+	path     string
+	interval time.Duration
+	notify   func(cfg *Config, err error)
+
+	mu       sync.Mutex
+	handle   *reloadHandle
+	data     []byte
+	modTime  time.Time
+	size     int64
+	rejected string
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// WatchOption represents a ConfigWatcher option.
+type WatchOption func(w *ConfigWatcher)
+
+// WithWatchInterval sets how often the file is checked for changes.
+func WithWatchInterval(value time.Duration) WatchOption {
+	return func(w *ConfigWatcher) {
+		w.interval = value
+	}
+}
+
+// WithReloadNotify sets a function called after every reload attempt, with the
+// applied configuration or the reason it was rejected.
+func WithReloadNotify(value func(cfg *Config, err error)) WatchOption {
+	return func(w *ConfigWatcher) {
+		w.notify = value
+	}
+}
+
+// WatchConfig loads the configuration file at path, sets the global logger from
+// it and watches the file for changes until Close is called.
+// An error is returned, and nothing is watched, when the initial file is invalid.
+func WatchConfig(path string, option ...WatchOption) (*ConfigWatcher, error) {
+	w := &ConfigWatcher{
+		path:     path,
+		interval: defaultWatchInterval,
+		handle:   &reloadHandle{},
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	for _, o := range option {
+		o(w)
+	}
+
+	if err := w.Reload(); err != nil {
+		return nil, err
+	}
+
+	go w.run()
+
+	return w, nil
+}
+
+// Reload reads and applies the configuration file, regardless of it having
+// changed. The global logger is left untouched when an error is returned.
+func (w *ConfigWatcher) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		return err
+	}
+
+	return w.apply(info, data)
+}
+
+// Close stops watching the file.
+func (w *ConfigWatcher) Close() error {
+	w.once.Do(func() {
+		close(w.stop)
+	})
+	<-w.done
+	return nil
+}
+
+func (w *ConfigWatcher) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.check()
+		}
+	}
+}
+
+func (w *ConfigWatcher) check() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	info, err := os.Stat(w.path)
+	if err != nil {
+		w.reject(nil, err)
+		return
+	}
+
+	if info.ModTime().Equal(w.modTime) && info.Size() == w.size {
+		return
+	}
+
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		w.reject(nil, err)
+		return
+	}
+
+	if bytes.Equal(data, w.data) {
+		w.modTime, w.size = info.ModTime(), info.Size()
+		return
+	}
+
+	if err := w.apply(info, data); err != nil {
+		w.reject(data, err)
+	}
+}
+
+func (w *ConfigWatcher) apply(info os.FileInfo, data []byte) error {
+	cfg, err := decodeConfig(w.path, data)
+	if err != nil {
+		return err
+	}
+
+	logger, err := New(cfg)
+	if err != nil {
+		return err
+	}
+
+	// New made the new logger the global one
+	previous := w.handle.swap(logger)
+	SetGlobalLogger(&reloadLogger{handle: w.handle})
+	if c, ok := previous.(Closer); ok {
+		if err := c.Close(); err != nil {
+			Errorf("log: closing the logger of the previous configuration of %s: %v", w.path, err)
+		}
+	}
+
+	w.data, w.modTime, w.size = data, info.ModTime(), info.Size()
+	w.rejected = ""
+	if w.notify != nil {
+		w.notify(cfg, nil)
+	}
+	return nil
+}
+
+// reject logs why the file was not applied. The content is remembered and
+// repeated errors are skipped, so that the same problem is reported only once.
+func (w *ConfigWatcher) reject(data []byte, err error) {
+	if data != nil {
+		w.data = data
+	}
+	if info, serr := os.Stat(w.path); serr == nil {
+		w.modTime, w.size = info.ModTime(), info.Size()
+	}
+
+	if err.Error() == w.rejected {
+		return
+	}
+	w.rejected = err.Error()
+
+	Errorf("log: keeping previous configuration, %s could not be applied: %v", w.path, err)
+	if w.notify != nil {
+		w.notify(nil, err)
+	}
+}
@@ -0,0 +1,263 @@
+package log
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type WatchSuite struct {
+	suite.Suite
+	dir     string
+	applied chan *Config
+	rejects chan error
+}
+
+func TestWatchSuite(t *testing.T) {
+	suite.Run(t, new(WatchSuite))
+}
+
+// recorder is a Logger registered as the "recorder" backend. It keeps the
+// Config it was built from, the messages logged at error level and its fields.
+type recorder struct {
+	Noop
+	cfg    *Config
+	errors chan string
+	fields Fields
+	closed int
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors <- format
+}
+
+// WithField returns a recorder with the field, recording the messages of r.
+func (r *recorder) WithField(key string, value interface{}) Logger {
+	fields := Fields{key: value}
+	for k, v := range r.fields {
+		if k != key {
+			fields[k] = v
+		}
+	}
+	return &recorder{cfg: r.cfg, errors: r.errors, fields: fields}
+}
+
+// WithFields returns a recorder with the fields, recording the messages of r.
+func (r *recorder) WithFields(keyValues map[string]interface{}) Logger {
+	var logger Logger = r
+	for k, v := range keyValues {
+		logger = logger.WithField(k, v)
+	}
+	return logger
+}
+
+func (r *recorder) Fields() Fields {
+	return r.fields
+}
+
+func (r *recorder) Sync() error {
+	return nil
+}
+
+func (r *recorder) Close() error {
+	r.closed++
+	return nil
+}
+
+func (s *WatchSuite) SetupTest() {
+	factoriesMu.Lock()
+	factories = map[string]Factory{
+		"recorder": func(cfg *Config) (Logger, error) {
+			if cfg.Console.Level == "LOUD" {
+				return nil, errors.New("unknown level")
+			}
+			r := &recorder{cfg: cfg, errors: make(chan string, 10)}
+			SetGlobalLogger(r)
+			return r, nil
+		},
+	}
+	factoriesMu.Unlock()
+
+	s.dir = s.T().TempDir()
+	s.applied = make(chan *Config, 10)
+	s.rejects = make(chan error, 10)
+}
+
+func (s *WatchSuite) TearDownTest() {
+	SetGlobalLogger(Noop{})
+}
+
+func (s *WatchSuite) write(name string, content string) string {
+	path := filepath.Join(s.dir, name)
+	s.Require().NoError(os.WriteFile(path, []byte(content), 0o600))
+	// make sure the modification time changes on coarse file systems
+	later := time.Now().Add(time.Duration(len(content)) * time.Second)
+	s.Require().NoError(os.Chtimes(path, later, later))
+	return path
+}
+
+// current returns the recorder of the current configuration, which the global
+// logger writes with.
+func (s *WatchSuite) current() *recorder {
+	return GetLogger().(*reloadLogger).handle.logger.(*recorder)
+}
+
+func (s *WatchSuite) watch(path string) *ConfigWatcher {
+	w, err := WatchConfig(path,
+		WithWatchInterval(5*time.Millisecond),
+		WithReloadNotify(func(cfg *Config, err error) {
+			if err != nil {
+				s.rejects <- err
+				return
+			}
+			s.applied <- cfg
+		}),
+	)
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { w.Close() })
+	<-s.applied
+	return w
+}
+
+func (s *WatchSuite) TestLoadConfig() {
+	yamlPath := s.write("log.yaml", "backend: recorder\nconsole:\n  level: DEBUG\nfile:\n  enabled: true\n  maxSize: 10\nnetwork:\n  maxBackoff: 1m\n")
+	cfg, err := LoadConfig(yamlPath)
+	s.Require().NoError(err)
+
+	want := DefaultConfig()
+	want.Backend = "recorder"
+	want.Console.Level = "DEBUG"
+	want.File.Enabled = true
+	want.File.MaxSize = 10
+	want.Network.MaxBackoff = time.Minute
+	s.Assert().Equal(want, cfg)
+
+	jsonPath := s.write("log.json", `{"backend": "recorder", "console": {"level": "DEBUG"}, "file": {"enabled": true, "maxSize": 10}, "network": {"maxBackoff": 60000000000}}`)
+	cfg, err = LoadConfig(jsonPath)
+	s.Require().NoError(err)
+	s.Assert().Equal(want, cfg)
+
+	cfg, err = LoadConfig(s.write("empty.yml", ""))
+	s.Require().NoError(err)
+	s.Assert().Equal(DefaultConfig(), cfg)
+}
+
+func (s *WatchSuite) TestLoadConfigErrors() {
+	_, err := LoadConfig(filepath.Join(s.dir, "missing.yaml"))
+	s.Assert().Error(err)
+
+	_, err = LoadConfig(s.write("unknown.yaml", "backend: recorder\nlevle: DEBUG\n"))
+	s.Assert().ErrorContains(err, "levle")
+
+	_, err = LoadConfig(s.write("unknown.json", `{"backend": "recorder", "levle": "DEBUG"}`))
+	s.Assert().ErrorContains(err, "levle")
+
+	_, err = LoadConfig(s.write("broken.json", `{"backend": `))
+	s.Assert().Error(err)
+}
+
+func (s *WatchSuite) TestWatchConfig() {
+	path := s.write("log.yaml", "backend: recorder\n")
+	s.watch(path)
+	s.Assert().Equal("INFO", s.current().cfg.Console.Level)
+
+	s.write("log.yaml", "backend: recorder\nconsole:\n  level: DEBUG\n")
+	cfg := <-s.applied
+	s.Assert().Equal("DEBUG", cfg.Console.Level)
+	s.Assert().Same(cfg, s.current().cfg)
+}
+
+func (s *WatchSuite) TestWatchConfigClosesReplacedLogger() {
+	path := s.write("log.yaml", "backend: recorder\n")
+	w := s.watch(path)
+	first := s.current()
+
+	s.write("log.yaml", "backend: recorder\nconsole:\n  level: LOUD\n")
+	s.Assert().Error(<-s.rejects)
+	s.Assert().Zero(first.closed)
+
+	s.write("log.yaml", "backend: recorder\nconsole:\n  level: DEBUG\n")
+	<-s.applied
+	second := s.current()
+	s.Assert().Equal(1, first.closed)
+	s.Assert().Zero(second.closed)
+
+	s.Require().NoError(w.Reload())
+	<-s.applied
+	s.Assert().Equal(1, first.closed)
+	s.Assert().Equal(1, second.closed)
+	s.Assert().Zero(s.current().closed)
+}
+
+func (s *WatchSuite) TestWatchConfigReloadsDerivedLoggers() {
+	path := s.write("log.yaml", "backend: recorder\n")
+	s.watch(path)
+	first := s.current()
+	derived := WithField("ID", "1")
+
+	s.write("log.yaml", "backend: recorder\nconsole:\n  level: DEBUG\n")
+	<-s.applied
+	second := s.current()
+	s.Require().Equal(1, first.closed)
+
+	// the logger retained before the reload writes with the new configuration
+	derived.Errorf("after the reload")
+	s.Assert().Equal("after the reload", <-second.errors)
+	s.Assert().Empty(first.errors)
+}
+
+func (s *WatchSuite) TestWatchConfigReloadsRedactedFields() {
+	path := s.write("log.yaml", "backend: recorder\n")
+	s.watch(path)
+	derived := WithField("password", "secret")
+	s.Assert().Equal(Fields{"password": "secret"}, derived.Fields())
+
+	s.write("log.yaml", "backend: recorder\nredactedFields: [password]\n")
+	<-s.applied
+	s.Assert().Equal(Fields{"password": Redacted}, derived.Fields())
+}
+
+func (s *WatchSuite) TestWatchConfigKeepsPreviousWhenInvalid() {
+	path := s.write("log.yaml", "backend: recorder\n")
+	s.watch(path)
+	previous := s.current()
+
+	s.write("log.yaml", "backend: recorder\nconsole:\n  level: LOUD\n")
+	s.Assert().ErrorContains(<-s.rejects, "unknown level")
+	s.Assert().Same(previous, s.current())
+	s.Assert().Contains(<-previous.errors, "keeping previous configuration")
+
+	s.write("log.yaml", "backend: [")
+	s.Assert().Error(<-s.rejects)
+	s.Assert().Same(previous, s.current())
+
+	s.write("log.yaml", "backend: recorder\nconsole:\n  level: WARN\n")
+	s.Assert().Equal("WARN", (<-s.applied).Console.Level)
+	s.Assert().NotSame(previous, s.current())
+}
+
+func (s *WatchSuite) TestWatchConfigInvalidInitialFile() {
+	_, err := WatchConfig(s.write("log.yaml", "backend: unknown\n"))
+	s.Assert().ErrorContains(err, "unknown backend")
+
+	_, err = WatchConfig(filepath.Join(s.dir, "missing.yaml"))
+	s.Assert().Error(err)
+}
+
+func (s *WatchSuite) TestReload() {
+	path := s.write("log.yaml", "backend: recorder\n")
+	w := s.watch(path)
+	s.Require().NoError(w.Close())
+
+	s.Require().NoError(os.WriteFile(path, []byte("backend: recorder\nconsole:\n  level: ERROR\n"), 0o600))
+	s.Require().NoError(w.Reload())
+	s.Assert().Equal("ERROR", (<-s.applied).Console.Level)
+
+	s.Require().NoError(os.WriteFile(path, []byte("backend: recorder\nconsole:\n  level: LOUD\n"), 0o600))
+	s.Assert().Error(w.Reload())
+	s.Assert().Equal("ERROR", s.current().cfg.Console.Level)
+}
@@ -1,128 +1,160 @@
 package log
 
+import "sync/atomic"
+
 // A global variable so that l functions can be directly accessed.
+// It is swapped atomically, so the global logger can be replaced while in use.
 var (
-	l Logger = Noop{}
+	global atomic.Pointer[Logger]
 )
 
+func init() {
+	SetGlobalLogger(Noop{})
+}
+
+func l() Logger {
+	return *global.Load()
+}
+
 // NewLogger returns an instance of logger.
 // Deprecated: prefer SetGlobalLogger
 func NewLogger(logger Logger) {
-	l = logger
+	SetGlobalLogger(logger)
 }
 
+// SetGlobalLogger sets the logger used by the package level functions.
+// It is safe to call while other goroutines are logging.
 func SetGlobalLogger(logger Logger) {
-	l = logger
+	global.Store(&logger)
 }
 
 // Printf logs a templated message.
 //
 // For templating details see implementation doc.
 func Printf(format string, args ...interface{}) {
-	l.Printf(format, args...)
+	l().Printf(format, args...)
 }
 
 // Tracef logs a templated message at trace level.
 //
 // For templating details see implementation doc.
 func Tracef(format string, args ...interface{}) {
-	l.Tracef(format, args...)
+	l().Tracef(format, args...)
 }
 
 // Trace logs a message at trace level.
 func Trace(args ...interface{}) {
-	l.Trace(args...)
+	l().Trace(args...)
 }
 
 // Debugf logs a templated message at debug level.
 //
 // For templating details see implementation doc.
 func Debugf(format string, args ...interface{}) {
-	l.Debugf(format, args...)
+	l().Debugf(format, args...)
 }
 
 // Debug logs a message at debug level.
 func Debug(args ...interface{}) {
-	l.Debug(args...)
+	l().Debug(args...)
 }
 
 // Infof logs a templated message at info level.
 //
 // For templating details see implementation doc.
 func Infof(format string, args ...interface{}) {
-	l.Infof(format, args...)
+	l().Infof(format, args...)
 }
 
 // Info logs a message at info level.
 func Info(args ...interface{}) {
-	l.Info(args...)
+	l().Info(args...)
 }
 
 // Warnf logs a templated message at warn level.
 //
 // For templating details see implementation doc.
 func Warnf(format string, args ...interface{}) {
-	l.Warnf(format, args...)
+	l().Warnf(format, args...)
 }
 
 // Warn logs a message at warn level.
 func Warn(args ...interface{}) {
-	l.Warn(args...)
+	l().Warn(args...)
 }
 
 // Errorf logs a templated message at error level.
 //
 // For templating details see implementation doc.
 func Errorf(format string, args ...interface{}) {
-	l.Errorf(format, args...)
+	l().Errorf(format, args...)
 }
 
 // Error logs a message at error level.
 func Error(args ...interface{}) {
-	l.Error(args...)
+	l().Error(args...)
 }
 
 // Panicf is equivalent to Printf() followed by a call to panic().
 func Panicf(format string, args ...interface{}) {
-	l.Panicf(format, args...)
+	l().Panicf(format, args...)
 }
 
 // Panic is equivalent to Print() followed by a call to panic().
 func Panic(args ...interface{}) {
-	l.Panic(args...)
+	l().Panic(args...)
 }
 
 // Fatal is equivalent to Print() followed by a call to os.Exit(1).
 func Fatal(args ...interface{}) {
-	l.Fatal(args...)
+	l().Fatal(args...)
 }
 
 // Fatalf is equivalent to Printf() followed by a call to os.Exit(1).
 func Fatalf(format string, args ...interface{}) {
-	l.Fatalf(format, args...)
+	l().Fatalf(format, args...)
 }
 
 // WithField adds a key and value to logger.
 func WithField(key string, value interface{}) Logger {
-	return l.WithField(key, value)
+	return l().WithField(key, value)
 }
 
 // WithError adds an error as a field to logger
 func WithError(err error) Logger {
-	return l.WithError(err)
+	return l().WithError(err)
 }
 
 // WithFields adds fields to logger.
 func WithFields(keyValues map[string]interface{}) Logger {
-	return l.WithFields(keyValues)
+	return l().WithFields(keyValues)
 }
 
 // WithTypeOf adds type information to logger.
 func WithTypeOf(obj interface{}) Logger {
-	return l.WithTypeOf(obj)
+	return l().WithTypeOf(obj)
 }
 
 // GetLogger returns instance of Logger.
 func GetLogger() Logger {
-	return l
+	return l()
+}
+
+// Sync sends the entries buffered by the outputs of the global logger, such as
+// the batches of the network outputs, and waits for them to be sent. It does
+// nothing when the global logger is not a Closer.
+func Sync() error {
+	if c, ok := l().(Closer); ok {
+		return c.Sync()
+	}
+	return nil
+}
+
+// Close syncs the global logger then closes its outputs, e.g. before the
+// process exits. It does nothing when the global logger is not a Closer.
+func Close() error {
+	if c, ok := l().(Closer); ok {
+		return c.Close()
+	}
+	return nil
 }
@@ -159,6 +159,36 @@ func (s *WrapperSuite) TestWrapperGetLogger() {
 	}
 }
 
+// closing is a Logger recording its Sync and Close calls.
+type closing struct {
+	Noop
+	calls []string
+}
+
+func (c *closing) Sync() error {
+	c.calls = append(c.calls, "Sync")
+	return nil
+}
+
+func (c *closing) Close() error {
+	c.calls = append(c.calls, "Close")
+	return errors.New("closed")
+}
+
+func (s *WrapperSuite) TestWrapperSyncClose() {
+	defer SetGlobalLogger(Noop{})
+
+	SetGlobalLogger(Noop{})
+	s.Assert().NoError(Sync())
+	s.Assert().NoError(Close())
+
+	c := &closing{}
+	SetGlobalLogger(c)
+	s.Assert().NoError(Sync())
+	s.Assert().EqualError(Close(), "closed")
+	s.Assert().Equal([]string{"Sync", "Close"}, c.calls)
+}
+
 // MOCK ------------------------------------------------------------
 // LoggerMock is an autogenerated mock type for the LoggerMock type
 type LoggerMock struct {